@@ -44,6 +44,8 @@ type options struct {
 	disableCertRotation  bool
 	disableMutation      bool
 	disableCRDManager    bool
+	registryHookAddress  string
+	registryHookToken    string
 	verifyTimeout        time.Duration
 	mutateTimeout        time.Duration
 }
@@ -60,6 +62,8 @@ func parse() *options {
 	flag.BoolVar(&opts.disableCertRotation, "disable-cert-rotation", false, "Disable certificate rotation")
 	flag.BoolVar(&opts.disableMutation, "disable-mutation", false, "Disable mutation wehbook")
 	flag.BoolVar(&opts.disableCRDManager, "disable-crd-manager", false, "Disable CRD manager for Gatekeeper provider")
+	flag.StringVar(&opts.registryHookAddress, "registry-webhook-address", "", "Address of the registry webhook invalidating cached referrers, e.g. :6002. Disabled if empty")
+	flag.StringVar(&opts.registryHookToken, "registry-webhook-token-file", "", "Path to the file holding the bearer token of the registry webhook")
 
 	flag.Parse()
 	logrus.Infof("Starting Ratify with options: %+v", opts)
@@ -75,15 +79,17 @@ func startRatify(opts *options) error {
 		certRotatorReady = make(chan struct{})
 	}
 	serverOpts := &httpserver.ServerOptions{
-		HTTPServerAddress:    opts.httpServerAddress,
-		CertFile:             opts.certFile,
-		KeyFile:              opts.keyFile,
-		GatekeeperCACertFile: opts.gatekeeperCACertFile,
-		VerifyTimeout:        opts.verifyTimeout,
-		MutateTimeout:        opts.mutateTimeout,
-		DisableMutation:      opts.disableMutation,
-		DisableCRDManager:    opts.disableCRDManager,
-		CertRotatorReady:     certRotatorReady,
+		HTTPServerAddress:        opts.httpServerAddress,
+		CertFile:                 opts.certFile,
+		KeyFile:                  opts.keyFile,
+		GatekeeperCACertFile:     opts.gatekeeperCACertFile,
+		VerifyTimeout:            opts.verifyTimeout,
+		MutateTimeout:            opts.mutateTimeout,
+		DisableMutation:          opts.disableMutation,
+		DisableCRDManager:        opts.disableCRDManager,
+		RegistryWebhookAddress:   opts.registryHookAddress,
+		RegistryWebhookTokenFile: opts.registryHookToken,
+		CertRotatorReady:         certRotatorReady,
 	}
 
 	go startManagerFunc(certRotatorReady, serverOpts.DisableMutation, serverOpts.DisableCRDManager)
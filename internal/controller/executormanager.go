@@ -26,6 +26,7 @@ import (
 	pf "github.com/notaryproject/ratify/v2/internal/policyenforcer/factory"
 	sf "github.com/notaryproject/ratify/v2/internal/store/factory"
 	vf "github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	"github.com/sirupsen/logrus"
 )
 
 // executorManager manages the lifecycle of executor instances across different
@@ -105,7 +106,11 @@ func (m *executorManager) refreshExecutor() error {
 		return fmt.Errorf("failed to create executor: %w", err)
 	}
 
-	m.executor.Store(executor)
+	if old := m.executor.Swap(executor); old != nil {
+		if err := old.Close(); err != nil {
+			logrus.Warnf("failed to close previous executor: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -17,7 +17,9 @@ package executor
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
 
 	"github.com/notaryproject/ratify-go"
@@ -25,6 +27,9 @@ import (
 	policyFactory "github.com/notaryproject/ratify/v2/internal/policyenforcer/factory"
 	"github.com/notaryproject/ratify/v2/internal/store"
 	storeFactory "github.com/notaryproject/ratify/v2/internal/store/factory"
+	"github.com/notaryproject/ratify/v2/internal/store/latestreferrers"
+	"github.com/notaryproject/ratify/v2/internal/store/registryhistory"
+	"github.com/notaryproject/ratify/v2/internal/store/subjectreferrer"
 	"github.com/notaryproject/ratify/v2/internal/verifier"
 	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
@@ -48,6 +53,33 @@ type ScopedOptions struct {
 	// Policy contains the configuration options for the policy enforcer.
 	// Optional.
 	Policy *policyFactory.NewPolicyEnforcerOptions `json:"policyEnforcer,omitempty"`
+
+	// LatestReferrers limits the referrers of the subject verified per
+	// artifact type to the newest ones. Nested referrers and listings by
+	// verifiers are not limited. Skipped referrers are listed in the
+	// validation result.
+	// Optional. If not provided, all referrers are verified.
+	LatestReferrers *latestreferrers.Options `json:"latestReferrers,omitempty"`
+
+	// IndexMode selects what is verified for image index subjects: "index"
+	// verifies the index itself, "platform" verifies the manifest of the
+	// requested platform and "all" verifies the manifests of all platforms.
+	// Optional. Defaults to "index".
+	IndexMode string `json:"indexMode,omitempty"`
+
+	// DefaultPlatform is the platform verified in "platform" index mode and
+	// resolved in "platform-digest" mutation style if the request does not
+	// specify one, e.g. "linux/arm64/v8". Optional. Defaults to the platform
+	// Ratify runs on. Required for "platform-digest" mutation style.
+	DefaultPlatform string `json:"defaultPlatform,omitempty"`
+
+	// MutationStyle selects how image references are mutated: "digest-only"
+	// replaces the tag with the digest, "retain-mutated-tag" adds the digest
+	// to the tag and "platform-digest" pins the manifest of the requested
+	// platform. As Gatekeeper mutation keys usually carry no platform,
+	// "platform-digest" pins the default platform and is only safe on
+	// single-arch clusters. Optional. Defaults to "digest-only".
+	MutationStyle string `json:"mutationStyle,omitempty"`
 }
 
 // Options contains the configuration options to create a scoped executor.
@@ -59,6 +91,16 @@ type Options struct {
 	Executors []*ScopedOptions `json:"executors"`
 }
 
+// ValidateArtifactOptions contains the optional parameters of an artifact
+// validation request.
+type ValidateArtifactOptions struct {
+	// Platform is the platform of the workload running the artifact, e.g.
+	// derived from the node selector of the Pod. It selects the manifest
+	// verified for image indexes in "platform" index mode. Optional. Missing
+	// fields default to the configured default platform.
+	Platform *ocispec.Platform
+}
+
 // ScopedExecutor manages multiple ratify.Executor instances, each associated
 // with specific scopes (registries or repositories). It provides a mechanism to
 // route artifact validation requests to the appropriate executor based on the
@@ -81,29 +123,70 @@ type ScopedExecutor struct {
 	wildcard   map[string]*ratify.Executor
 	registry   map[string]*ratify.Executor
 	repository map[string]*ratify.Executor
+
+	// indexOptions are the index options of the executors verifying the
+	// manifests of image indexes instead of the indexes themselves.
+	indexOptions map[*ratify.Executor]*indexOptions
+
+	// mutationStyles are the mutation styles of the executors not using the
+	// default mutation style.
+	mutationStyles map[*ratify.Executor]string
+
+	// platformOptions are the options to resolve the platform manifest of the
+	// executors using the "platform-digest" mutation style.
+	platformOptions map[*ratify.Executor]*indexOptions
+
+	// stores are the store muxes of the executors.
+	stores []*store.Mux
 }
 
 // NewScopedExecutor creates a new ScopedExecutor instance based on the provided
 // options. It initializes the executor for each scope defined in the options.
 // If no executors are provided, it returns an error.
-func NewScopedExecutor(opts *Options) (*ScopedExecutor, error) {
+func NewScopedExecutor(opts *Options) (_ *ScopedExecutor, err error) {
 	if opts == nil || len(opts.Executors) == 0 {
 		return nil, fmt.Errorf("at least 1 executor should be provided")
 	}
 	scopedExecutor := &ScopedExecutor{
-		wildcard:   make(map[string]*ratify.Executor),
-		registry:   make(map[string]*ratify.Executor),
-		repository: make(map[string]*ratify.Executor),
+		wildcard:        make(map[string]*ratify.Executor),
+		registry:        make(map[string]*ratify.Executor),
+		repository:      make(map[string]*ratify.Executor),
+		indexOptions:    make(map[*ratify.Executor]*indexOptions),
+		mutationStyles:  make(map[*ratify.Executor]string),
+		platformOptions: make(map[*ratify.Executor]*indexOptions),
 	}
+	defer func() {
+		if err != nil {
+			scopedExecutor.Close()
+		}
+	}()
 
 	for _, executorOpts := range opts.Executors {
 		if len(executorOpts.Scopes) == 0 {
 			return nil, fmt.Errorf("executor options must contain at least one scope")
 		}
-		executor, err := newExecutor(executorOpts)
+		indexOpts, err := newIndexOptions(executorOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create executor: %w", err)
+		}
+		platformOpts, err := newMutationOptions(executorOpts)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create executor: %w", err)
 		}
+		executor, storeMux, err := newExecutor(executorOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create executor: %w", err)
+		}
+		scopedExecutor.stores = append(scopedExecutor.stores, storeMux)
+		if indexOpts != nil {
+			scopedExecutor.indexOptions[executor] = indexOpts
+		}
+		if executorOpts.MutationStyle != "" {
+			scopedExecutor.mutationStyles[executor] = executorOpts.MutationStyle
+		}
+		if platformOpts != nil {
+			scopedExecutor.platformOptions[executor] = platformOpts
+		}
 		for _, scope := range executorOpts.Scopes {
 			if err = scopedExecutor.registerExecutor(scope, executor); err != nil {
 				return nil, fmt.Errorf("failed to register executor for scope %q: %w", scope, err)
@@ -114,38 +197,132 @@ func NewScopedExecutor(opts *Options) (*ScopedExecutor, error) {
 }
 
 // newExecutor creates a new [ratify.Executor] instance based on the provided
-// options.
-func newExecutor(opts *ScopedOptions) (*ratify.Executor, error) {
+// options. It also returns the store mux of the executor.
+func newExecutor(opts *ScopedOptions) (_ *ratify.Executor, _ *store.Mux, err error) {
 	verifiers, err := verifier.NewVerifiers(opts.Verifiers)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	storeMux, err := store.NewStore(opts.Stores, opts.Scopes)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
+	}
+	defer func() {
+		if err != nil {
+			storeMux.Close()
+		}
+	}()
+	var executorStore ratify.Store = storeMux
+	if opts.LatestReferrers != nil {
+		if executorStore, err = latestreferrers.New(executorStore, opts.LatestReferrers); err != nil {
+			return nil, nil, err
+		}
+	}
+	if slices.ContainsFunc(verifiers, func(v ratify.Verifier) bool {
+		return v.Verifiable(subjectreferrer.Descriptor(""))
+	}) {
+		// list the subject as a referrer of itself for verifiers evaluating
+		// the subject image.
+		if executorStore, err = subjectreferrer.New(executorStore); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	policy, err := policyenforcer.NewPolicyEnforcer(opts.Policy)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	return ratify.NewExecutor(storeMux, verifiers, policy)
+	executor, err := ratify.NewExecutor(executorStore, verifiers, policy)
+	if err != nil {
+		return nil, nil, err
+	}
+	return executor, storeMux, nil
 }
 
 // ValidateArtifact routes the artifact validation request to the appropriate
 // executor based on the artifact's reference. It returns the validation result
-// or an error if no matching executor is found.
-func (s *ScopedExecutor) ValidateArtifact(ctx context.Context, artifact string) (*ratify.ValidationResult, error) {
+// or an error if no matching executor is found. Registry requests still
+// failing after all retries fail the validation instead of returning an error,
+// and retried or throttled registry requests are reported in the result.
+func (s *ScopedExecutor) ValidateArtifact(ctx context.Context, artifact string, opts ValidateArtifactOptions) (*ratify.ValidationResult, error) {
 	executor, err := s.matchExecutor(artifact)
 	if err != nil {
 		return nil, fmt.Errorf("failed to match executor for artifact %q: %w", artifact, err)
 	}
+	ctx = registryhistory.WithValidation(ctx)
+	result, err := s.validateArtifact(ctx, executor, artifact, opts)
+	if err != nil {
+		if !errors.Is(err, registryhistory.ErrRetriesExhausted) {
+			return nil, err
+		}
+		result = registryhistory.FailedResult(artifact, err)
+	}
+	registryhistory.AddReport(ctx, artifact, result)
+	return result, nil
+}
+
+// validateArtifact validates the artifact with the executor.
+func (s *ScopedExecutor) validateArtifact(ctx context.Context, executor *ratify.Executor, artifact string, opts ValidateArtifactOptions) (*ratify.ValidationResult, error) {
+	if indexOpts, ok := s.indexOptions[executor]; ok {
+		return indexOpts.validateIndex(ctx, executor, artifact, opts.Platform)
+	}
+	ref, _, err := resolveSubject(ctx, executor.Store, artifact)
+	if err != nil {
+		return nil, err
+	}
+	return validate(ctx, executor, ref.String())
+}
+
+// validate validates the subject with the executor. The subject must be a
+// digested reference so that the stores can tell listings of its referrers
+// apart from listings of nested artifacts.
+func validate(ctx context.Context, executor *ratify.Executor, subject string) (*ratify.ValidationResult, error) {
 	opts := ratify.ValidateArtifactOptions{
-		Subject: artifact,
+		Subject: subject,
+	}
+	ctx = latestreferrers.WithValidation(subjectreferrer.WithValidation(ctx, subject), subject)
+	result, err := executor.ValidateArtifact(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	latestreferrers.AddSkippedReports(ctx, result)
+	return result, nil
+}
+
+// resolveSubject resolves the artifact and returns its reference pinned to
+// the resolved digest.
+func resolveSubject(ctx context.Context, store ratify.Store, artifact string) (registry.Reference, ocispec.Descriptor, error) {
+	ref, err := registry.ParseReference(artifact)
+	if err != nil {
+		return registry.Reference{}, ocispec.Descriptor{}, fmt.Errorf("failed to parse subject reference %s: %w", artifact, err)
+	}
+	desc, err := store.Resolve(ctx, artifact)
+	if err != nil {
+		return registry.Reference{}, ocispec.Descriptor{}, fmt.Errorf("failed to resolve subject reference %s: %w", artifact, err)
+	}
+	ref.Reference = desc.Digest.String()
+	return ref, desc, nil
+}
+
+// InvalidateReferrers drops the cached referrers listings of all subjects in
+// the given repository, e.g. "registry.example.com/namespace/repo", from the
+// referrers caches of all executors.
+func (s *ScopedExecutor) InvalidateReferrers(repository string) {
+	for _, storeMux := range s.stores {
+		storeMux.InvalidateReferrers(repository)
+	}
+}
+
+// Close releases the resources held by the stores of all executors. It is
+// called once the ScopedExecutor is replaced or no longer used.
+func (s *ScopedExecutor) Close() error {
+	var errs []error
+	for _, storeMux := range s.stores {
+		errs = append(errs, storeMux.Close())
 	}
-	return executor.ValidateArtifact(ctx, opts)
+	return errors.Join(errs...)
 }
 
 // Resolve retrieves the descriptor for the specified artifact by routing the
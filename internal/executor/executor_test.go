@@ -17,12 +17,17 @@ package executor
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"testing"
 
 	"github.com/notaryproject/ratify-go"
 
 	ef "github.com/notaryproject/ratify/v2/internal/policyenforcer/factory"
 	sf "github.com/notaryproject/ratify/v2/internal/store/factory"
+	"github.com/notaryproject/ratify/v2/internal/store/latestreferrers"
+	"github.com/notaryproject/ratify/v2/internal/store/registryhistory"
+	"github.com/notaryproject/ratify/v2/internal/store/subjectreferrer"
 	vf "github.com/notaryproject/ratify/v2/internal/verifier/factory"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
@@ -232,6 +237,83 @@ func TestNewExecutor(t *testing.T) {
 			expectErr:      false,
 			expectExecutor: true,
 		},
+		{
+			name: "invalid index mode",
+			opts: &Options{
+				Executors: []*ScopedOptions{
+					{
+						Scopes: []string{"test"},
+						Verifiers: []*vf.NewVerifierOptions{
+							{
+								Name: mockVerifierName,
+								Type: mockVerifierType,
+							},
+						},
+						Stores: []*sf.NewStoreOptions{
+							{
+								Type:   mockStoreType,
+								Scopes: []string{"test"},
+							},
+						},
+						IndexMode: "manifest",
+					},
+				},
+			},
+			expectErr:      true,
+			expectExecutor: false,
+		},
+		{
+			name: "invalid mutation style",
+			opts: &Options{
+				Executors: []*ScopedOptions{
+					{
+						Scopes: []string{"test"},
+						Verifiers: []*vf.NewVerifierOptions{
+							{
+								Name: mockVerifierName,
+								Type: mockVerifierType,
+							},
+						},
+						Stores: []*sf.NewStoreOptions{
+							{
+								Type:   mockStoreType,
+								Scopes: []string{"test"},
+							},
+						},
+						MutationStyle: "tag-only",
+					},
+				},
+			},
+			expectErr:      true,
+			expectExecutor: false,
+		},
+		{
+			name: "platform index mode and mutation style",
+			opts: &Options{
+				Executors: []*ScopedOptions{
+					{
+						Scopes: []string{"test"},
+						Verifiers: []*vf.NewVerifierOptions{
+							{
+								Name: mockVerifierName,
+								Type: mockVerifierType,
+							},
+						},
+						Stores: []*sf.NewStoreOptions{
+							{
+								Type:   mockStoreType,
+								Scopes: []string{"test"},
+							},
+						},
+						IndexMode:       IndexModePlatform,
+						DefaultPlatform: "linux/amd64",
+						MutationStyle:   MutationStylePlatformDigest,
+					},
+				},
+			},
+			expectErr:      false,
+			expectExecutor: true,
+		},
 	}
 
 	for _, test := range tests {
@@ -247,6 +329,95 @@ func TestNewExecutor(t *testing.T) {
 	}
 }
 
+func TestNewExecutor_SubjectReferrer(t *testing.T) {
+	const (
+		storeType    = "mock-subject-store"
+		verifierType = "mock-subject-verifier"
+	)
+	sf.RegisterStoreFactory(storeType, newMockStore)
+	vf.RegisterVerifierFactory(verifierType, createMockVerifier)
+
+	executor, _, err := newExecutor(&ScopedOptions{
+		Scopes: []string{"example.com"},
+		Verifiers: []*vf.NewVerifierOptions{
+			{
+				Name: mockVerifierName,
+				Type: verifierType,
+			},
+		},
+		Stores: []*sf.NewStoreOptions{
+			{
+				Type: storeType,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := executor.Store.(*subjectreferrer.Store); !ok {
+		t.Errorf("expected subject referrer store for verifiers of the subject, got %T", executor.Store)
+	}
+}
+
+func TestNewExecutor_LatestReferrers(t *testing.T) {
+	const (
+		storeType    = "mock-latest-store"
+		verifierType = "mock-latest-verifier"
+	)
+	sf.RegisterStoreFactory(storeType, newMockStore)
+	vf.RegisterVerifierFactory(verifierType, createMockVerifier)
+
+	tests := []struct {
+		name      string
+		opts      *latestreferrers.Options
+		expectErr bool
+	}{
+		{
+			name: "valid limits",
+			opts: &latestreferrers.Options{Limits: map[string]int{"application/vnd.test.report": 1}},
+		},
+		{
+			name:      "invalid limits",
+			opts:      &latestreferrers.Options{},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			executor, _, err := newExecutor(&ScopedOptions{
+				Scopes: []string{"example.com"},
+				Verifiers: []*vf.NewVerifierOptions{
+					{
+						Name: mockVerifierName,
+						Type: verifierType,
+					},
+				},
+				Stores: []*sf.NewStoreOptions{
+					{
+						Type: storeType,
+					},
+				},
+				LatestReferrers: test.opts,
+			})
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			// the mock verifier also verifies the subject referrer.
+			store, ok := executor.Store.(*subjectreferrer.Store)
+			if !ok {
+				t.Fatalf("expected subject referrer store, got %T", executor.Store)
+			}
+			if _, ok := store.Store.(*latestreferrers.Store); !ok {
+				t.Errorf("expected latest referrers store, got %T", store.Store)
+			}
+		})
+	}
+}
+
 func TestRegisterExecutor(t *testing.T) {
 	tests := []struct {
 		name             string
@@ -419,19 +590,53 @@ func TestMatchExecutor(t *testing.T) {
 func TestValidateArtifact(t *testing.T) {
 	scopedExecutor := &ScopedExecutor{
 		wildcard: map[string]*ratify.Executor{
-			"example.com": {},
+			"example.com": {
+				Store: &mockStore{},
+			},
 		},
 	}
 
-	if _, err := scopedExecutor.ValidateArtifact(context.Background(), "unknown.com/foo:v1"); err == nil {
+	if _, err := scopedExecutor.ValidateArtifact(context.Background(), "unknown.com/foo:v1", ValidateArtifactOptions{}); err == nil {
 		t.Error("expected error for unknown artifact, got nil")
 	}
 
-	if _, err := scopedExecutor.ValidateArtifact(context.Background(), "test.example.com/foo:v1"); err == nil {
+	if _, err := scopedExecutor.ValidateArtifact(context.Background(), "test.example.com/foo:v1", ValidateArtifactOptions{}); err == nil {
 		t.Error("expected error for artifact with wildcard scope, got nil")
 	}
 }
 
+// exhaustedStore fails to resolve artifacts as if the registry kept failing.
+type exhaustedStore struct {
+	mockStore
+}
+
+func (s *exhaustedStore) Resolve(_ context.Context, _ string) (ocispec.Descriptor, error) {
+	return ocispec.Descriptor{}, fmt.Errorf("429 Too Many Requests: %w", registryhistory.ErrRetriesExhausted)
+}
+
+func TestValidateArtifact_RetriesExhausted(t *testing.T) {
+	scopedExecutor := &ScopedExecutor{
+		wildcard: map[string]*ratify.Executor{
+			"example.com": {
+				Store:          &exhaustedStore{},
+				Verifiers:      []ratify.Verifier{&mockVerifier{}},
+				PolicyEnforcer: &mockPolicyEnforcer{},
+			},
+		},
+	}
+
+	result, err := scopedExecutor.ValidateArtifact(context.Background(), "test.example.com/foo:v1", ValidateArtifactOptions{})
+	if err != nil {
+		t.Fatalf("expected exhausted retries to be reported in the result, got error: %v", err)
+	}
+	if result.Succeeded {
+		t.Error("expected failed validation")
+	}
+	if len(result.ArtifactReports) != 1 || len(result.ArtifactReports[0].Results) != 1 || !errors.Is(result.ArtifactReports[0].Results[0].Err, registryhistory.ErrRetriesExhausted) {
+		t.Errorf("expected a report of the registry failure, got %+v", result.ArtifactReports)
+	}
+}
+
 func TestResolve(t *testing.T) {
 	scopedExecutor := &ScopedExecutor{
 		wildcard: map[string]*ratify.Executor{
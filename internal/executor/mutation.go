@@ -0,0 +1,107 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/notaryproject/ratify-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// MutationStyleDigestOnly mutates tagged references to digested
+	// references, stripping the tag.
+	MutationStyleDigestOnly = "digest-only"
+
+	// MutationStyleRetainMutatedTag mutates tagged references to references
+	// with both tag and digest, e.g. "repo:tag@sha256:...".
+	MutationStyleRetainMutatedTag = "retain-mutated-tag"
+
+	// MutationStylePlatformDigest mutates references of image indexes to the
+	// digest of the manifest of the requested platform or, as Gatekeeper
+	// mutation keys usually carry none, of the default platform. The style is
+	// therefore only safe on single-arch clusters and requires an explicit
+	// default platform.
+	MutationStylePlatformDigest = "platform-digest"
+)
+
+// newMutationOptions creates the index options used to resolve the platform
+// manifest for the mutation style of the scoped options. It returns nil if
+// the mutation style does not resolve platform manifests.
+func newMutationOptions(opts *ScopedOptions) (*indexOptions, error) {
+	switch opts.MutationStyle {
+	case "", MutationStyleDigestOnly, MutationStyleRetainMutatedTag:
+		return nil, nil
+	case MutationStylePlatformDigest:
+		// the platform Ratify runs on may differ from the nodes of the pod.
+		if opts.DefaultPlatform == "" {
+			return nil, fmt.Errorf("defaultPlatform is required for mutation style %q", MutationStylePlatformDigest)
+		}
+		return newIndexOptions(&ScopedOptions{
+			IndexMode:       IndexModePlatform,
+			DefaultPlatform: opts.DefaultPlatform,
+		})
+	default:
+		return nil, fmt.Errorf("invalid mutation style %q", opts.MutationStyle)
+	}
+}
+
+// MutationStyle returns the mutation style configured for the scope of the
+// artifact.
+func (s *ScopedExecutor) MutationStyle(artifact string) (string, error) {
+	executor, err := s.matchExecutor(artifact)
+	if err != nil {
+		return "", fmt.Errorf("failed to match executor for artifact %q: %w", artifact, err)
+	}
+	if style, ok := s.mutationStyles[executor]; ok {
+		return style, nil
+	}
+	return MutationStyleDigestOnly, nil
+}
+
+// ResolvePlatform retrieves the descriptor of the manifest of the platform
+// for the specified artifact. If the artifact is an image index, the
+// manifest is selected as in "platform" index mode. Otherwise, the descriptor
+// of the artifact is returned.
+func (s *ScopedExecutor) ResolvePlatform(ctx context.Context, artifact string, platform *ocispec.Platform) (ocispec.Descriptor, error) {
+	executor, err := s.matchExecutor(artifact)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to match executor for artifact %q: %w", artifact, err)
+	}
+	opts, ok := s.platformOptions[executor]
+	if !ok {
+		return ocispec.Descriptor{}, fmt.Errorf("platform resolution is not enabled for the artifact %q", artifact)
+	}
+	return opts.resolvePlatform(ctx, executor.Store, artifact, platform)
+}
+
+// resolvePlatform resolves the artifact to the manifest of the platform.
+func (o *indexOptions) resolvePlatform(ctx context.Context, store ratify.Store, artifact string, platform *ocispec.Platform) (ocispec.Descriptor, error) {
+	_, desc, index, err := fetchIndex(ctx, store, artifact)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if index == nil {
+		return desc, nil
+	}
+	manifests, err := o.selectManifests(index.Manifests, platform)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to select manifests of index %s: %w", desc.Digest, err)
+	}
+	return manifests[0], nil
+}
@@ -0,0 +1,220 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/notaryproject/ratify-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestNewMutationOptions(t *testing.T) {
+	tests := []struct {
+		name          string
+		opts          *ScopedOptions
+		expectErr     bool
+		expectOptions bool
+	}{
+		{
+			name: "default style",
+			opts: &ScopedOptions{},
+		},
+		{
+			name: "digest only",
+			opts: &ScopedOptions{MutationStyle: MutationStyleDigestOnly},
+		},
+		{
+			name: "retain mutated tag",
+			opts: &ScopedOptions{MutationStyle: MutationStyleRetainMutatedTag},
+		},
+		{
+			name:          "platform digest",
+			opts:          &ScopedOptions{MutationStyle: MutationStylePlatformDigest, DefaultPlatform: "linux/arm64"},
+			expectOptions: true,
+		},
+		{
+			name:      "platform digest without default platform",
+			opts:      &ScopedOptions{MutationStyle: MutationStylePlatformDigest},
+			expectErr: true,
+		},
+		{
+			name:      "platform digest with invalid default platform",
+			opts:      &ScopedOptions{MutationStyle: MutationStylePlatformDigest, DefaultPlatform: "linux"},
+			expectErr: true,
+		},
+		{
+			name:      "invalid style",
+			opts:      &ScopedOptions{MutationStyle: "tag-only"},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts, err := newMutationOptions(test.opts)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if (opts != nil) != test.expectOptions {
+				t.Fatalf("expected options: %v, got: %v", test.expectOptions, opts)
+			}
+			if opts != nil && opts.mode != IndexModePlatform {
+				t.Errorf("expected mode %q, got %q", IndexModePlatform, opts.mode)
+			}
+		})
+	}
+}
+
+func TestMutationStyle(t *testing.T) {
+	retainTag := &ratify.Executor{}
+	scopedExecutor := &ScopedExecutor{
+		registry: map[string]*ratify.Executor{
+			"default.example.com": {},
+			"tag.example.com":     retainTag,
+		},
+		mutationStyles: map[*ratify.Executor]string{
+			retainTag: MutationStyleRetainMutatedTag,
+		},
+	}
+
+	tests := []struct {
+		artifact    string
+		expectErr   bool
+		expectStyle string
+	}{
+		{
+			artifact:    "default.example.com/foo:v1",
+			expectStyle: MutationStyleDigestOnly,
+		},
+		{
+			artifact:    "tag.example.com/foo:v1",
+			expectStyle: MutationStyleRetainMutatedTag,
+		},
+		{
+			artifact:  "unknown.com/foo:v1",
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.artifact, func(t *testing.T) {
+			style, err := scopedExecutor.MutationStyle(test.artifact)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if style != test.expectStyle {
+				t.Errorf("expected style %q, got %q", test.expectStyle, style)
+			}
+		})
+	}
+}
+
+func TestResolvePlatform(t *testing.T) {
+	opts := &indexOptions{
+		mode:            IndexModePlatform,
+		defaultPlatform: &ocispec.Platform{OS: "linux", Architecture: "amd64"},
+	}
+	index := newIndexStore(t, ocispec.MediaTypeImageIndex, attestationManifest, amd64Manifest, arm64Manifest)
+	fetchFailed := newIndexStore(t, ocispec.MediaTypeImageIndex, amd64Manifest)
+	fetchFailed.fetchErr = errors.New("fetch failed")
+	manifest := newIndexStore(t, ocispec.MediaTypeImageIndex)
+	manifest.index = amd64Manifest
+
+	tests := []struct {
+		name         string
+		store        *indexStore
+		opts         *indexOptions
+		artifact     string
+		platform     *ocispec.Platform
+		expectErr    bool
+		expectDigest string
+	}{
+		{
+			name:         "default platform",
+			store:        index,
+			opts:         opts,
+			artifact:     testRepo + ":v1",
+			expectDigest: amd64Manifest.Digest.String(),
+		},
+		{
+			name:         "requested platform",
+			store:        index,
+			opts:         opts,
+			artifact:     testRepo + ":v1",
+			platform:     &ocispec.Platform{OS: "linux", Architecture: "arm64"},
+			expectDigest: arm64Manifest.Digest.String(),
+		},
+		{
+			name:         "manifest",
+			store:        manifest,
+			opts:         opts,
+			artifact:     testRepo + ":v1",
+			expectDigest: amd64Manifest.Digest.String(),
+		},
+		{
+			name:      "no matching platform",
+			store:     index,
+			opts:      opts,
+			artifact:  testRepo + ":v1",
+			platform:  &ocispec.Platform{OS: "windows"},
+			expectErr: true,
+		},
+		{
+			name:      "failed to fetch index",
+			store:     fetchFailed,
+			opts:      opts,
+			artifact:  testRepo + ":v1",
+			expectErr: true,
+		},
+		{
+			name:      "platform resolution disabled",
+			store:     index,
+			artifact:  testRepo + ":v1",
+			expectErr: true,
+		},
+		{
+			name:      "unknown scope",
+			store:     index,
+			opts:      opts,
+			artifact:  "unknown.com/foo:v1",
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			executor := &ratify.Executor{Store: test.store}
+			scopedExecutor := &ScopedExecutor{
+				registry:        map[string]*ratify.Executor{testRegistry: executor},
+				platformOptions: map[*ratify.Executor]*indexOptions{},
+			}
+			if test.opts != nil {
+				scopedExecutor.platformOptions[executor] = test.opts
+			}
+
+			desc, err := scopedExecutor.ResolvePlatform(context.Background(), test.artifact, test.platform)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if desc.Digest.String() != test.expectDigest {
+				t.Errorf("expected digest %q, got %q", test.expectDigest, desc.Digest)
+			}
+		})
+	}
+}
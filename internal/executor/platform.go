@@ -0,0 +1,211 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"runtime"
+	"strings"
+
+	"github.com/notaryproject/ratify-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry"
+)
+
+const (
+	// IndexModeIndex verifies image indexes like any other subject.
+	IndexModeIndex = "index"
+
+	// IndexModePlatform verifies the manifest of the requested platform
+	// instead of the image index.
+	IndexModePlatform = "platform"
+
+	// IndexModeAll verifies the manifests of all platforms instead of the
+	// image index.
+	IndexModeAll = "all"
+
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	// annotationReferenceType marks the attestation manifests that BuildKit
+	// adds to image indexes.
+	annotationReferenceType = "vnd.docker.reference.type"
+)
+
+// indexOptions selects the manifests verified for image index subjects.
+type indexOptions struct {
+	mode            string
+	defaultPlatform *ocispec.Platform
+}
+
+// newIndexOptions creates the index options of the scoped options. It returns
+// nil if image indexes are verified like any other subject.
+func newIndexOptions(opts *ScopedOptions) (*indexOptions, error) {
+	switch opts.IndexMode {
+	case "", IndexModeIndex:
+		return nil, nil
+	case IndexModePlatform, IndexModeAll:
+	default:
+		return nil, fmt.Errorf("invalid index mode %q", opts.IndexMode)
+	}
+	defaultPlatform := opts.DefaultPlatform
+	if defaultPlatform == "" {
+		// the platform of the node Ratify runs on.
+		defaultPlatform = runtime.GOOS + "/" + runtime.GOARCH
+	}
+	platform, err := parsePlatform(defaultPlatform)
+	if err != nil {
+		return nil, err
+	}
+	return &indexOptions{
+		mode:            opts.IndexMode,
+		defaultPlatform: platform,
+	}, nil
+}
+
+// parsePlatform parses a platform in the form "os/architecture[/variant]".
+func parsePlatform(platform string) (*ocispec.Platform, error) {
+	parts := strings.Split(platform, "/")
+	if len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+		return nil, fmt.Errorf("invalid platform %q: expected os/architecture[/variant]", platform)
+	}
+	p := &ocispec.Platform{
+		OS:           parts[0],
+		Architecture: parts[1],
+	}
+	if len(parts) == 3 {
+		p.Variant = parts[2]
+	}
+	return p, nil
+}
+
+// validateIndex validates the manifests of the image index selected by the
+// index options. Each manifest is validated as a subject of its own and its
+// reports are nested under a report of the index with the manifest as
+// artifact. Validation succeeds if all manifests are validated successfully.
+// Subjects that are not image indexes are validated as is.
+func (o *indexOptions) validateIndex(ctx context.Context, executor *ratify.Executor, artifact string, platform *ocispec.Platform) (*ratify.ValidationResult, error) {
+	ref, desc, index, err := fetchIndex(ctx, executor.Store, artifact)
+	if err != nil {
+		return nil, err
+	}
+	if index == nil {
+		return validate(ctx, executor, ref.String())
+	}
+	manifests, err := o.selectManifests(index.Manifests, platform)
+	if err != nil {
+		return nil, fmt.Errorf("failed to select manifests of index %s: %w", desc.Digest, err)
+	}
+
+	subject := ref.String()
+	result := &ratify.ValidationResult{
+		Succeeded: true,
+	}
+	for _, manifest := range manifests {
+		ref.Reference = manifest.Digest.String()
+		manifestResult, err := validate(ctx, executor, ref.String())
+		if err != nil {
+			return nil, err
+		}
+		result.Succeeded = result.Succeeded && manifestResult.Succeeded
+		result.ArtifactReports = append(result.ArtifactReports, &ratify.ValidationReport{
+			Subject:         subject,
+			Artifact:        manifest,
+			ArtifactReports: manifestResult.ArtifactReports,
+		})
+	}
+	return result, nil
+}
+
+// fetchIndex resolves the artifact and fetches the image index it refers to.
+// The returned reference is pinned to the resolved digest. The returned index
+// is nil if the artifact is not an image index.
+func fetchIndex(ctx context.Context, store ratify.Store, artifact string) (registry.Reference, ocispec.Descriptor, *ocispec.Index, error) {
+	ref, desc, err := resolveSubject(ctx, store, artifact)
+	if err != nil {
+		return registry.Reference{}, ocispec.Descriptor{}, nil, err
+	}
+	if desc.MediaType != ocispec.MediaTypeImageIndex && desc.MediaType != mediaTypeDockerManifestList {
+		return ref, desc, nil, nil
+	}
+
+	content, err := store.FetchManifest(ctx, ref.Registry+"/"+ref.Repository, desc)
+	if err != nil {
+		return registry.Reference{}, ocispec.Descriptor{}, nil, fmt.Errorf("failed to fetch index %s: %w", desc.Digest, err)
+	}
+	var index ocispec.Index
+	if err := json.Unmarshal(content, &index); err != nil {
+		return registry.Reference{}, ocispec.Descriptor{}, nil, fmt.Errorf("failed to unmarshal index %s: %w", desc.Digest, err)
+	}
+	return ref, desc, &index, nil
+}
+
+// selectManifests returns the image manifests of the index to validate. In
+// platform mode, the fields missing in the requested platform are taken from
+// the default platform and a platform without variant matches any variant.
+func (o *indexOptions) selectManifests(manifests []ocispec.Descriptor, platform *ocispec.Platform) ([]ocispec.Descriptor, error) {
+	want := *o.defaultPlatform
+	if platform != nil {
+		if platform.OS != "" {
+			want.OS = platform.OS
+		}
+		if platform.Architecture != "" {
+			want.Architecture = platform.Architecture
+			want.Variant = platform.Variant
+		}
+	}
+
+	var selected []ocispec.Descriptor
+	for _, manifest := range manifests {
+		if manifest.MediaType != ocispec.MediaTypeImageManifest && manifest.MediaType != mediaTypeDockerManifest {
+			continue
+		}
+		if _, ok := manifest.Annotations[annotationReferenceType]; ok {
+			continue
+		}
+		if o.mode == IndexModePlatform {
+			p := manifest.Platform
+			if p == nil || p.OS != want.OS || p.Architecture != want.Architecture || (want.Variant != "" && p.Variant != want.Variant) {
+				continue
+			}
+			// the first match is the manifest a runtime would pull.
+			return []ocispec.Descriptor{manifest}, nil
+		}
+		selected = append(selected, manifest)
+	}
+	if len(selected) == 0 {
+		if o.mode == IndexModePlatform {
+			return nil, fmt.Errorf("no manifest for platform %s", FormatPlatform(&want))
+		}
+		return nil, fmt.Errorf("no image manifests")
+	}
+	return selected, nil
+}
+
+// FormatPlatform formats a platform as "os/architecture[/variant]". It
+// returns an empty string for nil platforms.
+func FormatPlatform(p *ocispec.Platform) string {
+	if p == nil {
+		return ""
+	}
+	platform := p.OS + "/" + p.Architecture
+	if p.Variant != "" {
+		platform += "/" + p.Variant
+	}
+	return platform
+}
@@ -0,0 +1,486 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package executor
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry"
+)
+
+const (
+	testRegistry = "registry.test"
+	testRepo     = testRegistry + "/image"
+)
+
+var (
+	amd64Manifest = ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromString("amd64"),
+		Platform:  &ocispec.Platform{OS: "linux", Architecture: "amd64"},
+	}
+	arm64Manifest = ocispec.Descriptor{
+		MediaType: mediaTypeDockerManifest,
+		Digest:    digest.FromString("arm64"),
+		Platform:  &ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+	}
+	attestationManifest = ocispec.Descriptor{
+		MediaType:   ocispec.MediaTypeImageManifest,
+		Digest:      digest.FromString("attestation"),
+		Platform:    &ocispec.Platform{OS: "unknown", Architecture: "unknown"},
+		Annotations: map[string]string{annotationReferenceType: "attestation-manifest"},
+	}
+	nestedIndex = ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Digest:    digest.FromString("nested"),
+		Platform:  &ocispec.Platform{OS: "linux", Architecture: "amd64"},
+	}
+	signature = ocispec.Descriptor{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: "application/vnd.test.signature",
+		Digest:       digest.FromString("signature"),
+	}
+)
+
+// indexStore is a store serving an image index and a signature referrer for
+// each manifest.
+type indexStore struct {
+	index    ocispec.Descriptor
+	content  []byte
+	fetchErr error
+}
+
+func newIndexStore(t *testing.T, mediaType string, manifests ...ocispec.Descriptor) *indexStore {
+	t.Helper()
+	content, err := json.Marshal(ocispec.Index{
+		MediaType: mediaType,
+		Manifests: manifests,
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal index: %v", err)
+	}
+	return &indexStore{
+		index: ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(content),
+			Size:      int64(len(content)),
+		},
+		content: content,
+	}
+}
+
+func (s *indexStore) Resolve(_ context.Context, reference string) (ocispec.Descriptor, error) {
+	ref, err := registry.ParseReference(reference)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if _, err := ref.Digest(); err != nil {
+		return s.index, nil
+	}
+	for _, desc := range []ocispec.Descriptor{s.index, amd64Manifest, arm64Manifest} {
+		if desc.Digest.String() == ref.Reference {
+			return desc, nil
+		}
+	}
+	return ocispec.Descriptor{}, errors.New("not found")
+}
+
+func (s *indexStore) ListReferrers(_ context.Context, ref string, _ []string, fn func(referrers []ocispec.Descriptor) error) error {
+	if strings.HasSuffix(ref, signature.Digest.String()) {
+		return nil
+	}
+	return fn([]ocispec.Descriptor{signature})
+}
+
+func (s *indexStore) FetchBlob(_ context.Context, _ string, _ ocispec.Descriptor) ([]byte, error) {
+	return nil, errors.New("not found")
+}
+
+func (s *indexStore) FetchManifest(_ context.Context, _ string, _ ocispec.Descriptor) ([]byte, error) {
+	return s.content, s.fetchErr
+}
+
+// indexVerifier fails the verification of the arm64 manifest.
+type indexVerifier struct{}
+
+func (v *indexVerifier) Name() string {
+	return "index-verifier"
+}
+
+func (v *indexVerifier) Type() string {
+	return "index-verifier"
+}
+
+func (v *indexVerifier) Verifiable(_ ocispec.Descriptor) bool {
+	return true
+}
+
+func (v *indexVerifier) Verify(_ context.Context, opts *ratify.VerifyOptions) (*ratify.VerificationResult, error) {
+	result := &ratify.VerificationResult{
+		Verifier: v,
+	}
+	if opts.SubjectDescriptor.Digest == arm64Manifest.Digest {
+		result.Err = errors.New("verification failed")
+	}
+	return result, nil
+}
+
+// indexPolicyEnforcer succeeds if no verification failed.
+type indexPolicyEnforcer struct{}
+
+func (p *indexPolicyEnforcer) Evaluator(_ context.Context, _ string) (ratify.Evaluator, error) {
+	return &indexEvaluator{}, nil
+}
+
+type indexEvaluator struct {
+	failed bool
+}
+
+func (e *indexEvaluator) Pruned(_ context.Context, _, _, _ string) (ratify.PrunedState, error) {
+	return ratify.PrunedStateNone, nil
+}
+
+func (e *indexEvaluator) AddResult(_ context.Context, _, _ string, result *ratify.VerificationResult) error {
+	e.failed = e.failed || result.Err != nil
+	return nil
+}
+
+func (e *indexEvaluator) Commit(_ context.Context, _ string) error {
+	return nil
+}
+
+func (e *indexEvaluator) Evaluate(_ context.Context) (bool, error) {
+	return !e.failed, nil
+}
+
+func TestNewIndexOptions(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           *ScopedOptions
+		expectErr      bool
+		expectOptions  bool
+		expectPlatform string
+	}{
+		{
+			name: "default mode",
+			opts: &ScopedOptions{},
+		},
+		{
+			name: "index mode",
+			opts: &ScopedOptions{IndexMode: IndexModeIndex},
+		},
+		{
+			name:           "platform mode with default platform",
+			opts:           &ScopedOptions{IndexMode: IndexModePlatform},
+			expectOptions:  true,
+			expectPlatform: runtime.GOOS + "/" + runtime.GOARCH,
+		},
+		{
+			name:           "all mode with configured platform",
+			opts:           &ScopedOptions{IndexMode: IndexModeAll, DefaultPlatform: "linux/arm64/v8"},
+			expectOptions:  true,
+			expectPlatform: "linux/arm64/v8",
+		},
+		{
+			name:      "invalid mode",
+			opts:      &ScopedOptions{IndexMode: "manifest"},
+			expectErr: true,
+		},
+		{
+			name:      "platform without architecture",
+			opts:      &ScopedOptions{IndexMode: IndexModePlatform, DefaultPlatform: "linux"},
+			expectErr: true,
+		},
+		{
+			name:      "platform with empty os",
+			opts:      &ScopedOptions{IndexMode: IndexModePlatform, DefaultPlatform: "/amd64"},
+			expectErr: true,
+		},
+		{
+			name:      "platform with too many parts",
+			opts:      &ScopedOptions{IndexMode: IndexModePlatform, DefaultPlatform: "linux/arm64/v8/extra"},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			opts, err := newIndexOptions(test.opts)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if (opts != nil) != test.expectOptions {
+				t.Fatalf("expected options: %v, got: %v", test.expectOptions, opts)
+			}
+			if opts == nil {
+				return
+			}
+			if opts.mode != test.opts.IndexMode {
+				t.Errorf("expected mode %q, got %q", test.opts.IndexMode, opts.mode)
+			}
+			if platform := FormatPlatform(opts.defaultPlatform); platform != test.expectPlatform {
+				t.Errorf("expected default platform %q, got %q", test.expectPlatform, platform)
+			}
+		})
+	}
+}
+
+func TestSelectManifests(t *testing.T) {
+	manifests := []ocispec.Descriptor{attestationManifest, nestedIndex, amd64Manifest, arm64Manifest}
+	platformMode := &indexOptions{
+		mode:            IndexModePlatform,
+		defaultPlatform: &ocispec.Platform{OS: "linux", Architecture: "amd64"},
+	}
+
+	tests := []struct {
+		name      string
+		opts      *indexOptions
+		manifests []ocispec.Descriptor
+		platform  *ocispec.Platform
+		expectErr bool
+		expect    []ocispec.Descriptor
+	}{
+		{
+			name:      "default platform",
+			opts:      platformMode,
+			manifests: manifests,
+			expect:    []ocispec.Descriptor{amd64Manifest},
+		},
+		{
+			name:      "requested platform matches any variant",
+			opts:      platformMode,
+			manifests: manifests,
+			platform:  &ocispec.Platform{OS: "linux", Architecture: "arm64"},
+			expect:    []ocispec.Descriptor{arm64Manifest},
+		},
+		{
+			name:      "requested platform with variant",
+			opts:      platformMode,
+			manifests: manifests,
+			platform:  &ocispec.Platform{Architecture: "arm64", Variant: "v8"},
+			expect:    []ocispec.Descriptor{arm64Manifest},
+		},
+		{
+			name:      "requested os only",
+			opts:      platformMode,
+			manifests: manifests,
+			platform:  &ocispec.Platform{OS: "linux"},
+			expect:    []ocispec.Descriptor{amd64Manifest},
+		},
+		{
+			name:      "variant mismatch",
+			opts:      platformMode,
+			manifests: manifests,
+			platform:  &ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v7"},
+			expectErr: true,
+		},
+		{
+			name:      "no matching platform",
+			opts:      platformMode,
+			manifests: manifests,
+			platform:  &ocispec.Platform{OS: "windows", Architecture: "amd64"},
+			expectErr: true,
+		},
+		{
+			name:      "all manifests",
+			opts:      &indexOptions{mode: IndexModeAll, defaultPlatform: platformMode.defaultPlatform},
+			manifests: manifests,
+			platform:  &ocispec.Platform{OS: "windows", Architecture: "amd64"},
+			expect:    []ocispec.Descriptor{amd64Manifest, arm64Manifest},
+		},
+		{
+			name:      "no image manifests",
+			opts:      &indexOptions{mode: IndexModeAll, defaultPlatform: platformMode.defaultPlatform},
+			manifests: []ocispec.Descriptor{attestationManifest, nestedIndex},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			selected, err := test.opts.selectManifests(test.manifests, test.platform)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if len(selected) != len(test.expect) {
+				t.Fatalf("expected %d manifests, got %d", len(test.expect), len(selected))
+			}
+			for i, desc := range selected {
+				if desc.Digest != test.expect[i].Digest {
+					t.Errorf("expected manifest %s, got %s", test.expect[i].Digest, desc.Digest)
+				}
+			}
+		})
+	}
+}
+
+func TestValidateArtifact_IndexMode(t *testing.T) {
+	defaultPlatform := &ocispec.Platform{OS: "linux", Architecture: "amd64"}
+	index := newIndexStore(t, ocispec.MediaTypeImageIndex, attestationManifest, amd64Manifest, arm64Manifest)
+	manifestList := newIndexStore(t, mediaTypeDockerManifestList, amd64Manifest, arm64Manifest)
+	fetchFailed := newIndexStore(t, ocispec.MediaTypeImageIndex, amd64Manifest)
+	fetchFailed.fetchErr = errors.New("fetch failed")
+	invalidIndex := newIndexStore(t, ocispec.MediaTypeImageIndex)
+	invalidIndex.content = []byte("{")
+	manifest := newIndexStore(t, ocispec.MediaTypeImageIndex)
+	manifest.index = amd64Manifest
+
+	tests := []struct {
+		name            string
+		store           *indexStore
+		opts            *indexOptions
+		platform        *ocispec.Platform
+		expectErr       bool
+		expectSucceeded bool
+		expectSubjects  []digest.Digest
+	}{
+		{
+			name:            "index mode",
+			store:           index,
+			expectSucceeded: true,
+			expectSubjects:  []digest.Digest{index.index.Digest},
+		},
+		{
+			name:            "default platform",
+			store:           index,
+			opts:            &indexOptions{mode: IndexModePlatform, defaultPlatform: defaultPlatform},
+			expectSucceeded: true,
+			expectSubjects:  []digest.Digest{amd64Manifest.Digest},
+		},
+		{
+			name:           "requested platform",
+			store:          manifestList,
+			opts:           &indexOptions{mode: IndexModePlatform, defaultPlatform: defaultPlatform},
+			platform:       &ocispec.Platform{OS: "linux", Architecture: "arm64"},
+			expectSubjects: []digest.Digest{arm64Manifest.Digest},
+		},
+		{
+			name:           "all platforms",
+			store:          index,
+			opts:           &indexOptions{mode: IndexModeAll, defaultPlatform: defaultPlatform},
+			expectSubjects: []digest.Digest{amd64Manifest.Digest, arm64Manifest.Digest},
+		},
+		{
+			name:            "manifest subject",
+			store:           manifest,
+			opts:            &indexOptions{mode: IndexModeAll, defaultPlatform: defaultPlatform},
+			expectSucceeded: true,
+			expectSubjects:  []digest.Digest{amd64Manifest.Digest},
+		},
+		{
+			name:      "no matching platform",
+			store:     index,
+			opts:      &indexOptions{mode: IndexModePlatform, defaultPlatform: defaultPlatform},
+			platform:  &ocispec.Platform{OS: "windows"},
+			expectErr: true,
+		},
+		{
+			name:      "failed to fetch index",
+			store:     fetchFailed,
+			opts:      &indexOptions{mode: IndexModeAll, defaultPlatform: defaultPlatform},
+			expectErr: true,
+		},
+		{
+			name:      "invalid index",
+			store:     invalidIndex,
+			opts:      &indexOptions{mode: IndexModeAll, defaultPlatform: defaultPlatform},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			executor, err := ratify.NewExecutor(test.store, []ratify.Verifier{&indexVerifier{}}, &indexPolicyEnforcer{})
+			if err != nil {
+				t.Fatalf("failed to create executor: %v", err)
+			}
+			scopedExecutor := &ScopedExecutor{
+				registry:     map[string]*ratify.Executor{testRegistry: executor},
+				indexOptions: map[*ratify.Executor]*indexOptions{},
+			}
+			if test.opts != nil {
+				scopedExecutor.indexOptions[executor] = test.opts
+			}
+
+			result, err := scopedExecutor.ValidateArtifact(context.Background(), testRepo+":v1", ValidateArtifactOptions{Platform: test.platform})
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if result.Succeeded != test.expectSucceeded {
+				t.Errorf("expected succeeded: %v, got: %v", test.expectSucceeded, result.Succeeded)
+			}
+
+			reports := result.ArtifactReports
+			if test.opts != nil && test.store != manifest {
+				// the reports of each manifest are nested under a report of
+				// the index with the manifest as artifact.
+				if len(reports) != len(test.expectSubjects) {
+					t.Fatalf("expected %d manifest reports, got %d", len(test.expectSubjects), len(reports))
+				}
+				var nested []*ratify.ValidationReport
+				for i, report := range reports {
+					if report.Subject != testRepo+"@"+test.store.index.Digest.String() {
+						t.Errorf("unexpected subject %s", report.Subject)
+					}
+					if report.Artifact.Digest != test.expectSubjects[i] || report.Artifact.Platform == nil {
+						t.Errorf("unexpected artifact %v", report.Artifact)
+					}
+					nested = append(nested, report.ArtifactReports...)
+				}
+				reports = nested
+			}
+			if len(reports) != len(test.expectSubjects) {
+				t.Fatalf("expected %d reports, got %d", len(test.expectSubjects), len(reports))
+			}
+			for i, report := range reports {
+				if report.Subject != testRepo+"@"+test.expectSubjects[i].String() {
+					t.Errorf("expected subject %s, got %s", test.expectSubjects[i], report.Subject)
+				}
+				if report.Artifact.Digest != signature.Digest {
+					t.Errorf("expected artifact %s, got %s", signature.Digest, report.Artifact.Digest)
+				}
+			}
+		})
+	}
+}
+
+func TestFormatPlatform(t *testing.T) {
+	tests := []struct {
+		platform *ocispec.Platform
+		expect   string
+	}{
+		{platform: nil, expect: ""},
+		{platform: &ocispec.Platform{OS: "linux", Architecture: "amd64"}, expect: "linux/amd64"},
+		{platform: &ocispec.Platform{OS: "linux", Architecture: "arm", Variant: "v7"}, expect: "linux/arm/v7"},
+	}
+
+	for _, test := range tests {
+		if got := FormatPlatform(test.platform); got != test.expect {
+			t.Errorf("expected %q, got %q", test.expect, got)
+		}
+	}
+}
@@ -82,7 +82,11 @@ func (w *Watcher) loadExecutor() error {
 	if err != nil {
 		return fmt.Errorf("failed to create executor: %w", err)
 	}
-	w.executor.Store(e)
+	if old := w.executor.Swap(e); old != nil {
+		if err := old.Close(); err != nil {
+			logrus.Warnf("failed to close previous executor: %v", err)
+		}
+	}
 	return nil
 }
 
@@ -129,11 +133,17 @@ func (w *Watcher) Start() error {
 	return nil
 }
 
-// Stop stops the watcher and closes the underlying fsnotify watcher.
+// Stop stops the watcher, closes the underlying fsnotify watcher and closes
+// the current executor.
 func (w *Watcher) Stop() {
 	if err := w.watcher.Close(); err != nil {
 		logrus.Errorf("failed to close watcher: %v", err)
 	}
+	if e := w.executor.Load(); e != nil {
+		if err := e.Close(); err != nil {
+			logrus.Errorf("failed to close executor: %v", err)
+		}
+	}
 }
 
 func getConfigurationFile(configFilePath string) string {
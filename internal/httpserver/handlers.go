@@ -17,13 +17,17 @@ package httpserver
 
 import (
 	"context"
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 
+	"github.com/notaryproject/ratify/v2/internal/executor"
 	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"github.com/sirupsen/logrus"
 	"oras.land/oras-go/v2/registry"
 )
@@ -42,36 +46,16 @@ func (s *server) verify(ctx context.Context, w http.ResponseWriter, r *http.Requ
 	}
 
 	results := make([]externaldata.Item, len(providerRequest.Request.Keys))
-	for idx, artifact := range providerRequest.Request.Keys {
+	for idx, key := range providerRequest.Request.Keys {
 		results[idx] = externaldata.Item{
-			Key: artifact,
+			Key: key,
 		}
-		key := verifyKey(artifact)
-
-		// Fetch the cache value first.
-		val, err := s.cache.Get(ctx, key)
-		if err == nil && val != nil {
-			results[idx].Value = val
+		artifact, platform, err := parseRequestKey(key)
+		if err != nil {
+			results[idx].Error = err.Error()
 			continue
 		}
-
-		// Cache is missed, block multiple goroutines from validating the same
-		// artifact.
-		val, err, _ = s.sfGroup.Do(key, func() (any, error) {
-			executor := s.getExecutor()
-			if executor == nil {
-				return nil, errors.New("no valid executor configured")
-			}
-			result, err := executor.ValidateArtifact(ctx, artifact)
-			if err != nil {
-				return nil, err
-			}
-			renderedResult := convertResult(result)
-			if err = s.cache.Set(ctx, key, renderedResult); err != nil {
-				logrus.Warnf("failed to set verify cache for image %s: %v", artifact, err)
-			}
-			return renderedResult, nil
-		})
+		val, err := s.verifyArtifact(ctx, artifact, platform)
 		if err != nil {
 			results[idx].Error = err.Error()
 		}
@@ -81,6 +65,95 @@ func (s *server) verify(ctx context.Context, w http.ResponseWriter, r *http.Requ
 	return sendResponse(results, w, http.StatusOK, false)
 }
 
+// platformRequest is a request key carrying the platform the artifact will
+// run on, e.g. derived from the node selector of the Pod.
+type platformRequest struct {
+	Reference string `json:"reference"`
+	OS        string `json:"os,omitempty"`
+	Arch      string `json:"arch,omitempty"`
+	Variant   string `json:"variant,omitempty"`
+}
+
+// parseRequestKey parses a verification or mutation request key. A key is
+// either an artifact reference or a JSON serialized [platformRequest]. The
+// returned platform is nil if the key does not specify one.
+func parseRequestKey(key string) (string, *ocispec.Platform, error) {
+	if !strings.HasPrefix(key, "{") {
+		return key, nil, nil
+	}
+	var request platformRequest
+	if err := json.Unmarshal([]byte(key), &request); err != nil {
+		return "", nil, fmt.Errorf("failed to unmarshal request key: %w", err)
+	}
+	if request.Reference == "" {
+		return "", nil, errors.New("request key must contain a reference")
+	}
+	if request.OS == "" && request.Arch == "" {
+		return request.Reference, nil, nil
+	}
+	return request.Reference, &ocispec.Platform{
+		OS:           request.OS,
+		Architecture: request.Arch,
+		Variant:      request.Variant,
+	}, nil
+}
+
+// verifyArtifact validates the artifact and returns the rendered result.
+// Tagged references are resolved to digests first so that references to the
+// same subject by tag and by digest share the cached result. Results are
+// cached per platform.
+func (s *server) verifyArtifact(ctx context.Context, artifact string, platform *ocispec.Platform) (any, error) {
+	key := verifyKey(artifact, platform)
+
+	// Fetch the cache value first.
+	val, err := s.cache.Get(ctx, key)
+	if err == nil && val != nil {
+		return val, nil
+	}
+
+	// Cache is missed, block multiple goroutines from validating the same
+	// artifact.
+	val, err, _ = s.sfGroup.Do(key, func() (any, error) {
+		subject := artifact
+		if ref, err := registry.ParseReference(artifact); err == nil {
+			if _, err = ref.Digest(); err != nil {
+				if subject, err = s.resolveDigest(ctx, ref); err != nil {
+					return nil, err
+				}
+				digestKey := verifyKey(subject, platform)
+				if val, err := s.cache.Get(ctx, digestKey); err == nil && val != nil {
+					s.setCache(ctx, key, val, artifact)
+					return val, nil
+				}
+			}
+		}
+
+		scopedExecutor := s.getExecutor()
+		if scopedExecutor == nil {
+			return nil, errors.New("no valid executor configured")
+		}
+		result, err := scopedExecutor.ValidateArtifact(ctx, subject, executor.ValidateArtifactOptions{
+			Platform: platform,
+		})
+		if err != nil {
+			return nil, err
+		}
+		renderedResult := convertResult(result)
+		s.setCache(ctx, verifyKey(subject, platform), renderedResult, subject)
+		if subject != artifact {
+			s.setCache(ctx, key, renderedResult, artifact)
+		}
+		return renderedResult, nil
+	})
+	return val, err
+}
+
+func (s *server) setCache(ctx context.Context, key string, val any, artifact string) {
+	if err := s.cache.Set(ctx, key, val); err != nil {
+		logrus.Warnf("failed to set verify cache for image %s: %v", artifact, err)
+	}
+}
+
 // mutate handles the mutation request from Gatekeeper.
 func (s *server) mutate(ctx context.Context, w http.ResponseWriter, r *http.Request) error {
 	defer r.Body.Close()
@@ -101,28 +174,77 @@ func (s *server) mutate(ctx context.Context, w http.ResponseWriter, r *http.Requ
 	return sendResponse(results, w, http.StatusOK, true)
 }
 
-func (s *server) resolveReference(ctx context.Context, reference string) externaldata.Item {
+// resolveReference mutates the reference of the request key in the mutation
+// style configured for its scope. The reference is left unchanged if it
+// cannot be resolved.
+func (s *server) resolveReference(ctx context.Context, key string) externaldata.Item {
 	item := externaldata.Item{
-		Key:   reference,
-		Value: reference,
+		Key:   key,
+		Value: key,
+	}
+
+	reference, platform, err := parseRequestKey(key)
+	if err != nil {
+		item.Error = err.Error()
+		return item
 	}
+	item.Value = reference
 
 	ref, err := registry.ParseReference(reference)
 	if err != nil {
 		item.Error = fmt.Sprintf("failed to parse reference: %v", err)
 		return item
 	}
-	if _, err = ref.Digest(); err == nil {
-		item.Value = ref.String()
-		return item
+
+	// references out of any scope are mutated in the default style so that
+	// resolving them reports the error.
+	style := executor.MutationStyleDigestOnly
+	if scopedExecutor := s.getExecutor(); scopedExecutor != nil {
+		if configured, err := scopedExecutor.MutationStyle(reference); err == nil {
+			style = configured
+		}
 	}
 
+	var resolvedRef string
+	switch style {
+	case executor.MutationStylePlatformDigest:
+		resolvedRef, err = s.resolvePlatformDigest(ctx, ref, platform)
+	case executor.MutationStyleRetainMutatedTag:
+		if _, err = ref.Digest(); err == nil {
+			return item
+		}
+		resolvedRef, err = s.resolveDigest(ctx, ref)
+		if err == nil {
+			if ref.Reference == "" {
+				ref.Reference = "latest"
+			}
+			_, dgst, _ := strings.Cut(resolvedRef, "@")
+			resolvedRef = ref.String() + "@" + dgst
+		}
+	default:
+		if _, err = ref.Digest(); err == nil {
+			item.Value = ref.String()
+			return item
+		}
+		resolvedRef, err = s.resolveDigest(ctx, ref)
+	}
+	if err != nil {
+		item.Error = err.Error()
+	} else {
+		item.Value = resolvedRef
+	}
+	return item
+}
+
+// resolveDigest resolves the tagged reference to a digested reference.
+func (s *server) resolveDigest(ctx context.Context, ref registry.Reference) (string, error) {
 	// Fetch the cache value first.
-	key := mutateKey(reference)
+	key := mutateKey(ref.String())
 	val, err := s.cache.Get(ctx, key)
 	if err == nil && val != nil {
-		item.Value = val
-		return item
+		if resolvedRef, ok := val.(string); ok {
+			return resolvedRef, nil
+		}
 	}
 
 	// Cache is missed, block multiple goroutines from resolving the same
@@ -140,16 +262,121 @@ func (s *server) resolveReference(ctx context.Context, reference string) externa
 		resolvedRef := ref.String()
 
 		if err = s.cache.Set(ctx, key, resolvedRef); err != nil {
-			logrus.Warnf("failed to set mutate cache for image %s: %v", reference, err)
+			logrus.Warnf("failed to set mutate cache for image %s: %v", resolvedRef, err)
 		}
 		return resolvedRef, nil
 	})
 	if err != nil {
-		item.Error = err.Error()
-	} else {
-		item.Value = val
+		return "", err
 	}
-	return item
+	return val.(string), nil
+}
+
+// resolvePlatformDigest resolves the reference to a digested reference of the
+// manifest of the platform. Platforms missing in the request default to the
+// platform configured for the scope.
+func (s *server) resolvePlatformDigest(ctx context.Context, ref registry.Reference, platform *ocispec.Platform) (string, error) {
+	// Fetch the cache value first.
+	key := mutateKey(ref.String() + "_platform")
+	if platform != nil {
+		key = fmt.Sprintf("%s_%s/%s/%s", key, platform.OS, platform.Architecture, platform.Variant)
+	}
+	val, err := s.cache.Get(ctx, key)
+	if err == nil && val != nil {
+		if resolvedRef, ok := val.(string); ok {
+			return resolvedRef, nil
+		}
+	}
+
+	// Cache is missed, block multiple goroutines from resolving the same
+	// reference.
+	val, err, _ = s.sfGroup.Do(key, func() (any, error) {
+		scopedExecutor := s.getExecutor()
+		if scopedExecutor == nil {
+			return "", errors.New("no valid executor configured")
+		}
+		desc, err := scopedExecutor.ResolvePlatform(ctx, ref.String(), platform)
+		if err != nil {
+			return "", err
+		}
+		ref.Reference = desc.Digest.String()
+		resolvedRef := ref.String()
+
+		if err = s.cache.Set(ctx, key, resolvedRef); err != nil {
+			logrus.Warnf("failed to set mutate cache for image %s: %v", resolvedRef, err)
+		}
+		return resolvedRef, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return val.(string), nil
+}
+
+// registryEvent is a registry notification event. The format is shared by the
+// CNCF Distribution registry and compatible registries such as ACR.
+type registryEvent struct {
+	Action string `json:"action"`
+	Target struct {
+		Repository string `json:"repository"`
+		Digest     string `json:"digest,omitempty"`
+	} `json:"target"`
+	Request struct {
+		Host string `json:"host"`
+	} `json:"request"`
+}
+
+// invalidateReferrers handles registry webhook notifications and drops the
+// cached referrers of the repositories where content is pushed or deleted.
+// Both an envelope of events and a single event are accepted. Notifications
+// must carry the configured token as bearer token.
+func (s *server) invalidateReferrers(_ context.Context, w http.ResponseWriter, r *http.Request) error {
+	defer r.Body.Close()
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || len(s.registryWebhookToken) == 0 || subtle.ConstantTimeCompare([]byte(token), s.registryWebhookToken) != 1 {
+		w.WriteHeader(http.StatusUnauthorized)
+		return errors.New("unauthorized registry notification")
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return fmt.Errorf("failed to read request body: %w", err)
+	}
+
+	var envelope struct {
+		Events []registryEvent `json:"events"`
+	}
+	if err = json.Unmarshal(body, &envelope); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return fmt.Errorf("failed to unmarshal registry notification: %w", err)
+	}
+	if len(envelope.Events) == 0 {
+		var event registryEvent
+		if err = json.Unmarshal(body, &event); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return fmt.Errorf("failed to unmarshal registry notification: %w", err)
+		}
+		envelope.Events = []registryEvent{event}
+	}
+
+	scopedExecutor := s.getExecutor()
+	if scopedExecutor == nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		return errors.New("executor is not initialized")
+	}
+	for _, event := range envelope.Events {
+		if event.Action != "push" && event.Action != "delete" {
+			continue
+		}
+		if event.Request.Host == "" || event.Target.Repository == "" {
+			logrus.Warnf("skipping registry notification without host or repository: %+v", event)
+			continue
+		}
+		scopedExecutor.InvalidateReferrers(event.Request.Host + "/" + event.Target.Repository)
+	}
+	w.WriteHeader(http.StatusOK)
+	return nil
 }
 
 func sendResponse(results []externaldata.Item, w http.ResponseWriter, respCode int, isMutation bool) error {
@@ -173,6 +400,9 @@ func mutateKey(key string) string {
 	return fmt.Sprintf("%s_%s", mutatePath, key)
 }
 
-func verifyKey(key string) string {
+func verifyKey(key string, platform *ocispec.Platform) string {
+	if platform != nil {
+		key = fmt.Sprintf("%s_%s/%s/%s", key, platform.OS, platform.Architecture, platform.Variant)
+	}
 	return fmt.Sprintf("%s_%s", verifyPath, key)
 }
@@ -23,11 +23,21 @@ import (
 	"net/http/httptest"
 	"reflect"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/cache/ristretto"
 	"github.com/notaryproject/ratify/v2/internal/executor"
+	storeFactory "github.com/notaryproject/ratify/v2/internal/store/factory"
+	"github.com/notaryproject/ratify/v2/internal/store/referrerscache"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
 	"github.com/open-policy-agent/frameworks/constraint/pkg/externaldata"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 	"golang.org/x/sync/singleflight"
+	"oras.land/oras-go/v2/registry"
 )
 
 type mockCache struct {
@@ -124,6 +134,40 @@ func TestVerify(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "Platform request with cache hit",
+			requestBody: `{
+				"request": {
+					"keys": ["{\"reference\":\"artifact1\",\"os\":\"linux\",\"arch\":\"arm64\"}"]
+				}
+			}`,
+			cacheEntries: map[string]string{
+				"verify_artifact1":              "cachedValue",
+				"verify_artifact1_linux/arm64/": "cachedPlatformValue",
+			},
+			expectedError: false,
+			expectedItems: []externaldata.Item{
+				{
+					Key:   `{"reference":"artifact1","os":"linux","arch":"arm64"}`,
+					Value: "cachedPlatformValue",
+				},
+			},
+		},
+		{
+			name: "Invalid platform request",
+			requestBody: `{
+				"request": {
+					"keys": ["{\"os\":\"linux\"}"]
+				}
+			}`,
+			expectedError: false,
+			expectedItems: []externaldata.Item{
+				{
+					Key:   `{"os":"linux"}`,
+					Error: "request key must contain a reference",
+				},
+			},
+		},
 		{
 			name:          "Invalid JSON",
 			requestBody:   `{invalid-json}`,
@@ -276,3 +320,488 @@ func TestMutate(t *testing.T) {
 		})
 	}
 }
+
+const mockDigestStoreType = "mock-digest-store-type"
+
+// countingStore resolves tags to a fixed digest and counts referrers listings.
+type countingStore struct {
+	mockStore
+	listCalls atomic.Int32
+}
+
+func (s *countingStore) ListReferrers(_ context.Context, _ string, _ []string, _ func(referrers []ocispec.Descriptor) error) error {
+	s.listCalls.Add(1)
+	return nil
+}
+
+const mockIndexStoreType = "mock-index-store-type"
+
+// indexStore resolves tags to an image index of an amd64 and an arm64
+// manifest.
+type indexStore struct {
+	mockStore
+	index     ocispec.Descriptor
+	content   []byte
+	manifests []ocispec.Descriptor
+}
+
+func newIndexStore(t *testing.T) *indexStore {
+	t.Helper()
+	manifests := []ocispec.Descriptor{
+		{
+			MediaType: ocispec.MediaTypeImageManifest,
+			Digest:    digest.FromString("amd64"),
+			Platform:  &ocispec.Platform{OS: "linux", Architecture: "amd64"},
+		},
+		{
+			MediaType: ocispec.MediaTypeImageManifest,
+			Digest:    digest.FromString("arm64"),
+			Platform:  &ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+		},
+	}
+	content, err := json.Marshal(ocispec.Index{
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: manifests,
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal index: %v", err)
+	}
+	return &indexStore{
+		index: ocispec.Descriptor{
+			MediaType: ocispec.MediaTypeImageIndex,
+			Digest:    digest.FromBytes(content),
+			Size:      int64(len(content)),
+		},
+		content:   content,
+		manifests: manifests,
+	}
+}
+
+func (s *indexStore) Resolve(_ context.Context, reference string) (ocispec.Descriptor, error) {
+	ref, err := registry.ParseReference(reference)
+	if err != nil {
+		return ocispec.Descriptor{}, err
+	}
+	if ref.Repository == "missing" {
+		return ocispec.Descriptor{}, fmt.Errorf("not found")
+	}
+	for _, desc := range append([]ocispec.Descriptor{s.index}, s.manifests...) {
+		if desc.Digest.String() == ref.Reference {
+			return desc, nil
+		}
+	}
+	return s.index, nil
+}
+
+func (s *indexStore) FetchManifest(_ context.Context, _ string, _ ocispec.Descriptor) ([]byte, error) {
+	return s.content, nil
+}
+
+func TestMutate_Styles(t *testing.T) {
+	store := newIndexStore(t)
+	storeFactory.RegisterStoreFactory(mockIndexStoreType, func(_ *storeFactory.NewStoreOptions) (ratify.Store, error) {
+		return store, nil
+	})
+	newScopedOptions := func(scope, style string) *executor.ScopedOptions {
+		return &executor.ScopedOptions{
+			Scopes: []string{scope},
+			Verifiers: []*factory.NewVerifierOptions{
+				{
+					Name: mockVerifierName,
+					Type: mockVerifierType,
+				},
+			},
+			Stores: []*storeFactory.NewStoreOptions{
+				{
+					Type: mockIndexStoreType,
+				},
+			},
+			DefaultPlatform: "linux/amd64",
+			MutationStyle:   style,
+		}
+	}
+	scopedExecutor, err := executor.NewScopedExecutor(&executor.Options{
+		Executors: []*executor.ScopedOptions{
+			newScopedOptions("digest.test.io", ""),
+			newScopedOptions("tag.test.io", executor.MutationStyleRetainMutatedTag),
+			newScopedOptions("platform.test.io", executor.MutationStylePlatformDigest),
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+	indexDigest := store.index.Digest.String()
+	amd64Digest := store.manifests[0].Digest.String()
+	arm64Digest := store.manifests[1].Digest.String()
+
+	tests := []struct {
+		name        string
+		key         string
+		expectValue string
+		expectErr   bool
+	}{
+		{
+			name:        "digest only",
+			key:         "digest.test.io/image:v1",
+			expectValue: "digest.test.io/image@" + indexDigest,
+		},
+		{
+			name:        "digest only with tag and digest",
+			key:         "digest.test.io/image:v1@" + indexDigest,
+			expectValue: "digest.test.io/image@" + indexDigest,
+		},
+		{
+			name:        "retain mutated tag",
+			key:         "tag.test.io/image:v1",
+			expectValue: "tag.test.io/image:v1@" + indexDigest,
+		},
+		{
+			name:        "retain mutated tag without tag",
+			key:         "tag.test.io/image",
+			expectValue: "tag.test.io/image:latest@" + indexDigest,
+		},
+		{
+			name:        "retain mutated tag with tag and digest",
+			key:         "tag.test.io/image:v1@" + indexDigest,
+			expectValue: "tag.test.io/image:v1@" + indexDigest,
+		},
+		{
+			name:        "retain mutated tag with digest",
+			key:         "tag.test.io/image@" + indexDigest,
+			expectValue: "tag.test.io/image@" + indexDigest,
+		},
+		{
+			name:        "retain mutated tag fails to resolve",
+			key:         "tag.test.io/missing:v1",
+			expectValue: "tag.test.io/missing:v1",
+			expectErr:   true,
+		},
+		{
+			name:        "platform digest with default platform",
+			key:         "platform.test.io/image:v1",
+			expectValue: "platform.test.io/image@" + amd64Digest,
+		},
+		{
+			name:        "platform digest with requested platform",
+			key:         `{"reference":"platform.test.io/image:v1","os":"linux","arch":"arm64"}`,
+			expectValue: "platform.test.io/image@" + arm64Digest,
+		},
+		{
+			name:        "platform digest of index digest",
+			key:         "platform.test.io/image@" + indexDigest,
+			expectValue: "platform.test.io/image@" + amd64Digest,
+		},
+		{
+			name:        "platform digest of manifest digest",
+			key:         "platform.test.io/image@" + arm64Digest,
+			expectValue: "platform.test.io/image@" + arm64Digest,
+		},
+		{
+			name:        "platform digest without matching platform",
+			key:         `{"reference":"platform.test.io/image:v1","os":"windows","arch":"amd64"}`,
+			expectValue: "platform.test.io/image:v1",
+			expectErr:   true,
+		},
+		{
+			name:        "invalid request key",
+			key:         `{"os":"linux"}`,
+			expectValue: `{"os":"linux"}`,
+			expectErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			verifyCache, err := ristretto.NewRistrettoCache(time.Minute)
+			if err != nil {
+				t.Fatalf("failed to create cache: %v", err)
+			}
+			server := &server{
+				getExecutor: func() *executor.ScopedExecutor {
+					return scopedExecutor
+				},
+				cache:   verifyCache,
+				sfGroup: new(singleflight.Group),
+			}
+
+			item := server.resolveReference(context.Background(), test.key)
+			if item.Key != test.key {
+				t.Errorf("expected key %q, got %q", test.key, item.Key)
+			}
+			if (item.Error != "") != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, item.Error)
+			}
+			if item.Value != test.expectValue {
+				t.Errorf("expected value %q, got %q", test.expectValue, item.Value)
+			}
+		})
+	}
+}
+
+func TestParseRequestKey(t *testing.T) {
+	tests := []struct {
+		name           string
+		key            string
+		expectErr      bool
+		expectArtifact string
+		expectPlatform *ocispec.Platform
+	}{
+		{
+			name:           "reference",
+			key:            artifact1,
+			expectArtifact: artifact1,
+		},
+		{
+			name:           "reference with platform",
+			key:            `{"reference":"` + artifact1 + `","os":"linux","arch":"arm","variant":"v7"}`,
+			expectArtifact: artifact1,
+			expectPlatform: &ocispec.Platform{OS: "linux", Architecture: "arm", Variant: "v7"},
+		},
+		{
+			name:           "reference with architecture only",
+			key:            `{"reference":"` + artifact1 + `","arch":"arm64"}`,
+			expectArtifact: artifact1,
+			expectPlatform: &ocispec.Platform{Architecture: "arm64"},
+		},
+		{
+			name:           "reference without platform",
+			key:            `{"reference":"` + artifact1 + `"}`,
+			expectArtifact: artifact1,
+		},
+		{
+			name:      "missing reference",
+			key:       `{"os":"linux","arch":"amd64"}`,
+			expectErr: true,
+		},
+		{
+			name:      "invalid JSON",
+			key:       `{"reference":`,
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			artifact, platform, err := parseRequestKey(test.key)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if artifact != test.expectArtifact {
+				t.Errorf("expected artifact %q, got %q", test.expectArtifact, artifact)
+			}
+			if !reflect.DeepEqual(platform, test.expectPlatform) {
+				t.Errorf("expected platform %v, got %v", test.expectPlatform, platform)
+			}
+		})
+	}
+}
+
+func TestVerify_TagNormalization(t *testing.T) {
+	subjectDigest := digest.FromString("subject")
+	digestRef := "test.registry.io/test/image1@" + subjectDigest.String()
+	store := &countingStore{
+		mockStore: mockStore{
+			resolveMap: map[string]ocispec.Descriptor{
+				artifact1: {Digest: subjectDigest},
+				digestRef: {Digest: subjectDigest},
+			},
+		},
+	}
+	storeFactory.RegisterStoreFactory(mockDigestStoreType, func(_ *storeFactory.NewStoreOptions) (ratify.Store, error) {
+		return store, nil
+	})
+	scopedExecutor, err := executor.NewScopedExecutor(&executor.Options{
+		Executors: []*executor.ScopedOptions{
+			{
+				Scopes: []string{"test.registry.io"},
+				Verifiers: []*factory.NewVerifierOptions{
+					{
+						Name: mockVerifierName,
+						Type: mockVerifierType,
+					},
+				},
+				Stores: []*storeFactory.NewStoreOptions{
+					{
+						Type: mockDigestStoreType,
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+	verifyCache, err := ristretto.NewRistrettoCache(time.Minute)
+	if err != nil {
+		t.Fatalf("failed to create cache: %v", err)
+	}
+	server := &server{
+		getExecutor: func() *executor.ScopedExecutor {
+			return scopedExecutor
+		},
+		cache:   verifyCache,
+		sfGroup: new(singleflight.Group),
+	}
+
+	body := fmt.Sprintf(`{"request": {"keys": [%q, %q, %q]}}`, artifact1, digestRef, artifact1)
+	req := httptest.NewRequest(http.MethodPost, "/verify", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	if err := server.verify(context.Background(), w, req); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var response externaldata.ProviderResponse
+	if err := json.NewDecoder(w.Body).Decode(&response); err != nil {
+		t.Fatalf("failed to decode response: %v", err)
+	}
+	for _, item := range response.Response.Items {
+		if item.Error != "" {
+			t.Errorf("unexpected error for %s: %s", item.Key, item.Error)
+		}
+	}
+	if calls := store.listCalls.Load(); calls != 1 {
+		t.Errorf("expected tag and digest references to share the verify cache, got %d validations", calls)
+	}
+}
+
+func TestInvalidateReferrers(t *testing.T) {
+	const (
+		testToken        = "test-token"
+		referrersStore   = "mock-referrers-cache-store-type"
+		subjectReference = "test.registry.io/test/image1@sha256:498138d40d54f0fc20cd271e215366d3d8803f814b8f565b47c101480bbaaa88"
+	)
+	store := &countingStore{}
+	storeFactory.RegisterStoreFactory(referrersStore, func(_ *storeFactory.NewStoreOptions) (ratify.Store, error) {
+		return store, nil
+	})
+	scopedExecutor, err := executor.NewScopedExecutor(&executor.Options{
+		Executors: []*executor.ScopedOptions{
+			{
+				Scopes: []string{"test.registry.io"},
+				Verifiers: []*factory.NewVerifierOptions{
+					{
+						Name: mockVerifierName,
+						Type: mockVerifierType,
+					},
+				},
+				Stores: []*storeFactory.NewStoreOptions{
+					{
+						Type:           referrersStore,
+						ReferrersCache: &referrerscache.Options{},
+					},
+				},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create executor: %v", err)
+	}
+	validate := func() {
+		if _, err := scopedExecutor.ValidateArtifact(context.Background(), subjectReference, executor.ValidateArtifactOptions{}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		name            string
+		authorization   string
+		requestBody     string
+		expectedError   bool
+		expectedStatus  int
+		expectedListing bool
+	}{
+		{
+			name: "Distribution notification envelope",
+			requestBody: `{
+				"events": [
+					{
+						"action": "push",
+						"target": {"repository": "test/image1", "digest": "sha256:498138d40d54f0fc20cd271e215366d3d8803f814b8f565b47c101480bbaaa88"},
+						"request": {"host": "test.registry.io"}
+					},
+					{
+						"action": "pull",
+						"target": {"repository": "test/image1"},
+						"request": {"host": "test.registry.io"}
+					}
+				]
+			}`,
+			authorization:   "Bearer " + testToken,
+			expectedStatus:  http.StatusOK,
+			expectedListing: true,
+		},
+		{
+			name: "Single event",
+			requestBody: `{
+				"action": "delete",
+				"target": {"repository": "test/image1"},
+				"request": {"host": "test.registry.io"}
+			}`,
+			authorization:   "Bearer " + testToken,
+			expectedStatus:  http.StatusOK,
+			expectedListing: true,
+		},
+		{
+			name:           "Event of another repository",
+			authorization:  "Bearer " + testToken,
+			requestBody:    `{"action": "push", "target": {"repository": "test/image2"}, "request": {"host": "test.registry.io"}}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Event without host",
+			authorization:  "Bearer " + testToken,
+			requestBody:    `{"action": "push", "target": {"repository": "test/image1"}}`,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid JSON",
+			authorization:  "Bearer " + testToken,
+			requestBody:    `{invalid-json}`,
+			expectedError:  true,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Missing token",
+			requestBody:    `{"action": "push", "target": {"repository": "test/image1"}, "request": {"host": "test.registry.io"}}`,
+			expectedError:  true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Invalid token",
+			authorization:  "Bearer invalid",
+			requestBody:    `{"action": "push", "target": {"repository": "test/image1"}, "request": {"host": "test.registry.io"}}`,
+			expectedError:  true,
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			validate()
+			listCalls := store.listCalls.Load()
+
+			req := httptest.NewRequest(http.MethodPost, "/webhooks/registry", strings.NewReader(test.requestBody))
+			if test.authorization != "" {
+				req.Header.Set("Authorization", test.authorization)
+			}
+			w := httptest.NewRecorder()
+			server := &server{
+				getExecutor: func() *executor.ScopedExecutor {
+					return scopedExecutor
+				},
+				registryWebhookToken: []byte(testToken),
+			}
+			if err := server.invalidateReferrers(context.Background(), w, req); (err != nil) != test.expectedError {
+				t.Errorf("expected error: %v, got: %v", test.expectedError, err)
+			}
+			if w.Code != test.expectedStatus {
+				t.Errorf("expected status %d, got %d", test.expectedStatus, w.Code)
+			}
+
+			validate()
+			if listed := store.listCalls.Load() != listCalls; listed != test.expectedListing {
+				t.Errorf("expected referrers listing after notification: %v, got: %v", test.expectedListing, listed)
+			}
+		})
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"encoding/json"
 
 	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/executor"
 	"github.com/sirupsen/logrus"
 )
 
@@ -34,6 +35,7 @@ type verificationResult struct {
 type validationReport struct {
 	Subject         string                `json:"subject"`
 	Artifact        string                `json:"artifact"`
+	Platform        string                `json:"platform,omitempty"`
 	Results         []*verificationResult `json:"results,omitempty"`
 	ArtifactReports []*validationReport   `json:"artifactReports,omitempty"`
 }
@@ -74,6 +76,7 @@ func convertValidationReport(src *ratify.ValidationReport) *validationReport {
 	report := &validationReport{
 		Subject:         src.Subject,
 		Artifact:        src.Artifact.Digest.String(),
+		Platform:        executor.FormatPlatform(src.Artifact.Platform),
 		ArtifactReports: convertValidationReports(src.ArtifactReports),
 	}
 
@@ -135,6 +135,41 @@ func TestConvertResult(t *testing.T) {
 				},
 			},
 		},
+		{
+			name: "platform manifest report",
+			src: &ratify.ValidationResult{
+				Succeeded: true,
+				ArtifactReports: []*ratify.ValidationReport{
+					{
+						Subject: subject1,
+						Artifact: ocispec.Descriptor{
+							Digest:   "sha256:manifest",
+							Platform: &ocispec.Platform{OS: "linux", Architecture: "arm64", Variant: "v8"},
+						},
+						ArtifactReports: []*ratify.ValidationReport{
+							{
+								Subject: subject2,
+							},
+						},
+					},
+				},
+			},
+			expected: &result{
+				Succeeded: true,
+				ArtifactReports: []*validationReport{
+					{
+						Subject:  subject1,
+						Artifact: "sha256:manifest",
+						Platform: "linux/arm64/v8",
+						ArtifactReports: []*validationReport{
+							{
+								Subject: subject2,
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -16,8 +16,10 @@ limitations under the License.
 package httpserver
 
 import (
+	"bytes"
 	"context"
 	"crypto/tls"
+	"errors"
 	"fmt"
 	"net/http"
 	"net/url"
@@ -41,6 +43,7 @@ const (
 	serverRootURL        = "/ratify/gatekeeper/v2"
 	verifyPath           = "verify"
 	mutatePath           = "mutate"
+	registryWebhookPath  = "webhooks/registry"
 	defaultVerifyTimeout = 5 * time.Second
 	defaultMutateTimeout = 2 * time.Second
 	readTimeout          = 5 * time.Second
@@ -54,6 +57,11 @@ type server struct {
 	router      *mux.Router
 	cache       cache.Cache
 	sfGroup     *singleflight.Group
+
+	// registryWebhookRouter routes the requests of the registry webhook
+	// listener. It is nil if the registry webhook is disabled.
+	registryWebhookRouter *mux.Router
+	registryWebhookToken  []byte
 	ServerOptions
 }
 
@@ -96,6 +104,21 @@ type ServerOptions struct {
 
 	DisableCRDManager bool
 
+	// RegistryWebhookAddress is the address of a separate listener receiving
+	// registry notifications, e.g. ":6002". Pushes and deletions notified by
+	// the registry invalidate the cached referrers of the affected repository.
+	// The listener serves TLS with the server certificate if configured, but
+	// does not require Gatekeeper client certificates. If not provided,
+	// registry notifications are not received.
+	// Optional.
+	RegistryWebhookAddress string
+
+	// RegistryWebhookTokenFile is the path to the file holding the token
+	// registries must send as bearer token in the Authorization header of
+	// notifications.
+	// Required if RegistryWebhookAddress is provided.
+	RegistryWebhookTokenFile string
+
 	// CertRotatorReady is a channel that signals when the certificate rotator
 	// is ready. If not provided, the server will run without rotating the TLS
 	// certificates.
@@ -149,6 +172,12 @@ func newServer(serverOpts *ServerOptions, executorConfigPath string) (*server, *
 	if server.MutateTimeout == 0 {
 		server.MutateTimeout = defaultMutateTimeout
 	}
+	if server.RegistryWebhookAddress != "" {
+		if server.registryWebhookToken, err = readRegistryWebhookToken(server.RegistryWebhookTokenFile); err != nil {
+			return nil, nil, err
+		}
+		server.registryWebhookRouter = mux.NewRouter()
+	}
 
 	if err := server.registerHandlers(); err != nil {
 		return nil, nil, fmt.Errorf("failed to register handlers: %w", err)
@@ -166,6 +195,12 @@ func (s *server) registerHandlers() error {
 			return err
 		}
 	}
+
+	if s.registryWebhookRouter != nil {
+		if err := s.registerRegistryWebhookHandler(); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -188,6 +223,32 @@ func (s *server) registerVerifyHandler() error {
 	return nil
 }
 
+func (s *server) registerRegistryWebhookHandler() error {
+	webhookURL, err := url.JoinPath(serverRootURL, registryWebhookPath)
+	if err != nil {
+		return err
+	}
+	s.registryWebhookRouter.Methods(http.MethodPost).Path(webhookURL).Handler(middlewareWithTimeout(s.registryWebhookHandler(), s.VerifyTimeout))
+	return nil
+}
+
+// readRegistryWebhookToken reads the token registries must present to the
+// registry webhook.
+func readRegistryWebhookToken(tokenFile string) ([]byte, error) {
+	if tokenFile == "" {
+		return nil, errors.New("registry webhook token file is required if the registry webhook is enabled")
+	}
+	token, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read registry webhook token: %w", err)
+	}
+	token = bytes.TrimSpace(token)
+	if len(token) == 0 {
+		return nil, fmt.Errorf("registry webhook token file %s is empty", tokenFile)
+	}
+	return token, nil
+}
+
 func (s *server) verifyHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		_ = s.verify(r.Context(), w, r)
@@ -200,6 +261,14 @@ func (s *server) mutateHandler() http.HandlerFunc {
 	}
 }
 
+func (s *server) registryWebhookHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := s.invalidateReferrers(r.Context(), w, r); err != nil {
+			logrus.Warnf("failed to handle registry notification: %v", err)
+		}
+	}
+}
+
 func middlewareWithTimeout(next http.Handler, timeout time.Duration) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx, cancel := context.WithTimeout(r.Context(), timeout)
@@ -208,16 +277,11 @@ func middlewareWithTimeout(next http.Handler, timeout time.Duration) http.Handle
 	})
 }
 
-// Run starts the HTTP server and listens for incoming requests.
+// Run starts the HTTP server and listens for incoming requests. The registry
+// webhook, if enabled, is served by a separate HTTP server.
 // It also handles graceful shutdown on receiving an interrupt signal.
 func (s *server) Run(certRotatorReady chan struct{}, configWatcher *config.Watcher) error {
-	srv := &http.Server{
-		Addr:         s.HTTPServerAddress,
-		Handler:      s.router,
-		WriteTimeout: writeTimeout,
-		ReadTimeout:  readTimeout,
-		IdleTimeout:  idleTimeout,
-	}
+	srv := newHTTPServer(s.HTTPServerAddress, s.router)
 	go func() {
 		// Start the configuration watcher (if any) and ensure
 		// it is properly stopped when the server goroutine exits.
@@ -228,41 +292,17 @@ func (s *server) Run(certRotatorReady chan struct{}, configWatcher *config.Watch
 			}
 			defer configWatcher.Stop()
 		}
-
-		if s.CertFile != "" && s.KeyFile != "" {
-			logrus.Infof("starting server with TLS at %s", s.HTTPServerAddress)
-			if certRotatorReady != nil {
-				<-certRotatorReady
-				logrus.Infof("cert rotator is ready")
-			}
-
-			certWatcher, err := tlssecret.NewWatcher(s.GatekeeperCACertFile, s.CertFile, s.KeyFile)
-			if err != nil {
-				logrus.Errorf("failed to create TLS secret watcher: %v", err)
-				return
-			}
-			if err = certWatcher.Start(); err != nil {
-				logrus.Errorf("failed to start TLS secret watcher: %v", err)
-				return
-			}
-			defer certWatcher.Stop()
-
-			// Use GetConfigForClient to dynamically load certificates.
-			srv.TLSConfig = &tls.Config{
-				MinVersion:         tls.VersionTLS13,
-				GetConfigForClient: certWatcher.GetConfigForClient,
-			}
-			if err := srv.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
-				logrus.Errorf("failed to start server: %v", err)
-			}
-		} else {
-			logrus.Infof("starting server without TLS at %s", s.HTTPServerAddress)
-			if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-				logrus.Errorf("failed to start server: %v", err)
-			}
-		}
+		s.listenAndServe(srv, certRotatorReady, s.GatekeeperCACertFile)
 	}()
 
+	var registryWebhookSrv *http.Server
+	if s.registryWebhookRouter != nil {
+		registryWebhookSrv = newHTTPServer(s.RegistryWebhookAddress, s.registryWebhookRouter)
+		// registries are not Gatekeeper, so no client certificate is
+		// required.
+		go s.listenAndServe(registryWebhookSrv, certRotatorReady, "")
+	}
+
 	// Handle graceful shutdown.
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, os.Interrupt, syscall.SIGTERM)
@@ -270,9 +310,63 @@ func (s *server) Run(certRotatorReady chan struct{}, configWatcher *config.Watch
 
 	ctx, cancel := context.WithTimeout(context.Background(), s.VerifyTimeout)
 	defer cancel()
+	if registryWebhookSrv != nil {
+		if err := registryWebhookSrv.Shutdown(ctx); err != nil {
+			logrus.Errorf("failed to shutdown registry webhook server: %v", err)
+			return err
+		}
+	}
 	if err := srv.Shutdown(ctx); err != nil {
 		logrus.Errorf("failed to shutdown server: %v", err)
 		return err
 	}
 	return nil
 }
+
+func newHTTPServer(addr string, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:         addr,
+		Handler:      handler,
+		WriteTimeout: writeTimeout,
+		ReadTimeout:  readTimeout,
+		IdleTimeout:  idleTimeout,
+	}
+}
+
+// listenAndServe serves the HTTP server with TLS if the server certificate is
+// configured. Client certificates issued by the CA in caCertFile are required
+// if provided.
+func (s *server) listenAndServe(srv *http.Server, certRotatorReady chan struct{}, caCertFile string) {
+	if s.CertFile != "" && s.KeyFile != "" {
+		logrus.Infof("starting server with TLS at %s", srv.Addr)
+		if certRotatorReady != nil {
+			<-certRotatorReady
+			logrus.Infof("cert rotator is ready")
+		}
+
+		certWatcher, err := tlssecret.NewWatcher(caCertFile, s.CertFile, s.KeyFile)
+		if err != nil {
+			logrus.Errorf("failed to create TLS secret watcher: %v", err)
+			return
+		}
+		if err = certWatcher.Start(); err != nil {
+			logrus.Errorf("failed to start TLS secret watcher: %v", err)
+			return
+		}
+		defer certWatcher.Stop()
+
+		// Use GetConfigForClient to dynamically load certificates.
+		srv.TLSConfig = &tls.Config{
+			MinVersion:         tls.VersionTLS13,
+			GetConfigForClient: certWatcher.GetConfigForClient,
+		}
+		if err := srv.ListenAndServeTLS("", ""); err != nil && err != http.ErrServerClosed {
+			logrus.Errorf("failed to start server: %v", err)
+		}
+	} else {
+		logrus.Infof("starting server without TLS at %s", srv.Addr)
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			logrus.Errorf("failed to start server: %v", err)
+		}
+	}
+}
@@ -26,12 +26,15 @@ import (
 	"encoding/pem"
 	"fmt"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"syscall"
 	"testing"
 	"time"
 
+	"github.com/gorilla/mux"
 	"github.com/notaryproject/ratify-go"
 	"github.com/notaryproject/ratify/v2/internal/executor"
 	storeFactory "github.com/notaryproject/ratify/v2/internal/store/factory"
@@ -138,6 +141,69 @@ func TestStartServer(t *testing.T) {
 	}
 }
 
+func TestNewServer_RegistryWebhook(t *testing.T) {
+	tempDir := t.TempDir()
+	tokenPath := filepath.Join(tempDir, "token")
+	if err := os.WriteFile(tokenPath, []byte("test-token\n"), 0600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+	emptyTokenPath := filepath.Join(tempDir, "empty")
+	if err := os.WriteFile(emptyTokenPath, []byte("\n"), 0600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		tokenFile     string
+		expectedError bool
+	}{
+		{
+			name:      "Valid token file",
+			tokenFile: tokenPath,
+		},
+		{
+			name:          "Missing token file",
+			expectedError: true,
+		},
+		{
+			name:          "Non-existent token file",
+			tokenFile:     filepath.Join(tempDir, "non-existent"),
+			expectedError: true,
+		},
+		{
+			name:          "Empty token file",
+			tokenFile:     emptyTokenPath,
+			expectedError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server, _, err := newServer(&ServerOptions{
+				RegistryWebhookAddress:   ":8081",
+				RegistryWebhookTokenFile: test.tokenFile,
+			}, "")
+			if (err != nil) != test.expectedError {
+				t.Fatalf("expected error: %v, got: %v", test.expectedError, err)
+			}
+			if err != nil {
+				return
+			}
+			if string(server.registryWebhookToken) != "test-token" {
+				t.Errorf("expected token %q, got %q", "test-token", server.registryWebhookToken)
+			}
+			webhookURL := serverRootURL + "/" + registryWebhookPath
+			var match mux.RouteMatch
+			if !server.registryWebhookRouter.Match(httptest.NewRequest(http.MethodPost, webhookURL, nil), &match) {
+				t.Error("expected registry webhook to be served by the registry webhook listener")
+			}
+			if server.router.Match(httptest.NewRequest(http.MethodPost, webhookURL, nil), &match) {
+				t.Error("expected registry webhook not to be served by the Gatekeeper listener")
+			}
+		})
+	}
+}
+
 func TestStartServer_NoTLS(t *testing.T) {
 	tempDir := t.TempDir()
 
@@ -168,8 +234,14 @@ func TestStartServer_NoTLS(t *testing.T) {
 	if err := os.WriteFile(configPath, raw, 0600); err != nil {
 		t.Fatalf("failed to write config file: %v", err)
 	}
+	tokenPath := filepath.Join(tempDir, "token")
+	if err := os.WriteFile(tokenPath, []byte("test-token\n"), 0600); err != nil {
+		t.Fatalf("failed to write token file: %v", err)
+	}
 	serverOpts := &ServerOptions{
-		HTTPServerAddress: ":8080",
+		HTTPServerAddress:        ":8080",
+		RegistryWebhookAddress:   ":8081",
+		RegistryWebhookTokenFile: tokenPath,
 	}
 
 	errChan := make(chan error)
@@ -0,0 +1,164 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blobcache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/notaryproject/ratify-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultMaxMemoryBytes = 64 * 1024 * 1024 // 64 MiB
+
+// Options configures the content cache placed in front of a store.
+type Options struct {
+	// Directory is the path of the on-disk cache. The directory can be a
+	// volume shared by multiple replicas. If not set, the on-disk cache is
+	// disabled. Optional.
+	Directory string `json:"directory,omitempty"`
+
+	// MaxDiskBytes is the maximum total size of the on-disk cache in bytes.
+	// Least recently used entries are evicted once the limit is exceeded.
+	// Zero means no limit. Optional.
+	MaxDiskBytes int64 `json:"maxDiskBytes,omitempty"`
+
+	// InMemory enables the in-memory cache. Optional.
+	InMemory bool `json:"inMemory,omitempty"`
+
+	// MaxMemoryBytes is the maximum total size of the in-memory cache in
+	// bytes. Defaults to 64 MiB if InMemory is enabled. Optional.
+	MaxMemoryBytes int64 `json:"maxMemoryBytes,omitempty"`
+}
+
+// Store is a [ratify.Store] that caches manifests and blobs of the underlying
+// store by digest. Since the content is addressed by digest and thus
+// immutable, cached entries never expire and are only evicted when the cache
+// size exceeds the configured limit. Every cached entry is verified against
+// the requested digest before it is returned.
+type Store struct {
+	ratify.Store
+	memory *memoryCache
+	disk   *diskCache
+}
+
+// New creates a new [Store] caching the content fetched from the given store.
+func New(store ratify.Store, opts *Options) (*Store, error) {
+	if store == nil {
+		return nil, errors.New("store cannot be nil")
+	}
+	if opts == nil || (opts.Directory == "" && !opts.InMemory) {
+		return nil, errors.New("at least one of directory or inMemory must be set for the cache")
+	}
+	if opts.MaxDiskBytes < 0 || opts.MaxMemoryBytes < 0 {
+		return nil, errors.New("cache size limits must not be negative")
+	}
+
+	s := &Store{Store: store}
+	if opts.InMemory {
+		maxBytes := opts.MaxMemoryBytes
+		if maxBytes == 0 {
+			maxBytes = defaultMaxMemoryBytes
+		}
+		s.memory = newMemoryCache(maxBytes)
+	}
+	if opts.Directory != "" {
+		disk, err := newDiskCache(opts.Directory, opts.MaxDiskBytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create disk cache: %w", err)
+		}
+		s.disk = disk
+	}
+	return s, nil
+}
+
+// FetchBlob returns the blob from the cache if present, otherwise fetches it
+// from the underlying store and caches it.
+func (s *Store) FetchBlob(ctx context.Context, repo string, desc ocispec.Descriptor) ([]byte, error) {
+	return s.fetch(desc, func() ([]byte, error) {
+		return s.Store.FetchBlob(ctx, repo, desc)
+	})
+}
+
+// FetchManifest returns the manifest from the cache if present, otherwise
+// fetches it from the underlying store and caches it.
+func (s *Store) FetchManifest(ctx context.Context, repo string, desc ocispec.Descriptor) ([]byte, error) {
+	return s.fetch(desc, func() ([]byte, error) {
+		return s.Store.FetchManifest(ctx, repo, desc)
+	})
+}
+
+func (s *Store) fetch(desc ocispec.Descriptor, fetchFn func() ([]byte, error)) ([]byte, error) {
+	if err := desc.Digest.Validate(); err != nil {
+		// content without a valid digest cannot be cached.
+		return fetchFn()
+	}
+
+	if s.memory != nil {
+		if content, ok := s.memory.get(desc.Digest); ok {
+			if verifyContent(desc, content) {
+				return content, nil
+			}
+			s.memory.remove(desc.Digest)
+		}
+	}
+	if s.disk != nil {
+		content, err := s.disk.get(desc.Digest)
+		if err != nil {
+			logrus.Warnf("failed to read %s from disk cache: %v", desc.Digest, err)
+		} else if content != nil {
+			if verifyContent(desc, content) {
+				if s.memory != nil {
+					s.memory.set(desc.Digest, content)
+				}
+				return content, nil
+			}
+			logrus.Warnf("discarding corrupted disk cache entry %s", desc.Digest)
+			s.disk.remove(desc.Digest)
+		}
+	}
+
+	content, err := fetchFn()
+	if err != nil {
+		return nil, err
+	}
+	if !verifyContent(desc, content) {
+		// do not cache content not matching the descriptor. The caller is
+		// responsible for validating the content.
+		return content, nil
+	}
+	if s.memory != nil {
+		s.memory.set(desc.Digest, content)
+	}
+	if s.disk != nil {
+		if err := s.disk.set(desc.Digest, content); err != nil {
+			logrus.Warnf("failed to write %s to disk cache: %v", desc.Digest, err)
+		}
+	}
+	return content, nil
+}
+
+// verifyContent returns true if the content matches the digest and, if set,
+// the size of the descriptor.
+func verifyContent(desc ocispec.Descriptor, content []byte) bool {
+	if desc.Size > 0 && int64(len(content)) != desc.Size {
+		return false
+	}
+	return desc.Digest.Algorithm().Available() && desc.Digest.Algorithm().FromBytes(content) == desc.Digest
+}
@@ -0,0 +1,239 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blobcache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// countingStore is a mock store counting the content fetches.
+type countingStore struct {
+	content map[digest.Digest][]byte
+	fetches int
+	err     error
+}
+
+func (s *countingStore) Resolve(_ context.Context, _ string) (ocispec.Descriptor, error) {
+	return ocispec.Descriptor{}, nil
+}
+
+func (s *countingStore) ListReferrers(_ context.Context, _ string, _ []string, _ func(referrers []ocispec.Descriptor) error) error {
+	return nil
+}
+
+func (s *countingStore) FetchBlob(_ context.Context, _ string, desc ocispec.Descriptor) ([]byte, error) {
+	s.fetches++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.content[desc.Digest], nil
+}
+
+func (s *countingStore) FetchManifest(ctx context.Context, repo string, desc ocispec.Descriptor) ([]byte, error) {
+	return s.FetchBlob(ctx, repo, desc)
+}
+
+func newCountingStore(contents ...[]byte) (*countingStore, []ocispec.Descriptor) {
+	store := &countingStore{content: make(map[digest.Digest][]byte)}
+	descs := make([]ocispec.Descriptor, len(contents))
+	for i, content := range contents {
+		descs[i] = ocispec.Descriptor{
+			Digest: digest.FromBytes(content),
+			Size:   int64(len(content)),
+		}
+		store.content[descs[i].Digest] = content
+	}
+	return store, descs
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      *Options
+		expectErr bool
+	}{
+		{
+			name:      "nil options",
+			expectErr: true,
+		},
+		{
+			name:      "no cache enabled",
+			opts:      &Options{},
+			expectErr: true,
+		},
+		{
+			name:      "negative size",
+			opts:      &Options{InMemory: true, MaxMemoryBytes: -1},
+			expectErr: true,
+		},
+		{
+			name: "in-memory cache",
+			opts: &Options{InMemory: true},
+		},
+		{
+			name: "disk cache",
+			opts: &Options{Directory: t.TempDir(), MaxDiskBytes: 1024},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := New(&countingStore{}, test.opts)
+			if (err != nil) != test.expectErr {
+				t.Errorf("expected error: %v, got: %v", test.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestStore_Fetch(t *testing.T) {
+	tests := []struct {
+		name string
+		opts func(t *testing.T) *Options
+	}{
+		{
+			name: "memory only",
+			opts: func(_ *testing.T) *Options {
+				return &Options{InMemory: true}
+			},
+		},
+		{
+			name: "disk only",
+			opts: func(t *testing.T) *Options {
+				return &Options{Directory: t.TempDir()}
+			},
+		},
+		{
+			name: "memory and disk",
+			opts: func(t *testing.T) *Options {
+				return &Options{Directory: t.TempDir(), InMemory: true}
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			underlying, descs := newCountingStore([]byte("signature"))
+			store, err := New(underlying, test.opts(t))
+			if err != nil {
+				t.Fatalf("failed to create store: %v", err)
+			}
+			for range 3 {
+				content, err := store.FetchBlob(context.Background(), "test.io/repo", descs[0])
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !bytes.Equal(content, []byte("signature")) {
+					t.Fatalf("unexpected content: %s", content)
+				}
+				if _, err = store.FetchManifest(context.Background(), "test.io/repo", descs[0]); err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+			}
+			if underlying.fetches != 1 {
+				t.Errorf("expected 1 fetch from the underlying store, got %d", underlying.fetches)
+			}
+		})
+	}
+}
+
+func TestStore_FetchError(t *testing.T) {
+	underlying, descs := newCountingStore([]byte("signature"))
+	underlying.err = errors.New("fetch failed")
+	store, err := New(underlying, &Options{InMemory: true})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if _, err = store.FetchBlob(context.Background(), "test.io/repo", descs[0]); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+}
+
+func TestStore_MismatchedContentNotCached(t *testing.T) {
+	underlying, descs := newCountingStore([]byte("signature"))
+	underlying.content[descs[0].Digest] = []byte("tampered!")
+	store, err := New(underlying, &Options{InMemory: true, Directory: t.TempDir()})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	for range 2 {
+		if _, err = store.FetchBlob(context.Background(), "test.io/repo", descs[0]); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if underlying.fetches != 2 {
+		t.Errorf("expected mismatched content not to be cached, got %d fetches", underlying.fetches)
+	}
+}
+
+func TestStore_CorruptedDiskEntry(t *testing.T) {
+	dir := t.TempDir()
+	underlying, descs := newCountingStore([]byte("signature"))
+	store, err := New(underlying, &Options{Directory: dir})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if _, err = store.FetchBlob(context.Background(), "test.io/repo", descs[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	path := filepath.Join(dir, blobsDir, descs[0].Digest.Algorithm().String(), descs[0].Digest.Encoded())
+	if err := os.WriteFile(path, []byte("corrupted"), 0o644); err != nil {
+		t.Fatalf("failed to corrupt cache entry: %v", err)
+	}
+	content, err := store.FetchBlob(context.Background(), "test.io/repo", descs[0])
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !bytes.Equal(content, []byte("signature")) {
+		t.Fatalf("unexpected content: %s", content)
+	}
+	if underlying.fetches != 2 {
+		t.Errorf("expected corrupted entry to be refetched, got %d fetches", underlying.fetches)
+	}
+}
+
+func TestStore_SharedDirectory(t *testing.T) {
+	dir := t.TempDir()
+	underlying, descs := newCountingStore([]byte("signature"))
+	first, err := New(underlying, &Options{Directory: dir})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if _, err = first.FetchBlob(context.Background(), "test.io/repo", descs[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// a second replica mounting the same directory reuses the cached entry.
+	second, err := New(underlying, &Options{Directory: dir})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if _, err = second.FetchBlob(context.Background(), "test.io/repo", descs[0]); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if underlying.fetches != 1 {
+		t.Errorf("expected 1 fetch from the underlying store, got %d", underlying.fetches)
+	}
+}
@@ -0,0 +1,211 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blobcache
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	blobsDir       = "blobs"
+	tempFilePrefix = ".tmp-"
+)
+
+// diskCache is a size-bounded cache of content keyed by digest and stored in
+// a directory using the same layout as the blobs of an OCI image layout, i.e.
+// "blobs/<algorithm>/<encoded>".
+//
+// Entries are written to a temporary file and atomically renamed, so the
+// directory can be safely shared by multiple processes, e.g. replicas mounting
+// the same volume. The modification time of an entry is refreshed on every
+// read and used to evict the least recently used entries.
+type diskCache struct {
+	root     string
+	maxBytes int64
+
+	mu   sync.Mutex
+	size int64
+}
+
+func newDiskCache(root string, maxBytes int64) (*diskCache, error) {
+	if err := os.MkdirAll(filepath.Join(root, blobsDir), 0o755); err != nil {
+		return nil, err
+	}
+	c := &diskCache{
+		root:     root,
+		maxBytes: maxBytes,
+	}
+	entries, size, err := c.scan()
+	if err != nil {
+		return nil, err
+	}
+	logrus.Infof("loaded disk cache at %s with %d entries (%d bytes)", root, len(entries), size)
+	c.size = size
+	return c, nil
+}
+
+// get returns the cached content, or nil if the content is not cached.
+func (c *diskCache) get(dgst digest.Digest) ([]byte, error) {
+	path := c.path(dgst)
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	now := time.Now()
+	if err := os.Chtimes(path, now, now); err != nil {
+		logrus.Debugf("failed to refresh access time of disk cache entry %s: %v", dgst, err)
+	}
+	return content, nil
+}
+
+// set writes the content to the cache and evicts the least recently used
+// entries if the cache exceeds its size limit.
+func (c *diskCache) set(dgst digest.Digest, content []byte) error {
+	size := int64(len(content))
+	if c.maxBytes > 0 && size > c.maxBytes {
+		return nil
+	}
+	path := c.path(dgst)
+	if _, err := os.Stat(path); err == nil {
+		return nil
+	}
+
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, tempFilePrefix)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(content); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return err
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.size += size
+	if c.maxBytes > 0 && c.size > c.maxBytes {
+		c.evict()
+	}
+	return nil
+}
+
+// remove deletes the content from the cache.
+func (c *diskCache) remove(dgst digest.Digest) {
+	info, err := os.Stat(c.path(dgst))
+	if err != nil {
+		return
+	}
+	if err := os.Remove(c.path(dgst)); err != nil {
+		logrus.Debugf("failed to remove disk cache entry %s: %v", dgst, err)
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.size -= info.Size()
+}
+
+// evict removes the least recently used entries until the cache size drops
+// below 90% of its limit. The directory is rescanned since other processes may
+// share it. The caller must hold c.mu.
+func (c *diskCache) evict() {
+	entries, size, err := c.scan()
+	if err != nil {
+		logrus.Warnf("failed to scan disk cache for eviction: %v", err)
+		return
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].modTime.Before(entries[j].modTime)
+	})
+	target := c.maxBytes / 10 * 9
+	for _, entry := range entries {
+		if size <= target {
+			break
+		}
+		if err := os.Remove(entry.path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			logrus.Debugf("failed to evict disk cache entry %s: %v", entry.path, err)
+			continue
+		}
+		size -= entry.size
+	}
+	c.size = size
+}
+
+type diskEntry struct {
+	path    string
+	size    int64
+	modTime time.Time
+}
+
+// scan lists all entries of the cache and their total size.
+func (c *diskCache) scan() ([]diskEntry, int64, error) {
+	var entries []diskEntry
+	var total int64
+	err := filepath.WalkDir(filepath.Join(c.root, blobsDir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				// the entry may be removed by another process.
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if strings.HasPrefix(d.Name(), tempFilePrefix) {
+			// skip temporary files being written.
+			return nil
+		}
+		entries = append(entries, diskEntry{
+			path:    path,
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+		total += info.Size()
+		return nil
+	})
+	return entries, total, err
+}
+
+func (c *diskCache) path(dgst digest.Digest) string {
+	return filepath.Join(c.root, blobsDir, dgst.Algorithm().String(), dgst.Encoded())
+}
@@ -0,0 +1,108 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blobcache
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+)
+
+func TestDiskCache_SetGet(t *testing.T) {
+	cache, err := newDiskCache(t.TempDir(), 0)
+	if err != nil {
+		t.Fatalf("failed to create disk cache: %v", err)
+	}
+	content := []byte("content")
+	dgst := digest.FromBytes(content)
+
+	got, err := cache.get(dgst)
+	if err != nil || got != nil {
+		t.Fatalf("expected cache miss, got %q, %v", got, err)
+	}
+	if err := cache.set(dgst, content); err != nil {
+		t.Fatalf("failed to set content: %v", err)
+	}
+	got, err = cache.get(dgst)
+	if err != nil || !bytes.Equal(got, content) {
+		t.Fatalf("expected %q, got %q, %v", content, got, err)
+	}
+	cache.remove(dgst)
+	if got, _ = cache.get(dgst); got != nil {
+		t.Errorf("expected content to be removed, got %q", got)
+	}
+}
+
+func TestDiskCache_Evict(t *testing.T) {
+	cache, err := newDiskCache(t.TempDir(), 10)
+	if err != nil {
+		t.Fatalf("failed to create disk cache: %v", err)
+	}
+	a, b, c := []byte("aaaa"), []byte("bbbb"), []byte("cccc")
+	dgstA, dgstB, dgstC := digest.FromBytes(a), digest.FromBytes(b), digest.FromBytes(c)
+
+	if err := cache.set(dgstA, a); err != nil {
+		t.Fatalf("failed to set content: %v", err)
+	}
+	if err := cache.set(dgstB, b); err != nil {
+		t.Fatalf("failed to set content: %v", err)
+	}
+	// make a the least recently used entry regardless of the file system
+	// timestamp resolution.
+	past := time.Now().Add(-time.Hour)
+	if err := os.Chtimes(cache.path(dgstA), past, past); err != nil {
+		t.Fatalf("failed to change times: %v", err)
+	}
+	if err := cache.set(dgstC, c); err != nil {
+		t.Fatalf("failed to set content: %v", err)
+	}
+
+	if got, _ := cache.get(dgstA); got != nil {
+		t.Error("expected a to be evicted")
+	}
+	if got, _ := cache.get(dgstB); got == nil {
+		t.Error("expected b to be cached")
+	}
+	if got, _ := cache.get(dgstC); got == nil {
+		t.Error("expected c to be cached")
+	}
+	if cache.size != 8 {
+		t.Errorf("expected size 8, got %d", cache.size)
+	}
+}
+
+func TestDiskCache_LoadExisting(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := newDiskCache(dir, 0)
+	if err != nil {
+		t.Fatalf("failed to create disk cache: %v", err)
+	}
+	content := []byte("content")
+	if err := cache.set(digest.FromBytes(content), content); err != nil {
+		t.Fatalf("failed to set content: %v", err)
+	}
+
+	reloaded, err := newDiskCache(dir, 0)
+	if err != nil {
+		t.Fatalf("failed to create disk cache: %v", err)
+	}
+	if reloaded.size != int64(len(content)) {
+		t.Errorf("expected size %d, got %d", len(content), reloaded.size)
+	}
+}
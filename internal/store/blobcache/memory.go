@@ -0,0 +1,97 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blobcache
+
+import (
+	"container/list"
+	"sync"
+
+	"github.com/opencontainers/go-digest"
+)
+
+// memoryCache is a size-bounded LRU cache of content keyed by digest.
+type memoryCache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	size     int64
+	entries  map[digest.Digest]*list.Element
+	lru      *list.List
+}
+
+type memoryEntry struct {
+	digest  digest.Digest
+	content []byte
+}
+
+func newMemoryCache(maxBytes int64) *memoryCache {
+	return &memoryCache{
+		maxBytes: maxBytes,
+		entries:  make(map[digest.Digest]*list.Element),
+		lru:      list.New(),
+	}
+}
+
+// get returns the cached content and marks it as recently used.
+func (c *memoryCache) get(dgst digest.Digest) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.entries[dgst]
+	if !ok {
+		return nil, false
+	}
+	c.lru.MoveToFront(elem)
+	return elem.Value.(*memoryEntry).content, true
+}
+
+// set adds the content to the cache and evicts the least recently used
+// entries if the cache exceeds its size limit. Content larger than the limit
+// is not cached.
+func (c *memoryCache) set(dgst digest.Digest, content []byte) {
+	size := int64(len(content))
+	if size > c.maxBytes {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[dgst]; ok {
+		c.lru.MoveToFront(elem)
+		return
+	}
+	c.entries[dgst] = c.lru.PushFront(&memoryEntry{
+		digest:  dgst,
+		content: content,
+	})
+	c.size += size
+	for c.size > c.maxBytes {
+		c.removeElement(c.lru.Back())
+	}
+}
+
+// remove deletes the content from the cache.
+func (c *memoryCache) remove(dgst digest.Digest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if elem, ok := c.entries[dgst]; ok {
+		c.removeElement(elem)
+	}
+}
+
+func (c *memoryCache) removeElement(elem *list.Element) {
+	entry := c.lru.Remove(elem).(*memoryEntry)
+	delete(c.entries, entry.digest)
+	c.size -= int64(len(entry.content))
+}
@@ -0,0 +1,74 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package blobcache
+
+import (
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+func TestMemoryCache_LRU(t *testing.T) {
+	cache := newMemoryCache(10)
+	a, b, c := []byte("aaaa"), []byte("bbbb"), []byte("cccc")
+	dgstA, dgstB, dgstC := digest.FromBytes(a), digest.FromBytes(b), digest.FromBytes(c)
+
+	cache.set(dgstA, a)
+	cache.set(dgstB, b)
+	// touch a so that b becomes the least recently used entry.
+	if _, ok := cache.get(dgstA); !ok {
+		t.Fatal("expected a to be cached")
+	}
+	cache.set(dgstC, c)
+
+	if _, ok := cache.get(dgstB); ok {
+		t.Error("expected b to be evicted")
+	}
+	if _, ok := cache.get(dgstA); !ok {
+		t.Error("expected a to be cached")
+	}
+	if _, ok := cache.get(dgstC); !ok {
+		t.Error("expected c to be cached")
+	}
+	if cache.size != 8 {
+		t.Errorf("expected size 8, got %d", cache.size)
+	}
+}
+
+func TestMemoryCache_OversizedContent(t *testing.T) {
+	cache := newMemoryCache(2)
+	content := []byte("too large")
+	dgst := digest.FromBytes(content)
+	cache.set(dgst, content)
+	if _, ok := cache.get(dgst); ok {
+		t.Error("expected oversized content not to be cached")
+	}
+}
+
+func TestMemoryCache_Remove(t *testing.T) {
+	cache := newMemoryCache(10)
+	content := []byte("aaaa")
+	dgst := digest.FromBytes(content)
+	cache.set(dgst, content)
+	cache.set(dgst, content)
+	cache.remove(dgst)
+	if _, ok := cache.get(dgst); ok {
+		t.Error("expected content to be removed")
+	}
+	if cache.size != 0 {
+		t.Errorf("expected size 0, got %d", cache.size)
+	}
+}
@@ -19,6 +19,8 @@ import (
 	"fmt"
 
 	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/store/blobcache"
+	"github.com/notaryproject/ratify/v2/internal/store/referrerscache"
 )
 
 // NewStoreOptions defines the options for creating a new store.
@@ -30,6 +32,16 @@ type NewStoreOptions struct {
 
 	// Parameters is additional parameters for the store. Optional.
 	Parameters any `json:"parameters,omitempty"`
+
+	// Cache configures a digest-keyed cache of manifests and blobs in front of
+	// the store. If not set, content is always fetched from the store.
+	// Optional.
+	Cache *blobcache.Options `json:"cache,omitempty"`
+
+	// ReferrersCache configures a cache of the referrers listing of subjects
+	// keyed by subject digest. If not set, referrers are always listed from
+	// the store. Optional.
+	ReferrersCache *referrerscache.Options `json:"referrersCache,omitempty"`
 }
 
 // registeredStores saves the registered store factories.
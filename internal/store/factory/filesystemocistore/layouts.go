@@ -0,0 +1,554 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filesystemocistore
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry"
+)
+
+const (
+	indexFileName          = "index.json"
+	defaultRefreshInterval = 30 * time.Second
+)
+
+// tarballCacheDir is the directory of the decompressed gzipped tarballs.
+var tarballCacheDir = filepath.Join(os.TempDir(), "ratify-oci-layouts")
+
+// decompressedTarballs counts the layouts using each decompressed tarball in
+// tarballCacheDir. The counts are shared across stores so that reloading the
+// executor reuses the tarballs decompressed by the previous executor, and a
+// tarball is removed once no layout uses it.
+var decompressedTarballs = struct {
+	mu   sync.Mutex
+	refs map[string]int
+}{refs: make(map[string]int)}
+
+// layout is a single OCI image layout served by the store.
+type layout struct {
+	path    string
+	modTime time.Time
+	store   *ratify.OCIStore
+
+	// tarball is the decompressed tarball in tarballCacheDir if the layout is
+	// a gzipped tarball.
+	tarball string
+
+	// repositories are the fully qualified repositories referenced by the
+	// "org.opencontainers.image.ref.name" annotations in index.json. A layout
+	// without any fully qualified reference serves all repositories.
+	repositories map[string]struct{}
+}
+
+// close releases the decompressed tarball of the layout.
+func (l *layout) close() {
+	if l.tarball != "" {
+		releaseTarball(l.tarball)
+	}
+}
+
+// serves returns true if the layout is bound to the repository.
+func (l *layout) serves(repo string) bool {
+	_, ok := l.repositories[repo]
+	return ok
+}
+
+// layoutStore is a [ratify.Store] merging multiple OCI image layouts. Each
+// root is either an OCI image layout directory, an OCI image layout tarball
+// (optionally gzipped), or a directory containing such layouts. Directories of
+// layouts are rescanned periodically so that newly added layouts are served
+// without restarting.
+type layoutStore struct {
+	roots           []string
+	refreshInterval time.Duration
+
+	// bindRepositories binds layouts to the repositories of their
+	// "org.opencontainers.image.ref.name" annotations.
+	bindRepositories bool
+
+	// scanMu serializes the scans of the roots.
+	scanMu sync.Mutex
+
+	mu       sync.RWMutex
+	layouts  []*layout
+	lastScan time.Time
+	closed   bool
+}
+
+// newLayoutStore creates a new layoutStore from the given roots.
+func newLayoutStore(ctx context.Context, roots []string, refreshInterval time.Duration, bindRepositories bool) (*layoutStore, error) {
+	if len(roots) == 0 {
+		return nil, errors.New("at least one path is required")
+	}
+	for _, root := range roots {
+		if root == "" {
+			return nil, errors.New("path must be a non-empty string")
+		}
+		if _, err := os.Stat(root); err != nil {
+			return nil, fmt.Errorf("failed to access path %s: %w", root, err)
+		}
+	}
+	s := &layoutStore{
+		roots:            roots,
+		refreshInterval:  refreshInterval,
+		bindRepositories: bindRepositories,
+	}
+	s.scanMu.Lock()
+	defer s.scanMu.Unlock()
+	if err := s.scan(ctx, true); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// Close releases the decompressed tarballs of the layouts.
+func (s *layoutStore) Close() error {
+	s.scanMu.Lock()
+	defer s.scanMu.Unlock()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, l := range s.layouts {
+		l.close()
+	}
+	s.layouts = nil
+	s.closed = true
+	return nil
+}
+
+// Resolve resolves to a descriptor for the given artifact reference.
+func (s *layoutStore) Resolve(ctx context.Context, ref string) (ocispec.Descriptor, error) {
+	for _, l := range s.candidates(ctx, ref) {
+		desc, err := l.store.Resolve(ctx, ref)
+		if err == nil {
+			return desc, nil
+		}
+		if !errors.Is(err, errdef.ErrNotFound) {
+			return ocispec.Descriptor{}, err
+		}
+	}
+	return ocispec.Descriptor{}, fmt.Errorf("%s: %w", ref, errdef.ErrNotFound)
+}
+
+// ListReferrers returns the referrers of the subject merged from all layouts
+// containing the subject.
+func (s *layoutStore) ListReferrers(ctx context.Context, ref string, artifactTypes []string, fn func(referrers []ocispec.Descriptor) error) error {
+	var referrers []ocispec.Descriptor
+	seen := make(map[string]struct{})
+	for _, l := range s.candidates(ctx, ref) {
+		if err := l.store.ListReferrers(ctx, ref, artifactTypes, func(page []ocispec.Descriptor) error {
+			for _, referrer := range page {
+				if _, ok := seen[referrer.Digest.String()]; ok {
+					continue
+				}
+				seen[referrer.Digest.String()] = struct{}{}
+				referrers = append(referrers, referrer)
+			}
+			return nil
+		}); err != nil {
+			return err
+		}
+	}
+	if len(referrers) == 0 {
+		return nil
+	}
+	return fn(referrers)
+}
+
+// FetchBlob returns the blob from the first layout containing it.
+func (s *layoutStore) FetchBlob(ctx context.Context, repo string, desc ocispec.Descriptor) ([]byte, error) {
+	return s.fetch(ctx, repo, desc, func(l *layout) ([]byte, error) {
+		return l.store.FetchBlob(ctx, repo, desc)
+	})
+}
+
+// FetchManifest returns the manifest from the first layout containing it.
+func (s *layoutStore) FetchManifest(ctx context.Context, repo string, desc ocispec.Descriptor) ([]byte, error) {
+	return s.fetch(ctx, repo, desc, func(l *layout) ([]byte, error) {
+		return l.store.FetchManifest(ctx, repo, desc)
+	})
+}
+
+func (s *layoutStore) fetch(ctx context.Context, repo string, desc ocispec.Descriptor, fetchFn func(*layout) ([]byte, error)) ([]byte, error) {
+	for _, l := range s.candidates(ctx, repo) {
+		content, err := fetchFn(l)
+		if err == nil {
+			return content, nil
+		}
+		if !errors.Is(err, errdef.ErrNotFound) {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("%s: %s: %w", desc.Digest, desc.MediaType, errdef.ErrNotFound)
+}
+
+// candidates returns the layouts that may serve the given reference or
+// repository. Layouts bound to the repository come first, followed by layouts
+// not bound to any repository.
+func (s *layoutStore) candidates(ctx context.Context, ref string) []*layout {
+	s.refresh(ctx)
+
+	var repo string
+	if reference, err := registry.ParseReference(ref); err == nil {
+		repo = reference.Registry + "/" + reference.Repository
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var bound, unbound []*layout
+	for _, l := range s.layouts {
+		switch {
+		case len(l.repositories) == 0:
+			unbound = append(unbound, l)
+		case l.serves(repo):
+			bound = append(bound, l)
+		}
+	}
+	return append(bound, unbound...)
+}
+
+// refresh rescans the roots if the refresh interval has elapsed. Requests
+// arriving while another request rescans are served by the current layouts.
+func (s *layoutStore) refresh(ctx context.Context) {
+	s.mu.RLock()
+	stale := !s.closed && s.refreshInterval > 0 && time.Since(s.lastScan) >= s.refreshInterval
+	s.mu.RUnlock()
+	if !stale || !s.scanMu.TryLock() {
+		return
+	}
+	defer s.scanMu.Unlock()
+	if err := s.scan(ctx, false); err != nil {
+		logrus.Warnf("failed to rescan OCI layouts: %v", err)
+	}
+}
+
+// scan loads the layouts found under the roots. Layouts that are unchanged
+// since the last scan are reused. If strict is set, a root that is itself a
+// layout must load successfully; otherwise broken layouts are skipped so that
+// a partially copied layout is picked up by a later scan. The layouts are
+// loaded without holding s.mu, which is only locked to swap them. The caller
+// must hold s.scanMu.
+func (s *layoutStore) scan(ctx context.Context, strict bool) error {
+	s.mu.RLock()
+	existing := make(map[string]*layout, len(s.layouts))
+	for _, l := range s.layouts {
+		existing[l.path] = l
+	}
+	s.mu.RUnlock()
+
+	layouts, err := s.loadLayouts(ctx, existing, strict)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	s.layouts = layouts
+	s.lastScan = time.Now()
+	s.mu.Unlock()
+
+	// release the layouts that were replaced or removed.
+	for _, l := range layouts {
+		if existing[l.path] == l {
+			delete(existing, l.path)
+		}
+	}
+	for _, l := range existing {
+		l.close()
+	}
+	return nil
+}
+
+// loadLayouts loads the layouts found under the roots, reusing the existing
+// layouts that are unchanged.
+func (s *layoutStore) loadLayouts(ctx context.Context, existing map[string]*layout, strict bool) ([]*layout, error) {
+	var layouts []*layout
+	// fail releases the layouts loaded by this scan.
+	fail := func(err error) ([]*layout, error) {
+		for _, l := range layouts {
+			if existing[l.path] != l {
+				l.close()
+			}
+		}
+		return nil, err
+	}
+	for _, root := range s.roots {
+		paths, isLayout, err := layoutPaths(root)
+		if err != nil {
+			return fail(err)
+		}
+		for _, path := range paths {
+			info, err := os.Stat(path)
+			if err != nil {
+				logrus.Warnf("skipping OCI layout %s: %v", path, err)
+				continue
+			}
+			modTime := info.ModTime()
+			if info.IsDir() {
+				if indexInfo, err := os.Stat(filepath.Join(path, indexFileName)); err == nil {
+					modTime = indexInfo.ModTime()
+				}
+			}
+			if l, ok := existing[path]; ok && l.modTime.Equal(modTime) {
+				layouts = append(layouts, l)
+				continue
+			}
+
+			l, err := loadLayout(ctx, path, info, modTime, s.bindRepositories)
+			if err != nil {
+				if strict && isLayout {
+					return fail(fmt.Errorf("failed to load OCI layout %s: %w", path, err))
+				}
+				logrus.Warnf("skipping OCI layout %s: %v", path, err)
+				continue
+			}
+			logrus.Infof("loaded OCI layout %s", path)
+			layouts = append(layouts, l)
+		}
+	}
+	return layouts, nil
+}
+
+// layoutPaths returns the layout paths under the root and whether the root is
+// a layout itself.
+func layoutPaths(root string) ([]string, bool, error) {
+	info, err := os.Stat(root)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to access path %s: %w", root, err)
+	}
+	if !info.IsDir() || isLayoutDir(root) {
+		return []string{root}, true, nil
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to read directory %s: %w", root, err)
+	}
+	var paths []string
+	for _, entry := range entries {
+		path := filepath.Join(root, entry.Name())
+		if strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+		if isTarball(entry.Name()) || (entry.IsDir() && isLayoutDir(path)) {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+	return paths, false, nil
+}
+
+// loadLayout loads the OCI layout at the path. If bindRepositories is set,
+// the layout is bound to the repositories referenced by its index.
+func loadLayout(ctx context.Context, path string, info os.FileInfo, modTime time.Time, bindRepositories bool) (_ *layout, err error) {
+	l := &layout{
+		path:    path,
+		modTime: modTime,
+	}
+	defer func() {
+		if err != nil {
+			l.close()
+		}
+	}()
+
+	var index []byte
+	if info.IsDir() {
+		if l.store, err = ratify.NewOCIStoreFromFS(ctx, os.DirFS(path)); err != nil {
+			return nil, err
+		}
+		index, err = os.ReadFile(filepath.Join(path, indexFileName))
+	} else {
+		tarPath := path
+		if isGzip(path) {
+			if l.tarball, err = decompressTarball(path, info.ModTime()); err != nil {
+				return nil, err
+			}
+			tarPath = l.tarball
+		}
+		if l.store, err = ratify.NewOCIStoreFromTar(ctx, tarPath); err != nil {
+			return nil, err
+		}
+		index, err = readFileFromTar(tarPath, indexFileName)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", indexFileName, err)
+	}
+
+	repositories, err := indexRepositories(index)
+	if err != nil {
+		return nil, err
+	}
+	if bindRepositories {
+		l.repositories = repositories
+	}
+	return l, nil
+}
+
+// indexRepositories returns the fully qualified repositories referenced by the
+// "org.opencontainers.image.ref.name" annotations of the index.
+func indexRepositories(content []byte) (map[string]struct{}, error) {
+	var index ocispec.Index
+	if err := json.Unmarshal(content, &index); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", indexFileName, err)
+	}
+	repositories := make(map[string]struct{})
+	for _, manifest := range index.Manifests {
+		name := manifest.Annotations[ocispec.AnnotationRefName]
+		if name == "" {
+			continue
+		}
+		ref, err := registry.ParseReference(name)
+		if err != nil {
+			// the annotation is a plain tag.
+			continue
+		}
+		repositories[ref.Registry+"/"+ref.Repository] = struct{}{}
+	}
+	return repositories, nil
+}
+
+// decompressTarball decompresses the gzipped tarball into tarballCacheDir
+// and returns the path of the decompressed tarball. The decompressed tarball
+// is keyed by the path and modification time of the gzipped tarball, and
+// must be released by releaseTarball.
+func decompressTarball(path string, modTime time.Time) (string, error) {
+	sum := sha256.Sum256([]byte(path))
+	prefix := hex.EncodeToString(sum[:8]) + "-"
+	tarball := filepath.Join(tarballCacheDir, prefix+strconv.FormatInt(modTime.UnixNano(), 10)+".tar")
+
+	decompressedTarballs.mu.Lock()
+	defer decompressedTarballs.mu.Unlock()
+	if decompressedTarballs.refs[tarball] > 0 {
+		decompressedTarballs.refs[tarball]++
+		return tarball, nil
+	}
+	removeStaleTarballs(prefix)
+
+	if err := os.MkdirAll(tarballCacheDir, 0700); err != nil {
+		return "", err
+	}
+	src, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer src.Close()
+	gz, err := gzip.NewReader(src)
+	if err != nil {
+		return "", fmt.Errorf("failed to decompress %s: %w", path, err)
+	}
+	defer gz.Close()
+
+	dst, err := os.CreateTemp(tarballCacheDir, ".tmp-*")
+	if err != nil {
+		return "", err
+	}
+	if _, err := io.Copy(dst, gz); err != nil {
+		dst.Close()
+		os.Remove(dst.Name())
+		return "", fmt.Errorf("failed to decompress %s: %w", path, err)
+	}
+	if err := dst.Close(); err != nil {
+		os.Remove(dst.Name())
+		return "", err
+	}
+	if err := os.Rename(dst.Name(), tarball); err != nil {
+		os.Remove(dst.Name())
+		return "", err
+	}
+	decompressedTarballs.refs[tarball] = 1
+	return tarball, nil
+}
+
+// releaseTarball releases a tarball returned by decompressTarball and removes
+// it once it is no longer used.
+func releaseTarball(tarball string) {
+	decompressedTarballs.mu.Lock()
+	defer decompressedTarballs.mu.Unlock()
+	decompressedTarballs.refs[tarball]--
+	if decompressedTarballs.refs[tarball] > 0 {
+		return
+	}
+	delete(decompressedTarballs.refs, tarball)
+	if err := os.Remove(tarball); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		logrus.Warnf("failed to remove decompressed tarball %s: %v", tarball, err)
+	}
+}
+
+// removeStaleTarballs removes the unused decompressed tarballs with the
+// prefix, e.g. left behind by older versions of the gzipped tarball or by a
+// previous process. The caller must hold decompressedTarballs.mu.
+func removeStaleTarballs(prefix string) {
+	stale, _ := filepath.Glob(filepath.Join(tarballCacheDir, prefix+"*.tar"))
+	for _, tarball := range stale {
+		if decompressedTarballs.refs[tarball] == 0 {
+			os.Remove(tarball)
+		}
+	}
+}
+
+// readFileFromTar reads the named file at the root of the tarball.
+func readFileFromTar(path, name string) ([]byte, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	tr := tar.NewReader(f)
+	for {
+		header, err := tr.Next()
+		if err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("%s not found in tarball %s", name, path)
+			}
+			return nil, err
+		}
+		if filepath.Clean(header.Name) == name {
+			return io.ReadAll(tr)
+		}
+	}
+}
+
+func isLayoutDir(path string) bool {
+	info, err := os.Stat(filepath.Join(path, indexFileName))
+	return err == nil && !info.IsDir()
+}
+
+func isTarball(name string) bool {
+	return strings.HasSuffix(name, ".tar") || isGzip(name)
+}
+
+func isGzip(name string) bool {
+	return strings.HasSuffix(name, ".tar.gz") || strings.HasSuffix(name, ".tgz")
+}
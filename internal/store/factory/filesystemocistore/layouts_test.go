@@ -0,0 +1,412 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filesystemocistore
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2"
+	"oras.land/oras-go/v2/content/oci"
+)
+
+const testArtifactType = "application/vnd.test.signature"
+
+// testLayout describes the content of a layout created by createLayout.
+type testLayout struct {
+	subject  ocispec.Descriptor
+	referrer ocispec.Descriptor
+}
+
+// createLayout creates an OCI image layout in dir with a subject image tagged
+// with refName and a referrer of the subject. The seed makes the content of
+// each layout unique.
+func createLayout(t *testing.T, dir, refName, seed string) testLayout {
+	t.Helper()
+	ctx := context.Background()
+	store, err := oci.New(dir)
+	if err != nil {
+		t.Fatalf("failed to create layout: %v", err)
+	}
+	push := func(mediaType string, content []byte) ocispec.Descriptor {
+		desc := ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(content),
+			Size:      int64(len(content)),
+		}
+		if err := store.Push(ctx, desc, bytes.NewReader(content)); err != nil {
+			t.Fatalf("failed to push content: %v", err)
+		}
+		return desc
+	}
+	pushManifest := func(manifest ocispec.Manifest) ocispec.Descriptor {
+		manifest.Versioned.SchemaVersion = 2
+		manifest.MediaType = ocispec.MediaTypeImageManifest
+		content, err := json.Marshal(manifest)
+		if err != nil {
+			t.Fatalf("failed to marshal manifest: %v", err)
+		}
+		desc := push(ocispec.MediaTypeImageManifest, content)
+		desc.ArtifactType = manifest.ArtifactType
+		return desc
+	}
+
+	config := push(ocispec.MediaTypeImageConfig, []byte(`{"seed":"`+seed+`"}`))
+	subject := pushManifest(ocispec.Manifest{
+		Config: config,
+		Layers: []ocispec.Descriptor{},
+	})
+	if err := store.Tag(ctx, subject, refName); err != nil {
+		t.Fatalf("failed to tag subject: %v", err)
+	}
+	referrer := pushManifest(ocispec.Manifest{
+		ArtifactType: testArtifactType,
+		Config:       ocispec.DescriptorEmptyJSON,
+		Layers:       []ocispec.Descriptor{push("application/octet-stream", []byte(seed))},
+		Subject:      &subject,
+	})
+	push(ocispec.MediaTypeEmptyJSON, ocispec.DescriptorEmptyJSON.Data)
+	return testLayout{subject: subject, referrer: referrer}
+}
+
+// writeTarball archives the layout directory into a tarball, gzipped if the
+// path ends with ".tar.gz" or ".tgz".
+func writeTarball(t *testing.T, dir, path string) {
+	t.Helper()
+	var buf bytes.Buffer
+	var w io.Writer = &buf
+	var gz *gzip.Writer
+	if isGzip(path) {
+		gz = gzip.NewWriter(&buf)
+		w = gz
+	}
+	tw := tar.NewWriter(w)
+	if err := tw.AddFS(os.DirFS(dir)); err != nil {
+		t.Fatalf("failed to write tarball: %v", err)
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("failed to write tarball: %v", err)
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			t.Fatalf("failed to write tarball: %v", err)
+		}
+	}
+	if err := os.WriteFile(path, buf.Bytes(), 0600); err != nil {
+		t.Fatalf("failed to write tarball: %v", err)
+	}
+}
+
+func listReferrers(t *testing.T, s *layoutStore, ref string) []ocispec.Descriptor {
+	t.Helper()
+	var referrers []ocispec.Descriptor
+	if err := s.ListReferrers(context.Background(), ref, nil, func(page []ocispec.Descriptor) error {
+		referrers = append(referrers, page...)
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to list referrers: %v", err)
+	}
+	return referrers
+}
+
+func TestLayoutStore_Formats(t *testing.T) {
+	for _, name := range []string{"layout", "layout.tar", "layout.tar.gz", "layout.tgz"} {
+		t.Run(name, func(t *testing.T) {
+			tarballCacheDir = t.TempDir()
+			dir := t.TempDir()
+			layoutDir := filepath.Join(dir, "src")
+			l := createLayout(t, layoutDir, "v1", name)
+			path := layoutDir
+			if name != "layout" {
+				path = filepath.Join(dir, name)
+				writeTarball(t, layoutDir, path)
+			}
+
+			s, err := newLayoutStore(context.Background(), []string{path}, 0, false)
+			if err != nil {
+				t.Fatalf("failed to create store: %v", err)
+			}
+			desc, err := s.Resolve(context.Background(), "test.io/repo:v1")
+			if err != nil {
+				t.Fatalf("failed to resolve: %v", err)
+			}
+			if desc.Digest != l.subject.Digest {
+				t.Errorf("expected digest %s, got %s", l.subject.Digest, desc.Digest)
+			}
+			referrers := listReferrers(t, s, "test.io/repo@"+l.subject.Digest.String())
+			if len(referrers) != 1 || referrers[0].Digest != l.referrer.Digest {
+				t.Errorf("expected referrer %s, got %v", l.referrer.Digest, referrers)
+			}
+			if _, err := s.FetchManifest(context.Background(), "test.io/repo", l.referrer); err != nil {
+				t.Errorf("failed to fetch manifest: %v", err)
+			}
+		})
+	}
+}
+
+func TestLayoutStore_RepositoryAnnotations(t *testing.T) {
+	dir := t.TempDir()
+	app := createLayout(t, filepath.Join(dir, "app"), "registry.example.com/app:v1", "app")
+	db := createLayout(t, filepath.Join(dir, "db"), "registry.example.com/db:v1", "db")
+	generic := createLayout(t, filepath.Join(dir, "generic"), "v2", "generic")
+
+	s, err := newLayoutStore(context.Background(), []string{dir}, 0, true)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if len(s.layouts) != 3 {
+		t.Fatalf("expected 3 layouts, got %d", len(s.layouts))
+	}
+
+	tests := []struct {
+		ref       string
+		expected  digest.Digest
+		expectErr bool
+	}{
+		{ref: "registry.example.com/app:v1", expected: app.subject.Digest},
+		{ref: "registry.example.com/db:v1", expected: db.subject.Digest},
+		{ref: "registry.example.com/app:v2", expected: generic.subject.Digest},
+		{ref: "registry.example.com/other:v1", expectErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.ref, func(t *testing.T) {
+			desc, err := s.Resolve(context.Background(), test.ref)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if desc.Digest != test.expected {
+				t.Errorf("expected digest %s, got %s", test.expected, desc.Digest)
+			}
+		})
+	}
+
+	// the db layout is bound to another repository.
+	if referrers := listReferrers(t, s, "registry.example.com/app@"+db.subject.Digest.String()); len(referrers) != 0 {
+		t.Errorf("expected no referrers from layouts bound to other repositories, got %v", referrers)
+	}
+	if _, err := s.FetchManifest(context.Background(), "registry.example.com/app", db.referrer); err == nil {
+		t.Error("expected fetching from a layout bound to another repository to fail")
+	}
+
+	// without binding, all layouts serve all repositories.
+	unbound, err := newLayoutStore(context.Background(), []string{dir}, 0, false)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if referrers := listReferrers(t, unbound, "registry.example.com/app@"+db.subject.Digest.String()); len(referrers) != 1 {
+		t.Errorf("expected referrers from all layouts without binding, got %v", referrers)
+	}
+	if _, err := unbound.FetchManifest(context.Background(), "registry.example.com/app", db.referrer); err != nil {
+		t.Errorf("failed to fetch manifest without binding: %v", err)
+	}
+}
+
+func TestLayoutStore_MultipleRoots(t *testing.T) {
+	dir := t.TempDir()
+	a := createLayout(t, filepath.Join(dir, "a"), "v1", "a")
+	b := createLayout(t, filepath.Join(dir, "b"), "v1", "b")
+	// add a's subject and another referrer of it to layout b.
+	bStore, err := oci.New(filepath.Join(dir, "b"))
+	if err != nil {
+		t.Fatalf("failed to open layout: %v", err)
+	}
+	aStore, err := oci.New(filepath.Join(dir, "a"))
+	if err != nil {
+		t.Fatalf("failed to open layout: %v", err)
+	}
+	if err := oras.CopyGraph(context.Background(), aStore, bStore, a.subject, oras.DefaultCopyGraphOptions); err != nil {
+		t.Fatalf("failed to copy subject: %v", err)
+	}
+	manifest, _ := json.Marshal(ocispec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: testArtifactType,
+		Config:       ocispec.DescriptorEmptyJSON,
+		Layers:       []ocispec.Descriptor{ocispec.DescriptorEmptyJSON},
+		Subject:      &a.subject,
+	})
+	extra := ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromBytes(manifest), Size: int64(len(manifest))}
+	if err := bStore.Push(context.Background(), extra, bytes.NewReader(manifest)); err != nil {
+		t.Fatalf("failed to push manifest: %v", err)
+	}
+	if err := bStore.Tag(context.Background(), extra, "extra"); err != nil {
+		t.Fatalf("failed to tag manifest: %v", err)
+	}
+
+	s, err := newLayoutStore(context.Background(), []string{filepath.Join(dir, "a"), filepath.Join(dir, "b")}, 0, false)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if referrers := listReferrers(t, s, "test.io/repo@"+a.subject.Digest.String()); len(referrers) != 2 {
+		t.Errorf("expected referrers merged from both layouts, got %v", referrers)
+	}
+	if _, err := s.FetchManifest(context.Background(), "test.io/repo", b.referrer); err != nil {
+		t.Errorf("failed to fetch manifest from second root: %v", err)
+	}
+}
+
+func TestLayoutStore_Refresh(t *testing.T) {
+	tarballCacheDir = t.TempDir()
+	dir := t.TempDir()
+	s, err := newLayoutStore(context.Background(), []string{dir}, time.Nanosecond, false)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if _, err := s.Resolve(context.Background(), "test.io/repo:v1"); err == nil {
+		t.Fatal("expected resolving in an empty directory to fail")
+	}
+
+	src := t.TempDir()
+	l := createLayout(t, src, "v1", "refresh")
+	// a partially copied tarball is skipped until complete.
+	if err := os.WriteFile(filepath.Join(dir, "layout.tar.gz"), []byte("partial"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	if _, err := s.Resolve(context.Background(), "test.io/repo:v1"); err == nil {
+		t.Fatal("expected resolving with a broken tarball to fail")
+	}
+	writeTarball(t, src, filepath.Join(dir, "layout.tar.gz"))
+	// make sure the modification time differs from the partial copy.
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(dir, "layout.tar.gz"), future, future); err != nil {
+		t.Fatalf("failed to change times: %v", err)
+	}
+	desc, err := s.Resolve(context.Background(), "test.io/repo:v1")
+	if err != nil {
+		t.Fatalf("expected new layout to be picked up, got: %v", err)
+	}
+	if desc.Digest != l.subject.Digest {
+		t.Errorf("expected digest %s, got %s", l.subject.Digest, desc.Digest)
+	}
+
+	if err := os.Remove(filepath.Join(dir, "layout.tar.gz")); err != nil {
+		t.Fatalf("failed to remove file: %v", err)
+	}
+	if _, err := s.Resolve(context.Background(), "test.io/repo:v1"); err == nil {
+		t.Error("expected removed layout to be dropped")
+	}
+}
+
+func TestLayoutStore_DecompressedTarballs(t *testing.T) {
+	tarballCacheDir = t.TempDir()
+	cached := func() []string {
+		t.Helper()
+		tarballs, err := filepath.Glob(filepath.Join(tarballCacheDir, "*.tar"))
+		if err != nil {
+			t.Fatalf("failed to list decompressed tarballs: %v", err)
+		}
+		return tarballs
+	}
+
+	dir := t.TempDir()
+	src := t.TempDir()
+	createLayout(t, src, "v1", "decompressed")
+	path := filepath.Join(dir, "layout.tar.gz")
+	writeTarball(t, src, path)
+
+	s, err := newLayoutStore(context.Background(), []string{dir}, time.Nanosecond, false)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	// a store of the reloaded executor shares the decompressed tarball.
+	reloaded, err := newLayoutStore(context.Background(), []string{dir}, 0, false)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	first := cached()
+	if len(first) != 1 {
+		t.Fatalf("expected 1 decompressed tarball, got %v", first)
+	}
+
+	// rescanning an unchanged tarball keeps the decompressed tarball.
+	if _, err := s.Resolve(context.Background(), "test.io/repo:v1"); err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+	if tarballs := cached(); len(tarballs) != 1 || tarballs[0] != first[0] {
+		t.Fatalf("expected decompressed tarball %s to be reused, got %v", first[0], tarballs)
+	}
+
+	// an updated tarball replaces the decompressed tarball once no store
+	// uses it.
+	future := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatalf("failed to change times: %v", err)
+	}
+	if _, err := s.Resolve(context.Background(), "test.io/repo:v1"); err != nil {
+		t.Fatalf("failed to resolve: %v", err)
+	}
+	if tarballs := cached(); len(tarballs) != 2 {
+		t.Fatalf("expected the decompressed tarball of the reloaded store to be kept, got %v", tarballs)
+	}
+	if err := reloaded.Close(); err != nil {
+		t.Fatalf("failed to close store: %v", err)
+	}
+	if tarballs := cached(); len(tarballs) != 1 || tarballs[0] == first[0] {
+		t.Fatalf("expected only the new decompressed tarball, got %v", tarballs)
+	}
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("failed to close store: %v", err)
+	}
+	if tarballs := cached(); len(tarballs) != 0 {
+		t.Errorf("expected decompressed tarballs to be removed on close, got %v", tarballs)
+	}
+}
+
+func TestNewLayoutStore_Errors(t *testing.T) {
+	dir := t.TempDir()
+	broken := filepath.Join(dir, "broken.tar")
+	if err := os.WriteFile(broken, []byte("not a tarball"), 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	badIndex := filepath.Join(dir, "bad")
+	if err := os.MkdirAll(badIndex, 0700); err != nil {
+		t.Fatalf("failed to create directory: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(badIndex, indexFileName), []byte("{"), fs.FileMode(0600)); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		roots []string
+	}{
+		{name: "no roots"},
+		{name: "empty root", roots: []string{""}},
+		{name: "nonexistent root", roots: []string{filepath.Join(dir, "nonexistent")}},
+		{name: "broken tarball", roots: []string{broken}},
+		{name: "broken index", roots: []string{badIndex}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := newLayoutStore(context.Background(), test.roots, 0, false); err == nil {
+				t.Error("expected error, got nil")
+			}
+		})
+	}
+}
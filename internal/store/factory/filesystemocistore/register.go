@@ -17,8 +17,9 @@ package filesystemocistore
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"os"
+	"time"
 
 	"github.com/notaryproject/ratify-go"
 	"github.com/notaryproject/ratify/v2/internal/store/factory"
@@ -26,24 +27,59 @@ import (
 
 const filesystemOCIStoreType = "filesystem-oci-store"
 
+type options struct {
+	// Path is the path of an OCI image layout directory, an OCI image layout
+	// tarball (.tar, .tar.gz or .tgz), or a directory containing such layouts.
+	// Either Path or Paths is required.
+	Path string `json:"path,omitempty"`
+
+	// Paths is a list of paths in the same format as Path. Layouts found under
+	// all paths are merged into one store. Either Path or Paths is required.
+	Paths []string `json:"paths,omitempty"`
+
+	// RefreshInterval is the interval to rescan directories of layouts for
+	// added, updated or removed layouts, e.g. "1m". Set to "0s" to disable
+	// rescanning. Defaults to 30 seconds. Optional.
+	RefreshInterval *string `json:"refreshInterval,omitempty"`
+
+	// BindRepositories binds each layout to the fully qualified repositories
+	// of the "org.opencontainers.image.ref.name" annotations in its index.json,
+	// e.g. "registry.example.com/app:v1", so that the layout only serves
+	// artifacts of those repositories. Layouts without such annotations serve
+	// all repositories. Optional. Defaults to false, where all layouts serve
+	// all repositories.
+	BindRepositories bool `json:"bindRepositories,omitempty"`
+}
+
 func init() {
 	// Register the filesystem OCI store factory
 	factory.RegisterStoreFactory(filesystemOCIStoreType, func(opts *factory.NewStoreOptions) (ratify.Store, error) {
 		if opts.Parameters == nil {
 			return nil, fmt.Errorf("store parameters are required")
 		}
-		params, ok := opts.Parameters.(map[string]any)
-		if !ok {
-			return nil, fmt.Errorf("store parameters must be a map")
+		raw, err := json.Marshal(opts.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal store parameters: %w", err)
 		}
-		pathVal, ok := params["path"]
-		if !ok || pathVal == nil {
+		var params options
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal store parameters: %w", err)
+		}
+
+		paths := params.Paths
+		if params.Path != "" {
+			paths = append([]string{params.Path}, paths...)
+		}
+		if len(paths) == 0 {
 			return nil, fmt.Errorf("path parameter is required")
 		}
-		path, ok := pathVal.(string)
-		if !ok || path == "" {
-			return nil, fmt.Errorf("path parameter must be a non-empty string")
+
+		refreshInterval := defaultRefreshInterval
+		if params.RefreshInterval != nil {
+			if refreshInterval, err = time.ParseDuration(*params.RefreshInterval); err != nil || refreshInterval < 0 {
+				return nil, fmt.Errorf("invalid refreshInterval %q", *params.RefreshInterval)
+			}
 		}
-		return ratify.NewOCIStoreFromFS(context.Background(), os.DirFS(path))
+		return newLayoutStore(context.Background(), paths, refreshInterval, params.BindRepositories)
 	})
 }
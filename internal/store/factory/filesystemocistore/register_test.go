@@ -22,6 +22,9 @@ import (
 )
 
 func TestNewStore(t *testing.T) {
+	layoutDir := t.TempDir()
+	createLayout(t, layoutDir, "v1", "register")
+
 	tests := []struct {
 		name      string
 		opts      *factory.NewStoreOptions
@@ -79,6 +82,38 @@ func TestNewStore(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "Invalid refresh interval",
+			opts: &factory.NewStoreOptions{
+				Type: filesystemOCIStoreType,
+				Parameters: map[string]interface{}{
+					"path":            layoutDir,
+					"refreshInterval": "often",
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "Valid path",
+			opts: &factory.NewStoreOptions{
+				Type: filesystemOCIStoreType,
+				Parameters: map[string]interface{}{
+					"path": layoutDir,
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "Valid paths",
+			opts: &factory.NewStoreOptions{
+				Type: filesystemOCIStoreType,
+				Parameters: map[string]interface{}{
+					"paths":           []string{layoutDir, t.TempDir()},
+					"refreshInterval": "1m",
+				},
+			},
+			expectErr: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,207 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registrystore
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+	"oras.land/oras-go/v2/errdef"
+	"oras.land/oras-go/v2/registry/remote"
+	"oras.land/oras-go/v2/registry/remote/auth"
+)
+
+const (
+	// referrersAPIAuto detects whether the registry supports the referrers API
+	// and falls back to the referrers tag schema and cosign tags if not.
+	referrersAPIAuto = "auto"
+
+	// referrersAPIEnabled always uses the referrers API.
+	referrersAPIEnabled = "enabled"
+
+	// referrersAPIDisabled always uses the referrers tag schema and cosign
+	// tags.
+	referrersAPIDisabled = "disabled"
+
+	defaultCapabilityTTL = time.Hour
+
+	// cosignSignatureArtifactType is the artifact type reported for cosign
+	// signatures discovered by the "sha256-<digest>.sig" tag.
+	cosignSignatureArtifactType = "application/vnd.dev.cosign.artifact.sig.v1+json"
+
+	// cosignAttestationArtifactType is the artifact type reported for cosign
+	// attestations discovered by the "sha256-<digest>.att" tag.
+	cosignAttestationArtifactType = "application/vnd.dev.cosign.artifact.att.v1+json"
+)
+
+// cosignTagSuffixes maps the cosign tag suffixes to the artifact types
+// reported for the tagged manifests.
+var cosignTagSuffixes = []struct {
+	suffix       string
+	artifactType string
+}{
+	{suffix: ".sig", artifactType: cosignSignatureArtifactType},
+	{suffix: ".att", artifactType: cosignAttestationArtifactType},
+}
+
+// capability is the detected referrers API support of a registry.
+type capability struct {
+	supported  bool
+	detectedAt time.Time
+}
+
+// registryStore is a [ratify.RegistryStore] that detects whether each
+// registry supports the OCI referrers API and caches the result. Referrers of
+// subjects in registries without the referrers API are listed by the
+// referrers tag schema ("sha256-<digest>") and the cosign tags
+// ("sha256-<digest>.sig" and "sha256-<digest>.att").
+type registryStore struct {
+	*ratify.RegistryStore
+	client        *auth.Client
+	plainHTTP     bool
+	referrersAPI  string
+	capabilityTTL time.Duration
+
+	// capabilities maps registry hosts to the detected capability.
+	capabilities sync.Map // map[string]capability
+}
+
+// ListReferrers returns the referrers of the subject using the referrers API
+// or the tag schema fallback depending on the registry capability.
+func (s *registryStore) ListReferrers(ctx context.Context, ref string, artifactTypes []string, fn func(referrers []ocispec.Descriptor) error) error {
+	repo, err := remote.NewRepository(ref)
+	if err != nil {
+		return err
+	}
+	repo.Client = s.client
+	repo.PlainHTTP = s.plainHTTP
+
+	var desc ocispec.Descriptor
+	if subjectDigest, err := repo.Reference.Digest(); err == nil {
+		desc = ocispec.Descriptor{
+			Digest: subjectDigest,
+		}
+	} else {
+		desc, err = repo.Resolve(ctx, ref)
+		if err != nil {
+			// return empty referrer list if the subject is not found.
+			if errors.Is(err, errdef.ErrNotFound) {
+				return nil
+			}
+			return err
+		}
+	}
+
+	host := repo.Reference.Registry
+	supported, known := s.capability(host)
+	if known {
+		if err := repo.SetReferrersCapability(supported); err != nil {
+			return err
+		}
+	}
+
+	var artifactType string
+	if len(artifactTypes) == 1 {
+		artifactType = artifactTypes[0]
+	}
+	if err := repo.Referrers(ctx, desc, artifactType, func(referrers []ocispec.Descriptor) error {
+		referrers = filterReferrers(referrers, artifactTypes)
+		if len(referrers) == 0 {
+			return nil
+		}
+		return fn(referrers)
+	}); err != nil {
+		return err
+	}
+
+	if !known {
+		// the repository records the detected capability after a successful
+		// listing. Setting it to supported fails only if the referrers API
+		// was detected as unsupported.
+		supported = repo.SetReferrersCapability(true) == nil
+		logrus.Infof("detected referrers API support of registry %s: %t", host, supported)
+		s.capabilities.Store(host, capability{
+			supported:  supported,
+			detectedAt: time.Now(),
+		})
+	}
+	if supported {
+		return nil
+	}
+	return s.listCosignReferrers(ctx, repo, desc, artifactTypes, fn)
+}
+
+// capability returns the referrers API support of the registry and whether it
+// is known.
+func (s *registryStore) capability(host string) (bool, bool) {
+	switch s.referrersAPI {
+	case referrersAPIEnabled:
+		return true, true
+	case referrersAPIDisabled:
+		return false, true
+	}
+	val, ok := s.capabilities.Load(host)
+	if !ok {
+		return false, false
+	}
+	c := val.(capability)
+	if time.Since(c.detectedAt) >= s.capabilityTTL {
+		return false, false
+	}
+	return c.supported, true
+}
+
+// listCosignReferrers lists the cosign signatures and attestations of the
+// subject by the cosign tag conventions.
+func (s *registryStore) listCosignReferrers(ctx context.Context, repo *remote.Repository, subject ocispec.Descriptor, artifactTypes []string, fn func(referrers []ocispec.Descriptor) error) error {
+	prefix := strings.Replace(subject.Digest.String(), ":", "-", 1)
+	var referrers []ocispec.Descriptor
+	for _, tag := range cosignTagSuffixes {
+		if len(artifactTypes) > 0 && !slices.Contains(artifactTypes, tag.artifactType) {
+			continue
+		}
+		desc, err := repo.Resolve(ctx, prefix+tag.suffix)
+		if err != nil {
+			if errors.Is(err, errdef.ErrNotFound) {
+				continue
+			}
+			return fmt.Errorf("failed to resolve cosign tag %s: %w", prefix+tag.suffix, err)
+		}
+		desc.ArtifactType = tag.artifactType
+		referrers = append(referrers, desc)
+	}
+	if len(referrers) == 0 {
+		return nil
+	}
+	return fn(referrers)
+}
+
+func filterReferrers(referrers []ocispec.Descriptor, artifactTypes []string) []ocispec.Descriptor {
+	if len(artifactTypes) == 0 {
+		return referrers
+	}
+	return slices.DeleteFunc(referrers, func(desc ocispec.Descriptor) bool {
+		return !slices.Contains(artifactTypes, desc.ArtifactType)
+	})
+}
@@ -0,0 +1,316 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registrystore
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/store/factory"
+	"github.com/opencontainers/go-digest"
+	"github.com/opencontainers/image-spec/specs-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const testNotationArtifactType = "application/vnd.cncf.notary.signature"
+
+// testRegistry is a minimal registry serving a subject with a notation
+// signature and a cosign signature.
+type testRegistry struct {
+	*httptest.Server
+	supportsReferrersAPI bool
+	referrersAPICalls    atomic.Int32
+
+	// username and password are required by the registry if set.
+	username, password string
+	challenges         atomic.Int32
+
+	subject   ocispec.Descriptor
+	notation  ocispec.Descriptor
+	manifests map[string][]byte
+	mediaType map[string]string
+}
+
+func newTestRegistry(t *testing.T, supportsReferrersAPI bool) *testRegistry {
+	t.Helper()
+	r := &testRegistry{
+		supportsReferrersAPI: supportsReferrersAPI,
+		manifests:            make(map[string][]byte),
+		mediaType:            make(map[string]string),
+	}
+	add := func(mediaType string, v any, tags ...string) ocispec.Descriptor {
+		content, err := json.Marshal(v)
+		if err != nil {
+			t.Fatalf("failed to marshal manifest: %v", err)
+		}
+		desc := ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(content),
+			Size:      int64(len(content)),
+		}
+		for _, ref := range append(tags, desc.Digest.String()) {
+			r.manifests[ref] = content
+			r.mediaType[ref] = mediaType
+		}
+		return desc
+	}
+
+	r.subject = add(ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    ocispec.DescriptorEmptyJSON,
+		Layers:    []ocispec.Descriptor{},
+	}, "v1")
+	r.notation = add(ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		Versioned:    specs.Versioned{SchemaVersion: 2},
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: testNotationArtifactType,
+		Config:       ocispec.DescriptorEmptyJSON,
+		Layers:       []ocispec.Descriptor{ocispec.DescriptorEmptyJSON},
+		Subject:      &r.subject,
+	})
+	r.notation.ArtifactType = testNotationArtifactType
+	prefix := strings.Replace(r.subject.Digest.String(), ":", "-", 1)
+	add(ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    ocispec.DescriptorEmptyJSON,
+		Layers:    []ocispec.Descriptor{ocispec.DescriptorEmptyJSON},
+	}, prefix+".sig")
+	// referrers tag schema index.
+	referrersIndex := ocispec.Index{
+		Versioned: specs.Versioned{SchemaVersion: 2},
+		MediaType: ocispec.MediaTypeImageIndex,
+		Manifests: []ocispec.Descriptor{r.notation},
+	}
+	if !supportsReferrersAPI {
+		add(ocispec.MediaTypeImageIndex, referrersIndex, prefix)
+	}
+
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if username, password, ok := req.BasicAuth(); r.username != "" && (!ok || username != r.username || password != r.password) {
+			r.challenges.Add(1)
+			w.Header().Set("WWW-Authenticate", `Basic realm="test"`)
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		path := strings.TrimPrefix(req.URL.Path, "/v2/test/repo/")
+		switch {
+		case req.URL.Path == "/v2/":
+			w.WriteHeader(http.StatusOK)
+		case strings.HasPrefix(path, "referrers/"):
+			r.referrersAPICalls.Add(1)
+			if !r.supportsReferrersAPI {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", ocispec.MediaTypeImageIndex)
+			_ = json.NewEncoder(w).Encode(referrersIndex)
+		case strings.HasPrefix(path, "manifests/"):
+			ref := strings.TrimPrefix(path, "manifests/")
+			content, ok := r.manifests[ref]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			w.Header().Set("Content-Type", r.mediaType[ref])
+			w.Header().Set("Docker-Content-Digest", digest.FromBytes(content).String())
+			w.Header().Set("Content-Length", strconv.Itoa(len(content)))
+			if req.Method == http.MethodGet {
+				_, _ = w.Write(content)
+			}
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+func (r *testRegistry) ref(reference string) string {
+	return strings.TrimPrefix(r.URL, "http://") + "/test/repo" + reference
+}
+
+func newTestStore(t *testing.T, params map[string]interface{}) *registryStore {
+	t.Helper()
+	params["plain_http"] = true
+	store, err := factory.NewStore(&factory.NewStoreOptions{
+		Type:       registryStoreType,
+		Parameters: params,
+	})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	return store.(*registryStore)
+}
+
+func listArtifactTypes(t *testing.T, store *registryStore, ref string, artifactTypes []string) []string {
+	t.Helper()
+	var got []string
+	if err := store.ListReferrers(context.Background(), ref, artifactTypes, func(referrers []ocispec.Descriptor) error {
+		for _, referrer := range referrers {
+			got = append(got, referrer.ArtifactType)
+		}
+		return nil
+	}); err != nil {
+		t.Fatalf("failed to list referrers: %v", err)
+	}
+	return got
+}
+
+func TestRegistryStore_ListReferrers(t *testing.T) {
+	tests := []struct {
+		name                 string
+		supportsReferrersAPI bool
+		referrersAPI         string
+		artifactTypes        []string
+		expected             []string
+		expectedAPICalls     int32
+	}{
+		{
+			name:                 "referrers API supported",
+			supportsReferrersAPI: true,
+			expected:             []string{testNotationArtifactType},
+			expectedAPICalls:     2,
+		},
+		{
+			name:             "referrers API unsupported",
+			expected:         []string{testNotationArtifactType, cosignSignatureArtifactType},
+			expectedAPICalls: 1,
+		},
+		{
+			name:             "referrers API unsupported with artifact type filter",
+			artifactTypes:    []string{cosignSignatureArtifactType},
+			expected:         []string{cosignSignatureArtifactType},
+			expectedAPICalls: 1,
+		},
+		{
+			name:             "referrers API disabled",
+			referrersAPI:     referrersAPIDisabled,
+			expected:         []string{testNotationArtifactType, cosignSignatureArtifactType},
+			expectedAPICalls: 0,
+		},
+		{
+			name:                 "referrers API enabled",
+			supportsReferrersAPI: true,
+			referrersAPI:         referrersAPIEnabled,
+			expected:             []string{testNotationArtifactType},
+			expectedAPICalls:     2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			registry := newTestRegistry(t, test.supportsReferrersAPI)
+			store := newTestStore(t, map[string]interface{}{
+				"referrers_api": test.referrersAPI,
+			})
+			for _, ref := range []string{":v1", "@" + registry.subject.Digest.String()} {
+				got := listArtifactTypes(t, store, registry.ref(ref), test.artifactTypes)
+				if strings.Join(got, ",") != strings.Join(test.expected, ",") {
+					t.Errorf("expected referrers %v, got %v", test.expected, got)
+				}
+			}
+			if calls := registry.referrersAPICalls.Load(); calls != test.expectedAPICalls {
+				t.Errorf("expected %d referrers API calls, got %d", test.expectedAPICalls, calls)
+			}
+		})
+	}
+}
+
+func TestRegistryStore_CapabilityTTL(t *testing.T) {
+	registry := newTestRegistry(t, false)
+	store := newTestStore(t, map[string]interface{}{})
+	ref := registry.ref("@" + registry.subject.Digest.String())
+
+	listArtifactTypes(t, store, ref, nil)
+	listArtifactTypes(t, store, ref, nil)
+	if calls := registry.referrersAPICalls.Load(); calls != 1 {
+		t.Fatalf("expected capability to be cached, got %d referrers API calls", calls)
+	}
+
+	// the registry is upgraded and the cached capability expires.
+	registry.supportsReferrersAPI = true
+	store.capabilityTTL = 0
+	got := listArtifactTypes(t, store, ref, nil)
+	if calls := registry.referrersAPICalls.Load(); calls != 2 {
+		t.Errorf("expected capability to be detected again, got %d referrers API calls", calls)
+	}
+	if len(got) != 1 || got[0] != testNotationArtifactType {
+		t.Errorf("expected referrers from the referrers API, got %v", got)
+	}
+}
+
+func TestRegistryStore_SubjectNotFound(t *testing.T) {
+	registry := newTestRegistry(t, true)
+	store := newTestStore(t, map[string]interface{}{})
+	if got := listArtifactTypes(t, store, registry.ref(":missing"), nil); len(got) != 0 {
+		t.Errorf("expected no referrers, got %v", got)
+	}
+}
+
+// serverAddressCredGetter returns the credential of a single server address.
+type serverAddressCredGetter struct {
+	serverAddress string
+	credential    ratify.RegistryCredential
+}
+
+func (g *serverAddressCredGetter) Get(_ context.Context, serverAddress string) (ratify.RegistryCredential, error) {
+	if serverAddress != g.serverAddress {
+		return ratify.RegistryCredential{}, nil
+	}
+	return g.credential, nil
+}
+
+func TestRegistryStore_DockerHubCredential(t *testing.T) {
+	registry := newTestRegistry(t, true)
+	registry.username, registry.password = "user", "password"
+	// route the Docker Hub registry host to the test registry.
+	base := &http.Transport{
+		DialContext: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			return (&net.Dialer{}).DialContext(ctx, network, registry.Listener.Addr().String())
+		},
+	}
+	credGetter := &serverAddressCredGetter{
+		serverAddress: "https://index.docker.io/v1/",
+		credential:    ratify.RegistryCredential{Username: "user", Password: "password"},
+	}
+	store, err := newRegistryStore(&options{PlainHTTP: true}, base, credGetter, referrersAPIAuto, defaultCapabilityTTL)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	ref := "registry-1.docker.io/test/repo@" + registry.subject.Digest.String()
+	if got := listArtifactTypes(t, store, ref, nil); len(got) != 1 || got[0] != testNotationArtifactType {
+		t.Errorf("expected referrers from the referrers API, got %v", got)
+	}
+	if _, err := store.FetchManifest(context.Background(), "registry-1.docker.io/test/repo", registry.notation); err != nil {
+		t.Errorf("failed to fetch manifest: %v", err)
+	}
+	// the referrers listing and the underlying registry store share the
+	// authenticated client.
+	if challenges := registry.challenges.Load(); challenges != 1 {
+		t.Errorf("expected 1 authentication challenge, got %d", challenges)
+	}
+}
@@ -18,13 +18,24 @@ package registrystore
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"time"
 
 	"github.com/notaryproject/ratify-go"
 	"github.com/notaryproject/ratify/v2/internal/store/factory"
+	"oras.land/oras-go/v2/registry/remote/auth"
+	"oras.land/oras-go/v2/registry/remote/credentials"
 )
 
-const registryStoreType = "registry-store"
+const (
+	registryStoreType = "registry-store"
+
+	// defaultUserAgent is the user agent of the underlying registry store if
+	// not configured.
+	defaultUserAgent = "ratify-go"
+)
 
 type credential struct {
 	// Username is the username to login to the registry.
@@ -51,6 +62,26 @@ type options struct {
 
 	// Credential is the credential to use when accessing the registry.
 	Credential credential `json:"credential,omitempty"`
+
+	// RetryPolicy configures retries of throttled (429) and transiently failed
+	// (5xx) requests. Retries and throttles are reported in the validation
+	// result. If not set, failed requests are not retried. Optional.
+	RetryPolicy *retryPolicy `json:"retry_policy,omitempty"`
+
+	// RateLimit configures the per registry QPS and concurrency limits. If not
+	// set, requests are not throttled. Optional.
+	RateLimit *rateLimit `json:"rate_limit,omitempty"`
+
+	// ReferrersAPI is the use of the OCI referrers API, one of "auto",
+	// "enabled" or "disabled". With "auto", the support is detected per
+	// registry and registries without the referrers API fall back to the
+	// referrers tag schema and cosign tags. Defaults to "auto". Optional.
+	ReferrersAPI string `json:"referrers_api,omitempty"`
+
+	// ReferrersCapabilityTTL is the duration the detected referrers API
+	// support of a registry is cached, e.g. "30m". Defaults to 1 hour.
+	// Optional.
+	ReferrersCapabilityTTL string `json:"referrers_capability_ttl,omitempty"`
 }
 
 func init() {
@@ -65,21 +96,103 @@ func init() {
 			return nil, fmt.Errorf("failed to unmarshal store parameters: %w", err)
 		}
 
-		registryStoreOpts := ratify.RegistryStoreOptions{
-			PlainHTTP:        params.PlainHTTP,
-			UserAgent:        params.UserAgent,
-			MaxBlobBytes:     params.MaxBlobBytes,
-			MaxManifestBytes: params.MaxManifestBytes,
-			CredentialProvider: &defaultCredGetter{
-				username: params.Credential.Username,
-				password: params.Credential.Password,
-			},
+		referrersAPI := params.ReferrersAPI
+		switch referrersAPI {
+		case "":
+			referrersAPI = referrersAPIAuto
+		case referrersAPIAuto, referrersAPIEnabled, referrersAPIDisabled:
+		default:
+			return nil, fmt.Errorf("invalid referrers_api %q, expected one of %q, %q or %q", referrersAPI, referrersAPIAuto, referrersAPIEnabled, referrersAPIDisabled)
+		}
+		capabilityTTL := defaultCapabilityTTL
+		if params.ReferrersCapabilityTTL != "" {
+			if capabilityTTL, err = time.ParseDuration(params.ReferrersCapabilityTTL); err != nil || capabilityTTL <= 0 {
+				return nil, fmt.Errorf("invalid referrers_capability_ttl %q", params.ReferrersCapabilityTTL)
+			}
 		}
 
-		return ratify.NewRegistryStore(registryStoreOpts), nil
+		credGetter := &defaultCredGetter{
+			username: params.Credential.Username,
+			password: params.Credential.Password,
+		}
+		return newRegistryStore(&params, nil, credGetter, referrersAPI, capabilityTTL)
 	})
 }
 
+// newRegistryStore creates a registryStore sending requests through the base
+// transport. The referrers are listed with the authenticated client of the
+// underlying [ratify.RegistryStore], so that both share the cached tokens.
+func newRegistryStore(params *options, base http.RoundTripper, credGetter ratify.RegistryCredentialGetter, referrersAPI string, capabilityTTL time.Duration) (*registryStore, error) {
+	var httpClient *http.Client
+	if base != nil {
+		httpClient = &http.Client{Transport: base}
+	}
+	if params.RetryPolicy != nil || params.RateLimit != nil {
+		transport, err := newRetryTransport(base, params.RetryPolicy, params.RateLimit)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create registry transport: %w", err)
+		}
+		httpClient = &http.Client{Transport: transport}
+	}
+
+	client := &auth.Client{
+		Client:     httpClient,
+		Cache:      auth.NewCache(),
+		ClientID:   "ratify-go",
+		Credential: credentials.Credential(readOnlyCredentialStore{credGetter}),
+	}
+	if params.UserAgent != "" {
+		client.SetUserAgent(params.UserAgent)
+	} else {
+		client.SetUserAgent(defaultUserAgent)
+	}
+
+	// the underlying registry store authenticates through the client, so it
+	// is not given the credentials to authenticate on its own.
+	underlying := ratify.NewRegistryStore(ratify.RegistryStoreOptions{
+		HTTPClient:       &http.Client{Transport: authTransport{client: client}},
+		PlainHTTP:        params.PlainHTTP,
+		UserAgent:        params.UserAgent,
+		MaxBlobBytes:     params.MaxBlobBytes,
+		MaxManifestBytes: params.MaxManifestBytes,
+	})
+	return &registryStore{
+		RegistryStore: underlying,
+		client:        client,
+		plainHTTP:     params.PlainHTTP,
+		referrersAPI:  referrersAPI,
+		capabilityTTL: capabilityTTL,
+	}, nil
+}
+
+// authTransport is an [http.RoundTripper] sending requests through the
+// authenticated client.
+type authTransport struct {
+	client *auth.Client
+}
+
+// RoundTrip sends the request through the authenticated client.
+func (t authTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.client.Do(req)
+}
+
+// readOnlyCredentialStore is a [credentials.Store] returning the credentials
+// of a [ratify.RegistryCredentialGetter]. It is used with
+// [credentials.Credential] to map registry hosts to server addresses, e.g.
+// "registry-1.docker.io" to "https://index.docker.io/v1/", the same way as
+// [ratify.RegistryStore] does.
+type readOnlyCredentialStore struct {
+	ratify.RegistryCredentialGetter
+}
+
+func (readOnlyCredentialStore) Put(_ context.Context, _ string, _ auth.Credential) error {
+	return errors.New("registry credential: put: not supported")
+}
+
+func (readOnlyCredentialStore) Delete(_ context.Context, _ string) error {
+	return errors.New("registry credential: delete: not supported")
+}
+
 // defaultCredGetter is a simple implementation of [ratify.RegistryCredentialGetter]
 // interface.
 type defaultCredGetter struct {
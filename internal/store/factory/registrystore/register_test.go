@@ -45,6 +45,67 @@ func TestNewStore(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "Invalid retry policy",
+			opts: &factory.NewStoreOptions{
+				Type: registryStoreType,
+				Parameters: map[string]interface{}{
+					"retry_policy": map[string]interface{}{
+						"min_backoff": "invalid",
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "Invalid referrers API mode",
+			opts: &factory.NewStoreOptions{
+				Type: registryStoreType,
+				Parameters: map[string]interface{}{
+					"referrers_api": "sometimes",
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "Invalid referrers capability TTL",
+			opts: &factory.NewStoreOptions{
+				Type: registryStoreType,
+				Parameters: map[string]interface{}{
+					"referrers_capability_ttl": "-1m",
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "Valid referrers API options",
+			opts: &factory.NewStoreOptions{
+				Type: registryStoreType,
+				Parameters: map[string]interface{}{
+					"referrers_api":            referrersAPIAuto,
+					"referrers_capability_ttl": "30m",
+				},
+			},
+			expectErr: false,
+		},
+		{
+			name: "Valid retry policy and rate limit",
+			opts: &factory.NewStoreOptions{
+				Type: registryStoreType,
+				Parameters: map[string]interface{}{
+					"retry_policy": map[string]interface{}{
+						"max_retries": 3,
+						"min_backoff": "100ms",
+						"max_backoff": "2s",
+					},
+					"rate_limit": map[string]interface{}{
+						"qps":             5,
+						"max_concurrency": 4,
+					},
+				},
+			},
+			expectErr: false,
+		},
 		{
 			name: "Valid registry params",
 			opts: &factory.NewStoreOptions{
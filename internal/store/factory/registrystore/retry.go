@@ -0,0 +1,456 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registrystore
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/notaryproject/ratify/v2/internal/store/registryhistory"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/time/rate"
+)
+
+const (
+	defaultMinBackoff = 200 * time.Millisecond
+	defaultMaxBackoff = 5 * time.Second
+)
+
+// retryPolicy configures how failed registry requests are retried.
+type retryPolicy struct {
+	// MaxRetries is the maximum number of retries after the first attempt.
+	// Zero disables retries. Optional.
+	MaxRetries int `json:"max_retries,omitempty"`
+
+	// MinBackoff is the initial backoff duration, e.g. "200ms". Optional.
+	MinBackoff string `json:"min_backoff,omitempty"`
+
+	// MaxBackoff caps the backoff duration, including the wait requested by a
+	// Retry-After header, e.g. "5s". Optional.
+	MaxBackoff string `json:"max_backoff,omitempty"`
+}
+
+// rateLimit configures the client side throttling applied per registry host.
+type rateLimit struct {
+	// QPS is the maximum number of requests per second sent to a single
+	// registry host. Zero means no limit. Optional.
+	QPS float64 `json:"qps,omitempty"`
+
+	// Burst is the maximum burst of requests allowed above QPS. Defaults to 1
+	// if QPS is set. Optional.
+	Burst int `json:"burst,omitempty"`
+
+	// MaxConcurrency is the maximum number of in-flight requests to a single
+	// registry host. Zero means no limit. Optional.
+	MaxConcurrency int `json:"max_concurrency,omitempty"`
+}
+
+// attempt records a single retry or throttle event of a registry request.
+type attempt struct {
+	// status is the HTTP status code of a failed attempt, or 0 if the attempt
+	// failed with a transport error or was only throttled.
+	status int
+
+	// err is the transport error of a failed attempt.
+	err error
+
+	// wait is the duration waited before the next attempt or, for throttled
+	// requests, before the request was sent.
+	wait time.Duration
+
+	// throttled indicates that the request was delayed by the client side
+	// rate limiter instead of being rejected by the registry.
+	throttled bool
+}
+
+func (a attempt) String() string {
+	switch {
+	case a.throttled:
+		return fmt.Sprintf("throttled by client rate limit for %v", a.wait)
+	case a.err != nil:
+		return fmt.Sprintf("%v (retried after %v)", a.err, a.wait)
+	default:
+		return fmt.Sprintf("%d %s (retried after %v)", a.status, http.StatusText(a.status), a.wait)
+	}
+}
+
+// RetryError is returned when a registry request is still failing after all
+// retries are exhausted. It records every retry and throttle so that registry
+// flakiness can be told apart from signature verification failures.
+type RetryError struct {
+	// Method is the HTTP method of the request.
+	Method string
+
+	// URL is the requested URL without query parameters.
+	URL string
+
+	// Status is the HTTP status code of the last attempt, or 0 if the last
+	// attempt failed with a transport error.
+	Status int
+
+	// Err is the transport error of the last attempt, if any.
+	Err error
+
+	attempts []attempt
+
+	// exhausted indicates that the request failed because all retries are
+	// exhausted rather than because the request was cancelled.
+	exhausted bool
+}
+
+// Error implements the error interface.
+func (e *RetryError) Error() string {
+	var last string
+	if e.Err != nil {
+		last = e.Err.Error()
+	} else {
+		last = fmt.Sprintf("%d %s", e.Status, http.StatusText(e.Status))
+	}
+	history := make([]string, len(e.attempts))
+	for i, a := range e.attempts {
+		history[i] = a.String()
+	}
+	return fmt.Sprintf("registry request %s %s failed after %d retries: %s; history: [%s]", e.Method, e.URL, e.Retries(), last, strings.Join(history, ", "))
+}
+
+// Unwrap returns the transport error of the last attempt.
+func (e *RetryError) Unwrap() error {
+	return e.Err
+}
+
+// Is reports whether the error matches [registryhistory.ErrRetriesExhausted].
+func (e *RetryError) Is(target error) bool {
+	return e.exhausted && target == registryhistory.ErrRetriesExhausted
+}
+
+// Retries returns the number of retries performed.
+func (e *RetryError) Retries() int {
+	retries := 0
+	for _, a := range e.attempts {
+		if !a.throttled {
+			retries++
+		}
+	}
+	return retries
+}
+
+// retryTransport is an [http.RoundTripper] that retries throttled and
+// transiently failed requests with exponential backoff and jitter, honors the
+// Retry-After header, and limits the request rate and concurrency per registry
+// host.
+type retryTransport struct {
+	base       http.RoundTripper
+	maxRetries int
+	minBackoff time.Duration
+	maxBackoff time.Duration
+	rateLimit  rateLimit
+
+	mu       sync.Mutex
+	limiters map[string]*hostLimiter
+
+	// sleep waits for the given duration or until the context is done. It is
+	// replaceable for testing.
+	sleep func(ctx context.Context, d time.Duration) error
+}
+
+// hostLimiter holds the rate limiter and concurrency semaphore of a registry
+// host.
+type hostLimiter struct {
+	limiter *rate.Limiter
+	sem     chan struct{}
+}
+
+// newRetryTransport creates a new retryTransport wrapping the base transport.
+// If base is nil, [http.DefaultTransport] is used.
+func newRetryTransport(base http.RoundTripper, policy *retryPolicy, limit *rateLimit) (*retryTransport, error) {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	t := &retryTransport{
+		base:       base,
+		minBackoff: defaultMinBackoff,
+		maxBackoff: defaultMaxBackoff,
+		limiters:   make(map[string]*hostLimiter),
+		sleep:      sleepWithContext,
+	}
+	if policy != nil {
+		if policy.MaxRetries < 0 {
+			return nil, fmt.Errorf("max_retries must not be negative")
+		}
+		t.maxRetries = policy.MaxRetries
+		if policy.MinBackoff != "" {
+			d, err := time.ParseDuration(policy.MinBackoff)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("invalid min_backoff %q", policy.MinBackoff)
+			}
+			t.minBackoff = d
+		}
+		if policy.MaxBackoff != "" {
+			d, err := time.ParseDuration(policy.MaxBackoff)
+			if err != nil || d <= 0 {
+				return nil, fmt.Errorf("invalid max_backoff %q", policy.MaxBackoff)
+			}
+			t.maxBackoff = d
+		}
+		if t.minBackoff > t.maxBackoff {
+			return nil, fmt.Errorf("min_backoff %v must not exceed max_backoff %v", t.minBackoff, t.maxBackoff)
+		}
+	}
+	if limit != nil {
+		if limit.QPS < 0 || limit.Burst < 0 || limit.MaxConcurrency < 0 {
+			return nil, fmt.Errorf("rate limit values must not be negative")
+		}
+		t.rateLimit = *limit
+		if t.rateLimit.QPS > 0 && t.rateLimit.Burst == 0 {
+			t.rateLimit.Burst = 1
+		}
+	}
+	return t, nil
+}
+
+// RoundTrip implements the [http.RoundTripper] interface.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+	limiter := t.hostLimiter(req.URL.Host)
+	var attempts []attempt
+
+	for retry := 0; ; retry++ {
+		attemptReq := req
+		if retry > 0 && req.GetBody != nil {
+			body, err := req.GetBody()
+			if err != nil {
+				return nil, fmt.Errorf("failed to rewind request body: %w", err)
+			}
+			attemptReq = req.Clone(ctx)
+			attemptReq.Body = body
+		}
+
+		throttled, err := limiter.wait(ctx)
+		if throttled > 0 {
+			a := attempt{wait: throttled, throttled: true}
+			logrus.Debugf("registry request %s %s %v", req.Method, redactURL(req), a)
+			registryhistory.Record(ctx, fmt.Sprintf("%s %s: %v", req.Method, redactURL(req), a))
+			attempts = append(attempts, a)
+		}
+		if err != nil {
+			return nil, t.retryError(req, 0, err, attempts, false)
+		}
+		resp, err := t.base.RoundTrip(attemptReq)
+		if resp != nil && resp.Body != nil {
+			// keep the concurrency slot until the response body is consumed.
+			resp.Body = &releaseOnClose{ReadCloser: resp.Body, release: limiter.release}
+		} else {
+			limiter.release()
+		}
+
+		status := 0
+		if resp != nil {
+			status = resp.StatusCode
+		}
+		if !isRetryable(ctx, status, err) {
+			return resp, err
+		}
+		if retry >= t.maxRetries || !canRewind(req) {
+			if len(attempts) == 0 {
+				return resp, err
+			}
+			if resp != nil {
+				drainBody(resp)
+			}
+			return nil, t.retryError(req, status, err, attempts, true)
+		}
+
+		wait := t.backoff(retry)
+		if resp != nil {
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()); ok {
+				wait = min(max(retryAfter, wait), t.maxBackoff)
+			}
+			drainBody(resp)
+		}
+		a := attempt{status: status, err: err, wait: wait}
+		logrus.Infof("retrying registry request %s %s: %v", req.Method, redactURL(req), a)
+		registryhistory.Record(ctx, fmt.Sprintf("%s %s: %v", req.Method, redactURL(req), a))
+		attempts = append(attempts, a)
+
+		if err := t.sleep(ctx, wait); err != nil {
+			return nil, t.retryError(req, status, err, attempts, false)
+		}
+	}
+}
+
+// retryError builds a [RetryError] for the request.
+func (t *retryTransport) retryError(req *http.Request, status int, err error, attempts []attempt, exhausted bool) error {
+	return &RetryError{
+		Method:    req.Method,
+		URL:       redactURL(req),
+		Status:    status,
+		Err:       err,
+		attempts:  attempts,
+		exhausted: exhausted,
+	}
+}
+
+// backoff returns the exponential backoff with equal jitter for the given
+// retry.
+func (t *retryTransport) backoff(retry int) time.Duration {
+	d := t.minBackoff << min(retry, 30)
+	if d <= 0 || d > t.maxBackoff {
+		d = t.maxBackoff
+	}
+	// Equal jitter keeps at least half of the computed backoff so that
+	// concurrent clients spread out without retrying immediately.
+	half := d / 2
+	return half + rand.N(half+1)
+}
+
+// hostLimiter returns the limiter of the given host, creating it on demand.
+func (t *retryTransport) hostLimiter(host string) *hostLimiter {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if l, ok := t.limiters[host]; ok {
+		return l
+	}
+	l := &hostLimiter{}
+	if t.rateLimit.QPS > 0 {
+		l.limiter = rate.NewLimiter(rate.Limit(t.rateLimit.QPS), t.rateLimit.Burst)
+	}
+	if t.rateLimit.MaxConcurrency > 0 {
+		l.sem = make(chan struct{}, t.rateLimit.MaxConcurrency)
+	}
+	t.limiters[host] = l
+	return l
+}
+
+// wait blocks until the request is allowed by both the concurrency and the QPS
+// limit. It returns the duration the request was throttled.
+func (l *hostLimiter) wait(ctx context.Context) (time.Duration, error) {
+	start := time.Now()
+	if l.sem != nil {
+		select {
+		case l.sem <- struct{}{}:
+		case <-ctx.Done():
+			return time.Since(start), ctx.Err()
+		}
+	}
+	if l.limiter != nil {
+		if err := l.limiter.Wait(ctx); err != nil {
+			l.release()
+			return time.Since(start), err
+		}
+	}
+	throttled := time.Since(start)
+	if throttled < time.Millisecond {
+		throttled = 0
+	}
+	return throttled, nil
+}
+
+// release frees the concurrency slot acquired by wait.
+func (l *hostLimiter) release() {
+	if l.sem != nil {
+		<-l.sem
+	}
+}
+
+// releaseOnClose is a response body releasing the concurrency slot of the
+// request once closed.
+type releaseOnClose struct {
+	io.ReadCloser
+	once    sync.Once
+	release func()
+}
+
+// Close closes the body and releases the concurrency slot.
+func (b *releaseOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.release)
+	return err
+}
+
+// isRetryable returns true if the request should be retried based on the
+// response status or the transport error.
+func isRetryable(ctx context.Context, status int, err error) bool {
+	if err != nil {
+		// do not retry if the request is cancelled or timed out by the caller.
+		return ctx.Err() == nil
+	}
+	switch status {
+	case http.StatusTooManyRequests,
+		http.StatusInternalServerError,
+		http.StatusBadGateway,
+		http.StatusServiceUnavailable,
+		http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// canRewind returns true if the request body can be replayed.
+func canRewind(req *http.Request) bool {
+	return req.Body == nil || req.Body == http.NoBody || req.GetBody != nil
+}
+
+// parseRetryAfter parses the Retry-After header in either delay-seconds or
+// HTTP-date format.
+func parseRetryAfter(value string, now time.Time) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		return max(date.Sub(now), 0), true
+	}
+	return 0, false
+}
+
+// drainBody discards and closes the response body so that the underlying
+// connection can be reused.
+func drainBody(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 4*1024))
+	resp.Body.Close()
+}
+
+// redactURL returns the request URL without query parameters, which may carry
+// credentials.
+func redactURL(req *http.Request) string {
+	u := *req.URL
+	u.RawQuery = ""
+	u.User = nil
+	return u.String()
+}
+
+func sleepWithContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,388 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registrystore
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/store/registryhistory"
+)
+
+func TestNewRetryTransport(t *testing.T) {
+	tests := []struct {
+		name      string
+		policy    *retryPolicy
+		limit     *rateLimit
+		expectErr bool
+	}{
+		{
+			name: "no policy",
+		},
+		{
+			name:      "negative retries",
+			policy:    &retryPolicy{MaxRetries: -1},
+			expectErr: true,
+		},
+		{
+			name:      "invalid min backoff",
+			policy:    &retryPolicy{MinBackoff: "abc"},
+			expectErr: true,
+		},
+		{
+			name:      "invalid max backoff",
+			policy:    &retryPolicy{MaxBackoff: "-1s"},
+			expectErr: true,
+		},
+		{
+			name:      "min backoff exceeds max backoff",
+			policy:    &retryPolicy{MinBackoff: "10s", MaxBackoff: "1s"},
+			expectErr: true,
+		},
+		{
+			name:      "negative rate limit",
+			limit:     &rateLimit{QPS: -1},
+			expectErr: true,
+		},
+		{
+			name:   "valid policy",
+			policy: &retryPolicy{MaxRetries: 3, MinBackoff: "100ms", MaxBackoff: "2s"},
+			limit:  &rateLimit{QPS: 10, MaxConcurrency: 2},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := newRetryTransport(nil, test.policy, test.limit)
+			if (err != nil) != test.expectErr {
+				t.Errorf("expected error: %v, got: %v", test.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_RoundTrip(t *testing.T) {
+	tests := []struct {
+		name           string
+		statuses       []int
+		retryAfter     string
+		maxRetries     int
+		expectStatus   int
+		expectRetries  int
+		expectErr      bool
+		expectMinSleep time.Duration
+	}{
+		{
+			name:         "success without retry",
+			statuses:     []int{http.StatusOK},
+			maxRetries:   3,
+			expectStatus: http.StatusOK,
+		},
+		{
+			name:         "non-retryable status",
+			statuses:     []int{http.StatusNotFound},
+			maxRetries:   3,
+			expectStatus: http.StatusNotFound,
+		},
+		{
+			name:          "success after transient failures",
+			statuses:      []int{http.StatusServiceUnavailable, http.StatusBadGateway, http.StatusOK},
+			maxRetries:    3,
+			expectStatus:  http.StatusOK,
+			expectRetries: 2,
+		},
+		{
+			name:           "retry after header honored",
+			statuses:       []int{http.StatusTooManyRequests, http.StatusOK},
+			retryAfter:     "2",
+			maxRetries:     1,
+			expectStatus:   http.StatusOK,
+			expectRetries:  1,
+			expectMinSleep: 2 * time.Second,
+		},
+		{
+			name:          "retries exhausted",
+			statuses:      []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests},
+			maxRetries:    2,
+			expectRetries: 2,
+			expectErr:     true,
+		},
+		{
+			name:         "retries disabled",
+			statuses:     []int{http.StatusServiceUnavailable},
+			maxRetries:   0,
+			expectStatus: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				idx := int(calls.Add(1)) - 1
+				if idx >= len(test.statuses) {
+					idx = len(test.statuses) - 1
+				}
+				if test.retryAfter != "" {
+					w.Header().Set("Retry-After", test.retryAfter)
+				}
+				w.WriteHeader(test.statuses[idx])
+			}))
+			defer server.Close()
+
+			transport, err := newRetryTransport(nil, &retryPolicy{
+				MaxRetries: test.maxRetries,
+				MinBackoff: "1ms",
+				MaxBackoff: "5s",
+			}, nil)
+			if err != nil {
+				t.Fatalf("failed to create transport: %v", err)
+			}
+			var slept time.Duration
+			transport.sleep = func(_ context.Context, d time.Duration) error {
+				slept += d
+				return nil
+			}
+
+			req, _ := http.NewRequestWithContext(context.Background(), http.MethodGet, server.URL+"/v2/?token=secret", nil)
+			resp, err := transport.RoundTrip(req)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err != nil {
+				var retryErr *RetryError
+				if !errors.As(err, &retryErr) {
+					t.Fatalf("expected RetryError, got: %T", err)
+				}
+				if retryErr.Retries() != test.expectRetries {
+					t.Errorf("expected %d retries, got %d", test.expectRetries, retryErr.Retries())
+				}
+				if strings.Contains(err.Error(), "secret") {
+					t.Errorf("expected query to be redacted, got: %v", err)
+				}
+				return
+			}
+			defer resp.Body.Close()
+			if resp.StatusCode != test.expectStatus {
+				t.Errorf("expected status %d, got %d", test.expectStatus, resp.StatusCode)
+			}
+			if got := int(calls.Load()) - 1; got != test.expectRetries {
+				t.Errorf("expected %d retries, got %d", test.expectRetries, got)
+			}
+			if slept < test.expectMinSleep {
+				t.Errorf("expected to sleep at least %v, slept %v", test.expectMinSleep, slept)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_ContextCancelled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	transport, err := newRetryTransport(nil, &retryPolicy{MaxRetries: 5}, nil)
+	if err != nil {
+		t.Fatalf("failed to create transport: %v", err)
+	}
+	transport.sleep = func(_ context.Context, _ time.Duration) error {
+		return context.DeadlineExceeded
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	_, err = transport.RoundTrip(req)
+	var retryErr *RetryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected RetryError, got: %v", err)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected deadline exceeded, got: %v", err)
+	}
+	if errors.Is(err, registryhistory.ErrRetriesExhausted) {
+		t.Errorf("expected cancelled request not to exhaust retries, got: %v", err)
+	}
+}
+
+func TestRetryTransport_History(t *testing.T) {
+	tests := []struct {
+		name            string
+		statuses        []int
+		expectRecorded  int
+		expectExhausted bool
+	}{
+		{
+			name:     "success without retry",
+			statuses: []int{http.StatusOK},
+		},
+		{
+			name:           "success after retries",
+			statuses:       []int{http.StatusTooManyRequests, http.StatusServiceUnavailable, http.StatusOK},
+			expectRecorded: 2,
+		},
+		{
+			name:            "retries exhausted",
+			statuses:        []int{http.StatusTooManyRequests, http.StatusTooManyRequests, http.StatusTooManyRequests},
+			expectRecorded:  2,
+			expectExhausted: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var calls atomic.Int32
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				w.WriteHeader(test.statuses[calls.Add(1)-1])
+			}))
+			defer server.Close()
+
+			transport, err := newRetryTransport(nil, &retryPolicy{MaxRetries: 2}, nil)
+			if err != nil {
+				t.Fatalf("failed to create transport: %v", err)
+			}
+			transport.sleep = func(_ context.Context, _ time.Duration) error {
+				return nil
+			}
+
+			ctx := registryhistory.WithValidation(context.Background())
+			req, _ := http.NewRequestWithContext(ctx, http.MethodGet, server.URL+"/v2/", nil)
+			resp, err := transport.RoundTrip(req)
+			if errors.Is(err, registryhistory.ErrRetriesExhausted) != test.expectExhausted {
+				t.Errorf("expected exhausted retries: %v, got: %v", test.expectExhausted, err)
+			}
+			if resp != nil {
+				resp.Body.Close()
+			}
+
+			result := &ratify.ValidationResult{}
+			registryhistory.AddReport(ctx, "test.io/ns/repo:v1", result)
+			var recorded []string
+			if len(result.ArtifactReports) > 0 {
+				recorded = result.ArtifactReports[0].Results[0].Detail.(map[string][]string)["registryRequests"]
+			}
+			if len(recorded) != test.expectRecorded {
+				t.Errorf("expected %d recorded requests, got %v", test.expectRecorded, recorded)
+			}
+		})
+	}
+}
+
+func TestRetryTransport_ReleaseOnBodyClose(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	transport, err := newRetryTransport(nil, nil, &rateLimit{MaxConcurrency: 1})
+	if err != nil {
+		t.Fatalf("failed to create transport: %v", err)
+	}
+	req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err := transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// the slot is held until the body of the first response is closed.
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	req, _ = http.NewRequestWithContext(ctx, http.MethodGet, server.URL, nil)
+	if _, err := transport.RoundTrip(req); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the request to wait for the open response, got: %v", err)
+	}
+
+	resp.Body.Close()
+	resp.Body.Close()
+	req, _ = http.NewRequest(http.MethodGet, server.URL, nil)
+	resp, err = transport.RoundTrip(req)
+	if err != nil {
+		t.Fatalf("unexpected error after closing the response body: %v", err)
+	}
+	resp.Body.Close()
+}
+
+func TestRetryTransport_MaxConcurrency(t *testing.T) {
+	var inflight, peak atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		cur := inflight.Add(1)
+		for {
+			old := peak.Load()
+			if cur <= old || peak.CompareAndSwap(old, cur) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		inflight.Add(-1)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	transport, err := newRetryTransport(nil, nil, &rateLimit{MaxConcurrency: 2})
+	if err != nil {
+		t.Fatalf("failed to create transport: %v", err)
+	}
+	var wg sync.WaitGroup
+	for range 6 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			req, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			resp, err := transport.RoundTrip(req)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+			resp.Body.Close()
+		}()
+	}
+	wg.Wait()
+	if peak.Load() > 2 {
+		t.Errorf("expected at most 2 concurrent requests, got %d", peak.Load())
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	now := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		value    string
+		expected time.Duration
+		ok       bool
+	}{
+		{name: "empty", value: ""},
+		{name: "seconds", value: "3", expected: 3 * time.Second, ok: true},
+		{name: "negative seconds", value: "-1"},
+		{name: "http date", value: now.Add(10 * time.Second).Format(http.TimeFormat), expected: 10 * time.Second, ok: true},
+		{name: "past http date", value: now.Add(-time.Minute).Format(http.TimeFormat), expected: 0, ok: true},
+		{name: "invalid", value: "soon"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, ok := parseRetryAfter(test.value, now)
+			if ok != test.ok || got != test.expected {
+				t.Errorf("expected (%v, %v), got (%v, %v)", test.expected, test.ok, got, ok)
+			}
+		})
+	}
+}
@@ -0,0 +1,230 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package latestreferrers limits the referrers verified per artifact type to
+// the newest ones so that superseded artifacts, e.g. outdated vulnerability
+// reports, are neither fetched nor verified.
+package latestreferrers
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// anyArtifactType is the key of the limit of artifact types without a limit
+// of their own.
+const anyArtifactType = "*"
+
+// Options configures the number of newest referrers verified per artifact
+// type.
+type Options struct {
+	// Limits maps artifact types to the number of newest referrers of the
+	// type verified per subject. "*" applies to artifact types not listed.
+	// Referrers of artifact types without a limit are all verified. Required.
+	Limits map[string]int `json:"limits"`
+
+	// Annotation is the annotation holding the RFC 3339 creation time the
+	// referrers are ordered by. Referrers without a valid creation time are
+	// the oldest. Optional. Defaults to "org.opencontainers.image.created".
+	Annotation string `json:"annotation,omitempty"`
+}
+
+// skippedReferrer is a referrer that is not among the newest referrers of its
+// artifact type.
+type skippedReferrer struct {
+	desc  ocispec.Descriptor
+	limit int
+}
+
+// validation tracks the referrers skipped in a single validation.
+type validation struct {
+	// subject is the digested reference of the validated subject.
+	subject string
+
+	mu      sync.Mutex
+	listed  bool
+	skipped []skippedReferrer
+}
+
+type validationKey struct{}
+
+// WithValidation returns a context for validating the subject with the given
+// digested reference, e.g. "registry.example/repo@sha256:...". The referrers
+// skipped in the context are reported by [AddSkippedReports].
+func WithValidation(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, validationKey{}, &validation{
+		subject: subject,
+	})
+}
+
+// Store is a [ratify.Store] listing only the newest referrers of the limited
+// artifact types of the validated subject. Only the first listing of the
+// referrers of the subject in a context created by [WithValidation], i.e. the
+// walk of the executor, is limited and listed in a single page. Other
+// listings, e.g. of nested referrers or by verifiers looking up related
+// artifacts, are passed through.
+type Store struct {
+	ratify.Store
+	limits     map[string]int
+	annotation string
+}
+
+// New creates a new [Store] limiting the referrers listed by the given store.
+func New(store ratify.Store, opts *Options) (*Store, error) {
+	if store == nil {
+		return nil, errors.New("store cannot be nil")
+	}
+	if opts == nil || len(opts.Limits) == 0 {
+		return nil, errors.New("latest referrers limits are required")
+	}
+	for artifactType, limit := range opts.Limits {
+		if limit < 1 {
+			return nil, fmt.Errorf("invalid latest referrers limit %d for artifact type %q", limit, artifactType)
+		}
+	}
+	annotation := opts.Annotation
+	if annotation == "" {
+		annotation = ocispec.AnnotationCreated
+	}
+	return &Store{
+		Store:      store,
+		limits:     opts.Limits,
+		annotation: annotation,
+	}, nil
+}
+
+// ListReferrers lists the referrers of the artifact. The first listing of the
+// referrers of the subject in a context created by [WithValidation] lists all
+// referrers from the underlying store and passes the newest referrers of the
+// limited artifact types in listing order. The skipped referrers are recorded
+// in the context. Other listings are passed through.
+func (s *Store) ListReferrers(ctx context.Context, ref string, artifactTypes []string, fn func(referrers []ocispec.Descriptor) error) error {
+	v, ok := ctx.Value(validationKey{}).(*validation)
+	if !ok || ref != v.subject {
+		return s.Store.ListReferrers(ctx, ref, artifactTypes, fn)
+	}
+	v.mu.Lock()
+	first := !v.listed
+	v.listed = true
+	v.mu.Unlock()
+	if !first {
+		return s.Store.ListReferrers(ctx, ref, artifactTypes, fn)
+	}
+
+	var referrers []ocispec.Descriptor
+	if err := s.Store.ListReferrers(ctx, ref, artifactTypes, func(page []ocispec.Descriptor) error {
+		referrers = append(referrers, page...)
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	kept, skipped := s.filter(referrers)
+	if len(skipped) > 0 {
+		v.mu.Lock()
+		v.skipped = append(v.skipped, skipped...)
+		v.mu.Unlock()
+		logrus.Debugf("skipping %d superseded referrers of %s", len(skipped), ref)
+	}
+	if len(kept) == 0 {
+		return nil
+	}
+	return fn(kept)
+}
+
+// filter splits the referrers into the newest referrers of each artifact type
+// and the skipped ones, both in listing order.
+func (s *Store) filter(referrers []ocispec.Descriptor) ([]ocispec.Descriptor, []skippedReferrer) {
+	byType := make(map[string][]int)
+	for i, referrer := range referrers {
+		byType[referrer.ArtifactType] = append(byType[referrer.ArtifactType], i)
+	}
+	limits := make([]int, len(referrers))
+	skip := make([]bool, len(referrers))
+	for artifactType, indexes := range byType {
+		limit, ok := s.limits[artifactType]
+		if !ok {
+			if limit, ok = s.limits[anyArtifactType]; !ok {
+				continue
+			}
+		}
+		if len(indexes) <= limit {
+			continue
+		}
+		// newest first, keeping the listing order of equally old referrers.
+		slices.SortStableFunc(indexes, func(a, b int) int {
+			return s.created(referrers[b]).Compare(s.created(referrers[a]))
+		})
+		for _, i := range indexes[limit:] {
+			skip[i] = true
+			limits[i] = limit
+		}
+	}
+
+	var kept []ocispec.Descriptor
+	var skipped []skippedReferrer
+	for i, referrer := range referrers {
+		if skip[i] {
+			skipped = append(skipped, skippedReferrer{desc: referrer, limit: limits[i]})
+		} else {
+			kept = append(kept, referrer)
+		}
+	}
+	return kept, skipped
+}
+
+// created returns the creation time of the referrer, or the zero time if the
+// referrer has no valid creation time.
+func (s *Store) created(referrer ocispec.Descriptor) time.Time {
+	t, err := time.Parse(time.RFC3339, referrer.Annotations[s.annotation])
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// AddSkippedReports adds a report for each referrer skipped in the context
+// created by [WithValidation] next to the reports of the referrers of the
+// subject. The reports have a single result without verifier describing why
+// the referrer is skipped. They are added after policy evaluation and do not
+// affect the outcome of the validation.
+func AddSkippedReports(ctx context.Context, result *ratify.ValidationResult) {
+	v, ok := ctx.Value(validationKey{}).(*validation)
+	if !ok || result == nil {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for _, skipped := range v.skipped {
+		result.ArtifactReports = append(result.ArtifactReports, &ratify.ValidationReport{
+			Subject:  v.subject,
+			Artifact: skipped.desc,
+			Results: []*ratify.VerificationResult{
+				{
+					Description: fmt.Sprintf("Referrer skipped: not among the latest %d referrers of artifact type %s", skipped.limit, cmp.Or(skipped.desc.ArtifactType, "<none>")),
+				},
+			},
+		})
+	}
+}
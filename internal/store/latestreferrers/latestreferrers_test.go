@@ -0,0 +1,346 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package latestreferrers
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	testRepo      = "test.io/ns/repo"
+	typeReport    = "application/vnd.test.report"
+	typeSignature = "application/vnd.test.signature"
+)
+
+var testSubject = testRepo + "@" + digest.FromString("subject").String()
+
+// newReferrer returns a referrer of the artifact type created at the time,
+// if not empty.
+func newReferrer(name, artifactType, created string) ocispec.Descriptor {
+	desc := ocispec.Descriptor{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: artifactType,
+		Digest:       digest.FromString(name),
+	}
+	if created != "" {
+		desc.Annotations = map[string]string{ocispec.AnnotationCreated: created}
+	}
+	return desc
+}
+
+var (
+	report1   = newReferrer("report1", typeReport, "2024-01-01T00:00:00Z")
+	report2   = newReferrer("report2", typeReport, "2024-03-01T00:00:00Z")
+	report3   = newReferrer("report3", typeReport, "2024-02-01T00:00:00Z")
+	reportOld = newReferrer("report-old", typeReport, "")
+	reportBad = newReferrer("report-bad", typeReport, "yesterday")
+	sig1      = newReferrer("sig1", typeSignature, "2024-01-01T00:00:00Z")
+	sig2      = newReferrer("sig2", typeSignature, "2024-01-02T00:00:00Z")
+)
+
+// mockStore lists the referrers of subjects in pages of one referrer.
+type mockStore struct {
+	referrers map[string][]ocispec.Descriptor
+	listErr   error
+}
+
+func (s *mockStore) Resolve(_ context.Context, _ string) (ocispec.Descriptor, error) {
+	return ocispec.Descriptor{}, nil
+}
+
+func (s *mockStore) ListReferrers(_ context.Context, ref string, _ []string, fn func(referrers []ocispec.Descriptor) error) error {
+	if s.listErr != nil {
+		return s.listErr
+	}
+	for _, referrer := range s.referrers[ref] {
+		if err := fn([]ocispec.Descriptor{referrer}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *mockStore) FetchBlob(_ context.Context, _ string, _ ocispec.Descriptor) ([]byte, error) {
+	return nil, nil
+}
+
+func (s *mockStore) FetchManifest(_ context.Context, _ string, _ ocispec.Descriptor) ([]byte, error) {
+	return nil, nil
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		store     ratify.Store
+		opts      *Options
+		expectErr bool
+	}{
+		{
+			name:  "valid options",
+			store: &mockStore{},
+			opts:  &Options{Limits: map[string]int{typeReport: 1, "*": 3}, Annotation: "created"},
+		},
+		{
+			name:      "nil store",
+			opts:      &Options{Limits: map[string]int{typeReport: 1}},
+			expectErr: true,
+		},
+		{
+			name:      "nil options",
+			store:     &mockStore{},
+			expectErr: true,
+		},
+		{
+			name:      "no limits",
+			store:     &mockStore{},
+			opts:      &Options{},
+			expectErr: true,
+		},
+		{
+			name:      "non-positive limit",
+			store:     &mockStore{},
+			opts:      &Options{Limits: map[string]int{typeReport: 0}},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := New(test.store, test.opts)
+			if (err != nil) != test.expectErr {
+				t.Errorf("expected error: %v, got: %v", test.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestStore_ListReferrers(t *testing.T) {
+	referrers := []ocispec.Descriptor{reportOld, report1, sig1, report2, reportBad, sig2, report3}
+	tests := []struct {
+		name          string
+		limits        map[string]int
+		expectKept    []ocispec.Descriptor
+		expectSkipped []ocispec.Descriptor
+	}{
+		{
+			name:          "latest report",
+			limits:        map[string]int{typeReport: 1},
+			expectKept:    []ocispec.Descriptor{sig1, report2, sig2},
+			expectSkipped: []ocispec.Descriptor{reportOld, report1, reportBad, report3},
+		},
+		{
+			name:          "latest 2 reports",
+			limits:        map[string]int{typeReport: 2},
+			expectKept:    []ocispec.Descriptor{sig1, report2, sig2, report3},
+			expectSkipped: []ocispec.Descriptor{reportOld, report1, reportBad},
+		},
+		{
+			name:          "default limit",
+			limits:        map[string]int{typeReport: 4, "*": 1},
+			expectKept:    []ocispec.Descriptor{reportOld, report1, report2, sig2, report3},
+			expectSkipped: []ocispec.Descriptor{sig1, reportBad},
+		},
+		{
+			name:       "limit above count",
+			limits:     map[string]int{typeSignature: 2},
+			expectKept: referrers,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store, err := New(&mockStore{referrers: map[string][]ocispec.Descriptor{testSubject: referrers}}, &Options{Limits: test.limits})
+			if err != nil {
+				t.Fatalf("failed to create store: %v", err)
+			}
+			ctx := WithValidation(context.Background(), testSubject)
+			var kept []ocispec.Descriptor
+			if err := store.ListReferrers(ctx, testSubject, nil, func(page []ocispec.Descriptor) error {
+				kept = append(kept, page...)
+				return nil
+			}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !slices.EqualFunc(kept, test.expectKept, equalDigest) {
+				t.Errorf("expected referrers %v, got %v", digests(test.expectKept), digests(kept))
+			}
+
+			var skipped []ocispec.Descriptor
+			for _, s := range ctx.Value(validationKey{}).(*validation).skipped {
+				skipped = append(skipped, s.desc)
+			}
+			if !slices.EqualFunc(skipped, test.expectSkipped, equalDigest) {
+				t.Errorf("expected skipped referrers %v, got %v", digests(test.expectSkipped), digests(skipped))
+			}
+		})
+	}
+}
+
+func TestStore_ListReferrersError(t *testing.T) {
+	errList := errors.New("list failed")
+	store, err := New(&mockStore{listErr: errList}, &Options{Limits: map[string]int{typeReport: 1}})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if err := store.ListReferrers(context.Background(), testSubject, nil, nil); !errors.Is(err, errList) {
+		t.Errorf("expected listing error, got: %v", err)
+	}
+
+	store, err = New(&mockStore{referrers: map[string][]ocispec.Descriptor{testSubject: {report1}}}, &Options{Limits: map[string]int{typeReport: 1}})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	errStop := errors.New("stop")
+	if err := store.ListReferrers(context.Background(), testSubject, nil, func(_ []ocispec.Descriptor) error {
+		return errStop
+	}); err != errStop {
+		t.Errorf("expected callback error to be returned as is, got: %v", err)
+	}
+
+	called := false
+	if err := store.ListReferrers(context.Background(), testRepo+"@"+report1.Digest.String(), nil, func(_ []ocispec.Descriptor) error {
+		called = true
+		return nil
+	}); err != nil || called {
+		t.Errorf("expected no callback for artifacts without referrers, got: %v, %v", called, err)
+	}
+}
+
+func TestStore_ListReferrers_PassThrough(t *testing.T) {
+	report1Ref := testRepo + "@" + report1.Digest.String()
+	store, err := New(&mockStore{referrers: map[string][]ocispec.Descriptor{
+		testSubject: {report1, report2},
+		report1Ref:  {sig1, sig2},
+	}}, &Options{Limits: map[string]int{typeReport: 1, typeSignature: 1}})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	list := func(ctx context.Context, ref string) []ocispec.Descriptor {
+		var referrers []ocispec.Descriptor
+		if err := store.ListReferrers(ctx, ref, nil, func(page []ocispec.Descriptor) error {
+			referrers = append(referrers, page...)
+			return nil
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		return referrers
+	}
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		ref    string
+		expect int
+	}{
+		{
+			name:   "outside of validation",
+			ctx:    context.Background(),
+			ref:    testSubject,
+			expect: 2,
+		},
+		{
+			name:   "referrers of other artifacts",
+			ctx:    WithValidation(context.Background(), testSubject),
+			ref:    report1Ref,
+			expect: 2,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if referrers := list(test.ctx, test.ref); len(referrers) != test.expect {
+				t.Errorf("expected %d referrers, got %v", test.expect, digests(referrers))
+			}
+		})
+	}
+
+	// listings of the subject by verifiers after the executor walk are not
+	// limited.
+	ctx := WithValidation(context.Background(), testSubject)
+	if referrers := list(ctx, testSubject); len(referrers) != 1 {
+		t.Errorf("expected 1 referrer, got %v", digests(referrers))
+	}
+	if referrers := list(ctx, testSubject); len(referrers) != 2 {
+		t.Errorf("expected 2 referrers, got %v", digests(referrers))
+	}
+	if skipped := ctx.Value(validationKey{}).(*validation).skipped; len(skipped) != 1 {
+		t.Errorf("expected 1 skipped referrer, got %d", len(skipped))
+	}
+}
+
+func TestAddSkippedReports(t *testing.T) {
+	store, err := New(&mockStore{referrers: map[string][]ocispec.Descriptor{
+		testSubject: {report1, report2},
+	}}, &Options{Limits: map[string]int{typeReport: 1}})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	// without validation context, skipped referrers are not reported.
+	result := &ratify.ValidationResult{}
+	AddSkippedReports(context.Background(), result)
+	if len(result.ArtifactReports) != 0 {
+		t.Fatalf("expected no reports, got %d", len(result.ArtifactReports))
+	}
+
+	ctx := WithValidation(context.Background(), testSubject)
+	AddSkippedReports(ctx, result)
+	AddSkippedReports(ctx, nil)
+	if len(result.ArtifactReports) != 0 {
+		t.Fatalf("expected no reports before listing, got %d", len(result.ArtifactReports))
+	}
+
+	if err := store.ListReferrers(ctx, testSubject, nil, func(_ []ocispec.Descriptor) error {
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	result = &ratify.ValidationResult{
+		ArtifactReports: []*ratify.ValidationReport{
+			{Subject: testSubject, Artifact: report2},
+		},
+	}
+	AddSkippedReports(ctx, result)
+	if len(result.ArtifactReports) != 2 {
+		t.Fatalf("expected 2 reports, got %d", len(result.ArtifactReports))
+	}
+	skipped := result.ArtifactReports[1]
+	if skipped.Subject != testSubject || skipped.Artifact.Digest != report1.Digest {
+		t.Errorf("expected skipped report of %s for %s, got %+v", report1.Digest, testSubject, skipped)
+	}
+	if len(skipped.Results) != 1 || skipped.Results[0].Verifier != nil || !strings.Contains(skipped.Results[0].Description, typeReport) {
+		t.Errorf("unexpected skipped results %+v", skipped.Results)
+	}
+}
+
+func equalDigest(a, b ocispec.Descriptor) bool {
+	return a.Digest == b.Digest
+}
+
+func digests(descs []ocispec.Descriptor) []digest.Digest {
+	var dgsts []digest.Digest
+	for _, desc := range descs {
+		dgsts = append(dgsts, desc.Digest)
+	}
+	return dgsts
+}
@@ -0,0 +1,177 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package referrerscache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/cache"
+	"github.com/notaryproject/ratify/v2/internal/cache/ristretto"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+	"oras.land/oras-go/v2/registry"
+)
+
+const defaultTTL = time.Minute
+
+// Options configures the referrers cache placed in front of a store.
+type Options struct {
+	// TTL is the duration a referrers listing is cached, e.g. "5m". Defaults
+	// to 1 minute. Optional.
+	TTL string `json:"ttl,omitempty"`
+}
+
+// entry is a cached referrers listing of a subject.
+type entry struct {
+	referrers []ocispec.Descriptor
+	cachedAt  time.Time
+}
+
+// Store is a [ratify.Store] that caches the referrers listing of subjects.
+// Listings are keyed by the subject digest, so references to the same subject
+// by tag and by digest share the cached listing.
+type Store struct {
+	ratify.Store
+	cache cache.Cache
+	ttl   time.Duration
+
+	// invalidations records the last time the referrers of a repository
+	// were invalidated, e.g. by a registry webhook. Invalidations older than
+	// the TTL are dropped as the listings they invalidate have expired.
+	mu            sync.Mutex
+	invalidations map[string]time.Time
+}
+
+// New creates a new [Store] caching the referrers listed by the given store.
+func New(store ratify.Store, opts *Options) (*Store, error) {
+	if store == nil {
+		return nil, errors.New("store cannot be nil")
+	}
+	ttl := defaultTTL
+	if opts != nil && opts.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(opts.TTL); err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid referrers cache ttl %q", opts.TTL)
+		}
+	}
+	c, err := ristretto.NewRistrettoCache(ttl)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create referrers cache: %w", err)
+	}
+	return &Store{
+		Store:         store,
+		cache:         c,
+		ttl:           ttl,
+		invalidations: make(map[string]time.Time),
+	}, nil
+}
+
+// ListReferrers returns the cached referrers of the subject if present,
+// otherwise lists the referrers from the underlying store and caches them.
+// Tagged references are resolved to digests before looking up the cache.
+func (s *Store) ListReferrers(ctx context.Context, ref string, artifactTypes []string, fn func(referrers []ocispec.Descriptor) error) error {
+	reference, err := registry.ParseReference(ref)
+	if err != nil {
+		return err
+	}
+	if _, err := reference.Digest(); err != nil {
+		desc, err := s.Store.Resolve(ctx, ref)
+		if err != nil {
+			// let the underlying store decide how to handle unresolvable
+			// subjects.
+			return s.Store.ListReferrers(ctx, ref, artifactTypes, fn)
+		}
+		reference.Reference = desc.Digest.String()
+	}
+	repo := reference.Registry + "/" + reference.Repository
+	key := referrersKey(reference)
+
+	referrers, ok := s.get(ctx, repo, key)
+	if !ok {
+		// list all referrers so that the cached listing can serve requests
+		// with any artifact type filter. The listing time is taken before
+		// listing so that a concurrent invalidation is not missed.
+		listedAt := time.Now()
+		referrers = nil
+		if err := s.Store.ListReferrers(ctx, reference.String(), nil, func(page []ocispec.Descriptor) error {
+			referrers = append(referrers, page...)
+			return nil
+		}); err != nil {
+			return err
+		}
+		if err := s.cache.Set(ctx, key, &entry{
+			referrers: referrers,
+			cachedAt:  listedAt,
+		}); err != nil {
+			logrus.Warnf("failed to cache referrers of %s: %v", reference, err)
+		}
+	}
+
+	if len(artifactTypes) > 0 {
+		referrers = slices.DeleteFunc(slices.Clone(referrers), func(desc ocispec.Descriptor) bool {
+			return !slices.Contains(artifactTypes, desc.ArtifactType)
+		})
+	}
+	if len(referrers) == 0 {
+		return nil
+	}
+	return fn(referrers)
+}
+
+// get returns the cached referrers unless the repository has been invalidated
+// after the listing was cached.
+func (s *Store) get(ctx context.Context, repo, key string) ([]ocispec.Descriptor, bool) {
+	val, err := s.cache.Get(ctx, key)
+	if err != nil {
+		return nil, false
+	}
+	e, ok := val.(*entry)
+	if !ok {
+		return nil, false
+	}
+	s.mu.Lock()
+	invalidatedAt, ok := s.invalidations[repo]
+	s.mu.Unlock()
+	if ok && !e.cachedAt.After(invalidatedAt) {
+		return nil, false
+	}
+	return e.referrers, true
+}
+
+// Invalidate drops the cached referrers listings of all subjects in the given
+// repository, e.g. "registry.example.com/namespace/repo".
+func (s *Store) Invalidate(repository string) {
+	logrus.Infof("invalidating cached referrers of repository %s", repository)
+	now := time.Now()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for repo, invalidatedAt := range s.invalidations {
+		if now.Sub(invalidatedAt) > s.ttl {
+			delete(s.invalidations, repo)
+		}
+	}
+	s.invalidations[repository] = now
+}
+
+func referrersKey(ref registry.Reference) string {
+	return fmt.Sprintf("referrers_%s", ref)
+}
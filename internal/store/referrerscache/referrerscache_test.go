@@ -0,0 +1,224 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package referrerscache
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	testRepo         = "test.io/ns/repo"
+	testArtifactType = "application/vnd.test.signature"
+)
+
+var (
+	testSubjectDigest = digest.FromString("subject")
+	testReferrers     = []ocispec.Descriptor{
+		{
+			Digest:       digest.FromString("referrer1"),
+			ArtifactType: testArtifactType,
+		},
+		{
+			Digest:       digest.FromString("referrer2"),
+			ArtifactType: "application/vnd.test.sbom",
+		},
+	}
+)
+
+// mockStore is a store counting the referrers listings.
+type mockStore struct {
+	listCalls int
+	listErr   error
+}
+
+func (s *mockStore) Resolve(_ context.Context, ref string) (ocispec.Descriptor, error) {
+	if ref == testRepo+":v1" {
+		return ocispec.Descriptor{Digest: testSubjectDigest}, nil
+	}
+	return ocispec.Descriptor{}, errors.New("not found")
+}
+
+func (s *mockStore) ListReferrers(_ context.Context, _ string, _ []string, fn func(referrers []ocispec.Descriptor) error) error {
+	s.listCalls++
+	if s.listErr != nil {
+		return s.listErr
+	}
+	// return referrers in two pages.
+	for _, referrer := range testReferrers {
+		if err := fn([]ocispec.Descriptor{referrer}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *mockStore) FetchBlob(_ context.Context, _ string, _ ocispec.Descriptor) ([]byte, error) {
+	return nil, nil
+}
+
+func (s *mockStore) FetchManifest(_ context.Context, _ string, _ ocispec.Descriptor) ([]byte, error) {
+	return nil, nil
+}
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      *Options
+		expectErr bool
+	}{
+		{name: "default options"},
+		{name: "valid ttl", opts: &Options{TTL: "10m"}},
+		{name: "invalid ttl", opts: &Options{TTL: "ten minutes"}, expectErr: true},
+		{name: "non-positive ttl", opts: &Options{TTL: "0s"}, expectErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := New(&mockStore{}, test.opts)
+			if (err != nil) != test.expectErr {
+				t.Errorf("expected error: %v, got: %v", test.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestStore_ListReferrers(t *testing.T) {
+	underlying := &mockStore{}
+	store, err := New(underlying, nil)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	refs := []string{
+		testRepo + "@" + testSubjectDigest.String(),
+		testRepo + ":v1",
+		testRepo + "@" + testSubjectDigest.String(),
+	}
+	for _, ref := range refs {
+		var got []ocispec.Descriptor
+		if err := store.ListReferrers(context.Background(), ref, nil, func(referrers []ocispec.Descriptor) error {
+			got = append(got, referrers...)
+			return nil
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(got) != len(testReferrers) {
+			t.Fatalf("expected %d referrers, got %d", len(testReferrers), len(got))
+		}
+	}
+	if underlying.listCalls != 1 {
+		t.Errorf("expected tag and digest references to share the cache, got %d listings", underlying.listCalls)
+	}
+
+	var filtered []ocispec.Descriptor
+	if err := store.ListReferrers(context.Background(), refs[0], []string{testArtifactType}, func(referrers []ocispec.Descriptor) error {
+		filtered = append(filtered, referrers...)
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(filtered) != 1 || filtered[0].ArtifactType != testArtifactType {
+		t.Errorf("expected referrers to be filtered by artifact type, got %v", filtered)
+	}
+}
+
+func TestStore_ListReferrersError(t *testing.T) {
+	underlying := &mockStore{listErr: errors.New("list failed")}
+	store, err := New(underlying, nil)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	ref := testRepo + "@" + testSubjectDigest.String()
+	for range 2 {
+		if err := store.ListReferrers(context.Background(), ref, nil, func(_ []ocispec.Descriptor) error {
+			return nil
+		}); err == nil {
+			t.Fatal("expected error, got nil")
+		}
+	}
+	if underlying.listCalls != 2 {
+		t.Errorf("expected failed listings not to be cached, got %d listings", underlying.listCalls)
+	}
+
+	if err := store.ListReferrers(context.Background(), "invalid reference", nil, nil); err == nil {
+		t.Error("expected error for invalid reference, got nil")
+	}
+}
+
+func TestStore_CallbackError(t *testing.T) {
+	store, err := New(&mockStore{}, nil)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	errStop := errors.New("stop")
+	err = store.ListReferrers(context.Background(), testRepo+"@"+testSubjectDigest.String(), nil, func(_ []ocispec.Descriptor) error {
+		return errStop
+	})
+	if err != errStop {
+		t.Errorf("expected callback error to be returned as is, got: %v", err)
+	}
+}
+
+func TestInvalidate(t *testing.T) {
+	underlying := &mockStore{}
+	store, err := New(underlying, nil)
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	ref := testRepo + "@" + testSubjectDigest.String()
+	list := func() {
+		if err := store.ListReferrers(context.Background(), ref, nil, func(_ []ocispec.Descriptor) error {
+			return nil
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	list()
+	store.Invalidate("test.io/ns/other")
+	list()
+	if underlying.listCalls != 1 {
+		t.Errorf("expected invalidation of another repository not to affect the cache, got %d listings", underlying.listCalls)
+	}
+	store.Invalidate(testRepo)
+	list()
+	list()
+	if underlying.listCalls != 2 {
+		t.Errorf("expected 2 listings after invalidation, got %d", underlying.listCalls)
+	}
+}
+
+func TestInvalidate_Expiry(t *testing.T) {
+	store, err := New(&mockStore{}, &Options{TTL: "1ms"})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	store.Invalidate("test.io/ns/other")
+	time.Sleep(5 * time.Millisecond)
+	store.Invalidate(testRepo)
+	if len(store.invalidations) != 1 {
+		t.Errorf("expected invalidations older than the ttl to be dropped, got %v", store.invalidations)
+	}
+	if _, ok := store.invalidations[testRepo]; !ok {
+		t.Errorf("expected invalidation of %s, got %v", testRepo, store.invalidations)
+	}
+}
@@ -0,0 +1,106 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package registryhistory records the registry requests retried or throttled
+// while validating an artifact so that they are reported in the validation
+// result. Operators can then tell registry flakiness apart from verification
+// failures.
+package registryhistory
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	"github.com/notaryproject/ratify-go"
+)
+
+// ErrRetriesExhausted is matched by the errors of registry requests that are
+// still failing after all retries.
+var ErrRetriesExhausted = errors.New("registry request retries exhausted")
+
+// validation records the registry requests retried or throttled in a single
+// validation.
+type validation struct {
+	mu     sync.Mutex
+	events []string
+}
+
+type validationKey struct{}
+
+// WithValidation returns a context for validating a single artifact. The
+// registry requests recorded in the context are reported by [AddReport].
+func WithValidation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, validationKey{}, &validation{})
+}
+
+// Record records a retried or throttled registry request in the context
+// created by [WithValidation], e.g. "GET https://registry.example/v2/: 429 Too
+// Many Requests (retried after 1s)". It is a no-op outside of such a context.
+func Record(ctx context.Context, event string) {
+	v, ok := ctx.Value(validationKey{}).(*validation)
+	if !ok {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	v.events = append(v.events, event)
+}
+
+// AddReport adds a report listing the registry requests retried or throttled
+// in the context created by [WithValidation] to the result. The report has a
+// single result without verifier. It is added after policy evaluation and does
+// not affect the outcome of the validation.
+func AddReport(ctx context.Context, subject string, result *ratify.ValidationResult) {
+	v, ok := ctx.Value(validationKey{}).(*validation)
+	if !ok || result == nil {
+		return
+	}
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if len(v.events) == 0 {
+		return
+	}
+	result.ArtifactReports = append(result.ArtifactReports, &ratify.ValidationReport{
+		Subject: subject,
+		Results: []*ratify.VerificationResult{
+			{
+				Description: "Registry requests retried or throttled",
+				Detail: map[string][]string{
+					"registryRequests": v.events,
+				},
+			},
+		},
+	})
+}
+
+// FailedResult returns a failed validation result reporting the registry
+// request error of the subject, so that a registry that keeps failing is
+// reported like a verification failure instead of aborting the validation.
+func FailedResult(subject string, err error) *ratify.ValidationResult {
+	return &ratify.ValidationResult{
+		ArtifactReports: []*ratify.ValidationReport{
+			{
+				Subject: subject,
+				Results: []*ratify.VerificationResult{
+					{
+						Err:         err,
+						Description: "Registry request failed after retries",
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package registryhistory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/notaryproject/ratify-go"
+)
+
+const testSubject = "test.io/ns/repo:v1"
+
+func TestAddReport(t *testing.T) {
+	// without validation context, requests are not recorded.
+	Record(context.Background(), "GET https://test.io/v2/: throttled by client rate limit for 1s")
+	result := &ratify.ValidationResult{}
+	AddReport(context.Background(), testSubject, result)
+	if len(result.ArtifactReports) != 0 {
+		t.Fatalf("expected no reports, got %d", len(result.ArtifactReports))
+	}
+
+	ctx := WithValidation(context.Background())
+	AddReport(ctx, testSubject, result)
+	AddReport(ctx, testSubject, nil)
+	if len(result.ArtifactReports) != 0 {
+		t.Fatalf("expected no reports without recorded requests, got %d", len(result.ArtifactReports))
+	}
+
+	events := []string{
+		"GET https://test.io/v2/: throttled by client rate limit for 1s",
+		"GET https://test.io/v2/: 429 Too Many Requests (retried after 2s)",
+	}
+	for _, event := range events {
+		Record(ctx, event)
+	}
+	result = &ratify.ValidationResult{Succeeded: true}
+	AddReport(ctx, testSubject, result)
+	if !result.Succeeded {
+		t.Error("expected the report not to affect the outcome")
+	}
+	if len(result.ArtifactReports) != 1 {
+		t.Fatalf("expected 1 report, got %d", len(result.ArtifactReports))
+	}
+	report := result.ArtifactReports[0]
+	if report.Subject != testSubject || len(report.Results) != 1 || report.Results[0].Verifier != nil {
+		t.Fatalf("unexpected report %+v", report)
+	}
+	detail, ok := report.Results[0].Detail.(map[string][]string)
+	if !ok || fmt.Sprint(detail["registryRequests"]) != fmt.Sprint(events) {
+		t.Errorf("expected recorded requests %v, got %v", events, report.Results[0].Detail)
+	}
+}
+
+func TestFailedResult(t *testing.T) {
+	err := fmt.Errorf("failed to list referrers: %w", ErrRetriesExhausted)
+	result := FailedResult(testSubject, err)
+	if result.Succeeded {
+		t.Error("expected failed result")
+	}
+	if len(result.ArtifactReports) != 1 || len(result.ArtifactReports[0].Results) != 1 {
+		t.Fatalf("expected a single report with a single result, got %+v", result.ArtifactReports)
+	}
+	if got := result.ArtifactReports[0].Results[0].Err; !errors.Is(got, ErrRetriesExhausted) {
+		t.Errorf("expected the registry error to be reported, got %v", got)
+	}
+}
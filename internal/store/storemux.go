@@ -16,20 +16,57 @@ limitations under the License.
 package store
 
 import (
+	"errors"
 	"fmt"
+	"io"
 
 	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/store/blobcache"
 	"github.com/notaryproject/ratify/v2/internal/store/factory"
 	_ "github.com/notaryproject/ratify/v2/internal/store/factory/filesystemocistore" // Register the filesystem store factory
 	_ "github.com/notaryproject/ratify/v2/internal/store/factory/registrystore"      // Register the registry store factory
+	"github.com/notaryproject/ratify/v2/internal/store/referrerscache"
 )
 
-// NewStore creates a new StoreMux instance.
-func NewStore(opts []*factory.NewStoreOptions, globalScopes []string) (ratify.Store, error) {
+// Mux is a [ratify.StoreMux] keeping track of the referrers caches and the
+// closable stores of the registered stores.
+type Mux struct {
+	*ratify.StoreMux
+	referrersCaches []*referrerscache.Store
+	closers         []io.Closer
+}
+
+// Close closes the registered stores holding resources, e.g. decompressed
+// OCI layout tarballs.
+func (m *Mux) Close() error {
+	var errs []error
+	for _, closer := range m.closers {
+		errs = append(errs, closer.Close())
+	}
+	return errors.Join(errs...)
+}
+
+// InvalidateReferrers drops the cached referrers listings of all subjects in
+// the given repository from the referrers caches of the registered stores.
+func (m *Mux) InvalidateReferrers(repository string) {
+	for _, referrersCache := range m.referrersCaches {
+		referrersCache.Invalidate(repository)
+	}
+}
+
+// NewStore creates a new Mux instance.
+func NewStore(opts []*factory.NewStoreOptions, globalScopes []string) (_ *Mux, err error) {
 	if len(opts) == 0 {
 		return nil, fmt.Errorf("no store options provided")
 	}
-	storeMux := ratify.NewStoreMux()
+	storeMux := &Mux{
+		StoreMux: ratify.NewStoreMux(),
+	}
+	defer func() {
+		if err != nil {
+			storeMux.Close()
+		}
+	}()
 	for _, storeOptions := range opts {
 		if len(storeOptions.Scopes) == 0 {
 			// if no scopes are provided, use the global scopes of the executor.
@@ -39,6 +76,22 @@ func NewStore(opts []*factory.NewStoreOptions, globalScopes []string) (ratify.St
 		if err != nil {
 			return nil, fmt.Errorf("failed to create store for type %q: %w", storeOptions.Type, err)
 		}
+		if closer, ok := store.(io.Closer); ok {
+			storeMux.closers = append(storeMux.closers, closer)
+		}
+		if storeOptions.Cache != nil {
+			if store, err = blobcache.New(store, storeOptions.Cache); err != nil {
+				return nil, fmt.Errorf("failed to create cache for store type %q: %w", storeOptions.Type, err)
+			}
+		}
+		if storeOptions.ReferrersCache != nil {
+			referrersCache, err := referrerscache.New(store, storeOptions.ReferrersCache)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create referrers cache for store type %q: %w", storeOptions.Type, err)
+			}
+			storeMux.referrersCaches = append(storeMux.referrersCaches, referrersCache)
+			store = referrersCache
+		}
 		for _, scope := range storeOptions.Scopes {
 			if err = storeMux.Register(scope, store); err != nil {
 				return nil, fmt.Errorf("failed to register store for scope %q: %w", scope, err)
@@ -20,7 +20,10 @@ import (
 	"testing"
 
 	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/store/blobcache"
 	"github.com/notaryproject/ratify/v2/internal/store/factory"
+	"github.com/notaryproject/ratify/v2/internal/store/referrerscache"
+	"github.com/opencontainers/go-digest"
 	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
@@ -80,6 +83,60 @@ func TestNewStore(t *testing.T) {
 			globalScopes:  []string{"example.com"},
 			expectedError: false,
 		},
+		{
+			name: "valid store options with cache",
+			opts: []*factory.NewStoreOptions{
+				{
+					Type:       "mock-store",
+					Parameters: map[string]any{},
+					Cache: &blobcache.Options{
+						InMemory: true,
+					},
+				},
+			},
+			globalScopes:  []string{"example.com"},
+			expectedError: false,
+		},
+		{
+			name: "valid store options with referrers cache",
+			opts: []*factory.NewStoreOptions{
+				{
+					Type:       "mock-store",
+					Parameters: map[string]any{},
+					ReferrersCache: &referrerscache.Options{
+						TTL: "10m",
+					},
+				},
+			},
+			globalScopes:  []string{"example.com"},
+			expectedError: false,
+		},
+		{
+			name: "invalid referrers cache options",
+			opts: []*factory.NewStoreOptions{
+				{
+					Type:       "mock-store",
+					Parameters: map[string]any{},
+					ReferrersCache: &referrerscache.Options{
+						TTL: "-1s",
+					},
+				},
+			},
+			globalScopes:  []string{"example.com"},
+			expectedError: true,
+		},
+		{
+			name: "invalid cache options",
+			opts: []*factory.NewStoreOptions{
+				{
+					Type:       "mock-store",
+					Parameters: map[string]any{},
+					Cache:      &blobcache.Options{},
+				},
+			},
+			globalScopes:  []string{"example.com"},
+			expectedError: true,
+		},
 		{
 			name: "invalid store scope",
 			opts: []*factory.NewStoreOptions{
@@ -102,3 +159,79 @@ func TestNewStore(t *testing.T) {
 		})
 	}
 }
+
+// countingStore counts the referrers listings.
+type countingStore struct {
+	mockStore
+	listCalls int
+}
+
+func (s *countingStore) ListReferrers(_ context.Context, _ string, _ []string, _ func(referrers []ocispec.Descriptor) error) error {
+	s.listCalls++
+	return nil
+}
+
+func TestMux_InvalidateReferrers(t *testing.T) {
+	underlying := &countingStore{}
+	factory.RegisterStoreFactory("mock-counting-store", func(_ *factory.NewStoreOptions) (ratify.Store, error) {
+		return underlying, nil
+	})
+	storeMux, err := NewStore([]*factory.NewStoreOptions{
+		{
+			Type:           "mock-counting-store",
+			ReferrersCache: &referrerscache.Options{},
+		},
+	}, []string{"example.com"})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+
+	const repo = "example.com/test"
+	list := func() {
+		if err := storeMux.ListReferrers(context.Background(), repo+"@"+digest.FromString("subject").String(), nil, func(_ []ocispec.Descriptor) error {
+			return nil
+		}); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	list()
+	list()
+	storeMux.InvalidateReferrers(repo)
+	list()
+	if underlying.listCalls != 2 {
+		t.Errorf("expected 2 listings, got %d", underlying.listCalls)
+	}
+}
+
+// closableStore records whether it was closed.
+type closableStore struct {
+	mockStore
+	closed bool
+}
+
+func (s *closableStore) Close() error {
+	s.closed = true
+	return nil
+}
+
+func TestMux_Close(t *testing.T) {
+	underlying := &closableStore{}
+	factory.RegisterStoreFactory("mock-closable-store", func(_ *factory.NewStoreOptions) (ratify.Store, error) {
+		return underlying, nil
+	})
+	storeMux, err := NewStore([]*factory.NewStoreOptions{
+		{
+			Type:           "mock-closable-store",
+			ReferrersCache: &referrerscache.Options{},
+		},
+	}, []string{"example.com"})
+	if err != nil {
+		t.Fatalf("failed to create store: %v", err)
+	}
+	if err := storeMux.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !underlying.closed {
+		t.Error("expected the wrapped store to be closed")
+	}
+}
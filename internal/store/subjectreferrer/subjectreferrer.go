@@ -0,0 +1,129 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package subjectreferrer lists the subject of a validation as a referrer of
+// itself so that verifiers can evaluate the subject image like any other
+// artifact.
+package subjectreferrer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry"
+)
+
+// ArtifactType is the artifact type of the referrer standing for the subject.
+// Verifiers evaluating the subject image are verifiable for this artifact
+// type and read the subject from [ratify.VerifyOptions.SubjectDescriptor].
+const ArtifactType = "application/vnd.ratify.subject.v1"
+
+// validation tracks the subject referrer of a single validation.
+type validation struct {
+	// subject is the digested reference of the validated subject.
+	subject string
+
+	// referrer is the digest of the subject referrer.
+	referrer digest.Digest
+
+	mu     sync.Mutex
+	listed bool
+}
+
+type validationKey struct{}
+
+// WithValidation returns a context for validating the subject with the given
+// digested reference, e.g. "registry.example/repo@sha256:...". The first
+// listing of the referrers of the subject in the context lists the subject
+// referrer.
+func WithValidation(ctx context.Context, subject string) context.Context {
+	return context.WithValue(ctx, validationKey{}, &validation{
+		subject:  subject,
+		referrer: digest.FromString(ArtifactType + "@" + subject),
+	})
+}
+
+// Store is a [ratify.Store] listing the subject referrer before the referrers
+// of the subject of a validation. The subject referrer has no referrers.
+type Store struct {
+	ratify.Store
+}
+
+// New creates a new [Store] listing the subject referrer in addition to the
+// referrers listed by the given store.
+func New(store ratify.Store) (*Store, error) {
+	if store == nil {
+		return nil, errors.New("store cannot be nil")
+	}
+	return &Store{Store: store}, nil
+}
+
+// ListReferrers lists the referrers of the artifact. In a context created by
+// [WithValidation], the first listing of the referrers of the validated
+// subject starts with the subject referrer. Other listings are passed
+// through.
+func (s *Store) ListReferrers(ctx context.Context, ref string, artifactTypes []string, fn func(referrers []ocispec.Descriptor) error) error {
+	v, ok := ctx.Value(validationKey{}).(*validation)
+	if !ok {
+		return s.Store.ListReferrers(ctx, ref, artifactTypes, fn)
+	}
+	reference, err := registry.ParseReference(ref)
+	if err != nil {
+		return err
+	}
+	if reference.Reference == v.referrer.String() {
+		return nil
+	}
+
+	v.mu.Lock()
+	first := !v.listed && ref == v.subject
+	if first {
+		v.listed = true
+	}
+	v.mu.Unlock()
+
+	if first && (len(artifactTypes) == 0 || slices.Contains(artifactTypes, ArtifactType)) {
+		if err := fn([]ocispec.Descriptor{Descriptor(v.referrer)}); err != nil {
+			return err
+		}
+	}
+	return s.Store.ListReferrers(ctx, ref, artifactTypes, fn)
+}
+
+// MatchArtifactType reports whether the artifact type is one of the configured
+// artifact types. "*" matches any artifact type except [ArtifactType], which
+// only matches if configured explicitly: the subject referrer is not an
+// artifact in the registry and verifiers must opt in to evaluate it.
+func MatchArtifactType(artifactTypes []string, artifactType string) bool {
+	if slices.Contains(artifactTypes, artifactType) {
+		return true
+	}
+	return artifactType != ArtifactType && slices.Contains(artifactTypes, "*")
+}
+
+// Descriptor returns the descriptor of the subject referrer with the given
+// digest.
+func Descriptor(dgst digest.Digest) ocispec.Descriptor {
+	return ocispec.Descriptor{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: ArtifactType,
+		Digest:       dgst,
+	}
+}
@@ -0,0 +1,216 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package subjectreferrer
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	testRepo         = "test.io/ns/repo"
+	testArtifactType = "application/vnd.test.signature"
+)
+
+var testSubject = testRepo + "@" + digest.FromString("subject").String()
+
+var testReferrer = ocispec.Descriptor{
+	Digest:       digest.FromString("referrer"),
+	ArtifactType: testArtifactType,
+}
+
+// mockStore lists a single referrer for every artifact.
+type mockStore struct {
+	listed []string
+}
+
+func (s *mockStore) Resolve(_ context.Context, _ string) (ocispec.Descriptor, error) {
+	return ocispec.Descriptor{}, errors.New("not implemented")
+}
+
+func (s *mockStore) ListReferrers(_ context.Context, ref string, _ []string, fn func(referrers []ocispec.Descriptor) error) error {
+	s.listed = append(s.listed, ref)
+	return fn([]ocispec.Descriptor{testReferrer})
+}
+
+func (s *mockStore) FetchBlob(_ context.Context, _ string, _ ocispec.Descriptor) ([]byte, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (s *mockStore) FetchManifest(_ context.Context, _ string, _ ocispec.Descriptor) ([]byte, error) {
+	return nil, errors.New("not implemented")
+}
+
+func listReferrers(t *testing.T, ctx context.Context, s *Store, ref string, artifactTypes []string) []ocispec.Descriptor {
+	t.Helper()
+	var referrers []ocispec.Descriptor
+	if err := s.ListReferrers(ctx, ref, artifactTypes, func(page []ocispec.Descriptor) error {
+		referrers = append(referrers, page...)
+		return nil
+	}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return referrers
+}
+
+func TestNew(t *testing.T) {
+	if _, err := New(nil); err == nil {
+		t.Error("expected error for nil store, got nil")
+	}
+	if _, err := New(&mockStore{}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestStore_ListReferrers(t *testing.T) {
+	underlying := &mockStore{}
+	s, _ := New(underlying)
+	ctx := WithValidation(context.Background(), testSubject)
+
+	// listings of other artifacts before the subject are passed through
+	if referrers := listReferrers(t, ctx, s, testRepo+"@"+testReferrer.Digest.String(), nil); len(referrers) != 1 {
+		t.Errorf("expected 1 referrer, got %v", referrers)
+	}
+
+	referrers := listReferrers(t, ctx, s, testSubject, nil)
+	if len(referrers) != 2 || referrers[0].ArtifactType != ArtifactType || referrers[1].Digest != testReferrer.Digest {
+		t.Fatalf("expected subject referrer and underlying referrer, got %v", referrers)
+	}
+	subjectReferrer := referrers[0].Digest
+
+	// later listings of the subject, e.g. by verifiers, are passed through
+	if referrers := listReferrers(t, ctx, s, testSubject, nil); len(referrers) != 1 {
+		t.Errorf("expected 1 referrer, got %v", referrers)
+	}
+	// referrers of the subject referrer are not listed
+	if referrers := listReferrers(t, ctx, s, testRepo+"@"+subjectReferrer.String(), nil); len(referrers) != 0 {
+		t.Errorf("expected no referrers of the subject referrer, got %v", referrers)
+	}
+	if len(underlying.listed) != 3 {
+		t.Errorf("expected 3 underlying listings, got %v", underlying.listed)
+	}
+
+	// a new validation lists the subject referrer again
+	if referrers := listReferrers(t, WithValidation(context.Background(), testSubject), s, testSubject, nil); len(referrers) != 2 {
+		t.Errorf("expected 2 referrers, got %v", referrers)
+	}
+}
+
+func TestMatchArtifactType(t *testing.T) {
+	tests := []struct {
+		name          string
+		artifactTypes []string
+		artifactType  string
+		expect        bool
+	}{
+		{
+			name:          "listed artifact type",
+			artifactTypes: []string{testArtifactType},
+			artifactType:  testArtifactType,
+			expect:        true,
+		},
+		{
+			name:          "unlisted artifact type",
+			artifactTypes: []string{testArtifactType},
+			artifactType:  "application/vnd.test.sbom",
+		},
+		{
+			name:          "wildcard",
+			artifactTypes: []string{"*"},
+			artifactType:  testArtifactType,
+			expect:        true,
+		},
+		{
+			name:          "wildcard does not match the subject referrer",
+			artifactTypes: []string{"*"},
+			artifactType:  ArtifactType,
+		},
+		{
+			name:          "listed subject referrer",
+			artifactTypes: []string{"*", ArtifactType},
+			artifactType:  ArtifactType,
+			expect:        true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := MatchArtifactType(test.artifactTypes, test.artifactType); got != test.expect {
+				t.Errorf("expected %v, got %v", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestStore_ListReferrers_Filtered(t *testing.T) {
+	s, _ := New(&mockStore{})
+
+	tests := []struct {
+		name          string
+		ctx           context.Context
+		ref           string
+		artifactTypes []string
+		expect        int
+	}{
+		{
+			name:   "outside of validation",
+			ctx:    context.Background(),
+			ref:    testSubject,
+			expect: 1,
+		},
+		{
+			name:          "subject artifact type requested",
+			ctx:           WithValidation(context.Background(), testSubject),
+			ref:           testSubject,
+			artifactTypes: []string{ArtifactType},
+			expect:        2,
+		},
+		{
+			name:          "subject artifact type not requested",
+			ctx:           WithValidation(context.Background(), testSubject),
+			ref:           testSubject,
+			artifactTypes: []string{testArtifactType},
+			expect:        1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if referrers := listReferrers(t, test.ctx, s, test.ref, test.artifactTypes); len(referrers) != test.expect {
+				t.Errorf("expected %d referrers, got %v", test.expect, referrers)
+			}
+		})
+	}
+}
+
+func TestStore_ListReferrers_Errors(t *testing.T) {
+	s, _ := New(&mockStore{})
+	if err := s.ListReferrers(WithValidation(context.Background(), testSubject), "INVALID", nil, nil); err == nil {
+		t.Error("expected error for invalid reference, got nil")
+	}
+
+	errStop := errors.New("stop")
+	err := s.ListReferrers(WithValidation(context.Background(), testSubject), testSubject, nil, func(_ []ocispec.Descriptor) error {
+		return errStop
+	})
+	if !errors.Is(err, errStop) {
+		t.Errorf("expected error %v, got %v", errStop, err)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package artifact reads the content of artifacts verified by the built-in
+// verifiers.
+package artifact
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/notaryproject/ratify-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"oras.land/oras-go/v2/registry"
+)
+
+// Blob is a layer of an artifact with its content.
+type Blob struct {
+	// Descriptor is the descriptor of the layer.
+	Descriptor ocispec.Descriptor
+
+	// Content is the content of the layer.
+	Content []byte
+}
+
+// FetchManifest fetches the image manifest of the artifact.
+func FetchManifest(ctx context.Context, store ratify.Store, repo string, artifactDesc ocispec.Descriptor) (*ocispec.Manifest, error) {
+	manifestBytes, err := store.FetchManifest(ctx, repo, artifactDesc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest for artifact: %w", err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+	return &manifest, nil
+}
+
+// FetchBlobs fetches the layers of the artifact. It fails if the artifact has
+// no layers.
+func FetchBlobs(ctx context.Context, store ratify.Store, repo string, artifactDesc ocispec.Descriptor) ([]Blob, error) {
+	manifest, err := FetchManifest(ctx, store, repo, artifactDesc)
+	if err != nil {
+		return nil, err
+	}
+	if len(manifest.Layers) == 0 {
+		return nil, fmt.Errorf("artifact %s has no layers", artifactDesc.Digest)
+	}
+	blobs := make([]Blob, 0, len(manifest.Layers))
+	for _, layer := range manifest.Layers {
+		content, err := store.FetchBlob(ctx, repo, layer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch blob %s: %w", layer.Digest, err)
+		}
+		blobs = append(blobs, Blob{
+			Descriptor: layer,
+			Content:    content,
+		})
+	}
+	return blobs, nil
+}
+
+// ParsePinnedReference parses a reference to an artifact pinned by digest,
+// e.g. "registry.example/repo@sha256:...".
+func ParsePinnedReference(reference string) (registry.Reference, error) {
+	ref, err := registry.ParseReference(reference)
+	if err != nil {
+		return registry.Reference{}, fmt.Errorf("failed to parse reference: %w", err)
+	}
+	if _, err := ref.Digest(); err != nil {
+		return registry.Reference{}, fmt.Errorf("reference %s must be pinned by digest", reference)
+	}
+	return ref, nil
+}
+
+// FetchPinnedBlob fetches the single layer of the artifact referenced by a
+// reference parsed by [ParsePinnedReference].
+func FetchPinnedBlob(ctx context.Context, store ratify.Store, ref registry.Reference) ([]byte, error) {
+	desc, err := store.Resolve(ctx, ref.String())
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve %s: %w", ref, err)
+	}
+	if desc.Digest.String() != ref.Reference {
+		return nil, fmt.Errorf("%s resolved to unexpected digest %s", ref, desc.Digest)
+	}
+	blobs, err := FetchBlobs(ctx, store, ref.Registry+"/"+ref.Repository, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", ref, err)
+	}
+	if len(blobs) != 1 {
+		return nil, fmt.Errorf("artifact %s must have exactly one layer, got %d", ref, len(blobs))
+	}
+	return blobs[0].Content, nil
+}
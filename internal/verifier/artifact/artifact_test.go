@@ -0,0 +1,227 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package artifact
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const testRepo = "test-registry/test-repo"
+
+type mockStore struct {
+	resolved    ocispec.Descriptor
+	resolveErr  error
+	manifest    []byte
+	manifestErr error
+	blobs       map[digest.Digest][]byte
+}
+
+func (s *mockStore) Resolve(_ context.Context, _ string) (ocispec.Descriptor, error) {
+	return s.resolved, s.resolveErr
+}
+
+func (s *mockStore) ListReferrers(_ context.Context, _ string, _ []string, _ func(referrers []ocispec.Descriptor) error) error {
+	return nil
+}
+
+func (s *mockStore) FetchBlob(_ context.Context, _ string, desc ocispec.Descriptor) ([]byte, error) {
+	blob, ok := s.blobs[desc.Digest]
+	if !ok {
+		return nil, errors.New("blob not found")
+	}
+	return blob, nil
+}
+
+func (s *mockStore) FetchManifest(_ context.Context, _ string, _ ocispec.Descriptor) ([]byte, error) {
+	return s.manifest, s.manifestErr
+}
+
+func newMockStore(t *testing.T, layers ...[]byte) *mockStore {
+	t.Helper()
+	store := &mockStore{
+		blobs: make(map[digest.Digest][]byte),
+	}
+	manifest := ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    ocispec.DescriptorEmptyJSON,
+		Layers:    []ocispec.Descriptor{},
+	}
+	for _, layer := range layers {
+		desc := ocispec.Descriptor{
+			MediaType: "application/json",
+			Digest:    digest.FromBytes(layer),
+			Size:      int64(len(layer)),
+		}
+		store.blobs[desc.Digest] = layer
+		manifest.Layers = append(manifest.Layers, desc)
+	}
+	var err error
+	if store.manifest, err = json.Marshal(manifest); err != nil {
+		t.Fatalf("failed to marshal manifest: %v", err)
+	}
+	return store
+}
+
+func TestFetchBlobs(t *testing.T) {
+	missingBlob := newMockStore(t, []byte("a"))
+	missingBlob.blobs = map[digest.Digest][]byte{}
+
+	tests := []struct {
+		name        string
+		store       *mockStore
+		expectErr   bool
+		expectBlobs []string
+	}{
+		{
+			name:        "multiple layers",
+			store:       newMockStore(t, []byte("a"), []byte("b")),
+			expectBlobs: []string{"a", "b"},
+		},
+		{
+			name:      "no layers",
+			store:     newMockStore(t),
+			expectErr: true,
+		},
+		{
+			name:      "manifest not found",
+			store:     &mockStore{manifestErr: errors.New("not found")},
+			expectErr: true,
+		},
+		{
+			name:      "invalid manifest",
+			store:     &mockStore{manifest: []byte("{")},
+			expectErr: true,
+		},
+		{
+			name:      "blob not found",
+			store:     missingBlob,
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			blobs, err := FetchBlobs(context.Background(), test.store, testRepo, ocispec.Descriptor{})
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if len(blobs) != len(test.expectBlobs) {
+				t.Fatalf("expected %d blobs, got %d", len(test.expectBlobs), len(blobs))
+			}
+			for i, blob := range blobs {
+				if string(blob.Content) != test.expectBlobs[i] {
+					t.Errorf("expected blob %q, got %q", test.expectBlobs[i], blob.Content)
+				}
+				if blob.Descriptor.Digest != digest.FromString(test.expectBlobs[i]) {
+					t.Errorf("unexpected descriptor %v", blob.Descriptor)
+				}
+			}
+		})
+	}
+}
+
+func TestParsePinnedReference(t *testing.T) {
+	tests := []struct {
+		name      string
+		reference string
+		expectErr bool
+	}{
+		{
+			name:      "digest reference",
+			reference: testRepo + "@" + digest.FromString("a").String(),
+		},
+		{
+			name:      "tag reference",
+			reference: testRepo + ":v1",
+			expectErr: true,
+		},
+		{
+			name:      "malformed reference",
+			reference: "INVALID@@",
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParsePinnedReference(test.reference)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestFetchPinnedBlob(t *testing.T) {
+	pinned := digest.FromString("artifact")
+	ref, err := ParsePinnedReference(testRepo + "@" + pinned.String())
+	if err != nil {
+		t.Fatalf("failed to parse reference: %v", err)
+	}
+	withResolved := func(store *mockStore, dgst digest.Digest) *mockStore {
+		store.resolved = ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: dgst}
+		return store
+	}
+
+	tests := []struct {
+		name      string
+		store     *mockStore
+		expectErr bool
+	}{
+		{
+			name:  "single layer",
+			store: withResolved(newMockStore(t, []byte("a")), pinned),
+		},
+		{
+			name:      "resolve failed",
+			store:     &mockStore{resolveErr: errors.New("not found")},
+			expectErr: true,
+		},
+		{
+			name:      "unexpected digest",
+			store:     withResolved(newMockStore(t, []byte("a")), digest.FromString("other")),
+			expectErr: true,
+		},
+		{
+			name:      "no layers",
+			store:     withResolved(newMockStore(t), pinned),
+			expectErr: true,
+		},
+		{
+			name:      "multiple layers",
+			store:     withResolved(newMockStore(t, []byte("a"), []byte("b")), pinned),
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			content, err := FetchPinnedBlob(context.Background(), test.store, ref)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err == nil && string(content) != "a" {
+				t.Errorf("expected content %q, got %q", "a", content)
+			}
+		})
+	}
+}
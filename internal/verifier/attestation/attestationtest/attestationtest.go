@@ -0,0 +1,191 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package attestationtest provides DSSE and in-toto fixtures for testing
+// attestation verifiers.
+package attestationtest
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/attestation"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+)
+
+// Repository is the repository of the attestations and their subjects.
+const Repository = "test.registry.io/test/image"
+
+// Key is an ECDSA signing key with a self-signed certificate.
+type Key struct {
+	Key         *ecdsa.PrivateKey
+	Certificate *x509.Certificate
+	// PEM is the PEM encoded certificate.
+	PEM string
+}
+
+// NewKey generates a signing key with a certificate of the common name.
+func NewKey(t *testing.T, commonName string) *Key {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return &Key{
+		Key:         key,
+		Certificate: cert,
+		PEM:         string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+	}
+}
+
+// SignEnvelope returns a DSSE envelope of the payload signed by the keys.
+func SignEnvelope(t *testing.T, payloadType string, payload []byte, keys ...*Key) []byte {
+	t.Helper()
+	envelope := dsse.Envelope{
+		PayloadType: payloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+	}
+	for _, key := range keys {
+		digest := sha256.Sum256(dsse.PAE(payloadType, payload))
+		sig, err := ecdsa.SignASN1(rand.Reader, key.Key, digest[:])
+		if err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		envelope.Signatures = append(envelope.Signatures, dsse.Signature{Sig: base64.StdEncoding.EncodeToString(sig)})
+	}
+	content, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatalf("failed to marshal envelope: %v", err)
+	}
+	return content
+}
+
+// NewStatement returns an in-toto statement about the subject digest in
+// [Repository].
+func NewStatement(t *testing.T, predicateType string, subject digest.Digest, predicate any) []byte {
+	t.Helper()
+	content, err := json.Marshal(map[string]any{
+		"_type":         "https://in-toto.io/Statement/v1",
+		"predicateType": predicateType,
+		"subject": []map[string]any{
+			{
+				"name":   Repository,
+				"digest": map[string]string{subject.Algorithm().String(): subject.Encoded()},
+			},
+		},
+		"predicate": predicate,
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal statement: %v", err)
+	}
+	return content
+}
+
+// store serves a single attestation manifest and its envelope blobs.
+type store struct {
+	manifest []byte
+	blobs    map[digest.Digest][]byte
+}
+
+func (s *store) Resolve(_ context.Context, _ string) (ocispec.Descriptor, error) {
+	return ocispec.Descriptor{}, errors.New("not implemented")
+}
+
+func (s *store) ListReferrers(_ context.Context, _ string, _ []string, _ func(referrers []ocispec.Descriptor) error) error {
+	return errors.New("not implemented")
+}
+
+func (s *store) FetchBlob(_ context.Context, _ string, desc ocispec.Descriptor) ([]byte, error) {
+	blob, ok := s.blobs[desc.Digest]
+	if !ok {
+		return nil, errors.New("blob not found")
+	}
+	return blob, nil
+}
+
+func (s *store) FetchManifest(_ context.Context, _ string, _ ocispec.Descriptor) ([]byte, error) {
+	return s.manifest, nil
+}
+
+// NewVerifyOptions returns the verify options of an in-toto attestation of
+// the subject with the envelopes as layers.
+func NewVerifyOptions(t *testing.T, subject digest.Digest, envelopes ...[]byte) *ratify.VerifyOptions {
+	t.Helper()
+	s := &store{blobs: map[digest.Digest][]byte{}}
+	manifest := ocispec.Manifest{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: attestation.ArtifactTypeInToto,
+		Config:       ocispec.DescriptorEmptyJSON,
+		Layers:       []ocispec.Descriptor{},
+	}
+	for _, envelope := range envelopes {
+		desc := ocispec.Descriptor{
+			MediaType: attestation.ArtifactTypeDSSE,
+			Digest:    digest.FromBytes(envelope),
+			Size:      int64(len(envelope)),
+		}
+		manifest.Layers = append(manifest.Layers, desc)
+		s.blobs[desc.Digest] = envelope
+	}
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("failed to marshal manifest: %v", err)
+	}
+	s.manifest = content
+	return &ratify.VerifyOptions{
+		Store:      s,
+		Repository: Repository,
+		SubjectDescriptor: ocispec.Descriptor{
+			MediaType: ocispec.MediaTypeImageManifest,
+			Digest:    subject,
+			Size:      7,
+		},
+		ArtifactDescriptor: ocispec.Descriptor{
+			MediaType:    ocispec.MediaTypeImageManifest,
+			ArtifactType: attestation.ArtifactTypeInToto,
+			Digest:       digest.FromBytes(content),
+			Size:         int64(len(content)),
+		},
+	}
+}
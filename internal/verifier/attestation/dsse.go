@@ -0,0 +1,131 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package attestation verifies DSSE envelopes of in-toto attestations
+// verified by the built-in verifiers.
+package attestation
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+)
+
+// PublicKey is a trusted key verifying DSSE signatures.
+type PublicKey struct {
+	key crypto.PublicKey
+
+	// subject is the subject of the certificate of the key.
+	subject string
+}
+
+// NewPublicKeys returns the public keys of the certificates. Certificates of
+// unsupported key types are rejected.
+func NewPublicKeys(certs []*x509.Certificate) ([]PublicKey, error) {
+	keys := make([]PublicKey, 0, len(certs))
+	for _, cert := range certs {
+		switch cert.PublicKey.(type) {
+		case *ecdsa.PublicKey, *rsa.PublicKey, ed25519.PublicKey:
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T of certificate %s", cert.PublicKey, cert.Subject)
+		}
+		keys = append(keys, PublicKey{
+			key:     cert.PublicKey,
+			subject: cert.Subject.String(),
+		})
+	}
+	return keys, nil
+}
+
+// verify verifies the signature of the data. ECDSA signatures are over the
+// digest of the curve size, and RSA signatures are PSS or PKCS #1 v1.5
+// signatures over the SHA-256 digest.
+func (k PublicKey) verify(data, sig []byte) bool {
+	switch key := k.key.(type) {
+	case *ecdsa.PublicKey:
+		var digest []byte
+		switch key.Curve {
+		case elliptic.P384():
+			sum := sha512.Sum384(data)
+			digest = sum[:]
+		case elliptic.P521():
+			sum := sha512.Sum512(data)
+			digest = sum[:]
+		default:
+			sum := sha256.Sum256(data)
+			digest = sum[:]
+		}
+		return ecdsa.VerifyASN1(key, digest, sig)
+	case *rsa.PublicKey:
+		digest := sha256.Sum256(data)
+		if rsa.VerifyPSS(key, crypto.SHA256, digest[:], sig, nil) == nil {
+			return true
+		}
+		return rsa.VerifyPKCS1v15(key, crypto.SHA256, digest[:], sig) == nil
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, data, sig)
+	default:
+		return false
+	}
+}
+
+// VerifyEnvelope verifies the DSSE envelope with the trusted keys and returns
+// the payload and the subject of the certificate of the key verifying any
+// signature. Key IDs of the signatures are not used to select keys as they
+// are not authenticated.
+func VerifyEnvelope(content []byte, keys []PublicKey) (payloadType string, payload []byte, signer string, err error) {
+	var envelope dsse.Envelope
+	if err := json.Unmarshal(content, &envelope); err != nil {
+		return "", nil, "", fmt.Errorf("failed to parse DSSE envelope: %w", err)
+	}
+	if len(envelope.Signatures) == 0 {
+		return "", nil, "", errors.New("no signature found in DSSE envelope")
+	}
+	if payload, err = envelope.DecodeB64Payload(); err != nil {
+		return "", nil, "", fmt.Errorf("failed to decode DSSE payload: %w", err)
+	}
+	pae := dsse.PAE(envelope.PayloadType, payload)
+	for _, signature := range envelope.Signatures {
+		sig, err := decodeBase64(signature.Sig)
+		if err != nil {
+			continue
+		}
+		for _, key := range keys {
+			if key.verify(pae, sig) {
+				return envelope.PayloadType, payload, key.subject, nil
+			}
+		}
+	}
+	return "", nil, "", errors.New("no signature of the DSSE envelope is verified by the trusted keys")
+}
+
+// decodeBase64 decodes standard or URL-safe base64 as allowed by DSSE.
+func decodeBase64(s string) ([]byte, error) {
+	if b, err := base64.StdEncoding.DecodeString(s); err == nil {
+		return b, nil
+	}
+	return base64.URLEncoding.DecodeString(s)
+}
@@ -0,0 +1,275 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package attestation
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/sha512"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+)
+
+// testKey is a signing key with a self-signed certificate.
+type testKey struct {
+	signer crypto.Signer
+	cert   *x509.Certificate
+	pem    string
+	pss    bool
+}
+
+func newTestKey(t *testing.T, signer crypto.Signer) *testKey {
+	t.Helper()
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ratify-provenance-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, signer.Public(), signer)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return &testKey{
+		signer: signer,
+		cert:   cert,
+		pem:    string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})),
+	}
+}
+
+func newECDSAKey(t *testing.T, curve elliptic.Curve) *testKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return newTestKey(t, key)
+}
+
+// sign signs the data as expected by PublicKey.verify.
+func (k *testKey) sign(t *testing.T, data []byte) []byte {
+	t.Helper()
+	var (
+		sig []byte
+		err error
+	)
+	switch key := k.signer.(type) {
+	case *ecdsa.PrivateKey:
+		var digest []byte
+		switch key.Curve {
+		case elliptic.P384():
+			sum := sha512.Sum384(data)
+			digest = sum[:]
+		default:
+			sum := sha256.Sum256(data)
+			digest = sum[:]
+		}
+		sig, err = ecdsa.SignASN1(rand.Reader, key, digest)
+	case *rsa.PrivateKey:
+		digest := sha256.Sum256(data)
+		if k.pss {
+			sig, err = rsa.SignPSS(rand.Reader, key, crypto.SHA256, digest[:], nil)
+		} else {
+			sig, err = rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+		}
+	case ed25519.PrivateKey:
+		sig = ed25519.Sign(key, data)
+	}
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	return sig
+}
+
+// signEnvelope returns a DSSE envelope of the payload signed by the keys.
+func signEnvelope(t *testing.T, payloadType string, payload []byte, keys ...*testKey) []byte {
+	t.Helper()
+	envelope := dsse.Envelope{
+		PayloadType: payloadType,
+		Payload:     base64.StdEncoding.EncodeToString(payload),
+		Signatures:  []dsse.Signature{},
+	}
+	for _, key := range keys {
+		envelope.Signatures = append(envelope.Signatures, dsse.Signature{
+			KeyID: "unauthenticated-key-id",
+			Sig:   base64.StdEncoding.EncodeToString(key.sign(t, dsse.PAE(payloadType, payload))),
+		})
+	}
+	content, err := json.Marshal(envelope)
+	if err != nil {
+		t.Fatalf("failed to marshal envelope: %v", err)
+	}
+	return content
+}
+
+func trustedKeys(t *testing.T, keys ...*testKey) []PublicKey {
+	t.Helper()
+	certs := make([]*x509.Certificate, 0, len(keys))
+	for _, key := range keys {
+		certs = append(certs, key.cert)
+	}
+	trusted, err := NewPublicKeys(certs)
+	if err != nil {
+		t.Fatalf("failed to create public keys: %v", err)
+	}
+	return trusted
+}
+
+func TestVerifyEnvelope(t *testing.T) {
+	p256 := newECDSAKey(t, elliptic.P256())
+	p384 := newECDSAKey(t, elliptic.P384())
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	pkcs1 := newTestKey(t, rsaKey)
+	pss := newTestKey(t, rsaKey)
+	pss.pss = true
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	ed := newTestKey(t, edKey)
+	untrusted := newECDSAKey(t, elliptic.P256())
+
+	payload := []byte(`{"_type":"https://in-toto.io/Statement/v1"}`)
+	tampered := signEnvelope(t, PayloadTypeInToto, payload, p256)
+	var envelope dsse.Envelope
+	if err := json.Unmarshal(tampered, &envelope); err != nil {
+		t.Fatalf("failed to unmarshal envelope: %v", err)
+	}
+	envelope.PayloadType = "text/plain"
+	if tampered, err = json.Marshal(envelope); err != nil {
+		t.Fatalf("failed to marshal envelope: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		content      []byte
+		keys         []PublicKey
+		expectErr    bool
+		expectSigner string
+	}{
+		{
+			name:         "ECDSA P-256",
+			content:      signEnvelope(t, PayloadTypeInToto, payload, p256),
+			keys:         trustedKeys(t, p256),
+			expectSigner: p256.cert.Subject.String(),
+		},
+		{
+			name:    "ECDSA P-384",
+			content: signEnvelope(t, PayloadTypeInToto, payload, p384),
+			keys:    trustedKeys(t, p256, p384),
+		},
+		{
+			name:    "RSA PKCS #1 v1.5",
+			content: signEnvelope(t, PayloadTypeInToto, payload, pkcs1),
+			keys:    trustedKeys(t, pkcs1),
+		},
+		{
+			name:    "RSA PSS",
+			content: signEnvelope(t, PayloadTypeInToto, payload, pss),
+			keys:    trustedKeys(t, pss),
+		},
+		{
+			name:    "Ed25519",
+			content: signEnvelope(t, PayloadTypeInToto, payload, ed),
+			keys:    trustedKeys(t, ed),
+		},
+		{
+			name:    "any trusted signature",
+			content: signEnvelope(t, PayloadTypeInToto, payload, untrusted, p256),
+			keys:    trustedKeys(t, p256),
+		},
+		{
+			name:      "untrusted signature",
+			content:   signEnvelope(t, PayloadTypeInToto, payload, untrusted),
+			keys:      trustedKeys(t, p256),
+			expectErr: true,
+		},
+		{
+			name:      "tampered payload type",
+			content:   tampered,
+			keys:      trustedKeys(t, p256),
+			expectErr: true,
+		},
+		{
+			name:      "no signatures",
+			content:   signEnvelope(t, PayloadTypeInToto, payload),
+			keys:      trustedKeys(t, p256),
+			expectErr: true,
+		},
+		{
+			name:      "invalid payload",
+			content:   []byte(`{"payloadType":"a","payload":"!","signatures":[{"sig":"AA=="}]}`),
+			keys:      trustedKeys(t, p256),
+			expectErr: true,
+		},
+		{
+			name:      "invalid signature encoding",
+			content:   []byte(`{"payloadType":"a","payload":"AA==","signatures":[{"sig":"!"}]}`),
+			keys:      trustedKeys(t, p256),
+			expectErr: true,
+		},
+		{
+			name:      "invalid envelope",
+			content:   []byte(`{`),
+			keys:      trustedKeys(t, p256),
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			payloadType, got, signer, err := VerifyEnvelope(test.content, test.keys)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if payloadType != PayloadTypeInToto || string(got) != string(payload) {
+				t.Errorf("unexpected payload %s of type %s", got, payloadType)
+			}
+			if test.expectSigner != "" && signer != test.expectSigner {
+				t.Errorf("expected signer %s, got %s", test.expectSigner, signer)
+			}
+		})
+	}
+}
+
+func TestNewPublicKeys(t *testing.T) {
+	if _, err := NewPublicKeys([]*x509.Certificate{{PublicKey: "unsupported"}}); err == nil {
+		t.Fatalf("expected error for unsupported key type")
+	}
+}
@@ -0,0 +1,60 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package attestation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/notaryproject/ratify/v2/internal/verifier/keyprovider"
+	_ "github.com/notaryproject/ratify/v2/internal/verifier/keyprovider/filesystemprovider" // Register the filesystem key provider
+	_ "github.com/notaryproject/ratify/v2/internal/verifier/keyprovider/inlineprovider"     // Register the inline key provider
+)
+
+// KeyOptions is a map of key provider names to their options, e.g.
+// {"inline": "<PEM-encoded certificates>"}. The public keys of the provided
+// certificates verify the DSSE signatures.
+type KeyOptions map[string]any
+
+// LoadKeys loads the trusted public keys from the key providers.
+func LoadKeys(opts []KeyOptions) ([]PublicKey, error) {
+	if len(opts) == 0 {
+		return nil, errors.New("no key options provided")
+	}
+	var keys []PublicKey
+	for _, opt := range opts {
+		for name, val := range opt {
+			provider, err := keyprovider.CreateKeyProvider(name, val)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get key provider %s: %w", name, err)
+			}
+			certs, err := provider.GetCertificates(context.Background())
+			if err != nil {
+				return nil, fmt.Errorf("failed to get certificates from provider %s: %w", name, err)
+			}
+			providerKeys, err := NewPublicKeys(certs)
+			if err != nil {
+				return nil, err
+			}
+			keys = append(keys, providerKeys...)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no keys provided by the key providers")
+	}
+	return keys, nil
+}
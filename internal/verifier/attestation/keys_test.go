@@ -0,0 +1,69 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package attestation
+
+import (
+	"crypto/elliptic"
+	"testing"
+)
+
+func TestLoadKeys(t *testing.T) {
+	key := newECDSAKey(t, elliptic.P256())
+
+	tests := []struct {
+		name       string
+		opts       []KeyOptions
+		expectErr  bool
+		expectKeys int
+	}{
+		{
+			name:      "no key options",
+			expectErr: true,
+		},
+		{
+			name:      "non-registered key provider",
+			opts:      []KeyOptions{{"non-registered": nil}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid certificates",
+			opts:      []KeyOptions{{"inline": "invalid"}},
+			expectErr: true,
+		},
+		{
+			name:      "no keys provided",
+			opts:      []KeyOptions{{}},
+			expectErr: true,
+		},
+		{
+			name:       "inline certificates",
+			opts:       []KeyOptions{{"inline": key.pem}, {"inline": key.pem}},
+			expectKeys: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			keys, err := LoadKeys(test.opts)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if len(keys) != test.expectKeys {
+				t.Errorf("expected %d keys, got %d", test.expectKeys, len(keys))
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package attestation
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// ArtifactTypeInToto is the artifact type of in-toto attestations.
+	ArtifactTypeInToto = "application/vnd.in-toto+json"
+
+	// ArtifactTypeDSSE is the artifact type of DSSE envelopes.
+	ArtifactTypeDSSE = "application/vnd.dsse.envelope.v1+json"
+
+	// PayloadTypeInToto is the DSSE payload type of in-toto statements.
+	PayloadTypeInToto = "application/vnd.in-toto+json"
+
+	statementTypeV01 = "https://in-toto.io/Statement/v0.1"
+	statementTypeV1  = "https://in-toto.io/Statement/v1"
+)
+
+// Subject is a software artifact the statement is about.
+type Subject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+// Statement is an in-toto statement with a raw predicate.
+type Statement struct {
+	Type          string          `json:"_type"`
+	PredicateType string          `json:"predicateType"`
+	Subject       []Subject       `json:"subject"`
+	Predicate     json.RawMessage `json:"predicate"`
+}
+
+// ParseStatement parses the in-toto statement of a DSSE payload.
+func ParseStatement(payloadType string, payload []byte) (*Statement, error) {
+	if payloadType != PayloadTypeInToto {
+		return nil, fmt.Errorf("unsupported DSSE payload type %q, expected %q", payloadType, PayloadTypeInToto)
+	}
+	var s Statement
+	if err := json.Unmarshal(payload, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse in-toto statement: %w", err)
+	}
+	if s.Type != statementTypeV01 && s.Type != statementTypeV1 {
+		return nil, fmt.Errorf("unsupported in-toto statement type %q", s.Type)
+	}
+	return &s, nil
+}
+
+// MatchesSubject returns true if any subject of the statement has the digest
+// of the descriptor.
+func (s *Statement) MatchesSubject(desc ocispec.Descriptor) bool {
+	algorithm, encoded := desc.Digest.Algorithm().String(), desc.Digest.Encoded()
+	for _, subject := range s.Subject {
+		if strings.EqualFold(subject.Digest[algorithm], encoded) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,89 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package attestation
+
+import (
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestParseStatement(t *testing.T) {
+	tests := []struct {
+		name        string
+		payloadType string
+		payload     string
+		expectErr   bool
+	}{
+		{
+			name:        "statement v1",
+			payloadType: PayloadTypeInToto,
+			payload:     `{"_type":"https://in-toto.io/Statement/v1","predicateType":"https://slsa.dev/provenance/v1","predicate":{}}`,
+		},
+		{
+			name:        "statement v0.1",
+			payloadType: PayloadTypeInToto,
+			payload:     `{"_type":"https://in-toto.io/Statement/v0.1","predicateType":"https://slsa.dev/provenance/v0.2"}`,
+		},
+		{
+			name:        "unsupported payload type",
+			payloadType: "text/plain",
+			payload:     `{"_type":"https://in-toto.io/Statement/v1"}`,
+			expectErr:   true,
+		},
+		{
+			name:        "unsupported statement type",
+			payloadType: PayloadTypeInToto,
+			payload:     `{"_type":"https://example.com/Statement"}`,
+			expectErr:   true,
+		},
+		{
+			name:        "invalid statement",
+			payloadType: PayloadTypeInToto,
+			payload:     `{`,
+			expectErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := ParseStatement(test.payloadType, []byte(test.payload))
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err == nil && s.PredicateType == "" {
+				t.Errorf("expected predicate type to be parsed, got %+v", s)
+			}
+		})
+	}
+}
+
+func TestStatement_MatchesSubject(t *testing.T) {
+	subject := digest.FromString("subject")
+	s := &Statement{
+		Subject: []Subject{
+			{Name: "other", Digest: map[string]string{"sha512": "abc"}},
+			{Name: "test.registry.io/test/image", Digest: map[string]string{"sha256": subject.Encoded()}},
+		},
+	}
+	if !s.MatchesSubject(ocispec.Descriptor{Digest: subject}) {
+		t.Error("expected statement to match subject")
+	}
+	if s.MatchesSubject(ocispec.Descriptor{Digest: digest.FromString("other")}) {
+		t.Error("expected statement not to match other subject")
+	}
+}
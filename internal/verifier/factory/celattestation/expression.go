@@ -0,0 +1,151 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package celattestation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/cel-go/cel"
+	"github.com/notaryproject/ratify/v2/internal/verifier/attestation"
+)
+
+const (
+	// predicateVariable is the CEL variable of the decoded predicate.
+	predicateVariable = "predicate"
+
+	// statementVariable is the CEL variable of the decoded in-toto statement.
+	statementVariable = "statement"
+
+	// costLimit limits the evaluation cost of an expression.
+	costLimit = 1000000
+)
+
+// expressionOptions is a CEL expression the predicate must satisfy.
+type expressionOptions struct {
+	// Name is the name of the expression in the report. Optional. Defaults to
+	// the expression.
+	Name string `json:"name,omitempty"`
+
+	// Expression is the CEL expression evaluating to a boolean, e.g.
+	// "predicate.coverage >= 80". Required.
+	Expression string `json:"expression"`
+}
+
+// expression is a compiled CEL expression.
+type expression struct {
+	name    string
+	source  string
+	program cel.Program
+}
+
+// expressionResult is the evaluation result of an expression.
+type expressionResult struct {
+	// Name is the name of the expression.
+	Name string `json:"name"`
+
+	// Expression is the CEL expression.
+	Expression string `json:"expression"`
+
+	// Satisfied is true if the expression evaluated to true.
+	Satisfied bool `json:"satisfied"`
+
+	// Error is the evaluation error, if any. Optional.
+	Error string `json:"error,omitempty"`
+}
+
+// compileExpressions compiles the expressions so that errors are reported
+// when the verifier is created.
+func compileExpressions(opts []expressionOptions) ([]expression, error) {
+	if len(opts) == 0 {
+		return nil, errors.New("no expressions provided")
+	}
+	env, err := cel.NewEnv(
+		cel.Variable(predicateVariable, cel.DynType),
+		cel.Variable(statementVariable, cel.MapType(cel.StringType, cel.DynType)),
+		cel.CrossTypeNumericComparisons(true),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CEL environment: %w", err)
+	}
+	expressions := make([]expression, 0, len(opts))
+	for _, opt := range opts {
+		if opt.Expression == "" {
+			return nil, errors.New("empty expression provided")
+		}
+		ast, issues := env.Compile(opt.Expression)
+		if issues.Err() != nil {
+			return nil, fmt.Errorf("failed to compile expression %q: %w", opt.Expression, issues.Err())
+		}
+		if outputType := ast.OutputType(); !outputType.IsExactType(cel.BoolType) && !outputType.IsExactType(cel.DynType) {
+			return nil, fmt.Errorf("expression %q must evaluate to bool, got %s", opt.Expression, outputType)
+		}
+		program, err := env.Program(ast, cel.CostLimit(costLimit))
+		if err != nil {
+			return nil, fmt.Errorf("failed to create program of expression %q: %w", opt.Expression, err)
+		}
+		name := opt.Name
+		if name == "" {
+			name = opt.Expression
+		}
+		expressions = append(expressions, expression{
+			name:    name,
+			source:  opt.Expression,
+			program: program,
+		})
+	}
+	return expressions, nil
+}
+
+// evaluate evaluates the expression over the statement.
+func (e expression) evaluate(vars map[string]any) expressionResult {
+	result := expressionResult{
+		Name:       e.name,
+		Expression: e.source,
+	}
+	out, _, err := e.program.Eval(vars)
+	if err != nil {
+		result.Error = err.Error()
+		return result
+	}
+	satisfied, ok := out.Value().(bool)
+	if !ok {
+		result.Error = fmt.Sprintf("expression evaluated to %v, expected bool", out.Value())
+		return result
+	}
+	result.Satisfied = satisfied
+	return result
+}
+
+// variables returns the CEL variables of the statement with the predicate
+// decoded as JSON.
+func variables(s *attestation.Statement, payload []byte) (map[string]any, error) {
+	var predicate any
+	if len(s.Predicate) > 0 {
+		if err := json.Unmarshal(s.Predicate, &predicate); err != nil {
+			return nil, fmt.Errorf("failed to decode predicate: %w", err)
+		}
+	}
+	var statement map[string]any
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		return nil, fmt.Errorf("failed to decode statement: %w", err)
+	}
+	return map[string]any{
+		predicateVariable: predicate,
+		statementVariable: statement,
+	}, nil
+}
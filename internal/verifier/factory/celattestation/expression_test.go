@@ -0,0 +1,137 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package celattestation
+
+import (
+	"testing"
+
+	"github.com/notaryproject/ratify/v2/internal/verifier/attestation"
+)
+
+func TestCompileExpressions(t *testing.T) {
+	tests := []struct {
+		name       string
+		opts       []expressionOptions
+		expectErr  bool
+		expectName string
+	}{
+		{
+			name:      "no expressions",
+			expectErr: true,
+		},
+		{
+			name:      "empty expression",
+			opts:      []expressionOptions{{Name: "empty"}},
+			expectErr: true,
+		},
+		{
+			name:      "syntax error",
+			opts:      []expressionOptions{{Expression: "predicate.coverage >="}},
+			expectErr: true,
+		},
+		{
+			name:      "undeclared variable",
+			opts:      []expressionOptions{{Expression: "coverage >= 80"}},
+			expectErr: true,
+		},
+		{
+			name:      "non-bool expression",
+			opts:      []expressionOptions{{Expression: "1 + 2"}},
+			expectErr: true,
+		},
+		{
+			name:       "expression named by source",
+			opts:       []expressionOptions{{Expression: "predicate.coverage >= 80"}},
+			expectName: "predicate.coverage >= 80",
+		},
+		{
+			name:       "named expression",
+			opts:       []expressionOptions{{Name: "coverage", Expression: `statement.predicateType == "test" && predicate.coverage >= 80`}},
+			expectName: "coverage",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expressions, err := compileExpressions(test.opts)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err == nil && expressions[0].name != test.expectName {
+				t.Errorf("expected name %q, got %q", test.expectName, expressions[0].name)
+			}
+		})
+	}
+}
+
+func TestExpression_Evaluate(t *testing.T) {
+	payload := []byte(`{"_type":"https://in-toto.io/Statement/v1","predicateType":"https://example.com/test-result/v1","predicate":{"coverage":85.5,"passed":120,"failed":0,"suites":["unit","e2e"],"result":"PASSED"}}`)
+	s, err := attestation.ParseStatement(attestation.PayloadTypeInToto, payload)
+	if err != nil {
+		t.Fatalf("failed to parse statement: %v", err)
+	}
+	vars, err := variables(s, payload)
+	if err != nil {
+		t.Fatalf("failed to decode variables: %v", err)
+	}
+
+	tests := []struct {
+		expression      string
+		expectSatisfied bool
+		expectErr       bool
+	}{
+		{expression: "predicate.coverage >= 80", expectSatisfied: true},
+		{expression: "predicate.coverage >= 90"},
+		{expression: `predicate.failed == 0 && predicate.result == "PASSED"`, expectSatisfied: true},
+		{expression: `"e2e" in predicate.suites`, expectSatisfied: true},
+		{expression: `statement.predicateType.startsWith("https://example.com/")`, expectSatisfied: true},
+		{expression: "predicate.missing > 0", expectErr: true},
+		{expression: "predicate.result", expectErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expression, func(t *testing.T) {
+			expressions, err := compileExpressions([]expressionOptions{{Expression: test.expression}})
+			if err != nil {
+				t.Fatalf("failed to compile expression: %v", err)
+			}
+			result := expressions[0].evaluate(vars)
+			if result.Satisfied != test.expectSatisfied || (result.Error != "") != test.expectErr {
+				t.Errorf("expected satisfied: %v, error: %v, got %+v", test.expectSatisfied, test.expectErr, result)
+			}
+		})
+	}
+}
+
+func TestVariables(t *testing.T) {
+	payload := []byte(`{"_type":"https://in-toto.io/Statement/v1","predicateType":"test"}`)
+	s, err := attestation.ParseStatement(attestation.PayloadTypeInToto, payload)
+	if err != nil {
+		t.Fatalf("failed to parse statement: %v", err)
+	}
+	vars, err := variables(s, payload)
+	if err != nil {
+		t.Fatalf("failed to decode variables: %v", err)
+	}
+	if vars[predicateVariable] != nil {
+		t.Errorf("expected nil predicate, got %v", vars[predicateVariable])
+	}
+
+	s.Predicate = []byte(`{`)
+	if _, err := variables(s, payload); err == nil {
+		t.Error("expected error for invalid predicate")
+	}
+}
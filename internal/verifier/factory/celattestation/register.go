@@ -0,0 +1,85 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package celattestation
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/attestation"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+)
+
+const celAttestationType = "cel-attestation"
+
+type options struct {
+	// PredicateType is the in-toto predicate type of the attestations, e.g.
+	// "https://example.com/test-result/v1". Required.
+	PredicateType string `json:"predicateType"`
+
+	// Keys are the key providers of the trusted keys verifying the DSSE
+	// signatures. Required.
+	Keys []attestation.KeyOptions `json:"keys"`
+
+	// Expressions are the CEL expressions the predicate must satisfy. The
+	// predicate is available as `predicate` and the whole in-toto statement
+	// as `statement`. Required.
+	Expressions []expressionOptions `json:"expressions"`
+
+	// ArtifactTypes are the artifact types of the attestations. Optional.
+	// Defaults to the in-toto and DSSE artifact types.
+	ArtifactTypes []string `json:"artifactTypes,omitempty"`
+}
+
+func init() {
+	factory.RegisterVerifierFactory(celAttestationType, func(opts *factory.NewVerifierOptions) (ratify.Verifier, error) {
+		raw, err := json.Marshal(opts.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal verifier parameters: %w", err)
+		}
+
+		var params options
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal verifier parameters: %w", err)
+		}
+
+		if params.PredicateType == "" {
+			return nil, errors.New("predicateType is required")
+		}
+		keys, err := attestation.LoadKeys(params.Keys)
+		if err != nil {
+			return nil, err
+		}
+		expressions, err := compileExpressions(params.Expressions)
+		if err != nil {
+			return nil, err
+		}
+		artifactTypes := params.ArtifactTypes
+		if len(artifactTypes) == 0 {
+			artifactTypes = defaultArtifactTypes
+		}
+
+		return &verifier{
+			name:          opts.Name,
+			predicateType: params.PredicateType,
+			artifactTypes: artifactTypes,
+			keys:          keys,
+			expressions:   expressions,
+		}, nil
+	})
+}
@@ -0,0 +1,113 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package celattestation
+
+import (
+	"testing"
+
+	"github.com/notaryproject/ratify/v2/internal/verifier/attestation/attestationtest"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+)
+
+const (
+	testName          = "cel-attestation-test"
+	testPredicateType = "https://example.com/test-result/v1"
+)
+
+func TestNewVerifier(t *testing.T) {
+	key := attestationtest.NewKey(t, "ratify-cel-attestation-test")
+
+	tests := []struct {
+		name      string
+		params    any
+		expectErr bool
+	}{
+		{
+			name:      "unsupported params",
+			params:    make(chan int),
+			expectErr: true,
+		},
+		{
+			name:      "malformed params",
+			params:    "{",
+			expectErr: true,
+		},
+		{
+			name: "missing predicate type",
+			params: map[string]any{
+				"keys":        []map[string]any{{"inline": key.PEM}},
+				"expressions": []map[string]any{{"expression": "predicate.coverage >= 80"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "missing keys",
+			params: map[string]any{
+				"predicateType": testPredicateType,
+				"expressions":   []map[string]any{{"expression": "predicate.coverage >= 80"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "missing expressions",
+			params: map[string]any{
+				"predicateType": testPredicateType,
+				"keys":          []map[string]any{{"inline": key.PEM}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid expression",
+			params: map[string]any{
+				"predicateType": testPredicateType,
+				"keys":          []map[string]any{{"inline": key.PEM}},
+				"expressions":   []map[string]any{{"expression": "predicate.coverage >="}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid options",
+			params: map[string]any{
+				"predicateType": testPredicateType,
+				"keys":          []map[string]any{{"inline": key.PEM}},
+				"expressions": []map[string]any{
+					{"name": "coverage", "expression": "predicate.coverage >= 80"},
+					{"expression": "predicate.failed == 0"},
+				},
+				"artifactTypes": []string{"application/vnd.example.test-result+json"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+				Type:       celAttestationType,
+				Name:       testName,
+				Parameters: test.params,
+			})
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if v.Name() != testName || v.Type() != celAttestationType {
+				t.Errorf("unexpected verifier %s of type %s", v.Name(), v.Type())
+			}
+		})
+	}
+}
@@ -0,0 +1,139 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package celattestation
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/artifact"
+	"github.com/notaryproject/ratify/v2/internal/verifier/attestation"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// defaultArtifactTypes are the artifact types verified by default.
+var defaultArtifactTypes = []string{attestation.ArtifactTypeInToto, attestation.ArtifactTypeDSSE}
+
+// attestationDetail describes an evaluated in-toto statement.
+type attestationDetail struct {
+	// PredicateType is the predicate type of the statement.
+	PredicateType string `json:"predicateType"`
+
+	// Signer is the subject of the certificate of the key verifying the
+	// signature.
+	Signer string `json:"signer"`
+
+	// Results are the evaluation results of the expressions.
+	Results []expressionResult `json:"results"`
+}
+
+// verifier is a ratify.Verifier implementation that evaluates CEL
+// expressions over the predicates of signed in-toto attestations.
+type verifier struct {
+	name          string
+	predicateType string
+	artifactTypes []string
+	keys          []attestation.PublicKey
+	expressions   []expression
+}
+
+// Name returns the name of the verifier.
+func (v *verifier) Name() string {
+	return v.name
+}
+
+// Type returns the type of the verifier which is always `cel-attestation`.
+func (v *verifier) Type() string {
+	return celAttestationType
+}
+
+// Verifiable returns true if the artifact is an attestation of the
+// configured artifact types.
+func (v *verifier) Verifiable(artifact ocispec.Descriptor) bool {
+	return slices.Contains(v.artifactTypes, artifact.ArtifactType) && artifact.MediaType == ocispec.MediaTypeImageManifest
+}
+
+// Verify verifies the DSSE envelopes in the layers of the artifact. Each
+// envelope must be signed by a trusted key, and each statement of the
+// configured predicate type must be about the subject and satisfy all
+// expressions. Statements of other predicate types are ignored.
+func (v *verifier) Verify(ctx context.Context, opts *ratify.VerifyOptions) (*ratify.VerificationResult, error) {
+	blobs, err := artifact.FetchBlobs(ctx, opts.Store, opts.Repository, opts.ArtifactDescriptor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ratify.VerificationResult{
+		Verifier: v,
+	}
+	var details []attestationDetail
+	var unsatisfied []string
+	for _, blob := range blobs {
+		payloadType, payload, signer, err := attestation.VerifyEnvelope(blob.Content, v.keys)
+		if err != nil {
+			result.Err = fmt.Errorf("failed to verify DSSE envelope %s: %w", blob.Descriptor.Digest, err)
+			return result, nil
+		}
+		s, err := attestation.ParseStatement(payloadType, payload)
+		if err != nil {
+			result.Err = err
+			return result, nil
+		}
+		if s.PredicateType != v.predicateType {
+			continue
+		}
+		if !s.MatchesSubject(opts.SubjectDescriptor) {
+			result.Err = fmt.Errorf("subject %s not found in attestation statement", opts.SubjectDescriptor.Digest)
+			return result, nil
+		}
+		vars, err := variables(s, payload)
+		if err != nil {
+			result.Err = err
+			return result, nil
+		}
+		detail := attestationDetail{
+			PredicateType: s.PredicateType,
+			Signer:        signer,
+			Results:       make([]expressionResult, 0, len(v.expressions)),
+		}
+		for _, e := range v.expressions {
+			r := e.evaluate(vars)
+			if !r.Satisfied && !slices.Contains(unsatisfied, r.Name) {
+				unsatisfied = append(unsatisfied, r.Name)
+			}
+			detail.Results = append(detail.Results, r)
+		}
+		details = append(details, detail)
+	}
+	if len(details) == 0 {
+		result.Err = fmt.Errorf("no attestation of predicate type %s found in artifact %s", v.predicateType, opts.ArtifactDescriptor.Digest)
+		return result, nil
+	}
+
+	result.Detail = map[string]any{
+		"Attestations": details,
+	}
+	if len(unsatisfied) > 0 {
+		result.Err = fmt.Errorf("attestation does not satisfy expressions: %s", strings.Join(unsatisfied, ", "))
+		result.Description = "Attestation verification failed"
+		return result, nil
+	}
+	result.Description = "Attestation verification succeeded"
+	return result, nil
+}
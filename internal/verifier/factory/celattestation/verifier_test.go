@@ -0,0 +1,173 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package celattestation
+
+import (
+	"context"
+	"testing"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/attestation"
+	"github.com/notaryproject/ratify/v2/internal/verifier/attestation/attestationtest"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func newTestVerifier(t *testing.T, key *attestationtest.Key) ratify.Verifier {
+	t.Helper()
+	v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+		Type: celAttestationType,
+		Name: testName,
+		Parameters: map[string]any{
+			"predicateType": testPredicateType,
+			"keys":          []map[string]any{{"inline": key.PEM}},
+			"expressions": []map[string]any{
+				{"name": "coverage", "expression": "predicate.coverage >= 80"},
+				{"name": "no failures", "expression": "predicate.failed == 0"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+	return v
+}
+
+func TestVerifier_Verifiable(t *testing.T) {
+	v := newTestVerifier(t, attestationtest.NewKey(t, "ratify-cel-attestation-test"))
+	tests := []struct {
+		desc   ocispec.Descriptor
+		expect bool
+	}{
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: attestation.ArtifactTypeInToto}, true},
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: attestation.ArtifactTypeDSSE}, true},
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: "application/spdx+json"}, false},
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageIndex, ArtifactType: attestation.ArtifactTypeInToto}, false},
+	}
+	for _, test := range tests {
+		if got := v.Verifiable(test.desc); got != test.expect {
+			t.Errorf("expected Verifiable(%s, %s) to be %v, got %v", test.desc.MediaType, test.desc.ArtifactType, test.expect, got)
+		}
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	key := attestationtest.NewKey(t, "ratify-cel-attestation-test")
+	untrusted := attestationtest.NewKey(t, "ratify-cel-attestation-test")
+	subject := digest.FromString("subject")
+
+	passing := attestationtest.NewStatement(t, testPredicateType, subject, map[string]any{"coverage": 85, "failed": 0})
+	lowCoverage := attestationtest.NewStatement(t, testPredicateType, subject, map[string]any{"coverage": 60, "failed": 0})
+	missingField := attestationtest.NewStatement(t, testPredicateType, subject, map[string]any{"coverage": 90})
+	other := attestationtest.NewStatement(t, "https://slsa.dev/provenance/v1", subject, map[string]any{})
+
+	tests := []struct {
+		name              string
+		opts              *ratify.VerifyOptions
+		expectErr         bool
+		expectStoreErr    bool
+		expectAttestation int
+		expectUnsatisfied []string
+	}{
+		{
+			name:              "satisfied expressions",
+			opts:              attestationtest.NewVerifyOptions(t, subject, attestationtest.SignEnvelope(t, attestation.PayloadTypeInToto, passing, key)),
+			expectAttestation: 1,
+		},
+		{
+			name: "other predicate types are ignored",
+			opts: attestationtest.NewVerifyOptions(t, subject,
+				attestationtest.SignEnvelope(t, attestation.PayloadTypeInToto, other, key),
+				attestationtest.SignEnvelope(t, attestation.PayloadTypeInToto, passing, key)),
+			expectAttestation: 1,
+		},
+		{
+			name:              "unsatisfied expression",
+			opts:              attestationtest.NewVerifyOptions(t, subject, attestationtest.SignEnvelope(t, attestation.PayloadTypeInToto, lowCoverage, key)),
+			expectErr:         true,
+			expectAttestation: 1,
+			expectUnsatisfied: []string{"coverage"},
+		},
+		{
+			name:              "evaluation error",
+			opts:              attestationtest.NewVerifyOptions(t, subject, attestationtest.SignEnvelope(t, attestation.PayloadTypeInToto, missingField, key)),
+			expectErr:         true,
+			expectAttestation: 1,
+			expectUnsatisfied: []string{"no failures"},
+		},
+		{
+			name:      "no attestation of predicate type",
+			opts:      attestationtest.NewVerifyOptions(t, subject, attestationtest.SignEnvelope(t, attestation.PayloadTypeInToto, other, key)),
+			expectErr: true,
+		},
+		{
+			name:      "untrusted signature",
+			opts:      attestationtest.NewVerifyOptions(t, subject, attestationtest.SignEnvelope(t, attestation.PayloadTypeInToto, passing, untrusted)),
+			expectErr: true,
+		},
+		{
+			name:      "unsupported payload type",
+			opts:      attestationtest.NewVerifyOptions(t, subject, attestationtest.SignEnvelope(t, "text/plain", passing, key)),
+			expectErr: true,
+		},
+		{
+			name:      "subject mismatch",
+			opts:      attestationtest.NewVerifyOptions(t, digest.FromString("other"), attestationtest.SignEnvelope(t, attestation.PayloadTypeInToto, passing, key)),
+			expectErr: true,
+		},
+		{
+			name:           "no layers",
+			opts:           attestationtest.NewVerifyOptions(t, subject),
+			expectStoreErr: true,
+		},
+	}
+
+	v := newTestVerifier(t, key)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := v.Verify(context.Background(), test.opts)
+			if (err != nil) != test.expectStoreErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectStoreErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if (result.Err != nil) != test.expectErr {
+				t.Fatalf("expected verification error: %v, got: %v", test.expectErr, result.Err)
+			}
+			if test.expectAttestation == 0 {
+				return
+			}
+			details := result.Detail.(map[string]any)["Attestations"].([]attestationDetail)
+			if len(details) != test.expectAttestation {
+				t.Fatalf("expected %d attestations, got %+v", test.expectAttestation, details)
+			}
+			var unsatisfied []string
+			for _, r := range details[0].Results {
+				if !r.Satisfied {
+					unsatisfied = append(unsatisfied, r.Name)
+				}
+			}
+			if len(unsatisfied) != len(test.expectUnsatisfied) || (len(unsatisfied) > 0 && unsatisfied[0] != test.expectUnsatisfied[0]) {
+				t.Errorf("expected unsatisfied expressions %v, got %+v", test.expectUnsatisfied, details[0].Results)
+			}
+			if details[0].Signer != key.Certificate.Subject.String() {
+				t.Errorf("expected signer %s, got %s", key.Certificate.Subject, details[0].Signer)
+			}
+		})
+	}
+}
@@ -0,0 +1,142 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package freshness
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	mediaTypeDockerManifest = "application/vnd.docker.distribution.manifest.v2+json"
+
+	// sourceConfig is the source of creation times read from the image
+	// config.
+	sourceConfig = "config.created"
+)
+
+// errInvalidTime is returned for creation times that are not RFC 3339
+// timestamps.
+var errInvalidTime = errors.New("invalid creation time")
+
+// created is the creation time of the subject or an artifact.
+type created struct {
+	// Time is the creation time.
+	Time time.Time `json:"time"`
+
+	// Source is the annotation or field the creation time is read from.
+	Source string `json:"source"`
+
+	// Age is the age at verification time.
+	Age string `json:"age"`
+}
+
+// fetchSubjectCreated returns the creation time of the subject from the
+// annotations of its manifest or index, falling back to the "created" field
+// of the image config. It returns nil if no creation time is found.
+func fetchSubjectCreated(ctx context.Context, store ratify.Store, repo string, desc ocispec.Descriptor, annotations []string) (*created, error) {
+	content, err := fetchManifest(ctx, store, repo, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch subject manifest %s: %w", desc.Digest, err)
+	}
+	// the fields are shared by image manifests and indexes.
+	var manifest struct {
+		MediaType   string             `json:"mediaType"`
+		Config      ocispec.Descriptor `json:"config"`
+		Annotations map[string]string  `json:"annotations"`
+	}
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal subject manifest %s: %w", desc.Digest, err)
+	}
+	if c, err := fromAnnotations(manifest.Annotations, annotations); c != nil || err != nil {
+		return c, err
+	}
+
+	mediaType := manifest.MediaType
+	if mediaType == "" {
+		mediaType = desc.MediaType
+	}
+	if mediaType != ocispec.MediaTypeImageManifest && mediaType != mediaTypeDockerManifest {
+		return nil, nil
+	}
+	content, err = store.FetchBlob(ctx, repo, manifest.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch config %s: %w", manifest.Config.Digest, err)
+	}
+	var config ocispec.Image
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config %s: %w", manifest.Config.Digest, err)
+	}
+	if config.Created == nil || config.Created.IsZero() {
+		return nil, nil
+	}
+	return &created{Time: *config.Created, Source: sourceConfig}, nil
+}
+
+// fetchArtifactCreated returns the creation time of the artifact from the
+// annotations of its manifest. The annotations of the descriptor are ignored
+// as referrers listings are not covered by the artifact digest. It returns nil
+// if no creation time is found.
+func fetchArtifactCreated(ctx context.Context, store ratify.Store, repo string, desc ocispec.Descriptor, annotations []string) (*created, error) {
+	content, err := fetchManifest(ctx, store, repo, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest for artifact: %w", err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+	return fromAnnotations(manifest.Annotations, annotations)
+}
+
+// fetchManifest fetches the manifest of the descriptor and checks its content
+// against the descriptor digest.
+func fetchManifest(ctx context.Context, store ratify.Store, repo string, desc ocispec.Descriptor) ([]byte, error) {
+	if err := desc.Digest.Validate(); err != nil {
+		return nil, err
+	}
+	content, err := store.FetchManifest(ctx, repo, desc)
+	if err != nil {
+		return nil, err
+	}
+	if actual := desc.Digest.Algorithm().FromBytes(content); actual != desc.Digest {
+		return nil, fmt.Errorf("manifest digest %s does not match %s", actual, desc.Digest)
+	}
+	return content, nil
+}
+
+// fromAnnotations returns the creation time of the first of the annotations
+// present. It returns nil if none is present.
+func fromAnnotations(values map[string]string, annotations []string) (*created, error) {
+	for _, name := range annotations {
+		value, ok := values[name]
+		if !ok {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, fmt.Errorf("%w %q in annotation %s", errInvalidTime, value, name)
+		}
+		return &created{Time: t, Source: name}, nil
+	}
+	return nil, nil
+}
@@ -0,0 +1,298 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package freshness
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const testRepo = "test.registry.io/test/image"
+
+// mockStore serves manifests and blobs by digest.
+type mockStore struct {
+	manifests map[digest.Digest][]byte
+	blobs     map[digest.Digest][]byte
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{
+		manifests: map[digest.Digest][]byte{},
+		blobs:     map[digest.Digest][]byte{},
+	}
+}
+
+func (s *mockStore) Resolve(_ context.Context, _ string) (ocispec.Descriptor, error) {
+	return ocispec.Descriptor{}, errors.New("not implemented")
+}
+
+func (s *mockStore) ListReferrers(_ context.Context, _ string, _ []string, _ func(referrers []ocispec.Descriptor) error) error {
+	return errors.New("not implemented")
+}
+
+func (s *mockStore) FetchBlob(_ context.Context, _ string, desc ocispec.Descriptor) ([]byte, error) {
+	blob, ok := s.blobs[desc.Digest]
+	if !ok {
+		return nil, errors.New("blob not found")
+	}
+	return blob, nil
+}
+
+func (s *mockStore) FetchManifest(_ context.Context, _ string, desc ocispec.Descriptor) ([]byte, error) {
+	manifest, ok := s.manifests[desc.Digest]
+	if !ok {
+		return nil, errors.New("manifest not found")
+	}
+	return manifest, nil
+}
+
+// add adds the JSON encoded value as a manifest or a blob and returns its
+// descriptor.
+func (s *mockStore) add(t *testing.T, mediaType string, v any, manifest bool) ocispec.Descriptor {
+	t.Helper()
+	content, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal content: %v", err)
+	}
+	desc := ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(content),
+		Size:      int64(len(content)),
+	}
+	if manifest {
+		s.manifests[desc.Digest] = content
+	} else {
+		s.blobs[desc.Digest] = content
+	}
+	return desc
+}
+
+// addImage adds an image with the manifest annotations and the config
+// creation time, if not zero, and returns its descriptor.
+func (s *mockStore) addImage(t *testing.T, annotations map[string]string, configCreated time.Time) ocispec.Descriptor {
+	t.Helper()
+	config := ocispec.Image{}
+	if !configCreated.IsZero() {
+		config.Created = &configCreated
+	}
+	return s.add(t, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		MediaType:   ocispec.MediaTypeImageManifest,
+		Config:      s.add(t, ocispec.MediaTypeImageConfig, config, false),
+		Layers:      []ocispec.Descriptor{},
+		Annotations: annotations,
+	}, true)
+}
+
+func TestFetchSubjectCreated(t *testing.T) {
+	annotated := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	configCreated := time.Date(2023, 1, 2, 3, 4, 5, 0, time.UTC)
+	store := newMockStore()
+	missingConfig := store.addImage(t, nil, configCreated.Add(time.Hour))
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(store.manifests[missingConfig.Digest], &manifest); err != nil {
+		t.Fatalf("failed to unmarshal manifest: %v", err)
+	}
+	delete(store.blobs, manifest.Config.Digest)
+	invalidConfig := store.add(t, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Config:    store.add(t, ocispec.MediaTypeImageConfig, "config", false),
+	}, true)
+
+	tests := []struct {
+		name         string
+		desc         ocispec.Descriptor
+		annotations  []string
+		expectErr    bool
+		expectTime   time.Time
+		expectSource string
+	}{
+		{
+			name:         "default annotation",
+			desc:         store.addImage(t, map[string]string{ocispec.AnnotationCreated: annotated.Format(time.RFC3339)}, configCreated),
+			annotations:  defaultAnnotations,
+			expectTime:   annotated,
+			expectSource: ocispec.AnnotationCreated,
+		},
+		{
+			name:         "custom annotation",
+			desc:         store.addImage(t, map[string]string{"build.created": annotated.Format(time.RFC3339)}, time.Time{}),
+			annotations:  []string{ocispec.AnnotationCreated, "build.created"},
+			expectTime:   annotated,
+			expectSource: "build.created",
+		},
+		{
+			name:         "config created",
+			desc:         store.addImage(t, nil, configCreated),
+			annotations:  defaultAnnotations,
+			expectTime:   configCreated,
+			expectSource: sourceConfig,
+		},
+		{
+			name:        "no creation time",
+			desc:        store.addImage(t, nil, time.Time{}),
+			annotations: defaultAnnotations,
+		},
+		{
+			name: "index without annotation",
+			desc: store.add(t, ocispec.MediaTypeImageIndex, ocispec.Index{
+				MediaType: ocispec.MediaTypeImageIndex,
+				Manifests: []ocispec.Descriptor{},
+			}, true),
+			annotations: defaultAnnotations,
+		},
+		{
+			name:        "invalid annotation",
+			desc:        store.addImage(t, map[string]string{ocispec.AnnotationCreated: "yesterday"}, configCreated),
+			annotations: defaultAnnotations,
+			expectErr:   true,
+		},
+		{
+			name:        "manifest not found",
+			desc:        ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString("missing")},
+			annotations: defaultAnnotations,
+			expectErr:   true,
+		},
+		{
+			name:        "invalid manifest",
+			desc:        store.add(t, ocispec.MediaTypeImageManifest, "manifest", true),
+			annotations: defaultAnnotations,
+			expectErr:   true,
+		},
+		{
+			name:        "config not found",
+			desc:        missingConfig,
+			annotations: defaultAnnotations,
+			expectErr:   true,
+		},
+		{
+			name:        "invalid config",
+			desc:        invalidConfig,
+			annotations: defaultAnnotations,
+			expectErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := fetchSubjectCreated(context.Background(), store, testRepo, test.desc, test.annotations)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if test.expectSource == "" {
+				if c != nil {
+					t.Fatalf("expected no creation time, got %+v", c)
+				}
+				return
+			}
+			if c == nil || !c.Time.Equal(test.expectTime) || c.Source != test.expectSource {
+				t.Errorf("expected creation time %s from %s, got %+v", test.expectTime, test.expectSource, c)
+			}
+		})
+	}
+}
+
+func TestFetchArtifactCreated(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+	annotations := map[string]string{ocispec.AnnotationCreated: created.Format(time.RFC3339)}
+	store := newMockStore()
+	withAnnotations := func(desc ocispec.Descriptor, annotations map[string]string) ocispec.Descriptor {
+		desc.Annotations = annotations
+		return desc
+	}
+	tampered := store.addImage(t, map[string]string{"tampered": "true"}, time.Time{})
+	store.manifests[tampered.Digest] = store.manifests[store.addImage(t, annotations, time.Time{}).Digest]
+
+	tests := []struct {
+		name       string
+		desc       ocispec.Descriptor
+		expectErr  bool
+		expectTime time.Time
+	}{
+		{
+			name:       "manifest annotation",
+			desc:       store.addImage(t, annotations, time.Time{}),
+			expectTime: created,
+		},
+		{
+			name:       "descriptor annotation overridden by manifest",
+			desc:       withAnnotations(store.addImage(t, annotations, time.Time{}), map[string]string{ocispec.AnnotationCreated: "2025-01-02T03:04:05Z"}),
+			expectTime: created,
+		},
+		{
+			name: "descriptor annotation ignored",
+			desc: withAnnotations(store.addImage(t, nil, time.Time{}), annotations),
+		},
+		{
+			name: "no creation time",
+			desc: store.addImage(t, nil, time.Time{}),
+		},
+		{
+			name:      "invalid manifest annotation",
+			desc:      store.addImage(t, map[string]string{ocispec.AnnotationCreated: "2024-01-02"}, time.Time{}),
+			expectErr: true,
+		},
+		{
+			name:      "invalid digest",
+			desc:      ocispec.Descriptor{Digest: "invalid"},
+			expectErr: true,
+		},
+		{
+			name:      "manifest not found",
+			desc:      ocispec.Descriptor{Digest: digest.FromString("missing")},
+			expectErr: true,
+		},
+		{
+			name:      "manifest digest mismatch",
+			desc:      tampered,
+			expectErr: true,
+		},
+		{
+			name:      "invalid manifest",
+			desc:      store.add(t, ocispec.MediaTypeImageManifest, "manifest", true),
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, err := fetchArtifactCreated(context.Background(), store, testRepo, test.desc, defaultAnnotations)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if test.expectTime.IsZero() {
+				if c != nil {
+					t.Fatalf("expected no creation time, got %+v", c)
+				}
+				return
+			}
+			if c == nil || !c.Time.Equal(test.expectTime) {
+				t.Errorf("expected creation time %s, got %+v", test.expectTime, c)
+			}
+		})
+	}
+}
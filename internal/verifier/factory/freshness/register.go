@@ -0,0 +1,125 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package freshness
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const freshnessType = "freshness"
+
+// defaultAnnotations are the annotations the creation time is read from by
+// default.
+var defaultAnnotations = []string{ocispec.AnnotationCreated}
+
+// options are the age rules of the subject and the verified artifacts. At
+// least one rule must be set. Durations are Go durations, e.g. "4320h" for 180
+// days.
+type options struct {
+	// ArtifactTypes are the artifact types the verifier verifies. "*" matches
+	// any artifact type but the subject referrer type. Add "application/vnd.ratify.subject.v1" to evaluate
+	// the subject rules for subjects without referrers. Required.
+	ArtifactTypes []string `json:"artifactTypes"`
+
+	// MaxSubjectAge is the maximum age of the subject by its creation time.
+	// Optional.
+	MaxSubjectAge string `json:"maxSubjectAge,omitempty"`
+
+	// MaxArtifactAge is the maximum age of the verified artifact by its
+	// creation time. Optional.
+	MaxArtifactAge string `json:"maxArtifactAge,omitempty"`
+
+	// NewerThanSubject requires the verified artifact, e.g. a signature, not
+	// to be created before the subject. Optional.
+	NewerThanSubject bool `json:"newerThanSubject,omitempty"`
+
+	// SubjectAnnotations are the annotations of the subject manifest or index
+	// the creation time is read from, in order of precedence. The "created"
+	// field of the image config is used if none is present. Optional.
+	// Defaults to "org.opencontainers.image.created".
+	SubjectAnnotations []string `json:"subjectAnnotations,omitempty"`
+
+	// ArtifactAnnotations are the annotations of the artifact manifest the
+	// creation time is read from, in order of precedence. Optional. Defaults
+	// to "org.opencontainers.image.created".
+	ArtifactAnnotations []string `json:"artifactAnnotations,omitempty"`
+}
+
+func init() {
+	factory.RegisterVerifierFactory(freshnessType, func(opts *factory.NewVerifierOptions) (ratify.Verifier, error) {
+		raw, err := json.Marshal(opts.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal verifier parameters: %w", err)
+		}
+
+		var params options
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal verifier parameters: %w", err)
+		}
+
+		if len(params.ArtifactTypes) == 0 {
+			return nil, errors.New("artifactTypes are required")
+		}
+		maxSubjectAge, err := parseAge("maxSubjectAge", params.MaxSubjectAge)
+		if err != nil {
+			return nil, err
+		}
+		maxArtifactAge, err := parseAge("maxArtifactAge", params.MaxArtifactAge)
+		if err != nil {
+			return nil, err
+		}
+		if maxSubjectAge == 0 && maxArtifactAge == 0 && !params.NewerThanSubject {
+			return nil, errors.New("at least one of maxSubjectAge, maxArtifactAge and newerThanSubject must be set")
+		}
+		subjectAnnotations := params.SubjectAnnotations
+		if len(subjectAnnotations) == 0 {
+			subjectAnnotations = defaultAnnotations
+		}
+		artifactAnnotations := params.ArtifactAnnotations
+		if len(artifactAnnotations) == 0 {
+			artifactAnnotations = defaultAnnotations
+		}
+
+		return &verifier{
+			name:                opts.Name,
+			artifactTypes:       params.ArtifactTypes,
+			maxSubjectAge:       maxSubjectAge,
+			maxArtifactAge:      maxArtifactAge,
+			newerThanSubject:    params.NewerThanSubject,
+			subjectAnnotations:  subjectAnnotations,
+			artifactAnnotations: artifactAnnotations,
+		}, nil
+	})
+}
+
+// parseAge parses the optional maximum age of the named option.
+func parseAge(name, value string) (time.Duration, error) {
+	if value == "" {
+		return 0, nil
+	}
+	age, err := time.ParseDuration(value)
+	if err != nil || age <= 0 {
+		return 0, fmt.Errorf("invalid %s %q", name, value)
+	}
+	return age, nil
+}
@@ -0,0 +1,83 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package freshness
+
+import (
+	"testing"
+
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+)
+
+func TestNewVerifier(t *testing.T) {
+	artifactTypes := []string{testArtifactType}
+	tests := []struct {
+		name      string
+		params    any
+		expectErr bool
+	}{
+		{
+			name:   "subject age",
+			params: map[string]any{"artifactTypes": artifactTypes, "maxSubjectAge": "4320h"},
+		},
+		{
+			name:   "all rules",
+			params: map[string]any{"artifactTypes": artifactTypes, "maxSubjectAge": "4320h", "maxArtifactAge": "24h", "newerThanSubject": true, "subjectAnnotations": []string{"created"}},
+		},
+		{
+			name:      "unmarshalable parameters",
+			params:    make(chan int),
+			expectErr: true,
+		},
+		{
+			name:      "invalid parameters",
+			params:    map[string]any{"artifactTypes": "*"},
+			expectErr: true,
+		},
+		{
+			name:      "missing artifact types",
+			params:    map[string]any{"maxSubjectAge": "4320h"},
+			expectErr: true,
+		},
+		{
+			name:      "no rules",
+			params:    map[string]any{"artifactTypes": artifactTypes},
+			expectErr: true,
+		},
+		{
+			name:      "invalid subject age",
+			params:    map[string]any{"artifactTypes": artifactTypes, "maxSubjectAge": "180d"},
+			expectErr: true,
+		},
+		{
+			name:      "non-positive artifact age",
+			params:    map[string]any{"artifactTypes": artifactTypes, "maxArtifactAge": "0s"},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := factory.NewVerifier(&factory.NewVerifierOptions{
+				Type:       freshnessType,
+				Name:       testName,
+				Parameters: test.params,
+			})
+			if (err != nil) != test.expectErr {
+				t.Errorf("expected error: %v, got: %v", test.expectErr, err)
+			}
+		})
+	}
+}
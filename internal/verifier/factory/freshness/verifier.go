@@ -0,0 +1,179 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package freshness
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/store/subjectreferrer"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	ruleMaxSubjectAge    = "maxSubjectAge"
+	ruleMaxArtifactAge   = "maxArtifactAge"
+	ruleNewerThanSubject = "newerThanSubject"
+)
+
+// ruleResult is the result of evaluating a rule.
+type ruleResult struct {
+	// Name is the name of the rule.
+	Name string `json:"name"`
+
+	// Satisfied is true if the rule is satisfied.
+	Satisfied bool `json:"satisfied"`
+
+	// Reason describes why the rule is not satisfied.
+	Reason string `json:"reason,omitempty"`
+}
+
+// verifier is a ratify.Verifier implementation that evaluates the age of the
+// subject and of the verified artifacts by their creation times. For the
+// subject referrer listed by [subjectreferrer.Store] only the subject rules
+// are evaluated.
+type verifier struct {
+	name                string
+	artifactTypes       []string
+	maxSubjectAge       time.Duration
+	maxArtifactAge      time.Duration
+	newerThanSubject    bool
+	subjectAnnotations  []string
+	artifactAnnotations []string
+}
+
+// Name returns the name of the verifier.
+func (v *verifier) Name() string {
+	return v.name
+}
+
+// Type returns the type of the verifier which is always `freshness`.
+func (v *verifier) Type() string {
+	return freshnessType
+}
+
+// Verifiable returns true if the artifact is of the configured artifact
+// types.
+func (v *verifier) Verifiable(artifact ocispec.Descriptor) bool {
+	return subjectreferrer.MatchArtifactType(v.artifactTypes, artifact.ArtifactType)
+}
+
+// Verify evaluates the configured rules and reports the computed ages.
+// Missing creation times fail the rules using them.
+func (v *verifier) Verify(ctx context.Context, opts *ratify.VerifyOptions) (*ratify.VerificationResult, error) {
+	result := &ratify.VerificationResult{
+		Verifier: v,
+	}
+	isSubject := opts.ArtifactDescriptor.ArtifactType == subjectreferrer.ArtifactType
+	checkArtifact := !isSubject && (v.maxArtifactAge > 0 || v.newerThanSubject)
+	checkSubject := v.maxSubjectAge > 0 || (!isSubject && v.newerThanSubject)
+
+	var subject, artifact *created
+	var err error
+	if checkSubject {
+		subject, err = fetchSubjectCreated(ctx, opts.Store, opts.Repository, opts.SubjectDescriptor, v.subjectAnnotations)
+	}
+	if err == nil && checkArtifact {
+		artifact, err = fetchArtifactCreated(ctx, opts.Store, opts.Repository, opts.ArtifactDescriptor, v.artifactAnnotations)
+	}
+	if err != nil {
+		if errors.Is(err, errInvalidTime) {
+			result.Err = err
+			return result, nil
+		}
+		return nil, err
+	}
+
+	now := time.Now()
+	var results []ruleResult
+	if v.maxSubjectAge > 0 {
+		results = append(results, checkAge(ruleMaxSubjectAge, "subject", subject, v.maxSubjectAge, now))
+	}
+	if !isSubject && v.maxArtifactAge > 0 {
+		results = append(results, checkAge(ruleMaxArtifactAge, "artifact", artifact, v.maxArtifactAge, now))
+	}
+	if !isSubject && v.newerThanSubject {
+		results = append(results, checkNewer(subject, artifact))
+	}
+
+	detail := map[string]any{
+		"Rules": results,
+	}
+	if subject != nil {
+		subject.Age = age(subject.Time, now)
+		detail["Subject"] = subject
+	}
+	if artifact != nil {
+		artifact.Age = age(artifact.Time, now)
+		detail["Artifact"] = artifact
+	}
+	result.Detail = detail
+
+	var unsatisfied []string
+	for _, r := range results {
+		if !r.Satisfied {
+			unsatisfied = append(unsatisfied, r.Name)
+		}
+	}
+	if len(unsatisfied) > 0 {
+		result.Err = fmt.Errorf("artifact %s does not satisfy freshness rules: %s", opts.ArtifactDescriptor.Digest, strings.Join(unsatisfied, ", "))
+		result.Description = "Freshness verification failed"
+		return result, nil
+	}
+	result.Description = "Freshness verification succeeded"
+	return result, nil
+}
+
+// checkAge checks that the named target is not older than the maximum age.
+func checkAge(rule, target string, c *created, maxAge time.Duration, now time.Time) ruleResult {
+	res := ruleResult{Name: rule}
+	switch {
+	case c == nil:
+		res.Reason = fmt.Sprintf("%s has no creation time", target)
+	case now.Sub(c.Time) > maxAge:
+		res.Reason = fmt.Sprintf("%s created at %s is older than the maximum age %s", target, c.Time.Format(time.RFC3339), maxAge)
+	default:
+		res.Satisfied = true
+	}
+	return res
+}
+
+// checkNewer checks that the artifact is not created before the subject.
+// Artifacts created at the same time are accepted as creation times usually
+// have a precision of seconds and signing often immediately follows a build.
+func checkNewer(subject, artifact *created) ruleResult {
+	res := ruleResult{Name: ruleNewerThanSubject}
+	switch {
+	case subject == nil:
+		res.Reason = "subject has no creation time"
+	case artifact == nil:
+		res.Reason = "artifact has no creation time"
+	case artifact.Time.Before(subject.Time):
+		res.Reason = fmt.Sprintf("artifact created at %s is older than the subject created at %s", artifact.Time.Format(time.RFC3339), subject.Time.Format(time.RFC3339))
+	default:
+		res.Satisfied = true
+	}
+	return res
+}
+
+// age returns the age of the creation time rounded to seconds.
+func age(t, now time.Time) string {
+	return now.Sub(t).Round(time.Second).String()
+}
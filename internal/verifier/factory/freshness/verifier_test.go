@@ -0,0 +1,239 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package freshness
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/store/subjectreferrer"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	testName         = "test-freshness"
+	testArtifactType = "application/vnd.cncf.notary.signature"
+)
+
+func newTestVerifier(t *testing.T, params map[string]any) ratify.Verifier {
+	t.Helper()
+	v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+		Type:       freshnessType,
+		Name:       testName,
+		Parameters: params,
+	})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+	return v
+}
+
+func TestVerifier(t *testing.T) {
+	v := newTestVerifier(t, map[string]any{
+		"artifactTypes": []string{testArtifactType},
+		"maxSubjectAge": "4320h",
+	})
+	if v.Name() != testName {
+		t.Errorf("expected name %s, got %s", testName, v.Name())
+	}
+	if v.Type() != freshnessType {
+		t.Errorf("expected type %s, got %s", freshnessType, v.Type())
+	}
+	if !v.Verifiable(ocispec.Descriptor{ArtifactType: testArtifactType}) {
+		t.Error("expected the configured artifact type to be verifiable")
+	}
+	if v.Verifiable(subjectreferrer.Descriptor("")) {
+		t.Error("expected the subject referrer not to be verifiable unless configured")
+	}
+	v = newTestVerifier(t, map[string]any{"artifactTypes": []string{"*"}, "maxSubjectAge": "4320h"})
+	if !v.Verifiable(ocispec.Descriptor{ArtifactType: "application/vnd.other"}) {
+		t.Error("expected any artifact type to be verifiable")
+	}
+	if v.Verifiable(subjectreferrer.Descriptor("")) {
+		t.Error("expected the subject referrer not to be verifiable for any artifact type")
+	}
+	v = newTestVerifier(t, map[string]any{"artifactTypes": []string{"*", subjectreferrer.ArtifactType}, "maxSubjectAge": "4320h"})
+	if !v.Verifiable(subjectreferrer.Descriptor("")) {
+		t.Error("expected the configured subject referrer to be verifiable")
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	now := time.Now()
+	daysAgo := func(days int) map[string]string {
+		return map[string]string{ocispec.AnnotationCreated: now.Add(-time.Duration(days) * 24 * time.Hour).Format(time.RFC3339)}
+	}
+	store := newMockStore()
+	subject := store.addImage(t, daysAgo(10), time.Time{})
+	oldSubject := store.addImage(t, daysAgo(200), time.Time{})
+	unknownSubject := store.addImage(t, nil, time.Time{})
+	invalidSubject := store.addImage(t, map[string]string{ocispec.AnnotationCreated: "invalid"}, time.Time{})
+	artifact := func(annotations map[string]string) ocispec.Descriptor {
+		desc := store.addImage(t, annotations, time.Time{})
+		desc.ArtifactType = testArtifactType
+		return desc
+	}
+
+	allRules := map[string]any{
+		"artifactTypes":    []string{testArtifactType, subjectreferrer.ArtifactType},
+		"maxSubjectAge":    "4320h",
+		"maxArtifactAge":   "168h",
+		"newerThanSubject": true,
+	}
+	tests := []struct {
+		name              string
+		params            map[string]any
+		subject           ocispec.Descriptor
+		artifact          ocispec.Descriptor
+		expectStoreErr    bool
+		expectErr         bool
+		expectUnsatisfied []string
+	}{
+		{
+			name:     "fresh signature of a fresh image",
+			params:   allRules,
+			subject:  subject,
+			artifact: artifact(daysAgo(1)),
+		},
+		{
+			name:              "old image",
+			params:            allRules,
+			subject:           oldSubject,
+			artifact:          artifact(daysAgo(1)),
+			expectErr:         true,
+			expectUnsatisfied: []string{ruleMaxSubjectAge},
+		},
+		{
+			name:              "old signature",
+			params:            allRules,
+			subject:           subject,
+			artifact:          artifact(daysAgo(9)),
+			expectErr:         true,
+			expectUnsatisfied: []string{ruleMaxArtifactAge},
+		},
+		{
+			name:     "signature created with image",
+			params:   map[string]any{"artifactTypes": []string{testArtifactType}, "newerThanSubject": true},
+			subject:  subject,
+			artifact: artifact(daysAgo(10)),
+		},
+		{
+			name:              "signature older than image",
+			params:            map[string]any{"artifactTypes": []string{testArtifactType}, "newerThanSubject": true},
+			subject:           subject,
+			artifact:          artifact(daysAgo(11)),
+			expectErr:         true,
+			expectUnsatisfied: []string{ruleNewerThanSubject},
+		},
+		{
+			name:              "unknown creation times",
+			params:            allRules,
+			subject:           unknownSubject,
+			artifact:          artifact(nil),
+			expectErr:         true,
+			expectUnsatisfied: []string{ruleMaxSubjectAge, ruleMaxArtifactAge, ruleNewerThanSubject},
+		},
+		{
+			name:              "unknown artifact creation time",
+			params:            map[string]any{"artifactTypes": []string{testArtifactType}, "newerThanSubject": true},
+			subject:           subject,
+			artifact:          artifact(nil),
+			expectErr:         true,
+			expectUnsatisfied: []string{ruleNewerThanSubject},
+		},
+		{
+			name:     "subject referrer evaluates subject rules only",
+			params:   allRules,
+			subject:  subject,
+			artifact: subjectreferrer.Descriptor(digest.FromString("subject")),
+		},
+		{
+			name:              "old subject of subject referrer",
+			params:            allRules,
+			subject:           oldSubject,
+			artifact:          subjectreferrer.Descriptor(digest.FromString("subject")),
+			expectErr:         true,
+			expectUnsatisfied: []string{ruleMaxSubjectAge},
+		},
+		{
+			name:      "invalid creation time",
+			params:    allRules,
+			subject:   invalidSubject,
+			artifact:  artifact(daysAgo(1)),
+			expectErr: true,
+		},
+		{
+			name:           "subject not found",
+			params:         allRules,
+			subject:        ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString("missing")},
+			artifact:       artifact(daysAgo(1)),
+			expectStoreErr: true,
+		},
+		{
+			name:           "artifact not found",
+			params:         allRules,
+			subject:        subject,
+			artifact:       ocispec.Descriptor{ArtifactType: testArtifactType, Digest: digest.FromString("missing")},
+			expectStoreErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := newTestVerifier(t, test.params)
+			result, err := v.Verify(context.Background(), &ratify.VerifyOptions{
+				Store:              store,
+				Repository:         testRepo,
+				SubjectDescriptor:  test.subject,
+				ArtifactDescriptor: test.artifact,
+			})
+			if (err != nil) != test.expectStoreErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectStoreErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if (result.Err != nil) != test.expectErr {
+				t.Fatalf("expected verification error: %v, got: %v", test.expectErr, result.Err)
+			}
+			if result.Detail == nil {
+				return
+			}
+			detail := result.Detail.(map[string]any)
+			var unsatisfied []string
+			for _, r := range detail["Rules"].([]ruleResult) {
+				if !r.Satisfied {
+					unsatisfied = append(unsatisfied, r.Name)
+				}
+			}
+			if len(unsatisfied) != len(test.expectUnsatisfied) {
+				t.Fatalf("expected unsatisfied rules %v, got %+v", test.expectUnsatisfied, detail["Rules"])
+			}
+			for i, name := range unsatisfied {
+				if name != test.expectUnsatisfied[i] {
+					t.Errorf("expected unsatisfied rules %v, got %v", test.expectUnsatisfied, unsatisfied)
+				}
+			}
+			if s, ok := detail["Subject"].(*created); ok && s.Age == "" {
+				t.Error("expected the subject age to be reported")
+			}
+		})
+	}
+}
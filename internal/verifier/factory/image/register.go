@@ -0,0 +1,78 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+)
+
+const imageType = "image"
+
+// options are the declarative rules the subject must satisfy. At least one
+// rule must be set.
+type options struct {
+	// NonRoot requires the images not to run as root. Optional.
+	NonRoot bool `json:"nonRoot,omitempty"`
+
+	// Labels maps the config labels the images must carry to regular
+	// expressions their values must fully match. An empty expression only
+	// requires the label to be present. Optional.
+	Labels map[string]string `json:"labels,omitempty"`
+
+	// Annotations maps the annotations the subject manifest or index must
+	// carry to regular expressions their values must fully match. An empty
+	// expression only requires the annotation to be present. Optional.
+	Annotations map[string]string `json:"annotations,omitempty"`
+
+	// BaseImages is the allow-list of base image digests. The base image
+	// digest of each image is read from the
+	// "org.opencontainers.image.base.digest" annotation of its manifest or
+	// of the index. Optional.
+	BaseImages []string `json:"baseImages,omitempty"`
+
+	// Platforms are the platforms the subject must be built for, e.g.
+	// "linux/amd64". A platform without variant matches any variant.
+	// Optional.
+	Platforms []string `json:"platforms,omitempty"`
+}
+
+func init() {
+	factory.RegisterVerifierFactory(imageType, func(opts *factory.NewVerifierOptions) (ratify.Verifier, error) {
+		raw, err := json.Marshal(opts.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal verifier parameters: %w", err)
+		}
+
+		var params options
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal verifier parameters: %w", err)
+		}
+
+		rules, err := newRules(params)
+		if err != nil {
+			return nil, err
+		}
+
+		return &verifier{
+			name:  opts.Name,
+			rules: rules,
+		}, nil
+	})
+}
@@ -0,0 +1,213 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+const (
+	// annotationBaseDigest is the OCI annotation holding the digest of the
+	// base image.
+	annotationBaseDigest = "org.opencontainers.image.base.digest"
+
+	ruleNonRoot     = "nonRoot"
+	ruleLabels      = "labels"
+	ruleAnnotations = "annotations"
+	ruleBaseImages  = "baseImages"
+	rulePlatforms   = "platforms"
+)
+
+// ruleResult is the result of evaluating a rule.
+type ruleResult struct {
+	// Name is the name of the rule.
+	Name string `json:"name"`
+
+	// Satisfied is true if the subject satisfies the rule.
+	Satisfied bool `json:"satisfied"`
+
+	// Violations describe why the rule is not satisfied.
+	Violations []string `json:"violations,omitempty"`
+}
+
+// rule evaluates the subject and returns the violations of the rule.
+type rule struct {
+	name  string
+	check func(s *subject) []string
+}
+
+// evaluate evaluates the rule against the subject.
+func (r rule) evaluate(s *subject) ruleResult {
+	violations := r.check(s)
+	return ruleResult{
+		Name:       r.name,
+		Satisfied:  len(violations) == 0,
+		Violations: violations,
+	}
+}
+
+// newRules creates the rules from the options.
+func newRules(opts options) ([]rule, error) {
+	var rules []rule
+	if opts.NonRoot {
+		rules = append(rules, rule{name: ruleNonRoot, check: checkNonRoot})
+	}
+	if len(opts.Labels) > 0 {
+		patterns, err := compilePatterns(opts.Labels)
+		if err != nil {
+			return nil, fmt.Errorf("invalid labels: %w", err)
+		}
+		rules = append(rules, rule{name: ruleLabels, check: func(s *subject) []string {
+			var violations []string
+			for _, img := range s.images {
+				for _, v := range checkPatterns("label", patterns, img.config.Config.Labels) {
+					violations = append(violations, fmt.Sprintf("image %s: %s", img.digest, v))
+				}
+			}
+			return violations
+		}})
+	}
+	if len(opts.Annotations) > 0 {
+		patterns, err := compilePatterns(opts.Annotations)
+		if err != nil {
+			return nil, fmt.Errorf("invalid annotations: %w", err)
+		}
+		rules = append(rules, rule{name: ruleAnnotations, check: func(s *subject) []string {
+			return checkPatterns("annotation", patterns, s.annotations)
+		}})
+	}
+	if len(opts.BaseImages) > 0 {
+		allowed := opts.BaseImages
+		rules = append(rules, rule{name: ruleBaseImages, check: func(s *subject) []string {
+			return checkBaseImages(s, allowed)
+		}})
+	}
+	if len(opts.Platforms) > 0 {
+		for _, platform := range opts.Platforms {
+			if parts := strings.Split(platform, "/"); len(parts) < 2 || len(parts) > 3 || parts[0] == "" || parts[1] == "" {
+				return nil, fmt.Errorf("invalid platform %q, expected os/architecture[/variant]", platform)
+			}
+		}
+		required := opts.Platforms
+		rules = append(rules, rule{name: rulePlatforms, check: func(s *subject) []string {
+			return checkPlatforms(s, required)
+		}})
+	}
+	if len(rules) == 0 {
+		return nil, errors.New("at least one rule is required")
+	}
+	return rules, nil
+}
+
+// checkNonRoot reports the images running as root. Images without a user run
+// as root.
+func checkNonRoot(s *subject) []string {
+	var violations []string
+	for _, img := range s.images {
+		user, _, _ := strings.Cut(img.config.Config.User, ":")
+		if user == "" || user == "root" || user == "0" {
+			violations = append(violations, fmt.Sprintf("image %s runs as root", img.digest))
+		}
+	}
+	return violations
+}
+
+// compilePatterns compiles the value patterns keyed by name.
+func compilePatterns(patterns map[string]string) (map[string]*regexp.Regexp, error) {
+	compiled := make(map[string]*regexp.Regexp, len(patterns))
+	for key, pattern := range patterns {
+		if pattern == "" {
+			compiled[key] = nil
+			continue
+		}
+		re, err := regexp.Compile("^(?:" + pattern + ")$")
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for %s: %w", key, err)
+		}
+		compiled[key] = re
+	}
+	return compiled, nil
+}
+
+// checkPatterns reports the missing keys and the values not matching their
+// patterns.
+func checkPatterns(kind string, patterns map[string]*regexp.Regexp, values map[string]string) []string {
+	keys := make([]string, 0, len(patterns))
+	for key := range patterns {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var violations []string
+	for _, key := range keys {
+		value, ok := values[key]
+		if !ok {
+			violations = append(violations, fmt.Sprintf("%s %s is missing", kind, key))
+			continue
+		}
+		if re := patterns[key]; re != nil && !re.MatchString(value) {
+			violations = append(violations, fmt.Sprintf("%s %s value %q does not match %s", kind, key, value, re))
+		}
+	}
+	return violations
+}
+
+// checkBaseImages reports the images whose base image is missing or not
+// allowed.
+func checkBaseImages(s *subject, allowed []string) []string {
+	var violations []string
+	for _, img := range s.images {
+		base, ok := img.annotations[annotationBaseDigest]
+		if !ok {
+			base, ok = s.annotations[annotationBaseDigest]
+		}
+		switch {
+		case !ok:
+			violations = append(violations, fmt.Sprintf("image %s has no base image digest", img.digest))
+		case !slices.Contains(allowed, base):
+			violations = append(violations, fmt.Sprintf("image %s has base image %s not in the allow-list", img.digest, base))
+		}
+	}
+	return violations
+}
+
+// checkPlatforms reports the required platforms the subject is not built
+// for.
+func checkPlatforms(s *subject, required []string) []string {
+	var violations []string
+	for _, platform := range required {
+		if !slices.ContainsFunc(s.images, func(img image) bool {
+			return matchPlatform(platform, img.platform)
+		}) {
+			violations = append(violations, fmt.Sprintf("platform %s is missing", platform))
+		}
+	}
+	return violations
+}
+
+// matchPlatform returns true if the platform matches the required platform.
+// A required platform without variant matches any variant.
+func matchPlatform(required, platform string) bool {
+	if required == platform {
+		return true
+	}
+	return strings.Count(required, "/") == 1 && strings.HasPrefix(platform, required+"/")
+}
@@ -0,0 +1,178 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func newTestImage(platform, user string, labels, annotations map[string]string) image {
+	return image{
+		digest:      digest.FromString(platform),
+		platform:    platform,
+		annotations: annotations,
+		config: &ocispec.Image{
+			Config: ocispec.ImageConfig{User: user, Labels: labels},
+		},
+	}
+}
+
+func TestNewRules(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      options
+		expectErr bool
+		expect    []string
+	}{
+		{
+			name:      "no rules",
+			expectErr: true,
+		},
+		{
+			name:      "invalid label pattern",
+			opts:      options{Labels: map[string]string{"a": "("}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid annotation pattern",
+			opts:      options{Annotations: map[string]string{"a": "("}},
+			expectErr: true,
+		},
+		{
+			name:      "invalid platform",
+			opts:      options{Platforms: []string{"linux/amd64/v1/extra"}},
+			expectErr: true,
+		},
+		{
+			name: "all rules",
+			opts: options{
+				NonRoot:     true,
+				Labels:      map[string]string{"a": ""},
+				Annotations: map[string]string{"b": ""},
+				BaseImages:  []string{testBaseDigest},
+				Platforms:   []string{"linux/amd64"},
+			},
+			expect: []string{ruleNonRoot, ruleLabels, ruleAnnotations, ruleBaseImages, rulePlatforms},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rules, err := newRules(test.opts)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			var names []string
+			for _, r := range rules {
+				names = append(names, r.name)
+			}
+			if !reflect.DeepEqual(names, test.expect) {
+				t.Errorf("expected rules %v, got %v", test.expect, names)
+			}
+		})
+	}
+}
+
+func TestRules_Evaluate(t *testing.T) {
+	tests := []struct {
+		name             string
+		opts             options
+		subject          *subject
+		expectViolations int
+	}{
+		{
+			name: "non-root user",
+			opts: options{NonRoot: true},
+			subject: &subject{images: []image{
+				newTestImage("linux/amd64", "1000:1000", nil, nil),
+				newTestImage("linux/arm64", "app", nil, nil),
+			}},
+		},
+		{
+			name: "root users",
+			opts: options{NonRoot: true},
+			subject: &subject{images: []image{
+				newTestImage("linux/amd64", "", nil, nil),
+				newTestImage("linux/arm64", "0:0", nil, nil),
+				newTestImage("linux/386", "root", nil, nil),
+			}},
+			expectViolations: 3,
+		},
+		{
+			name: "labels",
+			opts: options{Labels: map[string]string{"source": "https://.*", "vendor": ""}},
+			subject: &subject{images: []image{
+				newTestImage("linux/amd64", "", map[string]string{"source": "https://example.com", "vendor": "x"}, nil),
+				newTestImage("linux/arm64", "", map[string]string{"source": "http://example.com"}, nil),
+			}},
+			expectViolations: 2,
+		},
+		{
+			name:             "annotations",
+			opts:             options{Annotations: map[string]string{"source": "https://.*", "vendor": ""}},
+			subject:          &subject{annotations: map[string]string{"source": "x https://example.com"}},
+			expectViolations: 2,
+		},
+		{
+			name: "base images",
+			opts: options{BaseImages: []string{testBaseDigest}},
+			subject: &subject{
+				annotations: map[string]string{annotationBaseDigest: testBaseDigest},
+				images: []image{
+					newTestImage("linux/amd64", "", nil, nil),
+					newTestImage("linux/arm64", "", nil, map[string]string{annotationBaseDigest: "sha256:other"}),
+				},
+			},
+			expectViolations: 1,
+		},
+		{
+			name:             "missing base image",
+			opts:             options{BaseImages: []string{testBaseDigest}},
+			subject:          &subject{images: []image{newTestImage("linux/amd64", "", nil, nil)}},
+			expectViolations: 1,
+		},
+		{
+			name: "platforms",
+			opts: options{Platforms: []string{"linux/amd64", "linux/arm64", "linux/arm/v7", "windows/amd64"}},
+			subject: &subject{images: []image{
+				newTestImage("linux/amd64", "", nil, nil),
+				newTestImage("linux/arm64/v8", "", nil, nil),
+				newTestImage("linux/arm/v6", "", nil, nil),
+			}},
+			expectViolations: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			rules, err := newRules(test.opts)
+			if err != nil {
+				t.Fatalf("failed to create rules: %v", err)
+			}
+			result := rules[0].evaluate(test.subject)
+			if len(result.Violations) != test.expectViolations {
+				t.Errorf("expected %d violations, got %v", test.expectViolations, result.Violations)
+			}
+			if result.Satisfied != (test.expectViolations == 0) {
+				t.Errorf("expected satisfied: %v, got: %v", test.expectViolations == 0, result.Satisfied)
+			}
+		})
+	}
+}
@@ -0,0 +1,138 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+
+	// annotationReferenceType marks the attestation manifests that BuildKit
+	// adds to image indexes.
+	annotationReferenceType = "vnd.docker.reference.type"
+)
+
+// errUnsupportedMediaType is returned for subjects that are neither image
+// manifests nor image indexes.
+var errUnsupportedMediaType = errors.New("unsupported subject media type")
+
+// image is a single-platform image of the subject.
+type image struct {
+	digest      digest.Digest
+	platform    string
+	annotations map[string]string
+	config      *ocispec.Image
+}
+
+// subject is the evaluated subject: a single image or the images of an
+// index.
+type subject struct {
+	// annotations are the annotations of the subject manifest or index.
+	annotations map[string]string
+
+	// images are the images of the subject. For an index, these are the
+	// images of all platforms.
+	images []image
+}
+
+// fetchSubject fetches the manifests and configs of the subject.
+func fetchSubject(ctx context.Context, store ratify.Store, repo string, desc ocispec.Descriptor) (*subject, error) {
+	switch desc.MediaType {
+	case ocispec.MediaTypeImageManifest, mediaTypeDockerManifest:
+		img, err := fetchImage(ctx, store, repo, desc)
+		if err != nil {
+			return nil, err
+		}
+		return &subject{
+			annotations: img.annotations,
+			images:      []image{*img},
+		}, nil
+	case ocispec.MediaTypeImageIndex, mediaTypeDockerManifestList:
+		content, err := store.FetchManifest(ctx, repo, desc)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch index %s: %w", desc.Digest, err)
+		}
+		var index ocispec.Index
+		if err := json.Unmarshal(content, &index); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal index %s: %w", desc.Digest, err)
+		}
+		s := &subject{annotations: index.Annotations}
+		for _, manifest := range index.Manifests {
+			if _, ok := manifest.Annotations[annotationReferenceType]; ok {
+				continue
+			}
+			if manifest.MediaType != ocispec.MediaTypeImageManifest && manifest.MediaType != mediaTypeDockerManifest {
+				continue
+			}
+			img, err := fetchImage(ctx, store, repo, manifest)
+			if err != nil {
+				return nil, err
+			}
+			if manifest.Platform != nil {
+				img.platform = formatPlatform(manifest.Platform.OS, manifest.Platform.Architecture, manifest.Platform.Variant)
+			}
+			s.images = append(s.images, *img)
+		}
+		return s, nil
+	default:
+		return nil, fmt.Errorf("%w %s", errUnsupportedMediaType, desc.MediaType)
+	}
+}
+
+// fetchImage fetches the manifest and config of a single-platform image.
+func fetchImage(ctx context.Context, store ratify.Store, repo string, desc ocispec.Descriptor) (*image, error) {
+	content, err := store.FetchManifest(ctx, repo, desc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch manifest %s: %w", desc.Digest, err)
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(content, &manifest); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal manifest %s: %w", desc.Digest, err)
+	}
+	content, err = store.FetchBlob(ctx, repo, manifest.Config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch config %s: %w", manifest.Config.Digest, err)
+	}
+	var config ocispec.Image
+	if err := json.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal config %s: %w", manifest.Config.Digest, err)
+	}
+	return &image{
+		digest:      desc.Digest,
+		platform:    formatPlatform(config.OS, config.Architecture, config.Variant),
+		annotations: manifest.Annotations,
+		config:      &config,
+	}, nil
+}
+
+// formatPlatform formats a platform as "os/architecture[/variant]".
+func formatPlatform(os, arch, variant string) string {
+	platform := os + "/" + arch
+	if variant != "" {
+		platform += "/" + variant
+	}
+	return platform
+}
@@ -0,0 +1,140 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"context"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestFetchSubject(t *testing.T) {
+	tests := []struct {
+		name            string
+		setup           func(store *mockStore) ocispec.Descriptor
+		expectErr       bool
+		expectPlatforms []string
+	}{
+		{
+			name: "image manifest",
+			setup: func(store *mockStore) ocispec.Descriptor {
+				config := newConfig("linux", "arm64", "", nil)
+				config.Variant = "v8"
+				return store.addImage(t, config, nil)
+			},
+			expectPlatforms: []string{"linux/arm64/v8"},
+		},
+		{
+			name: "docker manifest",
+			setup: func(store *mockStore) ocispec.Descriptor {
+				desc := store.addImage(t, newConfig("linux", "amd64", "", nil), nil)
+				desc.MediaType = mediaTypeDockerManifest
+				return desc
+			},
+			expectPlatforms: []string{"linux/amd64"},
+		},
+		{
+			name: "index skips attestation manifests and nested indexes",
+			setup: func(store *mockStore) ocispec.Descriptor {
+				attestation := withPlatform(store.addImage(t, ocispec.Image{}, nil), "unknown", "unknown")
+				attestation.Annotations = map[string]string{annotationReferenceType: "attestation-manifest"}
+				return store.addIndex(t, nil,
+					withPlatform(store.addImage(t, newConfig("linux", "amd64", "", nil), nil), "linux", "amd64"),
+					attestation,
+					store.addIndex(t, nil))
+			},
+			expectPlatforms: []string{"linux/amd64"},
+		},
+		{
+			name: "docker manifest list",
+			setup: func(store *mockStore) ocispec.Descriptor {
+				desc := store.addIndex(t, nil, store.addImage(t, newConfig("windows", "amd64", "", nil), nil))
+				desc.MediaType = mediaTypeDockerManifestList
+				return desc
+			},
+			expectPlatforms: []string{"windows/amd64"},
+		},
+		{
+			name: "index not found",
+			setup: func(_ *mockStore) ocispec.Descriptor {
+				return ocispec.Descriptor{MediaType: ocispec.MediaTypeImageIndex, Digest: digest.FromString("missing")}
+			},
+			expectErr: true,
+		},
+		{
+			name: "malformed index",
+			setup: func(store *mockStore) ocispec.Descriptor {
+				return store.addRaw(ocispec.MediaTypeImageIndex, []byte("{"))
+			},
+			expectErr: true,
+		},
+		{
+			name: "index with missing image",
+			setup: func(store *mockStore) ocispec.Descriptor {
+				return store.addIndex(t, nil, ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString("missing")})
+			},
+			expectErr: true,
+		},
+		{
+			name: "malformed manifest",
+			setup: func(store *mockStore) ocispec.Descriptor {
+				return store.addRaw(ocispec.MediaTypeImageManifest, []byte("{"))
+			},
+			expectErr: true,
+		},
+		{
+			name: "config not found",
+			setup: func(store *mockStore) ocispec.Descriptor {
+				return store.add(t, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+					Config: ocispec.Descriptor{MediaType: ocispec.MediaTypeImageConfig, Digest: digest.FromString("missing")},
+				})
+			},
+			expectErr: true,
+		},
+		{
+			name: "malformed config",
+			setup: func(store *mockStore) ocispec.Descriptor {
+				return store.add(t, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+					Config: store.addRaw(ocispec.MediaTypeImageConfig, []byte("{")),
+				})
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := newMockStore()
+			s, err := fetchSubject(context.Background(), store, testRepo, test.setup(store))
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if len(s.images) != len(test.expectPlatforms) {
+				t.Fatalf("expected %d images, got %d", len(test.expectPlatforms), len(s.images))
+			}
+			for i, img := range s.images {
+				if img.platform != test.expectPlatforms[i] {
+					t.Errorf("expected platform %s, got %s", test.expectPlatforms[i], img.platform)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,87 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/store/subjectreferrer"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// verifier is a ratify.Verifier implementation that evaluates the manifest,
+// config and index of the subject image against declarative rules. It
+// verifies the subject referrer listed by [subjectreferrer.Store] instead of
+// the referrers of the subject.
+type verifier struct {
+	name  string
+	rules []rule
+}
+
+// Name returns the name of the verifier.
+func (v *verifier) Name() string {
+	return v.name
+}
+
+// Type returns the type of the verifier which is always `image`.
+func (v *verifier) Type() string {
+	return imageType
+}
+
+// Verifiable returns true if the artifact is the subject referrer.
+func (v *verifier) Verifiable(artifact ocispec.Descriptor) bool {
+	return artifact.ArtifactType == subjectreferrer.ArtifactType
+}
+
+// Verify evaluates all rules against the subject.
+func (v *verifier) Verify(ctx context.Context, opts *ratify.VerifyOptions) (*ratify.VerificationResult, error) {
+	result := &ratify.VerificationResult{
+		Verifier: v,
+	}
+	s, err := fetchSubject(ctx, opts.Store, opts.Repository, opts.SubjectDescriptor)
+	if err != nil {
+		if errors.Is(err, errUnsupportedMediaType) {
+			result.Err = err
+			return result, nil
+		}
+		return nil, err
+	}
+
+	results := make([]ruleResult, 0, len(v.rules))
+	var unsatisfied []string
+	for _, r := range v.rules {
+		res := r.evaluate(s)
+		if !res.Satisfied {
+			unsatisfied = append(unsatisfied, res.Name)
+		}
+		results = append(results, res)
+	}
+
+	result.Detail = map[string]any{
+		"Rules": results,
+	}
+	if len(unsatisfied) > 0 {
+		result.Err = fmt.Errorf("image %s does not satisfy rules: %s", opts.SubjectDescriptor.Digest, strings.Join(unsatisfied, ", "))
+		result.Description = "Image verification failed"
+		return result, nil
+	}
+	result.Description = "Image verification succeeded"
+	return result, nil
+}
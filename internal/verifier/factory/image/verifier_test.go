@@ -0,0 +1,269 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package image
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/store/subjectreferrer"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	testName = "image-test"
+	testRepo = "registry.test/repo"
+)
+
+var testBaseDigest = digest.FromString("base").String()
+
+// mockStore serves manifests and blobs by digest.
+type mockStore struct {
+	contents map[digest.Digest][]byte
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{contents: map[digest.Digest][]byte{}}
+}
+
+func (s *mockStore) Resolve(_ context.Context, _ string) (ocispec.Descriptor, error) {
+	return ocispec.Descriptor{}, errors.New("not implemented")
+}
+
+func (s *mockStore) ListReferrers(_ context.Context, _ string, _ []string, _ func(referrers []ocispec.Descriptor) error) error {
+	return errors.New("not implemented")
+}
+
+func (s *mockStore) FetchBlob(_ context.Context, _ string, desc ocispec.Descriptor) ([]byte, error) {
+	content, ok := s.contents[desc.Digest]
+	if !ok {
+		return nil, errors.New("blob not found")
+	}
+	return content, nil
+}
+
+func (s *mockStore) FetchManifest(_ context.Context, _ string, desc ocispec.Descriptor) ([]byte, error) {
+	content, ok := s.contents[desc.Digest]
+	if !ok {
+		return nil, errors.New("manifest not found")
+	}
+	return content, nil
+}
+
+// add stores the JSON encoding of v and returns its descriptor.
+func (s *mockStore) add(t *testing.T, mediaType string, v any) ocispec.Descriptor {
+	t.Helper()
+	content, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal content: %v", err)
+	}
+	return s.addRaw(mediaType, content)
+}
+
+func (s *mockStore) addRaw(mediaType string, content []byte) ocispec.Descriptor {
+	desc := ocispec.Descriptor{
+		MediaType: mediaType,
+		Digest:    digest.FromBytes(content),
+		Size:      int64(len(content)),
+	}
+	s.contents[desc.Digest] = content
+	return desc
+}
+
+// addImage stores an image of the config with the manifest annotations.
+func (s *mockStore) addImage(t *testing.T, config ocispec.Image, annotations map[string]string) ocispec.Descriptor {
+	t.Helper()
+	return s.add(t, ocispec.MediaTypeImageManifest, ocispec.Manifest{
+		MediaType:   ocispec.MediaTypeImageManifest,
+		Config:      s.add(t, ocispec.MediaTypeImageConfig, config),
+		Layers:      []ocispec.Descriptor{},
+		Annotations: annotations,
+	})
+}
+
+// addIndex stores an index of the images with their platforms.
+func (s *mockStore) addIndex(t *testing.T, annotations map[string]string, manifests ...ocispec.Descriptor) ocispec.Descriptor {
+	t.Helper()
+	return s.add(t, ocispec.MediaTypeImageIndex, ocispec.Index{
+		MediaType:   ocispec.MediaTypeImageIndex,
+		Manifests:   manifests,
+		Annotations: annotations,
+	})
+}
+
+func newConfig(os, arch, user string, labels map[string]string) ocispec.Image {
+	return ocispec.Image{
+		Platform: ocispec.Platform{OS: os, Architecture: arch},
+		Config: ocispec.ImageConfig{
+			User:   user,
+			Labels: labels,
+		},
+	}
+}
+
+func withPlatform(desc ocispec.Descriptor, os, arch string) ocispec.Descriptor {
+	desc.Platform = &ocispec.Platform{OS: os, Architecture: arch}
+	return desc
+}
+
+func newVerifyOptions(store *mockStore, subject ocispec.Descriptor) *ratify.VerifyOptions {
+	return &ratify.VerifyOptions{
+		Store:              store,
+		Repository:         testRepo,
+		SubjectDescriptor:  subject,
+		ArtifactDescriptor: subjectreferrer.Descriptor(digest.FromString("referrer")),
+	}
+}
+
+func newTestVerifier(t *testing.T) ratify.Verifier {
+	t.Helper()
+	v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+		Name: testName,
+		Type: imageType,
+		Parameters: map[string]any{
+			"nonRoot":     true,
+			"labels":      map[string]string{"org.opencontainers.image.source": "https://github.com/.*"},
+			"annotations": map[string]string{"org.opencontainers.image.source": ""},
+			"baseImages":  []string{testBaseDigest},
+			"platforms":   []string{"linux/amd64", "linux/arm64"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+	return v
+}
+
+func TestVerifier_Verifiable(t *testing.T) {
+	v := newTestVerifier(t)
+	tests := []struct {
+		desc   ocispec.Descriptor
+		expect bool
+	}{
+		{subjectreferrer.Descriptor(digest.FromString("referrer")), true},
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: "application/spdx+json"}, false},
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest}, false},
+	}
+	for _, test := range tests {
+		if got := v.Verifiable(test.desc); got != test.expect {
+			t.Errorf("expected Verifiable(%s, %s) to be %v, got %v", test.desc.MediaType, test.desc.ArtifactType, test.expect, got)
+		}
+	}
+	if v.Name() != testName {
+		t.Errorf("expected name %s, got %s", testName, v.Name())
+	}
+	if v.Type() != imageType {
+		t.Errorf("expected type %s, got %s", imageType, v.Type())
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	labels := map[string]string{"org.opencontainers.image.source": "https://github.com/ratify-project/ratify"}
+	annotations := map[string]string{
+		"org.opencontainers.image.source": "https://github.com/ratify-project/ratify",
+		annotationBaseDigest:              testBaseDigest,
+	}
+
+	tests := []struct {
+		name              string
+		setup             func(store *mockStore) ocispec.Descriptor
+		expectErr         bool
+		expectStoreErr    bool
+		expectUnsatisfied []string
+	}{
+		{
+			name: "compliant index",
+			setup: func(store *mockStore) ocispec.Descriptor {
+				return store.addIndex(t, annotations,
+					withPlatform(store.addImage(t, newConfig("linux", "amd64", "1000", labels), nil), "linux", "amd64"),
+					withPlatform(store.addImage(t, newConfig("linux", "arm64", "1000", labels), nil), "linux", "arm64"))
+			},
+		},
+		{
+			name: "non-compliant index",
+			setup: func(store *mockStore) ocispec.Descriptor {
+				return store.addIndex(t, nil,
+					withPlatform(store.addImage(t, newConfig("linux", "amd64", "root", nil), nil), "linux", "amd64"))
+			},
+			expectErr:         true,
+			expectUnsatisfied: []string{ruleNonRoot, ruleLabels, ruleAnnotations, ruleBaseImages, rulePlatforms},
+		},
+		{
+			name: "single platform image",
+			setup: func(store *mockStore) ocispec.Descriptor {
+				return store.addImage(t, newConfig("linux", "amd64", "1000", labels), annotations)
+			},
+			expectErr:         true,
+			expectUnsatisfied: []string{rulePlatforms},
+		},
+		{
+			name: "unsupported media type",
+			setup: func(store *mockStore) ocispec.Descriptor {
+				return store.addRaw("application/octet-stream", []byte("blob"))
+			},
+			expectErr: true,
+		},
+		{
+			name: "subject not found",
+			setup: func(_ *mockStore) ocispec.Descriptor {
+				return ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString("missing")}
+			},
+			expectStoreErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := newTestVerifier(t)
+			store := newMockStore()
+			result, err := v.Verify(context.Background(), newVerifyOptions(store, test.setup(store)))
+			if test.expectStoreErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (result.Err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, result.Err)
+			}
+			if result.Detail == nil {
+				return
+			}
+			var unsatisfied []string
+			for _, r := range result.Detail.(map[string]any)["Rules"].([]ruleResult) {
+				if !r.Satisfied {
+					unsatisfied = append(unsatisfied, r.Name)
+				}
+			}
+			if len(unsatisfied) != len(test.expectUnsatisfied) {
+				t.Fatalf("expected unsatisfied rules %v, got %v", test.expectUnsatisfied, unsatisfied)
+			}
+			for i := range unsatisfied {
+				if unsatisfied[i] != test.expectUnsatisfied[i] {
+					t.Errorf("expected unsatisfied rules %v, got %v", test.expectUnsatisfied, unsatisfied)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notation
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/notaryproject/notation-go/dir"
+	"github.com/notaryproject/notation-go/plugin"
+	pluginframework "github.com/notaryproject/notation-plugin-framework-go/plugin"
+)
+
+const defaultPluginTimeout = 10 * time.Second
+
+// pluginOptions configures the Notation verification plugins executed for
+// signatures that require a plugin, e.g. signatures produced by KMS-backed
+// signing schemes.
+type pluginOptions struct {
+	// Directory is the directory of installed plugins. Each plugin is expected
+	// at "<directory>/<name>/notation-<name>" as installed by the Notation CLI.
+	// Required.
+	Directory string `json:"directory"`
+
+	// Timeout is the maximum duration of each plugin execution, e.g. "5s".
+	// Plugins exceeding the timeout are killed and fail the verification.
+	// Optional. Defaults to 10 seconds.
+	Timeout string `json:"timeout,omitempty"`
+
+	// Config is passed to the plugins as the plugin config of each request.
+	// Optional.
+	Config map[string]string `json:"config,omitempty"`
+}
+
+// newPluginManager creates the plugin manager configured by the options, or
+// nil if plugins are not configured.
+func newPluginManager(opts *pluginOptions) (plugin.Manager, error) {
+	if opts == nil {
+		return nil, nil
+	}
+	if opts.Directory == "" {
+		return nil, fmt.Errorf("plugin directory is required")
+	}
+	fi, err := os.Stat(opts.Directory)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access plugin directory: %w", err)
+	}
+	if !fi.IsDir() {
+		return nil, fmt.Errorf("plugin directory %s is not a directory", opts.Directory)
+	}
+
+	timeout := defaultPluginTimeout
+	if opts.Timeout != "" {
+		if timeout, err = time.ParseDuration(opts.Timeout); err != nil || timeout <= 0 {
+			return nil, fmt.Errorf("invalid plugin timeout %q", opts.Timeout)
+		}
+	}
+	return &timeoutPluginManager{
+		Manager: plugin.NewCLIManager(dir.NewSysFS(opts.Directory)),
+		timeout: timeout,
+	}, nil
+}
+
+// pluginConfig returns the plugin config passed to the plugins.
+func pluginConfig(opts *pluginOptions) map[string]string {
+	if opts == nil {
+		return nil
+	}
+	return opts.Config
+}
+
+// timeoutPluginManager is a [plugin.Manager] bounding each plugin execution by
+// a timeout.
+type timeoutPluginManager struct {
+	plugin.Manager
+	timeout time.Duration
+}
+
+// Get returns the installed plugin of the name.
+func (m *timeoutPluginManager) Get(ctx context.Context, name string) (pluginframework.Plugin, error) {
+	p, err := m.Manager.Get(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return &timeoutPlugin{
+		Plugin:  p,
+		timeout: m.timeout,
+	}, nil
+}
+
+// timeoutPlugin is a plugin bounding the executions of the verification
+// commands by a timeout. Signing commands are never executed by the verifier.
+type timeoutPlugin struct {
+	pluginframework.Plugin
+	timeout time.Duration
+}
+
+// GetMetadata returns the metadata of the plugin.
+func (p *timeoutPlugin) GetMetadata(ctx context.Context, req *pluginframework.GetMetadataRequest) (*pluginframework.GetMetadataResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	return p.Plugin.GetMetadata(ctx, req)
+}
+
+// VerifySignature verifies the signature by the plugin.
+func (p *timeoutPlugin) VerifySignature(ctx context.Context, req *pluginframework.VerifySignatureRequest) (*pluginframework.VerifySignatureResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+	return p.Plugin.VerifySignature(ctx, req)
+}
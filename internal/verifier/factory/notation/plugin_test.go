@@ -0,0 +1,222 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notation
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/notation-core-go/signature"
+	"github.com/notaryproject/notation-core-go/testhelper"
+	notationgo "github.com/notaryproject/notation-go"
+	notationverifier "github.com/notaryproject/notation-go/verifier"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const fakePluginName = "fake"
+
+// buildFakePlugin compiles the fake verification plugin into a new plugin
+// directory and returns the directory.
+func buildFakePlugin(t *testing.T) string {
+	t.Helper()
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go toolchain is required to build the fake plugin")
+	}
+	pluginDir := t.TempDir()
+	binName := "notation-" + fakePluginName
+	if runtime.GOOS == "windows" {
+		binName += ".exe"
+	}
+	cmd := exec.Command(goBin, "build", "-o", filepath.Join(pluginDir, fakePluginName, binName), "./testdata/fakeplugin")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("failed to build fake plugin: %v: %s", err, out)
+	}
+	return pluginDir
+}
+
+// pluginSigner signs with the RSA test leaf certificate and requires the
+// verification plugin of the name.
+type pluginSigner struct {
+	pluginName string
+}
+
+// Sign signs the artifact.
+func (s *pluginSigner) Sign(_ context.Context, desc ocispec.Descriptor, opts notationgo.SignerSignOptions) ([]byte, *signature.SignerInfo, error) {
+	leaf, root := testhelper.GetRSALeafCertificate(), testhelper.GetRSARootCertificate()
+	localSigner, err := signature.NewLocalSigner([]*x509.Certificate{leaf.Cert, root.Cert}, leaf.PrivateKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	payload, err := json.Marshal(map[string]any{"targetArtifact": desc})
+	if err != nil {
+		return nil, nil, err
+	}
+	env, err := signature.NewEnvelope(opts.SignatureMediaType)
+	if err != nil {
+		return nil, nil, err
+	}
+	blob, err := env.Sign(&signature.SignRequest{
+		Payload: signature.Payload{
+			ContentType: "application/vnd.cncf.notary.payload.v1+json",
+			Content:     payload,
+		},
+		Signer:        localSigner,
+		SigningTime:   time.Now(),
+		SigningScheme: signature.SigningSchemeX509,
+		SigningAgent:  "ratify-test",
+		ExtendedSignedAttributes: []signature.Attribute{
+			{Key: notationverifier.HeaderVerificationPlugin, Critical: true, Value: s.pluginName},
+		},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	content, err := env.Content()
+	if err != nil {
+		return nil, nil, err
+	}
+	return blob, &content.SignerInfo, nil
+}
+
+func TestVerifier_Plugin(t *testing.T) {
+	pluginDir := buildFakePlugin(t)
+	trustedRoot := encodePEM(testhelper.GetRSARootCertificate().Cert)
+
+	tests := []struct {
+		name       string
+		plugin     map[string]any
+		pluginName string
+		expectErr  bool
+	}{
+		{
+			name:       "accepted by plugin",
+			plugin:     map[string]any{"directory": pluginDir},
+			pluginName: fakePluginName,
+		},
+		{
+			name:       "rejected by plugin",
+			plugin:     map[string]any{"directory": pluginDir, "config": map[string]string{"result": "reject"}},
+			pluginName: fakePluginName,
+			expectErr:  true,
+		},
+		{
+			name:       "plugin timeout",
+			plugin:     map[string]any{"directory": pluginDir, "timeout": "500ms", "config": map[string]string{"sleep": "10s"}},
+			pluginName: fakePluginName,
+			expectErr:  true,
+		},
+		{
+			name:       "plugin not installed",
+			plugin:     map[string]any{"directory": pluginDir},
+			pluginName: "missing",
+			expectErr:  true,
+		},
+		{
+			name:       "plugins not configured",
+			pluginName: fakePluginName,
+			expectErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			params := map[string]any{
+				"certificates": []map[string]any{{"inline": trustedRoot}},
+			}
+			if test.plugin != nil {
+				params["plugin"] = test.plugin
+			}
+			v := newTestVerifier(t, params)
+			opts := signTestArtifact(t, &pluginSigner{pluginName: test.pluginName}, notationgo.SignerSignOptions{})
+
+			start := time.Now()
+			result, err := v.Verify(context.Background(), opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (result.Err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, result.Err)
+			}
+			if elapsed := time.Since(start); elapsed > 5*time.Second {
+				t.Errorf("expected the plugin to be killed by the timeout, took %s", elapsed)
+			}
+		})
+	}
+}
+
+func TestNewPluginManager(t *testing.T) {
+	pluginDir := t.TempDir()
+	file := filepath.Join(pluginDir, "file")
+	if err := os.WriteFile(file, nil, 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	tests := []struct {
+		name          string
+		opts          *pluginOptions
+		expectErr     bool
+		expectManager bool
+	}{
+		{
+			name: "no plugins",
+		},
+		{
+			name:          "valid directory",
+			opts:          &pluginOptions{Directory: pluginDir, Timeout: "1s"},
+			expectManager: true,
+		},
+		{
+			name:      "missing directory",
+			opts:      &pluginOptions{},
+			expectErr: true,
+		},
+		{
+			name:      "nonexistent directory",
+			opts:      &pluginOptions{Directory: filepath.Join(pluginDir, "missing")},
+			expectErr: true,
+		},
+		{
+			name:      "not a directory",
+			opts:      &pluginOptions{Directory: file},
+			expectErr: true,
+		},
+		{
+			name:      "invalid timeout",
+			opts:      &pluginOptions{Directory: pluginDir, Timeout: "0s"},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			manager, err := newPluginManager(test.opts)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if (manager != nil) != test.expectManager {
+				t.Errorf("expected manager: %v, got: %v", test.expectManager, manager)
+			}
+		})
+	}
+}
@@ -19,11 +19,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"slices"
 
+	notationverifier "github.com/notaryproject/notation-go/verifier"
 	"github.com/notaryproject/notation-go/verifier/trustpolicy"
 	"github.com/notaryproject/notation-go/verifier/truststore"
 	"github.com/notaryproject/ratify-go"
-	"github.com/notaryproject/ratify-verifier-go/notation"
 	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
 	"github.com/notaryproject/ratify/v2/internal/verifier/keyprovider"
 	_ "github.com/notaryproject/ratify/v2/internal/verifier/keyprovider/filesystemprovider" // Register the filesystem key provider
@@ -31,31 +32,71 @@ import (
 )
 
 const (
-	notationType   = "notation"
-	trustStoreName = "ratify"
-	typeKey        = "type"
+	notationType          = "notation"
+	defaultTrustStoreName = "ratify"
+	typeKey               = "type"
+	nameKey               = "name"
 )
 
 // trustStoreOptions is a map of options for the trust stores. The value of the
 // "type" key must be one of the following: "ca", "tsa", or "signingAuthority".
-// If the "type" key is not present, the default type is "ca".
+// If the "type" key is not present, the default type is "ca". The value of the
+// "name" key is the name of the trust store referenced by trust policies as
+// "<type>:<name>". If the "name" key is not present, the default name is
+// "ratify".
 // Other keys in the map are used to create key providers. Each trust store can
 // have multiple key providers.
 type trustStoreOptions map[string]any
 
 type options struct {
 	// Scopes is a list of registry scopes to be used by the Notation
-	// verifier. Optional. If not provided, the default scope is "*".
+	// verifier. Optional. If not provided, the default scope is "*". Must not
+	// be set with TrustPolicyDoc or TrustPolicies.
 	Scopes []string `json:"scopes"`
 
 	// TrustedIdentities is a list of trusted identities to be used by the
 	// Notation verifier. Optional. If not provided, default identity is "*".
+	// Must not be set with TrustPolicyDoc or TrustPolicies.
 	TrustedIdentities []string `json:"trustedIdentities"`
 
+	// VerificationLevel is the verification level of the generated default
+	// policy, one of "strict", "permissive", "audit" or "skip". Signatures
+	// under the "skip" level are not verified and fail. Optional. Defaults to
+	// "strict". Must not be set with TrustPolicyDoc or TrustPolicies.
+	VerificationLevel string `json:"verificationLevel,omitempty"`
+
+	// Override overrides the action of single checks of the generated default
+	// policy. See trustPolicyOptions.Override. Optional. Must not be set with
+	// TrustPolicyDoc or TrustPolicies.
+	Override map[trustpolicy.ValidationType]trustpolicy.ValidationAction `json:"override,omitempty"`
+
+	// Timestamp configures the timestamp countersignature checks of the
+	// generated default policy. See trustPolicyOptions.Timestamp. Optional.
+	// Must not be set with TrustPolicyDoc or TrustPolicies.
+	Timestamp *timestampOptions `json:"timestamp,omitempty"`
+
 	// Certificates is a list of certificates to be used by the Notation
 	// verifier. Certificates would be loaded into trust store for Notation
 	// verifier to access. Required.
 	Certificates []trustStoreOptions `json:"certificates"`
+
+	// TrustPolicyDoc is a full Notation trust policy document. Trust stores
+	// referenced by the policies must be configured in Certificates.
+	// Optional. Must not be set with TrustPolicies.
+	TrustPolicyDoc *trustpolicy.Document `json:"trustPolicyDoc,omitempty"`
+
+	// TrustPolicies is a list of named trust policies. Optional. Must not be
+	// set with TrustPolicyDoc. If neither is set, a single policy named
+	// "default" is generated from Scopes, TrustedIdentities and all trust
+	// stores.
+	TrustPolicies []trustPolicyOptions `json:"trustPolicies,omitempty"`
+
+	// Revocation configures the OCSP and CRL revocation checks. Optional.
+	Revocation *revocationOptions `json:"revocation,omitempty"`
+
+	// Plugin configures the Notation verification plugins. Optional. If not
+	// provided, signatures requiring a verification plugin fail.
+	Plugin *pluginOptions `json:"plugin,omitempty"`
 }
 
 func init() {
@@ -70,28 +111,49 @@ func init() {
 			return nil, fmt.Errorf("failed to unmarshal verifier parameters: %w", err)
 		}
 
-		trustStore, types, err := initTrustStore(params.Certificates)
+		trustStore, storeNames, err := initTrustStore(params.Certificates)
 		if err != nil {
 			return nil, fmt.Errorf("failed to initialize trust store: %w", err)
 		}
 
-		notationOpts := &notation.VerifierOptions{
-			Name:           opts.Name,
-			TrustPolicyDoc: initTrustPolicyDocument(params.Scopes, params.TrustedIdentities, types),
-			TrustStore:     trustStore,
+		policyDoc, timestampPolicies, err := initTrustPolicyDocument(&params, storeNames)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize trust policy document: %w", err)
+		}
+
+		verifierOpts, err := newRevocationVerifierOptions(params.Revocation)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize revocation: %w", err)
 		}
 
-		return notation.NewVerifier(notationOpts)
+		pluginManager, err := newPluginManager(params.Plugin)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize plugins: %w", err)
+		}
+
+		v, err := notationverifier.NewWithOptions(policyDoc, trustStore, pluginManager, verifierOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create notation verifier: %w", err)
+		}
+		return &verifier{
+			name:              opts.Name,
+			trustPolicyDoc:    policyDoc,
+			timestampPolicies: timestampPolicies,
+			verifier:          v,
+			pluginConfig:      pluginConfig(params.Plugin),
+		}, nil
 	})
 }
 
-func initTrustStore(opts []trustStoreOptions) (truststore.X509TrustStore, []truststore.Type, error) {
+// initTrustStore creates the trust store from the options and returns the
+// names of the configured trust stores in the "<type>:<name>" format.
+func initTrustStore(opts []trustStoreOptions) (truststore.X509TrustStore, []string, error) {
 	if len(opts) == 0 {
 		return nil, nil, fmt.Errorf("no trust store options provided")
 	}
 
 	trustStore := newTrustStore()
-	types := make(map[truststore.Type]struct{})
+	var storeNames []string
 	for _, opt := range opts {
 		var err error
 		storeType := truststore.TypeCA
@@ -100,13 +162,21 @@ func initTrustStore(opts []trustStoreOptions) (truststore.X509TrustStore, []trus
 				return nil, nil, fmt.Errorf("failed to get trust store type: %w", err)
 			}
 		}
-		if _, exists := types[storeType]; exists {
-			return nil, nil, fmt.Errorf("duplicate trust store type %s detected. Please check your configuration to ensure each trust store type is unique", storeType)
+		name := defaultTrustStoreName
+		if nameVal, ok := opt[nameKey]; ok {
+			if name, ok = nameVal.(string); !ok || name == "" {
+				return nil, nil, fmt.Errorf("trust store name must be a non-empty string")
+			}
+		}
+		storeName := fmt.Sprintf("%s:%s", storeType, name)
+		if slices.Contains(storeNames, storeName) {
+			return nil, nil, fmt.Errorf("duplicate trust store %s detected. Please check your configuration to ensure each trust store type and name is unique", storeName)
 		}
-		types[storeType] = struct{}{}
+		storeNames = append(storeNames, storeName)
+		trustStore.addCertificates(storeType, name, nil)
 
 		for key, val := range opt {
-			if key == typeKey {
+			if key == typeKey || key == nameKey {
 				continue
 			}
 			provider, err := keyprovider.CreateKeyProvider(key, val)
@@ -118,14 +188,10 @@ func initTrustStore(opts []trustStoreOptions) (truststore.X509TrustStore, []trus
 				return nil, nil, fmt.Errorf("failed to get certificates from provider %s: %w", key, err)
 			}
 
-			trustStore.addCertificates(storeType, trustStoreName, certs)
+			trustStore.addCertificates(storeType, name, certs)
 		}
 	}
-	names := make([]truststore.Type, 0, len(types))
-	for storeType := range types {
-		names = append(names, storeType)
-	}
-	return trustStore, names, nil
+	return trustStore, storeNames, nil
 }
 
 func getTrustStoreType(val any) (truststore.Type, error) {
@@ -139,30 +205,3 @@ func getTrustStoreType(val any) (truststore.Type, error) {
 	}
 	return storeType, nil
 }
-
-func initTrustPolicyDocument(scopes, trustedIdentities []string, storeTypes []truststore.Type) *trustpolicy.Document {
-	if len(scopes) == 0 {
-		scopes = []string{"*"}
-	}
-	if len(trustedIdentities) == 0 {
-		trustedIdentities = []string{"*"}
-	}
-	trustStoreNames := make([]string, len(storeTypes))
-	for i, storeType := range storeTypes {
-		trustStoreNames[i] = fmt.Sprintf("%s:%s", storeType, trustStoreName)
-	}
-	return &trustpolicy.Document{
-		Version: "1.0",
-		TrustPolicies: []trustpolicy.TrustPolicy{
-			{
-				Name:           "default",
-				RegistryScopes: scopes,
-				SignatureVerification: trustpolicy.SignatureVerification{
-					VerificationLevel: "strict",
-				},
-				TrustStores:       trustStoreNames,
-				TrustedIdentities: trustedIdentities,
-			},
-		},
-	}
-}
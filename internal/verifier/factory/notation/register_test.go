@@ -164,6 +164,82 @@ func TestNewVerifier(t *testing.T) {
 			},
 			expectErr: true,
 		},
+		{
+			name: "Invalid trust store name",
+			opts: &factory.NewVerifierOptions{
+				Type: notationType,
+				Name: testName,
+				Parameters: options{
+					Certificates: []trustStoreOptions{
+						{
+							"type":              "ca",
+							"name":              "",
+							mockKeyProviderName: nil,
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "Trust policy referencing unknown trust store",
+			opts: &factory.NewVerifierOptions{
+				Type: notationType,
+				Name: testName,
+				Parameters: options{
+					Certificates: []trustStoreOptions{
+						{
+							"type":              "ca",
+							"name":              "prod",
+							mockKeyProviderName: nil,
+						},
+					},
+					TrustPolicies: []trustPolicyOptions{
+						{
+							Name:        "staging",
+							TrustStores: []string{"ca:staging"},
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "Valid named trust stores and policies",
+			opts: &factory.NewVerifierOptions{
+				Type: notationType,
+				Name: testName,
+				Parameters: map[string]any{
+					"certificates": []map[string]any{
+						{
+							"type":              "ca",
+							"name":              "prod",
+							mockKeyProviderName: nil,
+						},
+						{
+							"type":              "ca",
+							"name":              "staging",
+							mockKeyProviderName: nil,
+						},
+					},
+					"trustPolicies": []map[string]any{
+						{
+							"name":              "prod",
+							"scopes":            []string{"prod.example.com/app"},
+							"trustStores":       []string{"ca:prod"},
+							"trustedIdentities": []string{"x509.subject: C=US, ST=WA, O=Prod, CN=prod"},
+						},
+						{
+							"name":              "staging",
+							"scopes":            []string{"staging.example.com/app"},
+							"trustStores":       []string{"ca:staging"},
+							"verificationLevel": "permissive",
+						},
+					},
+				},
+			},
+			expectErr: false,
+		},
 		{
 			name: "Valid notation options",
 			opts: &factory.NewVerifierOptions{
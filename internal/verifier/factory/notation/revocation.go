@@ -0,0 +1,309 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notation
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/notaryproject/notation-core-go/revocation"
+	corecrl "github.com/notaryproject/notation-core-go/revocation/crl"
+	"github.com/notaryproject/notation-core-go/revocation/purpose"
+	"github.com/notaryproject/notation-core-go/revocation/result"
+	notationverifier "github.com/notaryproject/notation-go/verifier"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultRevocationFetchTimeout = 5 * time.Second
+	defaultCRLCacheTTL            = 24 * time.Hour
+)
+
+// revocationOptions configures the revocation checks of the signing and
+// timestamping certificate chains. Certificates are checked by OCSP and fall
+// back to CRL if OCSP is not available.
+type revocationOptions struct {
+	// FetchTimeout is the timeout of each OCSP request and CRL download,
+	// e.g. "3s". Optional. Defaults to 5 seconds.
+	FetchTimeout string `json:"fetchTimeout,omitempty"`
+
+	// FailOpen treats certificates with unknown revocation status, e.g. due to
+	// unreachable OCSP responders or CRL distribution points, as not revoked.
+	// Revoked certificates always fail the verification. Optional. Defaults
+	// to false, i.e. fail closed.
+	FailOpen bool `json:"failOpen,omitempty"`
+
+	// CRLCache configures the cache of downloaded CRLs. Optional. If not
+	// provided, CRLs are cached in memory.
+	CRLCache *crlCacheOptions `json:"crlCache,omitempty"`
+}
+
+// crlCacheOptions configures the CRL cache.
+type crlCacheOptions struct {
+	// Directory is the directory to cache CRLs in. Cached CRLs survive
+	// restarts and can be shared by replicas mounting the same volume.
+	// Optional. If not provided, CRLs are cached in memory.
+	Directory string `json:"directory,omitempty"`
+
+	// TTL is the maximum duration a CRL is cached, e.g. "1h". A CRL is never
+	// used after its next update time regardless of the TTL. Optional.
+	// Defaults to 24 hours.
+	TTL string `json:"ttl,omitempty"`
+}
+
+// newRevocationVerifierOptions creates the revocation validators configured
+// by the options.
+func newRevocationVerifierOptions(opts *revocationOptions) (notationverifier.VerifierOptions, error) {
+	if opts == nil {
+		opts = &revocationOptions{}
+	}
+	timeout := defaultRevocationFetchTimeout
+	if opts.FetchTimeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(opts.FetchTimeout); err != nil || timeout <= 0 {
+			return notationverifier.VerifierOptions{}, fmt.Errorf("invalid revocation fetchTimeout %q", opts.FetchTimeout)
+		}
+	}
+	cache, err := newCRLCache(opts.CRLCache)
+	if err != nil {
+		return notationverifier.VerifierOptions{}, err
+	}
+
+	httpClient := &http.Client{Timeout: timeout}
+	fetcher, err := corecrl.NewHTTPFetcher(httpClient)
+	if err != nil {
+		return notationverifier.VerifierOptions{}, fmt.Errorf("failed to create CRL fetcher: %w", err)
+	}
+	fetcher.Cache = cache
+	// a broken cache must not fail the verification.
+	fetcher.DiscardCacheError = true
+
+	newValidator := func(certChainPurpose purpose.Purpose) (revocation.Validator, error) {
+		validator, err := revocation.NewWithOptions(revocation.Options{
+			OCSPHTTPClient:   httpClient,
+			CRLFetcher:       fetcher,
+			CertChainPurpose: certChainPurpose,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create revocation validator: %w", err)
+		}
+		if opts.FailOpen {
+			return &failOpenValidator{Validator: validator}, nil
+		}
+		return validator, nil
+	}
+	codeSigningValidator, err := newValidator(purpose.CodeSigning)
+	if err != nil {
+		return notationverifier.VerifierOptions{}, err
+	}
+	timestampingValidator, err := newValidator(purpose.Timestamping)
+	if err != nil {
+		return notationverifier.VerifierOptions{}, err
+	}
+	return notationverifier.VerifierOptions{
+		RevocationCodeSigningValidator:  codeSigningValidator,
+		RevocationTimestampingValidator: timestampingValidator,
+	}, nil
+}
+
+// failOpenValidator is a [revocation.Validator] treating certificates with
+// unknown revocation status as not revoked.
+type failOpenValidator struct {
+	revocation.Validator
+}
+
+// ValidateContext checks the revocation status of the certificate chain.
+func (v *failOpenValidator) ValidateContext(ctx context.Context, opts revocation.ValidateContextOptions) ([]*result.CertRevocationResult, error) {
+	certResults, err := v.Validator.ValidateContext(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	for i, certResult := range certResults {
+		if certResult.Result != result.ResultUnknown {
+			continue
+		}
+		subject := ""
+		if i < len(opts.CertChain) {
+			subject = opts.CertChain[i].Subject.String()
+		}
+		for _, serverResult := range certResult.ServerResults {
+			logrus.Warnf("revocation status of certificate %q is unknown, ignored by fail-open: %v", subject, serverResult.Error)
+		}
+		certResult.Result = result.ResultOK
+	}
+	return certResults, nil
+}
+
+// newCRLCache creates the CRL cache configured by the options.
+func newCRLCache(opts *crlCacheOptions) (corecrl.Cache, error) {
+	if opts == nil {
+		opts = &crlCacheOptions{}
+	}
+	ttl := defaultCRLCacheTTL
+	if opts.TTL != "" {
+		var err error
+		if ttl, err = time.ParseDuration(opts.TTL); err != nil || ttl <= 0 {
+			return nil, fmt.Errorf("invalid CRL cache ttl %q", opts.TTL)
+		}
+	}
+	if opts.Directory == "" {
+		return &memoryCRLCache{
+			ttl:     ttl,
+			entries: make(map[string]memoryCRLCacheEntry),
+		}, nil
+	}
+	if err := os.MkdirAll(opts.Directory, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create CRL cache directory: %w", err)
+	}
+	return &fileCRLCache{
+		ttl:       ttl,
+		directory: opts.Directory,
+	}, nil
+}
+
+type memoryCRLCacheEntry struct {
+	bundle   *corecrl.Bundle
+	cachedAt time.Time
+}
+
+// memoryCRLCache is an in-memory [corecrl.Cache] with a TTL.
+type memoryCRLCache struct {
+	ttl     time.Duration
+	mu      sync.RWMutex
+	entries map[string]memoryCRLCacheEntry
+}
+
+// Get returns the cached CRL bundle of the url.
+func (c *memoryCRLCache) Get(_ context.Context, url string) (*corecrl.Bundle, error) {
+	c.mu.RLock()
+	entry, ok := c.entries[url]
+	c.mu.RUnlock()
+	if !ok || time.Since(entry.cachedAt) >= c.ttl {
+		return nil, corecrl.ErrCacheMiss
+	}
+	return entry.bundle, nil
+}
+
+// Set caches the CRL bundle of the url.
+func (c *memoryCRLCache) Set(_ context.Context, url string, bundle *corecrl.Bundle) error {
+	if bundle == nil || bundle.BaseCRL == nil {
+		return errors.New("CRL bundle must contain a base CRL")
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.entries[url] = memoryCRLCacheEntry{
+		bundle:   bundle,
+		cachedAt: time.Now(),
+	}
+	return nil
+}
+
+// fileCRLCacheContent is the content of a cached CRL file.
+type fileCRLCacheContent struct {
+	URL      string    `json:"url"`
+	CachedAt time.Time `json:"cachedAt"`
+	BaseCRL  []byte    `json:"baseCRL"`
+	DeltaCRL []byte    `json:"deltaCRL,omitempty"`
+}
+
+// fileCRLCache is a file based [corecrl.Cache] with a TTL.
+type fileCRLCache struct {
+	ttl       time.Duration
+	directory string
+}
+
+// Get returns the cached CRL bundle of the url.
+func (c *fileCRLCache) Get(_ context.Context, url string) (*corecrl.Bundle, error) {
+	raw, err := os.ReadFile(c.path(url))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, corecrl.ErrCacheMiss
+		}
+		return nil, err
+	}
+	var content fileCRLCacheContent
+	if err := json.Unmarshal(raw, &content); err != nil {
+		return nil, fmt.Errorf("failed to parse cached CRL of %s: %w", url, err)
+	}
+	if content.URL != url || time.Since(content.CachedAt) >= c.ttl {
+		return nil, corecrl.ErrCacheMiss
+	}
+	bundle := &corecrl.Bundle{}
+	if bundle.BaseCRL, err = x509.ParseRevocationList(content.BaseCRL); err != nil {
+		return nil, fmt.Errorf("failed to parse cached CRL of %s: %w", url, err)
+	}
+	if len(content.DeltaCRL) > 0 {
+		if bundle.DeltaCRL, err = x509.ParseRevocationList(content.DeltaCRL); err != nil {
+			return nil, fmt.Errorf("failed to parse cached delta CRL of %s: %w", url, err)
+		}
+	}
+	return bundle, nil
+}
+
+// Set caches the CRL bundle of the url.
+func (c *fileCRLCache) Set(_ context.Context, url string, bundle *corecrl.Bundle) error {
+	if bundle == nil || bundle.BaseCRL == nil {
+		return errors.New("CRL bundle must contain a base CRL")
+	}
+	content := fileCRLCacheContent{
+		URL:      url,
+		CachedAt: time.Now(),
+		BaseCRL:  bundle.BaseCRL.Raw,
+	}
+	if bundle.DeltaCRL != nil {
+		content.DeltaCRL = bundle.DeltaCRL.Raw
+	}
+	raw, err := json.Marshal(content)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so that readers never see a partially
+	// written file.
+	tmp, err := os.CreateTemp(c.directory, ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(raw); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path(url)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+func (c *fileCRLCache) path(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return filepath.Join(c.directory, hex.EncodeToString(sum[:]))
+}
@@ -0,0 +1,354 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notation
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	corecrl "github.com/notaryproject/notation-core-go/revocation/crl"
+	notationgo "github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signer"
+	"golang.org/x/crypto/ocsp"
+)
+
+// revocationResponder is a local CRL and OCSP responder for a test CA.
+type revocationResponder struct {
+	*httptest.Server
+	rootKey  *rsa.PrivateKey
+	root     *x509.Certificate
+	requests atomic.Int32
+	revoked  atomic.Bool
+	down     atomic.Bool
+}
+
+func newRevocationResponder(t *testing.T) *revocationResponder {
+	t.Helper()
+	r := &revocationResponder{}
+	r.rootKey = generateKey(t)
+	r.root = createCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Country: []string{"US"}, Organization: []string{"Ratify"}, CommonName: "Revocation Test Root"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageCRLSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil, &r.rootKey.PublicKey, r.rootKey)
+
+	r.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.requests.Add(1)
+		if r.down.Load() {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		switch {
+		case req.URL.Path == "/leaf.crl":
+			list := &x509.RevocationList{
+				Number:     big.NewInt(time.Now().UnixNano()),
+				ThisUpdate: time.Now().Add(-time.Minute),
+				NextUpdate: time.Now().Add(time.Hour),
+			}
+			if r.revoked.Load() {
+				list.RevokedCertificateEntries = []x509.RevocationListEntry{
+					{SerialNumber: big.NewInt(2), RevocationTime: time.Now().Add(-time.Minute)},
+				}
+			}
+			crl, err := x509.CreateRevocationList(rand.Reader, list, r.root, r.rootKey)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			_, _ = w.Write(crl)
+		case strings.HasPrefix(req.URL.Path, "/ocsp"):
+			// OCSP requests are sent by GET with the base64 encoded request
+			// in the path, or by POST.
+			body, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(req.URL.Path, "/ocsp/"))
+			if req.Method == http.MethodPost {
+				body, err = io.ReadAll(req.Body)
+			}
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			ocspReq, err := ocsp.ParseRequest(body)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			template := ocsp.Response{
+				Status:       ocsp.Good,
+				SerialNumber: ocspReq.SerialNumber,
+				ThisUpdate:   time.Now().Add(-time.Minute),
+				NextUpdate:   time.Now().Add(time.Hour),
+			}
+			if r.revoked.Load() {
+				template.Status = ocsp.Revoked
+				template.RevokedAt = time.Now().Add(-time.Minute)
+			}
+			resp, err := ocsp.CreateResponse(r.root, r.root, template, r.rootKey)
+			if err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			w.Header().Set("Content-Type", "application/ocsp-response")
+			_, _ = w.Write(resp)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	t.Cleanup(r.Close)
+	return r
+}
+
+// newSigner returns a signer with a leaf certificate issued by the test CA
+// pointing to the CRL distribution point or the OCSP responder.
+func (r *revocationResponder) newSigner(t *testing.T, useOCSP bool) notationgo.Signer {
+	t.Helper()
+	key := generateKey(t)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{Country: []string{"US"}, Organization: []string{"Ratify"}, CommonName: "Revocation Test Leaf"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+	}
+	if useOCSP {
+		template.OCSPServer = []string{r.URL + "/ocsp"}
+	} else {
+		template.CRLDistributionPoints = []string{r.URL + "/leaf.crl"}
+	}
+	leaf := createCertificate(t, template, r.root, &key.PublicKey, r.rootKey)
+	s, err := signer.NewGenericSigner(key, []*x509.Certificate{leaf, r.root})
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	return s
+}
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return key
+}
+
+func createCertificate(t *testing.T, template, parent *x509.Certificate, pub *rsa.PublicKey, priv *rsa.PrivateKey) *x509.Certificate {
+	t.Helper()
+	if parent == nil {
+		parent = template
+	}
+	raw, err := x509.CreateCertificate(rand.Reader, template, parent, pub, priv)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(raw)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return cert
+}
+
+func TestVerifier_Revocation(t *testing.T) {
+	responder := newRevocationResponder(t)
+	root := encodePEM(responder.root)
+
+	tests := []struct {
+		name           string
+		useOCSP        bool
+		revoked        bool
+		down           bool
+		revocation     map[string]any
+		expectErr      bool
+		expectRequests int32
+	}{
+		{
+			name:           "CRL not revoked",
+			expectRequests: 1,
+		},
+		{
+			name:           "CRL revoked",
+			revoked:        true,
+			expectErr:      true,
+			expectRequests: 1,
+		},
+		{
+			name:           "OCSP not revoked",
+			useOCSP:        true,
+			expectRequests: 2,
+		},
+		{
+			name:           "OCSP revoked",
+			useOCSP:        true,
+			revoked:        true,
+			expectErr:      true,
+			expectRequests: 2,
+		},
+		{
+			name:           "responder down fail closed",
+			down:           true,
+			expectErr:      true,
+			expectRequests: 2,
+		},
+		{
+			name:           "responder down fail open",
+			down:           true,
+			revocation:     map[string]any{"failOpen": true},
+			expectRequests: 2,
+		},
+		{
+			name:           "revoked fail open",
+			revoked:        true,
+			revocation:     map[string]any{"failOpen": true},
+			expectErr:      true,
+			expectRequests: 1,
+		},
+		{
+			name:           "CRL cache expired",
+			revocation:     map[string]any{"crlCache": map[string]any{"ttl": "1ns"}},
+			expectRequests: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			responder.requests.Store(0)
+			responder.revoked.Store(test.revoked)
+			responder.down.Store(test.down)
+			params := map[string]any{
+				"certificates": []map[string]any{{"inline": root}},
+			}
+			if test.revocation != nil {
+				params["revocation"] = test.revocation
+			}
+			v := newTestVerifier(t, params)
+			opts := signTestArtifact(t, responder.newSigner(t, test.useOCSP), notationgo.SignerSignOptions{})
+
+			// verify twice to exercise the CRL cache.
+			for range 2 {
+				result, err := v.Verify(context.Background(), opts)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if (result.Err != nil) != test.expectErr {
+					t.Fatalf("expected error: %v, got: %v", test.expectErr, result.Err)
+				}
+			}
+			if requests := responder.requests.Load(); requests != test.expectRequests {
+				t.Errorf("expected %d requests to the responder, got %d", test.expectRequests, requests)
+			}
+		})
+	}
+}
+
+func TestVerifier_RevocationFileCache(t *testing.T) {
+	responder := newRevocationResponder(t)
+	params := map[string]any{
+		"certificates": []map[string]any{{"inline": encodePEM(responder.root)}},
+		"revocation": map[string]any{
+			"crlCache": map[string]any{"directory": t.TempDir()},
+		},
+	}
+	opts := signTestArtifact(t, responder.newSigner(t, false), notationgo.SignerSignOptions{})
+
+	// a new verifier, e.g. after a restart, reuses the cached CRL.
+	for range 2 {
+		result, err := newTestVerifier(t, params).Verify(context.Background(), opts)
+		if err != nil || result.Err != nil {
+			t.Fatalf("unexpected error: %v, %v", err, result.Err)
+		}
+	}
+	if requests := responder.requests.Load(); requests != 1 {
+		t.Errorf("expected 1 request to the responder, got %d", requests)
+	}
+}
+
+func TestNewRevocationVerifierOptions(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      *revocationOptions
+		expectErr bool
+	}{
+		{name: "default options"},
+		{name: "valid options", opts: &revocationOptions{FetchTimeout: "2s", FailOpen: true, CRLCache: &crlCacheOptions{TTL: "1h"}}},
+		{name: "invalid fetch timeout", opts: &revocationOptions{FetchTimeout: "soon"}, expectErr: true},
+		{name: "non-positive fetch timeout", opts: &revocationOptions{FetchTimeout: "0s"}, expectErr: true},
+		{name: "invalid CRL cache ttl", opts: &revocationOptions{CRLCache: &crlCacheOptions{TTL: "-1h"}}, expectErr: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := newRevocationVerifierOptions(test.opts)
+			if (err != nil) != test.expectErr {
+				t.Errorf("expected error: %v, got: %v", test.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestCRLCache(t *testing.T) {
+	responder := newRevocationResponder(t)
+	raw, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
+		Number:     big.NewInt(1),
+		ThisUpdate: time.Now(),
+		NextUpdate: time.Now().Add(time.Hour),
+	}, responder.root, responder.rootKey)
+	if err != nil {
+		t.Fatalf("failed to create CRL: %v", err)
+	}
+	crl, err := x509.ParseRevocationList(raw)
+	if err != nil {
+		t.Fatalf("failed to parse CRL: %v", err)
+	}
+
+	for _, dir := range []string{"", t.TempDir()} {
+		cache, err := newCRLCache(&crlCacheOptions{Directory: dir})
+		if err != nil {
+			t.Fatalf("failed to create cache: %v", err)
+		}
+		ctx := context.Background()
+		if _, err := cache.Get(ctx, "http://example.com/a.crl"); err != corecrl.ErrCacheMiss {
+			t.Errorf("expected cache miss, got %v", err)
+		}
+		if err := cache.Set(ctx, "http://example.com/a.crl", nil); err == nil {
+			t.Error("expected error for empty bundle, got nil")
+		}
+		if err := cache.Set(ctx, "http://example.com/a.crl", &corecrl.Bundle{BaseCRL: crl}); err != nil {
+			t.Fatalf("failed to set CRL: %v", err)
+		}
+		bundle, err := cache.Get(ctx, "http://example.com/a.crl")
+		if err != nil {
+			t.Fatalf("failed to get CRL: %v", err)
+		}
+		if bundle.BaseCRL.Number.Cmp(crl.Number) != 0 {
+			t.Errorf("expected CRL number %v, got %v", crl.Number, bundle.BaseCRL.Number)
+		}
+	}
+}
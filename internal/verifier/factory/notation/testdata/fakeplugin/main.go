@@ -0,0 +1,99 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command fakeplugin is a Notation verification plugin for tests. It accepts
+// all signatures unless the plugin config sets "result" to "reject", and
+// sleeps for the duration of the "sleep" config before responding.
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+type verifySignatureRequest struct {
+	TrustPolicy struct {
+		SignatureVerification []string `json:"signatureVerification"`
+	} `json:"trustPolicy"`
+	PluginConfig map[string]string `json:"pluginConfig"`
+}
+
+type verificationResult struct {
+	Success bool   `json:"success"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		fail("missing command")
+	}
+	name := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(os.Args[0]), "notation-"), ".exe")
+
+	switch os.Args[1] {
+	case "get-plugin-metadata":
+		respond(map[string]any{
+			"name":                      name,
+			"description":               "fake verification plugin",
+			"version":                   "1.0.0",
+			"url":                       "https://example.com/fakeplugin",
+			"supportedContractVersions": []string{"1.0"},
+			"capabilities":              []string{"SIGNATURE_VERIFIER.TRUSTED_IDENTITY"},
+		})
+	case "verify-signature":
+		var req verifySignatureRequest
+		if err := json.NewDecoder(os.Stdin).Decode(&req); err != nil {
+			fail(err.Error())
+		}
+		if sleep, ok := req.PluginConfig["sleep"]; ok {
+			d, err := time.ParseDuration(sleep)
+			if err != nil {
+				fail(err.Error())
+			}
+			time.Sleep(d)
+		}
+		result := verificationResult{Success: true}
+		if req.PluginConfig["result"] == "reject" {
+			result = verificationResult{Reason: "rejected by fake plugin"}
+		}
+		results := make(map[string]verificationResult)
+		for _, capability := range req.TrustPolicy.SignatureVerification {
+			results[capability] = result
+		}
+		respond(map[string]any{
+			"verificationResults": results,
+			"processedAttributes": []string{},
+		})
+	default:
+		fail(fmt.Sprintf("unsupported command %s", os.Args[1]))
+	}
+}
+
+func respond(resp any) {
+	if err := json.NewEncoder(os.Stdout).Encode(resp); err != nil {
+		fail(err.Error())
+	}
+}
+
+func fail(msg string) {
+	_ = json.NewEncoder(os.Stderr).Encode(map[string]string{
+		"errorCode":    "ERROR",
+		"errorMessage": msg,
+	})
+	os.Exit(1)
+}
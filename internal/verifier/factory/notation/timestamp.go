@@ -0,0 +1,207 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notation
+
+import (
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	notationgo "github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+	"github.com/notaryproject/notation-go/verifier/truststore"
+	"github.com/notaryproject/tspclient-go"
+)
+
+// timestampOptions configures the RFC 3161 timestamp countersignature checks
+// of a trust policy.
+type timestampOptions struct {
+	// Required fails the verification of signatures without a timestamp
+	// countersignature from a trusted TSA. Optional. Defaults to false.
+	Required bool `json:"required,omitempty"`
+
+	// TSATrustStores is a list of trust stores in the "tsa:<name>" format
+	// trusted to issue timestamp countersignatures. Each trust store must be
+	// configured in the Certificates of the verifier. Required if Required is
+	// set.
+	TSATrustStores []string `json:"tsaTrustStores,omitempty"`
+
+	// VerifyTimestamp is when the timestamp countersignature is verified, one
+	// of "always" or "afterCertExpiry". With "afterCertExpiry", signatures are
+	// accepted without timestamp while the signing certificate is valid, and
+	// signatures signed by an expired certificate are only valid with a
+	// trusted timestamp from the validity period of the certificate.
+	// Optional. Defaults to "always" if Required is set, and to
+	// "afterCertExpiry" otherwise.
+	VerifyTimestamp string `json:"verifyTimestamp,omitempty"`
+
+	// ClockSkew is the maximum allowed difference between the signing time
+	// claimed by the signer and the timestamp, and the maximum duration a
+	// timestamp may be ahead of the local clock, e.g. "5m". Optional. If not
+	// provided, the clock skew is not checked.
+	ClockSkew string `json:"clockSkew,omitempty"`
+}
+
+// timestampPolicy is the timestamp checks of a trust policy performed in
+// addition to the checks of Notation.
+type timestampPolicy struct {
+	required  bool
+	clockSkew time.Duration
+}
+
+// timestampDetail is the timestamp countersignature reported in the
+// verification result.
+type timestampDetail struct {
+	// Time is the time asserted by the TSA.
+	Time time.Time
+
+	// TSA is the subject of the TSA signing certificate.
+	TSA string
+
+	// Verified is true if the countersignature was verified against the TSA
+	// trust stores of the trust policy. Timestamps are not verified if the
+	// policy has no TSA trust store, or only verifies timestamps after the
+	// expiry of the signing certificate.
+	Verified bool
+}
+
+// applyTimestampOptions applies the timestamp options to the trust policy and
+// returns the additional checks of the policy.
+func applyTimestampOptions(policy *trustpolicy.TrustPolicy, opts *timestampOptions) (*timestampPolicy, error) {
+	if opts == nil {
+		return nil, nil
+	}
+	for _, storeName := range opts.TSATrustStores {
+		if !strings.HasPrefix(storeName, string(truststore.TypeTSA)+":") {
+			return nil, fmt.Errorf("trust policy %q has TSA trust store %q which is not of type %s", policy.Name, storeName, truststore.TypeTSA)
+		}
+		if !slices.Contains(policy.TrustStores, storeName) {
+			policy.TrustStores = append(policy.TrustStores, storeName)
+		}
+	}
+
+	verifyTimestamp := trustpolicy.TimestampOption(opts.VerifyTimestamp)
+	if verifyTimestamp == "" {
+		verifyTimestamp = trustpolicy.OptionAfterCertExpiry
+		if opts.Required {
+			verifyTimestamp = trustpolicy.OptionAlways
+		}
+	}
+	switch verifyTimestamp {
+	case trustpolicy.OptionAlways:
+	case trustpolicy.OptionAfterCertExpiry:
+		if opts.Required {
+			return nil, fmt.Errorf("trust policy %q requires timestamps which must be verified %q", policy.Name, trustpolicy.OptionAlways)
+		}
+	default:
+		return nil, fmt.Errorf("trust policy %q has invalid verifyTimestamp %q", policy.Name, opts.VerifyTimestamp)
+	}
+	policy.SignatureVerification.VerifyTimestamp = verifyTimestamp
+
+	if opts.Required && !hasTSATrustStore(policy) {
+		return nil, fmt.Errorf("trust policy %q requires timestamps but has no TSA trust store", policy.Name)
+	}
+
+	tsPolicy := &timestampPolicy{
+		required: opts.Required,
+	}
+	if opts.ClockSkew != "" {
+		var err error
+		if tsPolicy.clockSkew, err = time.ParseDuration(opts.ClockSkew); err != nil || tsPolicy.clockSkew <= 0 {
+			return nil, fmt.Errorf("trust policy %q has invalid clockSkew %q", policy.Name, opts.ClockSkew)
+		}
+	}
+	return tsPolicy, nil
+}
+
+// check checks the timestamp of the verified signature against the policy.
+func (p *timestampPolicy) check(outcome *notationgo.VerificationOutcome, timestamp *timestampDetail) error {
+	if p.required {
+		if timestamp == nil {
+			return errors.New("signature has no timestamp countersignature but the trust policy requires one")
+		}
+		if !timestamp.Verified {
+			return errors.New("timestamp countersignature was not verified but the trust policy requires a trusted one")
+		}
+	}
+	if timestamp == nil || p.clockSkew == 0 {
+		return nil
+	}
+	signingTime := outcome.EnvelopeContent.SignerInfo.SignedAttributes.SigningTime
+	if diff := timestamp.Time.Sub(signingTime).Abs(); diff > p.clockSkew {
+		return fmt.Errorf("signing time %s differs from timestamp %s by %s which exceeds the allowed clock skew %s", signingTime.Format(time.RFC3339), timestamp.Time.Format(time.RFC3339), diff, p.clockSkew)
+	}
+	if ahead := time.Until(timestamp.Time); ahead > p.clockSkew {
+		return fmt.Errorf("timestamp %s is %s ahead of the local clock which exceeds the allowed clock skew %s", timestamp.Time.Format(time.RFC3339), ahead, p.clockSkew)
+	}
+	return nil
+}
+
+// getTimestamp returns the timestamp countersignature of the signature
+// verified under the trust policy, or nil if the signature has none.
+func getTimestamp(policy *trustpolicy.TrustPolicy, outcome *notationgo.VerificationOutcome, loggedChecks []string) (*timestampDetail, error) {
+	token := outcome.EnvelopeContent.SignerInfo.UnsignedAttributes.TimestampSignature
+	if len(token) == 0 {
+		return nil, nil
+	}
+	signedToken, err := tspclient.ParseSignedToken(token)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse timestamp countersignature: %w", err)
+	}
+	info, err := signedToken.Info()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse timestamp countersignature: %w", err)
+	}
+	detail := &timestampDetail{
+		Time:     info.GenTime,
+		Verified: timestampVerified(policy, outcome, loggedChecks),
+	}
+	if len(signedToken.SignerInfos) > 0 {
+		if cert, err := signedToken.SigningCertificate(&signedToken.SignerInfos[0]); err == nil {
+			detail.TSA = cert.Subject.String()
+		}
+	}
+	return detail, nil
+}
+
+// timestampVerified returns true if Notation verified the timestamp
+// countersignature of the signature under the trust policy.
+func timestampVerified(policy *trustpolicy.TrustPolicy, outcome *notationgo.VerificationOutcome, loggedChecks []string) bool {
+	if policy == nil || !hasTSATrustStore(policy) {
+		return false
+	}
+	if outcome.VerificationLevel.Enforcement[trustpolicy.TypeAuthenticTimestamp] == trustpolicy.ActionSkip {
+		return false
+	}
+	if slices.Contains(loggedChecks, string(trustpolicy.TypeAuthenticTimestamp)) {
+		return false
+	}
+	if policy.SignatureVerification.VerifyTimestamp != trustpolicy.OptionAfterCertExpiry {
+		return true
+	}
+	return slices.ContainsFunc(outcome.EnvelopeContent.SignerInfo.CertificateChain, func(cert *x509.Certificate) bool {
+		return time.Now().After(cert.NotAfter)
+	})
+}
+
+func hasTSATrustStore(policy *trustpolicy.TrustPolicy) bool {
+	return slices.ContainsFunc(policy.TrustStores, func(storeName string) bool {
+		return strings.HasPrefix(storeName, string(truststore.TypeTSA)+":")
+	})
+}
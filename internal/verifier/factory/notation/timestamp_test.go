@@ -0,0 +1,377 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notation
+
+import (
+	"context"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/digitorus/timestamp"
+	notationgo "github.com/notaryproject/notation-go"
+	"github.com/notaryproject/notation-go/signer"
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+	"github.com/notaryproject/tspclient-go"
+)
+
+// testTSA is a local RFC 3161 timestamping authority.
+type testTSA struct {
+	*httptest.Server
+	root *x509.Certificate
+	cert *x509.Certificate
+	key  *rsa.PrivateKey
+
+	// offset is added to the current time in issued timestamps.
+	offset atomic.Int64
+}
+
+func newTestTSA(t *testing.T) *testTSA {
+	t.Helper()
+	rootKey := generateKey(t)
+	root := createCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Country: []string{"US"}, Organization: []string{"Ratify"}, CommonName: "TSA Test Root"},
+		NotBefore:             time.Now().Add(-24 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil, &rootKey.PublicKey, rootKey)
+
+	// the timestamping extended key usage must be the only and critical one.
+	eku, err := asn1.Marshal([]asn1.ObjectIdentifier{{1, 3, 6, 1, 5, 5, 7, 3, 8}})
+	if err != nil {
+		t.Fatalf("failed to marshal extended key usage: %v", err)
+	}
+	key := generateKey(t)
+	cert := createCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(2),
+		Subject:      pkix.Name{Country: []string{"US"}, Organization: []string{"Ratify"}, CommonName: "TSA Test"},
+		NotBefore:    time.Now().Add(-24 * time.Hour),
+		NotAfter:     time.Now().Add(24 * time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtraExtensions: []pkix.Extension{
+			{Id: asn1.ObjectIdentifier{2, 5, 29, 37}, Critical: true, Value: eku},
+		},
+	}, root, &key.PublicKey, rootKey)
+
+	tsa := &testTSA{
+		root: root,
+		cert: cert,
+		key:  key,
+	}
+	tsa.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		req, err := timestamp.ParseRequest(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		ts := timestamp.Timestamp{
+			HashAlgorithm:     req.HashAlgorithm,
+			HashedMessage:     req.HashedMessage,
+			Time:              time.Now().Add(time.Duration(tsa.offset.Load())),
+			Nonce:             req.Nonce,
+			Policy:            asn1.ObjectIdentifier{1, 2, 3, 4, 1},
+			AddTSACertificate: req.Certificates,
+		}
+		resp, err := ts.CreateResponse(tsa.cert, tsa.key)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/timestamp-reply")
+		_, _ = w.Write(resp)
+	}))
+	t.Cleanup(tsa.Close)
+	return tsa
+}
+
+// signOptions returns the sign options requesting a timestamp from the TSA.
+func (tsa *testTSA) signOptions(t *testing.T) notationgo.SignerSignOptions {
+	t.Helper()
+	timestamper, err := tspclient.NewHTTPTimestamper(nil, tsa.URL)
+	if err != nil {
+		t.Fatalf("failed to create timestamper: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(tsa.root)
+	return notationgo.SignerSignOptions{
+		Timestamper: timestamper,
+		TSARootCAs:  roots,
+	}
+}
+
+func TestVerifier_Timestamp(t *testing.T) {
+	tsa := newTestTSA(t)
+	untrustedTSA := newTestTSA(t)
+
+	// signingCA issues a short-lived signing certificate to test signatures
+	// verified after the expiry of the signing certificate.
+	caKey := generateKey(t)
+	ca := createCertificate(t, &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Country: []string{"US"}, Organization: []string{"Ratify"}, CommonName: "Signing Test Root"},
+		NotBefore:             time.Now().Add(-24 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}, nil, &caKey.PublicKey, caKey)
+	newExpiringSigner := func(t *testing.T, validity time.Duration) notationgo.Signer {
+		key := generateKey(t)
+		leaf := createCertificate(t, &x509.Certificate{
+			SerialNumber: big.NewInt(2),
+			Subject:      pkix.Name{Country: []string{"US"}, Organization: []string{"Ratify"}, CommonName: "Expiring Test Leaf"},
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(validity),
+			KeyUsage:     x509.KeyUsageDigitalSignature,
+			ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		}, ca, &key.PublicKey, caKey)
+		s, err := signer.NewGenericSigner(key, []*x509.Certificate{leaf, ca})
+		if err != nil {
+			t.Fatalf("failed to create signer: %v", err)
+		}
+		return s
+	}
+
+	certificates := []map[string]any{
+		{"inline": encodePEM(ca)},
+		{"type": "tsa", "name": "trusted", "inline": encodePEM(tsa.root)},
+		{"type": "tsa", "name": "other", "inline": encodePEM(untrustedTSA.root)},
+	}
+
+	tests := []struct {
+		name            string
+		timestamp       map[string]any
+		timestamped     bool
+		tsaOffset       time.Duration
+		expired         bool
+		expectErr       bool
+		expectTimestamp bool
+		expectVerified  bool
+	}{
+		{
+			name:            "required timestamp present",
+			timestamp:       map[string]any{"required": true, "tsaTrustStores": []string{"tsa:trusted"}},
+			timestamped:     true,
+			expectTimestamp: true,
+			expectVerified:  true,
+		},
+		{
+			name:      "required timestamp missing",
+			timestamp: map[string]any{"required": true, "tsaTrustStores": []string{"tsa:trusted"}},
+			expectErr: true,
+		},
+		{
+			name:        "required timestamp from untrusted TSA",
+			timestamp:   map[string]any{"required": true, "tsaTrustStores": []string{"tsa:other"}},
+			timestamped: true,
+			expectErr:   true,
+		},
+		{
+			name:      "optional timestamp missing",
+			timestamp: map[string]any{"tsaTrustStores": []string{"tsa:trusted"}},
+		},
+		{
+			name:            "optional timestamp present",
+			timestamp:       map[string]any{"tsaTrustStores": []string{"tsa:trusted"}},
+			timestamped:     true,
+			expectTimestamp: true,
+		},
+		{
+			name:            "timestamp within clock skew",
+			timestamp:       map[string]any{"tsaTrustStores": []string{"tsa:trusted"}, "verifyTimestamp": "always", "clockSkew": "5m"},
+			timestamped:     true,
+			tsaOffset:       time.Minute,
+			expectTimestamp: true,
+			expectVerified:  true,
+		},
+		{
+			name:            "timestamp exceeding clock skew",
+			timestamp:       map[string]any{"tsaTrustStores": []string{"tsa:trusted"}, "verifyTimestamp": "always", "clockSkew": "5m"},
+			timestamped:     true,
+			tsaOffset:       time.Hour,
+			expectErr:       true,
+			expectTimestamp: true,
+			expectVerified:  true,
+		},
+		{
+			name:            "expired signing certificate with trusted timestamp",
+			timestamp:       map[string]any{"tsaTrustStores": []string{"tsa:trusted"}},
+			timestamped:     true,
+			expired:         true,
+			expectTimestamp: true,
+			expectVerified:  true,
+		},
+		{
+			name:      "expired signing certificate without timestamp",
+			timestamp: map[string]any{"tsaTrustStores": []string{"tsa:trusted"}},
+			expired:   true,
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tsa.offset.Store(int64(test.tsaOffset))
+			params := map[string]any{
+				"certificates": certificates,
+			}
+			if test.timestamp != nil {
+				params["timestamp"] = test.timestamp
+			}
+			v := newTestVerifier(t, params)
+
+			var signOpts notationgo.SignerSignOptions
+			if test.timestamped {
+				signOpts = tsa.signOptions(t)
+			}
+			validity := time.Hour
+			if test.expired {
+				validity = 2 * time.Second
+			}
+			opts := signTestArtifact(t, newExpiringSigner(t, validity), signOpts)
+			if test.expired {
+				time.Sleep(3 * time.Second)
+			}
+
+			result, err := v.Verify(context.Background(), opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (result.Err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, result.Err)
+			}
+			detail := result.Detail.(map[string]string)
+			timestamp, ok := detail["Timestamp"]
+			if ok != test.expectTimestamp {
+				t.Fatalf("expected timestamp detail: %v, got: %q", test.expectTimestamp, timestamp)
+			}
+			if !ok {
+				return
+			}
+			if verified := strconv.FormatBool(test.expectVerified); detail["TimestampVerified"] != verified {
+				t.Errorf("expected verified: %s, got: %s", verified, detail["TimestampVerified"])
+			}
+			if detail["TSA"] != tsa.cert.Subject.String() {
+				t.Errorf("expected TSA %q, got %q", tsa.cert.Subject.String(), detail["TSA"])
+			}
+			ts, err := time.Parse(time.RFC3339, timestamp)
+			if err != nil {
+				t.Fatalf("failed to parse timestamp %q: %v", timestamp, err)
+			}
+			if diff := time.Until(ts) - test.tsaOffset; diff.Abs() > time.Minute {
+				t.Errorf("unexpected timestamp time %s", ts)
+			}
+		})
+	}
+}
+
+func TestApplyTimestampOptions(t *testing.T) {
+	tests := []struct {
+		name              string
+		trustStores       []string
+		opts              *timestampOptions
+		expectErr         bool
+		expectTrustStores []string
+		expectPolicy      *timestampPolicy
+	}{
+		{
+			name:              "no options",
+			trustStores:       []string{"ca:ratify"},
+			expectTrustStores: []string{"ca:ratify"},
+		},
+		{
+			name:              "TSA trust stores appended",
+			trustStores:       []string{"ca:ratify", "tsa:a"},
+			opts:              &timestampOptions{Required: true, TSATrustStores: []string{"tsa:a", "tsa:b"}, ClockSkew: "1m"},
+			expectTrustStores: []string{"ca:ratify", "tsa:a", "tsa:b"},
+			expectPolicy:      &timestampPolicy{required: true, clockSkew: time.Minute},
+		},
+		{
+			name:        "TSA trust store of wrong type",
+			trustStores: []string{"ca:ratify"},
+			opts:        &timestampOptions{TSATrustStores: []string{"ca:other"}},
+			expectErr:   true,
+		},
+		{
+			name:        "required without TSA trust store",
+			trustStores: []string{"ca:ratify"},
+			opts:        &timestampOptions{Required: true},
+			expectErr:   true,
+		},
+		{
+			name:        "required verified after cert expiry",
+			trustStores: []string{"ca:ratify", "tsa:a"},
+			opts:        &timestampOptions{Required: true, VerifyTimestamp: "afterCertExpiry"},
+			expectErr:   true,
+		},
+		{
+			name:        "invalid verifyTimestamp",
+			trustStores: []string{"ca:ratify"},
+			opts:        &timestampOptions{VerifyTimestamp: "never"},
+			expectErr:   true,
+		},
+		{
+			name:        "invalid clock skew",
+			trustStores: []string{"ca:ratify"},
+			opts:        &timestampOptions{ClockSkew: "-1m"},
+			expectErr:   true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policy := &trustpolicy.TrustPolicy{
+				Name:        "test",
+				TrustStores: test.trustStores,
+			}
+			tsPolicy, err := applyTimestampOptions(policy, test.opts)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if test.expectErr {
+				return
+			}
+			if len(policy.TrustStores) != len(test.expectTrustStores) {
+				t.Fatalf("expected trust stores %v, got %v", test.expectTrustStores, policy.TrustStores)
+			}
+			for i, storeName := range test.expectTrustStores {
+				if policy.TrustStores[i] != storeName {
+					t.Errorf("expected trust stores %v, got %v", test.expectTrustStores, policy.TrustStores)
+				}
+			}
+			if (tsPolicy == nil) != (test.expectPolicy == nil) || (tsPolicy != nil && *tsPolicy != *test.expectPolicy) {
+				t.Errorf("expected timestamp policy %+v, got %+v", test.expectPolicy, tsPolicy)
+			}
+		})
+	}
+}
@@ -0,0 +1,159 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notation
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+	"github.com/notaryproject/notation-go/verifier/truststore"
+)
+
+const (
+	trustPolicyVersion       = "1.0"
+	defaultTrustPolicyName   = "default"
+	defaultVerificationLevel = "strict"
+)
+
+// trustPolicyOptions is a named trust policy of the Notation verifier.
+type trustPolicyOptions struct {
+	// Name is the unique name of the trust policy. Required.
+	Name string `json:"name"`
+
+	// Scopes is a list of registry scopes the policy applies to. Optional. If
+	// not provided, the default scope is "*".
+	Scopes []string `json:"scopes,omitempty"`
+
+	// TrustStores is a list of trust stores in the "<type>:<name>" format,
+	// e.g. "ca:prod". Each trust store must be configured in the Certificates
+	// of the verifier. Required unless VerificationLevel is "skip".
+	TrustStores []string `json:"trustStores,omitempty"`
+
+	// TrustedIdentities is a list of trusted identities. Optional. If not
+	// provided, the default identity is "*".
+	TrustedIdentities []string `json:"trustedIdentities,omitempty"`
+
+	// VerificationLevel is one of "strict", "permissive", "audit" or "skip".
+	// Signatures under the "skip" level are not verified and fail. Optional.
+	// Defaults to "strict".
+	VerificationLevel string `json:"verificationLevel,omitempty"`
+
+	// Override overrides the action of single checks of the verification
+	// level. Keys are one of "integrity", "authenticity",
+	// "authenticTimestamp", "expiry" or "revocation", and values are one of
+	// "enforce", "log" or "skip", e.g. {"expiry": "log"} to log expired
+	// signatures instead of failing. Optional.
+	Override map[trustpolicy.ValidationType]trustpolicy.ValidationAction `json:"override,omitempty"`
+
+	// Timestamp configures the timestamp countersignature checks. Optional.
+	Timestamp *timestampOptions `json:"timestamp,omitempty"`
+}
+
+// initTrustPolicyDocument returns the trust policy document configured by the
+// options and the timestamp checks of the policies by policy name. It fails if
+// a policy references a trust store that is not configured.
+func initTrustPolicyDocument(opts *options, storeNames []string) (*trustpolicy.Document, map[string]*timestampPolicy, error) {
+	if opts.TrustPolicyDoc != nil || len(opts.TrustPolicies) > 0 {
+		if len(opts.Scopes) > 0 || len(opts.TrustedIdentities) > 0 || opts.VerificationLevel != "" || len(opts.Override) > 0 || opts.Timestamp != nil {
+			return nil, nil, errors.New("scopes, trustedIdentities, verificationLevel, override and timestamp must not be set with trustPolicyDoc or trustPolicies")
+		}
+	}
+
+	var doc *trustpolicy.Document
+	timestampPolicies := make(map[string]*timestampPolicy)
+	switch {
+	case opts.TrustPolicyDoc != nil && len(opts.TrustPolicies) > 0:
+		return nil, nil, errors.New("only one of trustPolicyDoc and trustPolicies can be set")
+	case opts.TrustPolicyDoc != nil:
+		doc = opts.TrustPolicyDoc
+	case len(opts.TrustPolicies) > 0:
+		doc = &trustpolicy.Document{
+			Version: trustPolicyVersion,
+		}
+		for _, policy := range opts.TrustPolicies {
+			trustPolicy := newTrustPolicy(policy.Name, policy.Scopes, policy.TrustStores, policy.TrustedIdentities, policy.VerificationLevel)
+			trustPolicy.SignatureVerification.Override = policy.Override
+			tsPolicy, err := applyTimestampOptions(&trustPolicy, policy.Timestamp)
+			if err != nil {
+				return nil, nil, err
+			}
+			if tsPolicy != nil {
+				timestampPolicies[policy.Name] = tsPolicy
+			}
+			doc.TrustPolicies = append(doc.TrustPolicies, trustPolicy)
+		}
+	default:
+		trustPolicy := newTrustPolicy(defaultTrustPolicyName, opts.Scopes, storeNames, opts.TrustedIdentities, opts.VerificationLevel)
+		trustPolicy.SignatureVerification.Override = opts.Override
+		if trustPolicy.SignatureVerification.VerificationLevel == trustpolicy.LevelSkip.Name {
+			trustPolicy.TrustStores = nil
+		} else if opts.Timestamp != nil && len(opts.Timestamp.TSATrustStores) > 0 {
+			// only the selected TSA trust stores are trusted for timestamps.
+			trustPolicy.TrustStores = slices.DeleteFunc(slices.Clone(trustPolicy.TrustStores), func(storeName string) bool {
+				return strings.HasPrefix(storeName, string(truststore.TypeTSA)+":")
+			})
+		}
+		tsPolicy, err := applyTimestampOptions(&trustPolicy, opts.Timestamp)
+		if err != nil {
+			return nil, nil, err
+		}
+		if tsPolicy != nil {
+			timestampPolicies[defaultTrustPolicyName] = tsPolicy
+		}
+		doc = &trustpolicy.Document{
+			Version:       trustPolicyVersion,
+			TrustPolicies: []trustpolicy.TrustPolicy{trustPolicy},
+		}
+	}
+
+	for _, policy := range doc.TrustPolicies {
+		for _, storeName := range policy.TrustStores {
+			if !slices.Contains(storeNames, storeName) {
+				return nil, nil, fmt.Errorf("trust policy %q references unknown trust store %q, configured trust stores: %v", policy.Name, storeName, storeNames)
+			}
+		}
+	}
+	if err := doc.Validate(); err != nil {
+		return nil, nil, err
+	}
+	return doc, timestampPolicies, nil
+}
+
+// newTrustPolicy returns a trust policy with defaults applied.
+func newTrustPolicy(name string, scopes, trustStores, trustedIdentities []string, level string) trustpolicy.TrustPolicy {
+	if len(scopes) == 0 {
+		scopes = []string{"*"}
+	}
+	if level == "" {
+		level = defaultVerificationLevel
+	}
+	// trusted identities must not be set for the skip level.
+	if len(trustedIdentities) == 0 && level != trustpolicy.LevelSkip.Name {
+		trustedIdentities = []string{"*"}
+	}
+	return trustpolicy.TrustPolicy{
+		Name:           name,
+		RegistryScopes: scopes,
+		SignatureVerification: trustpolicy.SignatureVerification{
+			VerificationLevel: level,
+		},
+		TrustStores:       trustStores,
+		TrustedIdentities: trustedIdentities,
+	}
+}
@@ -0,0 +1,276 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notation
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+)
+
+func TestInitTrustPolicyDocument(t *testing.T) {
+	storeNames := []string{"ca:prod", "ca:staging", "tsa:a", "tsa:b"}
+
+	tests := []struct {
+		name           string
+		opts           *options
+		expectErr      bool
+		expectPolicies []trustpolicy.TrustPolicy
+	}{
+		{
+			name: "default policy",
+			opts: &options{
+				Scopes: []string{"registry.example.com/app"},
+			},
+			expectPolicies: []trustpolicy.TrustPolicy{
+				{
+					Name:                  defaultTrustPolicyName,
+					RegistryScopes:        []string{"registry.example.com/app"},
+					SignatureVerification: trustpolicy.SignatureVerification{VerificationLevel: "strict"},
+					TrustStores:           storeNames,
+					TrustedIdentities:     []string{"*"},
+				},
+			},
+		},
+		{
+			name: "default policy with selected TSA trust store",
+			opts: &options{
+				Timestamp: &timestampOptions{TSATrustStores: []string{"tsa:b"}},
+			},
+			expectPolicies: []trustpolicy.TrustPolicy{
+				{
+					Name:                  defaultTrustPolicyName,
+					RegistryScopes:        []string{"*"},
+					SignatureVerification: trustpolicy.SignatureVerification{VerificationLevel: "strict"},
+					TrustStores:           []string{"ca:prod", "ca:staging", "tsa:b"},
+					TrustedIdentities:     []string{"*"},
+				},
+			},
+		},
+		{
+			name: "timestamp with named policies",
+			opts: &options{
+				Timestamp:     &timestampOptions{TSATrustStores: []string{"tsa:b"}},
+				TrustPolicies: []trustPolicyOptions{{Name: "prod", TrustStores: []string{"ca:prod"}}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "default policy with level and override",
+			opts: &options{
+				VerificationLevel: "permissive",
+				Override: map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+					trustpolicy.TypeExpiry: trustpolicy.ActionLog,
+				},
+			},
+			expectPolicies: []trustpolicy.TrustPolicy{
+				{
+					Name:                  defaultTrustPolicyName,
+					RegistryScopes:        []string{"*"},
+					SignatureVerification: trustpolicy.SignatureVerification{VerificationLevel: "permissive"},
+					TrustStores:           storeNames,
+					TrustedIdentities:     []string{"*"},
+				},
+			},
+		},
+		{
+			name: "default policy with skip level",
+			opts: &options{
+				VerificationLevel: "skip",
+			},
+			expectPolicies: []trustpolicy.TrustPolicy{
+				{
+					Name:                  defaultTrustPolicyName,
+					RegistryScopes:        []string{"*"},
+					SignatureVerification: trustpolicy.SignatureVerification{VerificationLevel: "skip"},
+				},
+			},
+		},
+		{
+			name: "invalid override",
+			opts: &options{
+				TrustPolicies: []trustPolicyOptions{
+					{
+						Name:        "prod",
+						TrustStores: []string{"ca:prod"},
+						Override: map[trustpolicy.ValidationType]trustpolicy.ValidationAction{
+							trustpolicy.TypeIntegrity: trustpolicy.ActionLog,
+						},
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "verification level with named policies",
+			opts: &options{
+				VerificationLevel: "audit",
+				TrustPolicies:     []trustPolicyOptions{{Name: "prod", TrustStores: []string{"ca:prod"}}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "named policies",
+			opts: &options{
+				TrustPolicies: []trustPolicyOptions{
+					{
+						Name:              "prod",
+						Scopes:            []string{"prod.example.com/app"},
+						TrustStores:       []string{"ca:prod"},
+						TrustedIdentities: []string{"x509.subject: C=US, ST=WA, O=Prod, CN=prod"},
+					},
+					{
+						Name:              "staging",
+						Scopes:            []string{"staging.example.com/app"},
+						TrustStores:       []string{"ca:staging"},
+						VerificationLevel: "audit",
+					},
+					{
+						Name:              "unsigned",
+						Scopes:            []string{"dev.example.com/app"},
+						VerificationLevel: "skip",
+					},
+				},
+			},
+			expectPolicies: []trustpolicy.TrustPolicy{
+				{
+					Name:                  "prod",
+					RegistryScopes:        []string{"prod.example.com/app"},
+					SignatureVerification: trustpolicy.SignatureVerification{VerificationLevel: "strict"},
+					TrustStores:           []string{"ca:prod"},
+					TrustedIdentities:     []string{"x509.subject: C=US, ST=WA, O=Prod, CN=prod"},
+				},
+				{
+					Name:                  "staging",
+					RegistryScopes:        []string{"staging.example.com/app"},
+					SignatureVerification: trustpolicy.SignatureVerification{VerificationLevel: "audit"},
+					TrustStores:           []string{"ca:staging"},
+					TrustedIdentities:     []string{"*"},
+				},
+				{
+					Name:                  "unsigned",
+					RegistryScopes:        []string{"dev.example.com/app"},
+					SignatureVerification: trustpolicy.SignatureVerification{VerificationLevel: "skip"},
+				},
+			},
+		},
+		{
+			name: "full trust policy document",
+			opts: &options{
+				TrustPolicyDoc: &trustpolicy.Document{
+					Version: "1.0",
+					TrustPolicies: []trustpolicy.TrustPolicy{
+						{
+							Name:                  "prod",
+							RegistryScopes:        []string{"*"},
+							SignatureVerification: trustpolicy.SignatureVerification{VerificationLevel: "permissive"},
+							TrustStores:           []string{"ca:prod"},
+							TrustedIdentities:     []string{"*"},
+						},
+					},
+				},
+			},
+			expectPolicies: []trustpolicy.TrustPolicy{
+				{
+					Name:                  "prod",
+					RegistryScopes:        []string{"*"},
+					SignatureVerification: trustpolicy.SignatureVerification{VerificationLevel: "permissive"},
+					TrustStores:           []string{"ca:prod"},
+					TrustedIdentities:     []string{"*"},
+				},
+			},
+		},
+		{
+			name: "unknown trust store",
+			opts: &options{
+				TrustPolicies: []trustPolicyOptions{
+					{Name: "prod", TrustStores: []string{"ca:unknown"}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "unknown trust store type",
+			opts: &options{
+				TrustPolicies: []trustPolicyOptions{
+					{Name: "prod", TrustStores: []string{"tsa:prod"}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "both document and named policies",
+			opts: &options{
+				TrustPolicyDoc: &trustpolicy.Document{Version: "1.0"},
+				TrustPolicies:  []trustPolicyOptions{{Name: "prod", TrustStores: []string{"ca:prod"}}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "scopes with named policies",
+			opts: &options{
+				Scopes:        []string{"*"},
+				TrustPolicies: []trustPolicyOptions{{Name: "prod", TrustStores: []string{"ca:prod"}}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "duplicate policy names",
+			opts: &options{
+				TrustPolicies: []trustPolicyOptions{
+					{Name: "prod", Scopes: []string{"a.example.com/app"}, TrustStores: []string{"ca:prod"}},
+					{Name: "prod", Scopes: []string{"b.example.com/app"}, TrustStores: []string{"ca:prod"}},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid verification level",
+			opts: &options{
+				TrustPolicies: []trustPolicyOptions{
+					{Name: "prod", TrustStores: []string{"ca:prod"}, VerificationLevel: "lenient"},
+				},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc, _, err := initTrustPolicyDocument(test.opts, storeNames)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if test.expectErr {
+				return
+			}
+			if len(doc.TrustPolicies) != len(test.expectPolicies) {
+				t.Fatalf("expected %d policies, got %d", len(test.expectPolicies), len(doc.TrustPolicies))
+			}
+			for i, expected := range test.expectPolicies {
+				got := doc.TrustPolicies[i]
+				if got.Name != expected.Name ||
+					got.SignatureVerification.VerificationLevel != expected.SignatureVerification.VerificationLevel ||
+					!slices.Equal(got.RegistryScopes, expected.RegistryScopes) ||
+					!slices.Equal(got.TrustStores, expected.TrustStores) ||
+					!slices.Equal(got.TrustedIdentities, expected.TrustedIdentities) {
+					t.Errorf("expected policy %+v, got %+v", expected, got)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,162 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notation
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	notationgo "github.com/notaryproject/notation-go"
+	notationregistry "github.com/notaryproject/notation-go/registry"
+	"github.com/notaryproject/notation-go/verifier/trustpolicy"
+	"github.com/notaryproject/ratify-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// verifier is a ratify.Verifier implementation that verifies Notation
+// signatures.
+type verifier struct {
+	name           string
+	trustPolicyDoc *trustpolicy.Document
+
+	// timestampPolicies maps trust policy names to the timestamp checks.
+	timestampPolicies map[string]*timestampPolicy
+	verifier          notationgo.Verifier
+
+	// pluginConfig is passed to the verification plugins.
+	pluginConfig map[string]string
+}
+
+// Name returns the name of the verifier.
+func (v *verifier) Name() string {
+	return v.name
+}
+
+// Type returns the type of the verifier which is always `notation`.
+func (v *verifier) Type() string {
+	return notationType
+}
+
+// Verifiable returns true if the artifact is a Notation signature.
+func (v *verifier) Verifiable(artifact ocispec.Descriptor) bool {
+	return artifact.ArtifactType == notationregistry.ArtifactTypeNotation && artifact.MediaType == ocispec.MediaTypeImageManifest
+}
+
+// Verify verifies the Notation signature.
+func (v *verifier) Verify(ctx context.Context, opts *ratify.VerifyOptions) (*ratify.VerificationResult, error) {
+	signatureDesc, err := getSignatureBlobDesc(ctx, opts.Store, opts.Repository, opts.ArtifactDescriptor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get signature blob descriptor: %w", err)
+	}
+
+	signatureBlob, err := opts.Store.FetchBlob(ctx, opts.Repository, signatureDesc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch signature blob: %w", err)
+	}
+
+	result := &ratify.VerificationResult{
+		Verifier: v,
+	}
+	artifactRef := opts.Repository + "@" + opts.SubjectDescriptor.Digest.String()
+	detail := make(map[string]string)
+	policy, err := v.trustPolicyDoc.GetApplicableTrustPolicy(artifactRef)
+	var tsPolicy *timestampPolicy
+	if err == nil {
+		tsPolicy = v.timestampPolicies[policy.Name]
+		detail["TrustPolicy"] = policy.Name
+		detail["VerificationLevel"] = policy.SignatureVerification.VerificationLevel
+	}
+	result.Detail = detail
+
+	outcome, err := v.verifier.Verify(ctx, opts.SubjectDescriptor, signatureBlob, notationgo.VerifierVerifyOptions{
+		SignatureMediaType: signatureDesc.MediaType,
+		ArtifactReference:  artifactRef,
+		PluginConfig:       v.pluginConfig,
+	})
+	if err != nil {
+		result.Err = err
+		return result, nil
+	}
+
+	if outcome.VerificationLevel.Name == trustpolicy.LevelSkip.Name {
+		result.Err = fmt.Errorf("signature of %s is not verified as trust policy %q has verification level %q", artifactRef, detail["TrustPolicy"], trustpolicy.LevelSkip.Name)
+		result.Description = "Notation signature verification skipped by trust policy"
+		return result, nil
+	}
+
+	loggedChecks := getLoggedChecks(outcome)
+	if len(loggedChecks) > 0 {
+		detail["LoggedChecks"] = strings.Join(loggedChecks, ",")
+	}
+	cert := outcome.EnvelopeContent.SignerInfo.CertificateChain[0]
+	detail["Issuer"] = cert.Issuer.String()
+	detail["SN"] = cert.Subject.String()
+
+	timestamp, err := getTimestamp(policy, outcome, loggedChecks)
+	if err != nil {
+		result.Err = err
+		return result, nil
+	}
+	if timestamp != nil {
+		detail["Timestamp"] = timestamp.Time.Format(time.RFC3339)
+		detail["TSA"] = timestamp.TSA
+		detail["TimestampVerified"] = strconv.FormatBool(timestamp.Verified)
+	}
+	if tsPolicy != nil {
+		if err := tsPolicy.check(outcome, timestamp); err != nil {
+			result.Err = err
+			return result, nil
+		}
+	}
+	result.Description = "Notation signature verification succeeded"
+	return result, nil
+}
+
+// getLoggedChecks returns the types of the failed checks that were logged
+// instead of enforced by the verification level or overrides of the trust
+// policy.
+func getLoggedChecks(outcome *notationgo.VerificationOutcome) []string {
+	var checks []string
+	for _, result := range outcome.VerificationResults {
+		if result == nil || result.Error == nil || result.Action != trustpolicy.ActionLog {
+			continue
+		}
+		checks = append(checks, string(result.Type))
+	}
+	return checks
+}
+
+func getSignatureBlobDesc(ctx context.Context, store ratify.Store, repo string, artifactDesc ocispec.Descriptor) (ocispec.Descriptor, error) {
+	manifestBytes, err := store.FetchManifest(ctx, repo, artifactDesc)
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to fetch manifest for artifact: %w", err)
+	}
+
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to unmarshal manifest: %w", err)
+	}
+
+	if len(manifest.Layers) != 1 {
+		return ocispec.Descriptor{}, fmt.Errorf("notation signature manifest requires exactly one signature envelope blob, got %d", len(manifest.Layers))
+	}
+
+	return manifest.Layers[0], nil
+}
@@ -0,0 +1,233 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package notation
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"testing"
+
+	"github.com/notaryproject/notation-core-go/signature/jws"
+	"github.com/notaryproject/notation-core-go/testhelper"
+	notationgo "github.com/notaryproject/notation-go"
+	notationregistry "github.com/notaryproject/notation-go/registry"
+	"github.com/notaryproject/notation-go/signer"
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const testRepo = "test.registry.io/test/image"
+
+// mockStore serves a single signature manifest and its envelope blob.
+type mockStore struct {
+	manifest []byte
+	blob     []byte
+}
+
+func (s *mockStore) Resolve(_ context.Context, _ string) (ocispec.Descriptor, error) {
+	return ocispec.Descriptor{}, errors.New("not implemented")
+}
+
+func (s *mockStore) ListReferrers(_ context.Context, _ string, _ []string, _ func(referrers []ocispec.Descriptor) error) error {
+	return errors.New("not implemented")
+}
+
+func (s *mockStore) FetchBlob(_ context.Context, _ string, _ ocispec.Descriptor) ([]byte, error) {
+	return s.blob, nil
+}
+
+func (s *mockStore) FetchManifest(_ context.Context, _ string, _ ocispec.Descriptor) ([]byte, error) {
+	return s.manifest, nil
+}
+
+// signTestArtifact signs a test subject with the given signer and returns the
+// verify options of the signature.
+func signTestArtifact(t *testing.T, s notationgo.Signer, signOpts notationgo.SignerSignOptions) *ratify.VerifyOptions {
+	t.Helper()
+	subject := ocispec.Descriptor{
+		MediaType: ocispec.MediaTypeImageManifest,
+		Digest:    digest.FromString("subject"),
+		Size:      7,
+	}
+	if signOpts.SignatureMediaType == "" {
+		signOpts.SignatureMediaType = jws.MediaTypeEnvelope
+	}
+	blob, _, err := s.Sign(context.Background(), subject, signOpts)
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	manifest, err := json.Marshal(ocispec.Manifest{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: notationregistry.ArtifactTypeNotation,
+		Config:       ocispec.DescriptorEmptyJSON,
+		Layers: []ocispec.Descriptor{
+			{
+				MediaType: signOpts.SignatureMediaType,
+				Digest:    digest.FromBytes(blob),
+				Size:      int64(len(blob)),
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal manifest: %v", err)
+	}
+	return &ratify.VerifyOptions{
+		Store:             &mockStore{manifest: manifest, blob: blob},
+		Repository:        testRepo,
+		SubjectDescriptor: subject,
+		ArtifactDescriptor: ocispec.Descriptor{
+			MediaType:    ocispec.MediaTypeImageManifest,
+			ArtifactType: notationregistry.ArtifactTypeNotation,
+			Digest:       digest.FromBytes(manifest),
+			Size:         int64(len(manifest)),
+		},
+	}
+}
+
+// newTestSigner returns a signer using the RSA test leaf certificate issued
+// by the RSA test root certificate.
+func newTestSigner(t *testing.T) notationgo.Signer {
+	t.Helper()
+	leaf, root := testhelper.GetRSALeafCertificate(), testhelper.GetRSARootCertificate()
+	s, err := signer.NewGenericSigner(leaf.PrivateKey, []*x509.Certificate{leaf.Cert, root.Cert})
+	if err != nil {
+		t.Fatalf("failed to create signer: %v", err)
+	}
+	return s
+}
+
+func encodePEM(certs ...*x509.Certificate) string {
+	var out []byte
+	for _, cert := range certs {
+		out = append(out, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})...)
+	}
+	return string(out)
+}
+
+func newTestVerifier(t *testing.T, params map[string]any) ratify.Verifier {
+	t.Helper()
+	v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+		Type:       notationType,
+		Name:       testName,
+		Parameters: params,
+	})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+	return v
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	trustedRoot := encodePEM(testhelper.GetRSARootCertificate().Cert)
+	untrustedRoot := encodePEM(testhelper.GetECRootCertificate().Cert)
+
+	tests := []struct {
+		name         string
+		params       map[string]any
+		expectErr    bool
+		expectLevel  string
+		expectLogged string
+	}{
+		{
+			name: "trusted signature",
+			params: map[string]any{
+				"certificates": []map[string]any{{"inline": trustedRoot}},
+			},
+			expectLevel: "strict",
+		},
+		{
+			name: "untrusted signature with strict level",
+			params: map[string]any{
+				"certificates": []map[string]any{{"inline": untrustedRoot}},
+			},
+			expectErr:   true,
+			expectLevel: "strict",
+		},
+		{
+			name: "untrusted signature with audit level",
+			params: map[string]any{
+				"certificates":      []map[string]any{{"inline": untrustedRoot}},
+				"verificationLevel": "audit",
+			},
+			expectLevel:  "audit",
+			expectLogged: "authenticity",
+		},
+		{
+			name: "untrusted signature with authenticity override",
+			params: map[string]any{
+				"certificates": []map[string]any{{"type": "ca", "name": "legacy", "inline": untrustedRoot}},
+				"trustPolicies": []map[string]any{
+					{
+						"name":        "migration",
+						"trustStores": []string{"ca:legacy"},
+						"override":    map[string]string{"authenticity": "log"},
+					},
+				},
+			},
+			expectLevel:  "strict",
+			expectLogged: "authenticity",
+		},
+		{
+			name: "skip level",
+			params: map[string]any{
+				"certificates":      []map[string]any{{"inline": untrustedRoot}},
+				"verificationLevel": "skip",
+			},
+			expectErr:   true,
+			expectLevel: "skip",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := newTestVerifier(t, test.params)
+			opts := signTestArtifact(t, newTestSigner(t), notationgo.SignerSignOptions{})
+			if !v.Verifiable(opts.ArtifactDescriptor) {
+				t.Fatal("expected signature to be verifiable")
+			}
+			result, err := v.Verify(context.Background(), opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (result.Err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, result.Err)
+			}
+			detail := result.Detail.(map[string]string)
+			if detail["VerificationLevel"] != test.expectLevel {
+				t.Errorf("expected verification level %q, got %q", test.expectLevel, detail["VerificationLevel"])
+			}
+			if detail["LoggedChecks"] != test.expectLogged {
+				t.Errorf("expected logged checks %q, got %q", test.expectLogged, detail["LoggedChecks"])
+			}
+		})
+	}
+}
+
+func TestVerifier_InvalidSignatureManifest(t *testing.T) {
+	v := newTestVerifier(t, map[string]any{
+		"certificates": []map[string]any{{"inline": encodePEM(testhelper.GetRSARootCertificate().Cert)}},
+	})
+	opts := signTestArtifact(t, newTestSigner(t), notationgo.SignerSignOptions{})
+	opts.Store = &mockStore{manifest: []byte(`{"layers":[]}`)}
+	if _, err := v.Verify(context.Background(), opts); err == nil {
+		t.Error("expected error for manifest without signature envelope, got nil")
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	pluginCommon "github.com/notaryproject/ratify/v2/pkg/common/plugin"
+)
+
+const (
+	pluginType           = "plugin"
+	defaultVersion       = "1.0.0"
+	defaultTimeout       = 30 * time.Second
+	defaultMaxOutputSize = 1 << 20
+)
+
+// defaultStore is the referrer store configuration passed to plugins by
+// default. It makes plugins fetch content with the built-in ORAS store.
+var defaultStore = map[string]any{"name": "oras"}
+
+type options struct {
+	// Plugin is the name of the plugin executable. Required.
+	Plugin string `json:"plugin"`
+
+	// PluginDirs are the directories the plugin executable is looked up in.
+	// Required.
+	PluginDirs []string `json:"pluginDirs"`
+
+	// ArtifactTypes are the artifact types the plugin verifies. "*" matches
+	// any artifact type but the subject referrer type. Required.
+	ArtifactTypes []string `json:"artifactTypes"`
+
+	// Version is the plugin protocol version passed to the plugin. Optional.
+	// Defaults to "1.0.0".
+	Version string `json:"version,omitempty"`
+
+	// Config is the verifier configuration passed to the plugin. The name of
+	// the verifier is added as "name". Optional.
+	Config map[string]any `json:"config,omitempty"`
+
+	// Store is the referrer store configuration the plugin uses to fetch
+	// content. Optional. Defaults to the ORAS store.
+	Store map[string]any `json:"store,omitempty"`
+
+	// Timeout is the maximum duration of a single plugin invocation, e.g.
+	// "10s". Optional. Defaults to 30 seconds.
+	Timeout string `json:"timeout,omitempty"`
+
+	// MaxOutputSize is the maximum number of bytes the plugin may write to
+	// each of stdout and stderr. Optional. Defaults to 1 MiB.
+	MaxOutputSize int64 `json:"maxOutputSize,omitempty"`
+}
+
+func init() {
+	factory.RegisterVerifierFactory(pluginType, func(opts *factory.NewVerifierOptions) (ratify.Verifier, error) {
+		raw, err := json.Marshal(opts.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal verifier parameters: %w", err)
+		}
+
+		var params options
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal verifier parameters: %w", err)
+		}
+
+		if len(params.ArtifactTypes) == 0 {
+			return nil, errors.New("artifactTypes are required")
+		}
+		path, err := pluginCommon.FindInPaths(params.Plugin, params.PluginDirs)
+		if err != nil {
+			return nil, err
+		}
+		timeout := defaultTimeout
+		if params.Timeout != "" {
+			if timeout, err = time.ParseDuration(params.Timeout); err != nil || timeout <= 0 {
+				return nil, fmt.Errorf("invalid plugin timeout %q", params.Timeout)
+			}
+		}
+		maxOutputSize := params.MaxOutputSize
+		switch {
+		case maxOutputSize < 0:
+			return nil, fmt.Errorf("invalid plugin maxOutputSize %d", maxOutputSize)
+		case maxOutputSize == 0:
+			maxOutputSize = defaultMaxOutputSize
+		}
+		version := params.Version
+		if version == "" {
+			version = defaultVersion
+		}
+		store := params.Store
+		if len(store) == 0 {
+			store = defaultStore
+		}
+		config := make(map[string]any, len(params.Config)+1)
+		for key, val := range params.Config {
+			config[key] = val
+		}
+		config["name"] = opts.Name
+
+		return &verifier{
+			name:          opts.Name,
+			path:          path,
+			pluginDirs:    params.PluginDirs,
+			artifactTypes: params.ArtifactTypes,
+			version:       version,
+			config:        config,
+			store:         store,
+			timeout:       timeout,
+			executor: &pluginCommon.DefaultExecutor{
+				Stderr:        os.Stderr,
+				MaxOutputSize: maxOutputSize,
+			},
+		}, nil
+	})
+}
@@ -0,0 +1,125 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+)
+
+const (
+	testName         = "plugin-test"
+	testPlugin       = "test-verifier"
+	testArtifactType = "application/vnd.test.artifact"
+)
+
+// writePlugin writes an executable plugin script into a new directory and
+// returns the directory.
+func writePlugin(t *testing.T, script string) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, testPlugin), []byte(script), 0o700); err != nil {
+		t.Fatalf("failed to write plugin: %v", err)
+	}
+	return dir
+}
+
+func TestNewVerifier(t *testing.T) {
+	dir := writePlugin(t, "#!/bin/sh\n")
+
+	tests := []struct {
+		name      string
+		params    any
+		expectErr bool
+	}{
+		{
+			name:      "unsupported params",
+			params:    make(chan int),
+			expectErr: true,
+		},
+		{
+			name:      "malformed params",
+			params:    "{",
+			expectErr: true,
+		},
+		{
+			name: "missing artifact types",
+			params: map[string]any{
+				"plugin":     testPlugin,
+				"pluginDirs": []string{dir},
+			},
+			expectErr: true,
+		},
+		{
+			name: "plugin not found",
+			params: map[string]any{
+				"plugin":        "missing",
+				"pluginDirs":    []string{dir},
+				"artifactTypes": []string{testArtifactType},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid timeout",
+			params: map[string]any{
+				"plugin":        testPlugin,
+				"pluginDirs":    []string{dir},
+				"artifactTypes": []string{testArtifactType},
+				"timeout":       "-1s",
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid max output size",
+			params: map[string]any{
+				"plugin":        testPlugin,
+				"pluginDirs":    []string{dir},
+				"artifactTypes": []string{testArtifactType},
+				"maxOutputSize": -1,
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid params",
+			params: map[string]any{
+				"plugin":        testPlugin,
+				"pluginDirs":    []string{dir},
+				"artifactTypes": []string{testArtifactType},
+				"version":       "2.0.0",
+				"config":        map[string]any{"threshold": 3},
+				"store":         map[string]any{"name": "oras", "useHttp": true},
+				"timeout":       "5s",
+				"maxOutputSize": 4096,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := factory.NewVerifier(&factory.NewVerifierOptions{
+				Name:       testName,
+				Type:       pluginType,
+				Parameters: test.params,
+			})
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,140 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/store/subjectreferrer"
+	pluginCommon "github.com/notaryproject/ratify/v2/pkg/common/plugin"
+	"github.com/notaryproject/ratify/v2/pkg/verifier/config"
+	vp "github.com/notaryproject/ratify/v2/pkg/verifier/plugin"
+	"github.com/notaryproject/ratify/v2/pkg/verifier/types"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/ratify-project/ratify/pkg/ocispecs"
+	rc "github.com/ratify-project/ratify/pkg/referrerstore/config"
+)
+
+// verifier is a ratify.Verifier implementation that runs an external verifier
+// plugin using the exec plugin protocol of `pkg/verifier/plugin/skel`.
+type verifier struct {
+	name          string
+	path          string
+	pluginDirs    []string
+	artifactTypes []string
+	version       string
+	config        map[string]any
+	store         map[string]any
+	timeout       time.Duration
+	executor      pluginCommon.Executor
+}
+
+// Name returns the name of the verifier.
+func (v *verifier) Name() string {
+	return v.name
+}
+
+// Type returns the type of the verifier which is always `plugin`.
+func (v *verifier) Type() string {
+	return pluginType
+}
+
+// Verifiable returns true if the artifact is of the configured artifact
+// types.
+func (v *verifier) Verifiable(artifact ocispec.Descriptor) bool {
+	return subjectreferrer.MatchArtifactType(v.artifactTypes, artifact.ArtifactType)
+}
+
+// Verify runs the plugin against the artifact. Failures to run the plugin,
+// including timeouts and oversized output, fail the verification.
+func (v *verifier) Verify(ctx context.Context, opts *ratify.VerifyOptions) (*ratify.VerificationResult, error) {
+	input, err := json.Marshal(config.PluginInputConfig{
+		Config: v.config,
+		StoreConfig: rc.StoreConfig{
+			Version:       v.version,
+			PluginBinDirs: v.pluginDirs,
+			Store:         v.store,
+		},
+		ReferencDesc: ocispecs.ReferenceDescriptor{
+			Descriptor:   opts.ArtifactDescriptor,
+			ArtifactType: opts.ArtifactDescriptor.ArtifactType,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal plugin input: %w", err)
+	}
+	args := vp.VerifierPluginArgs{
+		Command:          vp.VerifyCommand,
+		Version:          v.version,
+		SubjectReference: opts.Repository + "@" + opts.SubjectDescriptor.Digest.String(),
+	}
+
+	result := &ratify.VerificationResult{
+		Verifier: v,
+	}
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+	output, err := v.executor.ExecutePlugin(ctx, v.path, nil, input, args.AsEnviron())
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			result.Err = fmt.Errorf("plugin %s timed out after %s", v.path, v.timeout)
+		} else {
+			result.Err = fmt.Errorf("plugin %s failed: %w", v.path, err)
+		}
+		return result, nil
+	}
+
+	var pluginResult types.VerifierResult
+	if err := json.Unmarshal(output, &pluginResult); err != nil {
+		result.Err = fmt.Errorf("failed to parse output of plugin %s: %w", v.path, err)
+		return result, nil
+	}
+	result.Description = pluginResult.Message
+	if d := detail(pluginResult); d != nil {
+		result.Detail = d
+	}
+	if !pluginResult.IsSuccess {
+		reason := pluginResult.ErrorReason
+		if reason == "" {
+			reason = pluginResult.Message
+		}
+		result.Err = fmt.Errorf("plugin verification failed: %s", reason)
+	}
+	return result, nil
+}
+
+// detail returns the result detail of the plugin result.
+func detail(r types.VerifierResult) map[string]any {
+	d := map[string]any{}
+	if r.Extensions != nil {
+		d["Extensions"] = r.Extensions
+	}
+	if r.ErrorReason != "" {
+		d["ErrorReason"] = r.ErrorReason
+	}
+	if r.Remediation != "" {
+		d["Remediation"] = r.Remediation
+	}
+	if len(d) == 0 {
+		return nil
+	}
+	return d
+}
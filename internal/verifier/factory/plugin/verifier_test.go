@@ -0,0 +1,274 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"runtime"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/store/subjectreferrer"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	"github.com/notaryproject/ratify/v2/pkg/verifier/config"
+	vp "github.com/notaryproject/ratify/v2/pkg/verifier/plugin"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const testRepo = "registry.test/repo"
+
+// mockExecutor records the plugin invocation and returns the configured
+// output. It blocks until the context is done if block is set.
+type mockExecutor struct {
+	output  []byte
+	err     error
+	block   bool
+	stdin   []byte
+	environ []string
+}
+
+func (e *mockExecutor) ExecutePlugin(ctx context.Context, _ string, _ []string, stdinData []byte, environ []string) ([]byte, error) {
+	e.stdin = stdinData
+	e.environ = environ
+	if e.block {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+	return e.output, e.err
+}
+
+func (e *mockExecutor) FindInPaths(_ string, _ []string) (string, error) {
+	return "", errors.New("not implemented")
+}
+
+func newVerifyOptions() *ratify.VerifyOptions {
+	return &ratify.VerifyOptions{
+		Repository: testRepo,
+		SubjectDescriptor: ocispec.Descriptor{
+			MediaType: ocispec.MediaTypeImageManifest,
+			Digest:    digest.FromString("subject"),
+			Size:      7,
+		},
+		ArtifactDescriptor: ocispec.Descriptor{
+			MediaType:    ocispec.MediaTypeImageManifest,
+			ArtifactType: testArtifactType,
+			Digest:       digest.FromString("artifact"),
+			Size:         8,
+		},
+	}
+}
+
+func newTestVerifier(executor *mockExecutor) *verifier {
+	return &verifier{
+		name:          testName,
+		path:          testPlugin,
+		artifactTypes: []string{testArtifactType},
+		version:       defaultVersion,
+		config:        map[string]any{"name": testName},
+		store:         defaultStore,
+		timeout:       time.Second,
+		executor:      executor,
+	}
+}
+
+func TestVerifier_Verifiable(t *testing.T) {
+	v := newTestVerifier(&mockExecutor{})
+	if !v.Verifiable(ocispec.Descriptor{ArtifactType: testArtifactType}) {
+		t.Error("expected configured artifact type to be verifiable")
+	}
+	if v.Verifiable(ocispec.Descriptor{ArtifactType: "application/spdx+json"}) {
+		t.Error("expected other artifact type not to be verifiable")
+	}
+	v.artifactTypes = []string{"*"}
+	if !v.Verifiable(ocispec.Descriptor{ArtifactType: "application/spdx+json"}) {
+		t.Error("expected any artifact type to be verifiable")
+	}
+	if v.Verifiable(subjectreferrer.Descriptor("")) {
+		t.Error("expected the subject referrer not to be verifiable for any artifact type")
+	}
+	if v.Name() != testName {
+		t.Errorf("expected name %s, got %s", testName, v.Name())
+	}
+	if v.Type() != pluginType {
+		t.Errorf("expected type %s, got %s", pluginType, v.Type())
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	tests := []struct {
+		name              string
+		executor          *mockExecutor
+		expectErr         bool
+		expectDescription string
+		expectDetail      bool
+	}{
+		{
+			name:              "success",
+			executor:          &mockExecutor{output: []byte(`{"isSuccess": true, "message": "ok", "extensions": {"count": 1}}`)},
+			expectDescription: "ok",
+			expectDetail:      true,
+		},
+		{
+			name:              "failure",
+			executor:          &mockExecutor{output: []byte(`{"isSuccess": false, "message": "failed", "errorReason": "bad", "remediation": "fix"}`)},
+			expectErr:         true,
+			expectDescription: "failed",
+			expectDetail:      true,
+		},
+		{
+			name:              "failure without reason",
+			executor:          &mockExecutor{output: []byte(`{"isSuccess": false, "message": "failed"}`)},
+			expectErr:         true,
+			expectDescription: "failed",
+		},
+		{
+			name:      "malformed output",
+			executor:  &mockExecutor{output: []byte(`not json`)},
+			expectErr: true,
+		},
+		{
+			name:      "plugin failed",
+			executor:  &mockExecutor{err: errors.New("exit status 1")},
+			expectErr: true,
+		},
+		{
+			name:      "plugin timed out",
+			executor:  &mockExecutor{block: true},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := newTestVerifier(test.executor)
+			v.timeout = 10 * time.Millisecond
+			result, err := v.Verify(context.Background(), newVerifyOptions())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (result.Err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, result.Err)
+			}
+			if result.Description != test.expectDescription {
+				t.Errorf("expected description %q, got %q", test.expectDescription, result.Description)
+			}
+			if (result.Detail != nil) != test.expectDetail {
+				t.Errorf("expected detail: %v, got: %v", test.expectDetail, result.Detail)
+			}
+		})
+	}
+}
+
+func TestVerifier_Verify_Input(t *testing.T) {
+	executor := &mockExecutor{output: []byte(`{"isSuccess": true}`)}
+	opts := newVerifyOptions()
+	if _, err := newTestVerifier(executor).Verify(context.Background(), opts); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var input config.PluginInputConfig
+	if err := json.Unmarshal(executor.stdin, &input); err != nil {
+		t.Fatalf("failed to unmarshal plugin input: %v", err)
+	}
+	if input.Config["name"] != testName {
+		t.Errorf("expected verifier name %s in config, got %v", testName, input.Config["name"])
+	}
+	if input.StoreConfig.Store["name"] != "oras" || input.StoreConfig.Version != defaultVersion {
+		t.Errorf("unexpected store config %v", input.StoreConfig)
+	}
+	if input.ReferencDesc.Digest != opts.ArtifactDescriptor.Digest || input.ReferencDesc.ArtifactType != testArtifactType {
+		t.Errorf("unexpected reference descriptor %v", input.ReferencDesc)
+	}
+
+	for _, env := range []string{
+		vp.CommandEnvKey + "=" + vp.VerifyCommand,
+		vp.VersionEnvKey + "=" + defaultVersion,
+		vp.SubjectEnvKey + "=" + testRepo + "@" + opts.SubjectDescriptor.Digest.String(),
+	} {
+		if !slices.Contains(executor.environ, env) {
+			t.Errorf("expected environment variable %s", env)
+		}
+	}
+}
+
+func TestVerifier_Verify_Exec(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	tests := []struct {
+		name          string
+		script        string
+		maxOutputSize int
+		expectErr     bool
+	}{
+		{
+			name:   "plugin protocol",
+			script: "#!/bin/sh\ncat > /dev/null\n[ \"$RATIFY_VERIFIER_COMMAND\" = VERIFY ] || exit 1\necho '{\"isSuccess\": true, \"message\": \"ok\"}'\n",
+		},
+		{
+			name:      "plugin error",
+			script:    "#!/bin/sh\necho '{\"code\": 8, \"msg\": \"failed\"}'\nexit 1\n",
+			expectErr: true,
+		},
+		{
+			name:          "output exceeds limit",
+			script:        "#!/bin/sh\necho '{\"isSuccess\": true, \"message\": \"" + strings.Repeat("x", 128) + "\"}'\n",
+			maxOutputSize: 64,
+			expectErr:     true,
+		},
+		{
+			name:      "timeout",
+			script:    "#!/bin/sh\nexec sleep 5\n",
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			params := map[string]any{
+				"plugin":        testPlugin,
+				"pluginDirs":    []string{writePlugin(t, test.script)},
+				"artifactTypes": []string{testArtifactType},
+				"timeout":       "500ms",
+			}
+			if test.maxOutputSize > 0 {
+				params["maxOutputSize"] = test.maxOutputSize
+			}
+			v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+				Name:       testName,
+				Type:       pluginType,
+				Parameters: params,
+			})
+			if err != nil {
+				t.Fatalf("failed to create verifier: %v", err)
+			}
+			result, err := v.Verify(context.Background(), newVerifyOptions())
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (result.Err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, result.Err)
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// materialRule is a material required in the provenance.
+type materialRule struct {
+	// URI is the URI of the material, e.g.
+	// "git+https://github.com/org/repo@refs/heads/main". A trailing "*"
+	// matches any suffix. Required.
+	URI string `json:"uri"`
+
+	// Digest is the required digest of the material by algorithm, e.g.
+	// {"sha1": "..."}. Optional. If not provided, any digest matches.
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// expectations are the expected build information of the provenance. Empty
+// expectations are not checked. Patterns ending with "*" match any suffix.
+type expectations struct {
+	// BuilderIDs are the patterns of trusted builder IDs, e.g.
+	// "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v*".
+	// Optional.
+	BuilderIDs []string `json:"builderIDs,omitempty"`
+
+	// BuildTypes are the patterns of expected build types. Optional.
+	BuildTypes []string `json:"buildTypes,omitempty"`
+
+	// SourceRepositories are the patterns of expected source repositories,
+	// e.g. "https://github.com/org/repo". The "git+" prefix and ".git" suffix
+	// are ignored. Optional.
+	SourceRepositories []string `json:"sourceRepositories,omitempty"`
+
+	// SourceRefs are the patterns of expected source refs, e.g.
+	// "refs/heads/main" or "refs/tags/v*". Optional.
+	SourceRefs []string `json:"sourceRefs,omitempty"`
+
+	// RequiredMaterials are the materials required in the provenance.
+	// Optional.
+	RequiredMaterials []materialRule `json:"requiredMaterials,omitempty"`
+
+	// PredicateTypes are the accepted SLSA provenance predicate types.
+	// Optional. Defaults to SLSA v0.2 and v1.
+	PredicateTypes []string `json:"predicateTypes,omitempty"`
+}
+
+// validate checks the patterns of the expectations.
+func (e *expectations) validate() error {
+	for _, m := range e.RequiredMaterials {
+		if m.URI == "" {
+			return fmt.Errorf("required material requires uri")
+		}
+	}
+	for _, patterns := range [][]string{e.BuilderIDs, e.BuildTypes, e.SourceRepositories, e.SourceRefs} {
+		if slices.Contains(patterns, "") {
+			return fmt.Errorf("empty pattern in provenance expectations")
+		}
+	}
+	return nil
+}
+
+// check returns the violations of the expectations by the provenance.
+func (e *expectations) check(p *provenance) []string {
+	var violations []string
+	if len(e.PredicateTypes) > 0 && !slices.Contains(e.PredicateTypes, p.predicateType) {
+		violations = append(violations, fmt.Sprintf("predicate type %q is not accepted", p.predicateType))
+	}
+	if len(e.BuilderIDs) > 0 && !matchAny(e.BuilderIDs, p.builderID, strings.EqualFold) {
+		violations = append(violations, fmt.Sprintf("builder ID %q is not trusted", p.builderID))
+	}
+	if len(e.BuildTypes) > 0 && !matchAny(e.BuildTypes, p.buildType, strings.EqualFold) {
+		violations = append(violations, fmt.Sprintf("build type %q is not expected", p.buildType))
+	}
+	if len(e.SourceRepositories) > 0 {
+		normalized := make([]string, len(e.SourceRepositories))
+		for i, repository := range e.SourceRepositories {
+			normalized[i] = normalizeRepository(repository)
+		}
+		if !matchAny(normalized, p.sourceRepository, strings.EqualFold) {
+			violations = append(violations, fmt.Sprintf("source repository %q is not expected", p.sourceRepository))
+		}
+	}
+	if len(e.SourceRefs) > 0 && !matchAny(e.SourceRefs, p.sourceRef, equalString) {
+		violations = append(violations, fmt.Sprintf("source ref %q is not expected", p.sourceRef))
+	}
+	for _, rule := range e.RequiredMaterials {
+		if !slices.ContainsFunc(p.materials, rule.matches) {
+			violations = append(violations, fmt.Sprintf("required material %q not found", rule.URI))
+		}
+	}
+	return violations
+}
+
+// matches returns true if the material has the URI and digest of the rule.
+func (r materialRule) matches(m material) bool {
+	if !matchPattern(r.URI, m.URI, equalString) {
+		return false
+	}
+	for algorithm, value := range r.Digest {
+		if !strings.EqualFold(m.Digest[algorithm], value) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchAny returns true if the value matches any of the patterns.
+func matchAny(patterns []string, value string, equal func(a, b string) bool) bool {
+	for _, pattern := range patterns {
+		if matchPattern(pattern, value, equal) {
+			return true
+		}
+	}
+	return false
+}
+
+// matchPattern returns true if the value equals the pattern, or has the
+// prefix of a pattern ending with "*".
+func matchPattern(pattern, value string, equal func(a, b string) bool) bool {
+	if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+		return len(value) >= len(prefix) && equal(value[:len(prefix)], prefix)
+	}
+	return equal(value, pattern)
+}
+
+func equalString(a, b string) bool {
+	return a == b
+}
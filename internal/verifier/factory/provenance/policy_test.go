@@ -0,0 +1,139 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"testing"
+)
+
+func TestExpectations_Validate(t *testing.T) {
+	tests := []struct {
+		name         string
+		expectations expectations
+		expectErr    bool
+	}{
+		{
+			name: "valid expectations",
+			expectations: expectations{
+				BuilderIDs:        []string{"https://github.com/actions/runner*"},
+				RequiredMaterials: []materialRule{{URI: "git+https://github.com/org/repo*"}},
+			},
+		},
+		{
+			name:         "empty pattern",
+			expectations: expectations{SourceRefs: []string{""}},
+			expectErr:    true,
+		},
+		{
+			name:         "required material without uri",
+			expectations: expectations{RequiredMaterials: []materialRule{{Digest: map[string]string{"sha1": "abc"}}}},
+			expectErr:    true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := test.expectations.validate(); (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestExpectations_Check(t *testing.T) {
+	p := &provenance{
+		predicateType:    predicateV1,
+		builderID:        "https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v2.0.0",
+		buildType:        "https://slsa-framework.github.io/github-actions-buildtypes/workflow/v1",
+		sourceRepository: "https://github.com/org/repo",
+		sourceRef:        "refs/tags/v1.0.0",
+		materials: []material{
+			{URI: "git+https://github.com/org/repo@refs/tags/v1.0.0", Digest: map[string]string{"gitCommit": "ABC"}},
+		},
+	}
+
+	tests := []struct {
+		name             string
+		expectations     expectations
+		expectViolations int
+	}{
+		{
+			name: "no expectations",
+		},
+		{
+			name: "all expectations met",
+			expectations: expectations{
+				PredicateTypes:     []string{predicateV1},
+				BuilderIDs:         []string{"https://github.com/slsa-framework/slsa-github-generator/.github/workflows/generator_container_slsa3.yml@refs/tags/v*"},
+				BuildTypes:         []string{"HTTPS://slsa-framework.github.io/github-actions-buildtypes/workflow/v1"},
+				SourceRepositories: []string{"git+https://github.com/org/other", "git+https://github.com/org/repo.git"},
+				SourceRefs:         []string{"refs/tags/*"},
+				RequiredMaterials: []materialRule{
+					{URI: "git+https://github.com/org/repo@*", Digest: map[string]string{"gitCommit": "abc"}},
+				},
+			},
+		},
+		{
+			name: "untrusted builder and unexpected ref",
+			expectations: expectations{
+				BuilderIDs: []string{"https://github.com/actions/runner"},
+				SourceRefs: []string{"refs/heads/main"},
+			},
+			expectViolations: 2,
+		},
+		{
+			name: "source ref is case sensitive",
+			expectations: expectations{
+				SourceRefs: []string{"refs/tags/V*"},
+			},
+			expectViolations: 1,
+		},
+		{
+			name: "unaccepted predicate type and build type",
+			expectations: expectations{
+				PredicateTypes: []string{predicateV02},
+				BuildTypes:     []string{"https://example.com/buildtype"},
+			},
+			expectViolations: 2,
+		},
+		{
+			name: "unexpected source repository",
+			expectations: expectations{
+				SourceRepositories: []string{"https://github.com/org/other"},
+			},
+			expectViolations: 1,
+		},
+		{
+			name: "required material with other digest",
+			expectations: expectations{
+				RequiredMaterials: []materialRule{
+					{URI: "git+https://github.com/org/repo@refs/tags/v1.0.0", Digest: map[string]string{"gitCommit": "def"}},
+					{URI: "pkg:docker/golang*"},
+				},
+			},
+			expectViolations: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			violations := test.expectations.check(p)
+			if len(violations) != test.expectViolations {
+				t.Errorf("expected %d violations, got %v", test.expectViolations, violations)
+			}
+		})
+	}
+}
@@ -0,0 +1,176 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	slsa02 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v0.2"
+	slsa1 "github.com/in-toto/in-toto-golang/in_toto/slsa_provenance/v1"
+	"github.com/notaryproject/ratify/v2/internal/verifier/attestation"
+)
+
+// material is an input of the build.
+type material struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest,omitempty"`
+}
+
+// provenance is the build information of a SLSA provenance predicate relevant
+// for the verification, independent of the SLSA version.
+type provenance struct {
+	predicateType    string
+	builderID        string
+	buildType        string
+	sourceRepository string
+	sourceRef        string
+	materials        []material
+}
+
+// isProvenance returns true if the statement is a SLSA provenance statement.
+func isProvenance(s *attestation.Statement) bool {
+	return s.PredicateType == slsa02.PredicateSLSAProvenance || s.PredicateType == slsa1.PredicateSLSAProvenance
+}
+
+// parseProvenance parses the SLSA provenance predicate of the statement.
+func parseProvenance(s *attestation.Statement) (*provenance, error) {
+	switch s.PredicateType {
+	case slsa02.PredicateSLSAProvenance:
+		var predicate slsa02.ProvenancePredicate
+		if err := json.Unmarshal(s.Predicate, &predicate); err != nil {
+			return nil, fmt.Errorf("failed to parse SLSA v0.2 provenance: %w", err)
+		}
+		return provenanceFromV02(&predicate), nil
+	case slsa1.PredicateSLSAProvenance:
+		var predicate slsa1.ProvenancePredicate
+		if err := json.Unmarshal(s.Predicate, &predicate); err != nil {
+			return nil, fmt.Errorf("failed to parse SLSA v1 provenance: %w", err)
+		}
+		return provenanceFromV1(&predicate)
+	default:
+		return nil, fmt.Errorf("unsupported predicate type %q", s.PredicateType)
+	}
+}
+
+// provenanceFromV02 returns the build information of a SLSA v0.2 predicate.
+// The source is the config source of the invocation.
+func provenanceFromV02(predicate *slsa02.ProvenancePredicate) *provenance {
+	p := &provenance{
+		predicateType: slsa02.PredicateSLSAProvenance,
+		builderID:     predicate.Builder.ID,
+		buildType:     predicate.BuildType,
+	}
+	p.sourceRepository, p.sourceRef = parseSourceURI(predicate.Invocation.ConfigSource.URI)
+	for _, m := range predicate.Materials {
+		p.materials = append(p.materials, material{
+			URI:    m.URI,
+			Digest: m.Digest,
+		})
+	}
+	return p
+}
+
+// provenanceFromV1 returns the build information of a SLSA v1 predicate. The
+// source is the workflow of the external parameters as set by GitHub Actions
+// build types, the source external parameter, or the first git dependency.
+func provenanceFromV1(predicate *slsa1.ProvenancePredicate) (*provenance, error) {
+	p := &provenance{
+		predicateType: slsa1.PredicateSLSAProvenance,
+		builderID:     predicate.RunDetails.Builder.ID,
+		buildType:     predicate.BuildDefinition.BuildType,
+	}
+	for _, dependency := range predicate.BuildDefinition.ResolvedDependencies {
+		p.materials = append(p.materials, material{
+			URI:    dependency.URI,
+			Digest: dependency.Digest,
+		})
+	}
+
+	raw, err := json.Marshal(predicate.BuildDefinition.ExternalParameters)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal external parameters: %w", err)
+	}
+	var params struct {
+		Workflow *struct {
+			Repository string `json:"repository"`
+			Ref        string `json:"ref"`
+		} `json:"workflow"`
+		Source any `json:"source"`
+	}
+	// external parameters are build type specific and may not be an object.
+	_ = json.Unmarshal(raw, &params)
+	switch {
+	case params.Workflow != nil && params.Workflow.Repository != "":
+		p.sourceRepository = normalizeRepository(params.Workflow.Repository)
+		p.sourceRef = params.Workflow.Ref
+	case sourceString(params.Source) != "":
+		p.sourceRepository, p.sourceRef = parseSourceURI(sourceString(params.Source))
+	default:
+		for _, m := range p.materials {
+			if strings.HasPrefix(m.URI, "git+") {
+				p.sourceRepository, p.sourceRef = parseSourceURI(m.URI)
+				break
+			}
+		}
+	}
+	return p, nil
+}
+
+// sourceString returns the URI of a source external parameter, which is
+// either a URI or a resource descriptor.
+func sourceString(source any) string {
+	switch s := source.(type) {
+	case string:
+		return s
+	case map[string]any:
+		uri, _ := s["uri"].(string)
+		return uri
+	default:
+		return ""
+	}
+}
+
+// parseSourceURI splits a source URI like
+// "git+https://github.com/org/repo@refs/heads/main" into the normalized
+// repository and the ref.
+func parseSourceURI(uri string) (repository, ref string) {
+	if uri == "" {
+		return "", ""
+	}
+	// the ref follows the last "@" after the host, if any.
+	rest := strings.TrimPrefix(uri, "git+")
+	scheme, path, ok := strings.Cut(rest, "://")
+	if !ok {
+		scheme, path = "", rest
+	}
+	if i := strings.LastIndex(path, "@"); i > strings.Index(path, "/") && strings.Contains(path, "/") {
+		path, ref = path[:i], path[i+1:]
+	}
+	if scheme != "" {
+		path = scheme + "://" + path
+	}
+	return normalizeRepository(path), ref
+}
+
+// normalizeRepository normalizes the repository URI for comparison by
+// removing the "git+" prefix and ".git" suffix.
+func normalizeRepository(repository string) string {
+	repository = strings.TrimPrefix(repository, "git+")
+	repository = strings.TrimSuffix(repository, "/")
+	return strings.TrimSuffix(repository, ".git")
+}
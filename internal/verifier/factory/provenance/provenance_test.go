@@ -0,0 +1,193 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/notaryproject/ratify/v2/internal/verifier/attestation"
+	"github.com/notaryproject/ratify/v2/internal/verifier/attestation/attestationtest"
+	"github.com/opencontainers/go-digest"
+)
+
+const (
+	predicateV02 = "https://slsa.dev/provenance/v0.2"
+	predicateV1  = "https://slsa.dev/provenance/v1"
+)
+
+func TestParseProvenance(t *testing.T) {
+	subject := digest.FromString("subject")
+	tests := []struct {
+		name          string
+		predicateType string
+		predicate     any
+		expectErr     bool
+		expect        provenance
+	}{
+		{
+			name:          "SLSA v0.2",
+			predicateType: predicateV02,
+			predicate: map[string]any{
+				"builder":   map[string]any{"id": "https://github.com/actions/runner"},
+				"buildType": "https://github.com/slsa-framework/slsa-github-generator/container@v1",
+				"invocation": map[string]any{
+					"configSource": map[string]any{
+						"uri": "git+https://github.com/org/repo.git@refs/heads/main",
+					},
+				},
+				"materials": []map[string]any{
+					{"uri": "git+https://github.com/org/repo@refs/heads/main", "digest": map[string]string{"sha1": "abc"}},
+				},
+			},
+			expect: provenance{
+				predicateType:    predicateV02,
+				builderID:        "https://github.com/actions/runner",
+				buildType:        "https://github.com/slsa-framework/slsa-github-generator/container@v1",
+				sourceRepository: "https://github.com/org/repo",
+				sourceRef:        "refs/heads/main",
+				materials: []material{
+					{URI: "git+https://github.com/org/repo@refs/heads/main", Digest: map[string]string{"sha1": "abc"}},
+				},
+			},
+		},
+		{
+			name:          "SLSA v1 with workflow",
+			predicateType: predicateV1,
+			predicate: map[string]any{
+				"buildDefinition": map[string]any{
+					"buildType": "https://actions.github.io/buildtypes/workflow/v1",
+					"externalParameters": map[string]any{
+						"workflow": map[string]any{
+							"repository": "https://github.com/org/repo",
+							"ref":        "refs/tags/v1.0.0",
+							"path":       ".github/workflows/release.yml",
+						},
+					},
+				},
+				"runDetails": map[string]any{
+					"builder": map[string]any{"id": "https://github.com/actions/runner/github-hosted"},
+				},
+			},
+			expect: provenance{
+				predicateType:    predicateV1,
+				builderID:        "https://github.com/actions/runner/github-hosted",
+				buildType:        "https://actions.github.io/buildtypes/workflow/v1",
+				sourceRepository: "https://github.com/org/repo",
+				sourceRef:        "refs/tags/v1.0.0",
+			},
+		},
+		{
+			name:          "SLSA v1 with source parameter",
+			predicateType: predicateV1,
+			predicate: map[string]any{
+				"buildDefinition": map[string]any{
+					"externalParameters": map[string]any{
+						"source": map[string]any{"uri": "git+https://gitlab.com/org/repo@refs/heads/main"},
+					},
+				},
+			},
+			expect: provenance{
+				predicateType:    predicateV1,
+				sourceRepository: "https://gitlab.com/org/repo",
+				sourceRef:        "refs/heads/main",
+			},
+		},
+		{
+			name:          "SLSA v1 with git dependency",
+			predicateType: predicateV1,
+			predicate: map[string]any{
+				"buildDefinition": map[string]any{
+					"externalParameters": "opaque",
+					"resolvedDependencies": []map[string]any{
+						{"uri": "pkg:docker/golang@1.24"},
+						{"uri": "git+https://github.com/org/repo@refs/heads/release", "digest": map[string]string{"gitCommit": "def"}},
+					},
+				},
+			},
+			expect: provenance{
+				predicateType:    predicateV1,
+				sourceRepository: "https://github.com/org/repo",
+				sourceRef:        "refs/heads/release",
+				materials: []material{
+					{URI: "pkg:docker/golang@1.24"},
+					{URI: "git+https://github.com/org/repo@refs/heads/release", Digest: map[string]string{"gitCommit": "def"}},
+				},
+			},
+		},
+		{
+			name:          "invalid SLSA v0.2 predicate",
+			predicateType: predicateV02,
+			predicate:     "invalid",
+			expectErr:     true,
+		},
+		{
+			name:          "invalid SLSA v1 predicate",
+			predicateType: predicateV1,
+			predicate:     []string{"invalid"},
+			expectErr:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			s, err := attestation.ParseStatement(attestation.PayloadTypeInToto, attestationtest.NewStatement(t, test.predicateType, subject, test.predicate))
+			if err != nil {
+				t.Fatalf("failed to parse statement: %v", err)
+			}
+			p, err := parseProvenance(s)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			got, _ := json.Marshal(p.materials)
+			expected, _ := json.Marshal(test.expect.materials)
+			if p.predicateType != test.expect.predicateType ||
+				p.builderID != test.expect.builderID ||
+				p.buildType != test.expect.buildType ||
+				p.sourceRepository != test.expect.sourceRepository ||
+				p.sourceRef != test.expect.sourceRef ||
+				string(got) != string(expected) {
+				t.Errorf("expected provenance %+v, got %+v", test.expect, *p)
+			}
+		})
+	}
+}
+
+func TestParseSourceURI(t *testing.T) {
+	tests := []struct {
+		uri              string
+		expectRepository string
+		expectRef        string
+	}{
+		{"", "", ""},
+		{"git+https://github.com/org/repo@refs/heads/main", "https://github.com/org/repo", "refs/heads/main"},
+		{"https://github.com/org/repo.git", "https://github.com/org/repo", ""},
+		{"git+ssh://git@github.com/org/repo.git@v1.0.0", "ssh://git@github.com/org/repo", "v1.0.0"},
+		{"github.com/org/repo/", "github.com/org/repo", ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.uri, func(t *testing.T) {
+			repository, ref := parseSourceURI(test.uri)
+			if repository != test.expectRepository || ref != test.expectRef {
+				t.Errorf("expected (%q, %q), got (%q, %q)", test.expectRepository, test.expectRef, repository, ref)
+			}
+		})
+	}
+}
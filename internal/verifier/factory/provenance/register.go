@@ -0,0 +1,71 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/attestation"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+)
+
+const provenanceType = "provenance"
+
+type options struct {
+	// Keys are the key providers of the trusted keys. Required.
+	Keys []attestation.KeyOptions `json:"keys"`
+
+	// ArtifactTypes are the artifact types of the provenance attestations.
+	// Optional. Defaults to the in-toto and DSSE artifact types.
+	ArtifactTypes []string `json:"artifactTypes,omitempty"`
+
+	expectations
+}
+
+func init() {
+	factory.RegisterVerifierFactory(provenanceType, func(opts *factory.NewVerifierOptions) (ratify.Verifier, error) {
+		raw, err := json.Marshal(opts.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal verifier parameters: %w", err)
+		}
+
+		var params options
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal verifier parameters: %w", err)
+		}
+
+		keys, err := attestation.LoadKeys(params.Keys)
+		if err != nil {
+			return nil, err
+		}
+		if err := params.validate(); err != nil {
+			return nil, err
+		}
+		artifactTypes := params.ArtifactTypes
+		if len(artifactTypes) == 0 {
+			artifactTypes = defaultArtifactTypes
+		}
+
+		return &verifier{
+			name:          opts.Name,
+			artifactTypes: artifactTypes,
+			keys:          keys,
+			expectations:  params.expectations,
+		}, nil
+	})
+}
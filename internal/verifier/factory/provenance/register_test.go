@@ -0,0 +1,102 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"testing"
+
+	"github.com/notaryproject/ratify/v2/internal/verifier/attestation/attestationtest"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+)
+
+const testName = "provenance-test"
+
+func TestNewVerifier(t *testing.T) {
+	key := attestationtest.NewKey(t, "ratify-provenance-test")
+
+	tests := []struct {
+		name      string
+		params    any
+		expectErr bool
+	}{
+		{
+			name:      "unsupported params",
+			params:    make(chan int),
+			expectErr: true,
+		},
+		{
+			name:      "malformed params",
+			params:    "{",
+			expectErr: true,
+		},
+		{
+			name:      "missing keys",
+			params:    map[string]any{},
+			expectErr: true,
+		},
+		{
+			name: "non-registered key provider",
+			params: map[string]any{
+				"keys": []map[string]any{{"non-registered": nil}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid certificates",
+			params: map[string]any{
+				"keys": []map[string]any{{"inline": "invalid"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "empty builder ID pattern",
+			params: map[string]any{
+				"keys":       []map[string]any{{"inline": key.PEM}},
+				"builderIDs": []string{""},
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid options",
+			params: map[string]any{
+				"keys":               []map[string]any{{"inline": key.PEM}},
+				"artifactTypes":      []string{"application/vnd.in-toto+json"},
+				"builderIDs":         []string{"https://github.com/actions/runner*"},
+				"sourceRepositories": []string{"https://github.com/org/repo"},
+				"requiredMaterials":  []map[string]any{{"uri": "git+https://github.com/org/repo*"}},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+				Type:       provenanceType,
+				Name:       testName,
+				Parameters: test.params,
+			})
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if v.Name() != testName || v.Type() != provenanceType {
+				t.Errorf("unexpected verifier %s of type %s", v.Name(), v.Type())
+			}
+		})
+	}
+}
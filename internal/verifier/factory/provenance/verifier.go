@@ -0,0 +1,148 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/artifact"
+	"github.com/notaryproject/ratify/v2/internal/verifier/attestation"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// defaultArtifactTypes are the artifact types verified by default.
+var defaultArtifactTypes = []string{attestation.ArtifactTypeInToto, attestation.ArtifactTypeDSSE}
+
+// provenanceDetail describes a verified SLSA provenance statement.
+type provenanceDetail struct {
+	// PredicateType is the SLSA provenance predicate type.
+	PredicateType string `json:"predicateType"`
+
+	// Signer is the subject of the certificate of the key verifying the
+	// signature.
+	Signer string `json:"signer"`
+
+	// BuilderID is the ID of the builder.
+	BuilderID string `json:"builderID,omitempty"`
+
+	// BuildType is the type of the build.
+	BuildType string `json:"buildType,omitempty"`
+
+	// SourceRepository is the repository of the build source.
+	SourceRepository string `json:"sourceRepository,omitempty"`
+
+	// SourceRef is the ref of the build source.
+	SourceRef string `json:"sourceRef,omitempty"`
+
+	// Violations are the violated expectations. Optional.
+	Violations []string `json:"violations,omitempty"`
+}
+
+// verifier is a ratify.Verifier implementation that verifies SLSA provenance
+// attestations in DSSE envelopes.
+type verifier struct {
+	name          string
+	artifactTypes []string
+	keys          []attestation.PublicKey
+	expectations  expectations
+}
+
+// Name returns the name of the verifier.
+func (v *verifier) Name() string {
+	return v.name
+}
+
+// Type returns the type of the verifier which is always `provenance`.
+func (v *verifier) Type() string {
+	return provenanceType
+}
+
+// Verifiable returns true if the artifact is an attestation of the
+// configured artifact types.
+func (v *verifier) Verifiable(artifact ocispec.Descriptor) bool {
+	return slices.Contains(v.artifactTypes, artifact.ArtifactType) && artifact.MediaType == ocispec.MediaTypeImageManifest
+}
+
+// Verify verifies the DSSE envelopes in the layers of the artifact. Each
+// envelope must be signed by a trusted key, and each SLSA provenance
+// statement must be about the subject and meet the expectations. Statements
+// of other predicate types are ignored.
+func (v *verifier) Verify(ctx context.Context, opts *ratify.VerifyOptions) (*ratify.VerificationResult, error) {
+	blobs, err := artifact.FetchBlobs(ctx, opts.Store, opts.Repository, opts.ArtifactDescriptor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ratify.VerificationResult{
+		Verifier: v,
+	}
+	var details []provenanceDetail
+	violated := false
+	for _, blob := range blobs {
+		payloadType, payload, signer, err := attestation.VerifyEnvelope(blob.Content, v.keys)
+		if err != nil {
+			result.Err = fmt.Errorf("failed to verify DSSE envelope %s: %w", blob.Descriptor.Digest, err)
+			return result, nil
+		}
+		s, err := attestation.ParseStatement(payloadType, payload)
+		if err != nil {
+			result.Err = err
+			return result, nil
+		}
+		if !isProvenance(s) {
+			continue
+		}
+		if !s.MatchesSubject(opts.SubjectDescriptor) {
+			result.Err = fmt.Errorf("subject %s not found in provenance statement", opts.SubjectDescriptor.Digest)
+			return result, nil
+		}
+		p, err := parseProvenance(s)
+		if err != nil {
+			result.Err = err
+			return result, nil
+		}
+		detail := provenanceDetail{
+			PredicateType:    p.predicateType,
+			Signer:           signer,
+			BuilderID:        p.builderID,
+			BuildType:        p.buildType,
+			SourceRepository: p.sourceRepository,
+			SourceRef:        p.sourceRef,
+			Violations:       v.expectations.check(p),
+		}
+		violated = violated || len(detail.Violations) > 0
+		details = append(details, detail)
+	}
+	if len(details) == 0 {
+		result.Err = fmt.Errorf("no SLSA provenance statement found in artifact %s", opts.ArtifactDescriptor.Digest)
+		return result, nil
+	}
+
+	result.Detail = map[string]any{
+		"Provenance": details,
+	}
+	if violated {
+		result.Err = errors.New("provenance does not meet the expectations")
+		result.Description = "Provenance verification failed"
+		return result, nil
+	}
+	result.Description = "Provenance verification succeeded"
+	return result, nil
+}
@@ -0,0 +1,195 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package provenance
+
+import (
+	"context"
+	"testing"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/attestation"
+	"github.com/notaryproject/ratify/v2/internal/verifier/attestation/attestationtest"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+func TestVerifier_Verifiable(t *testing.T) {
+	v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+		Type:       provenanceType,
+		Name:       testName,
+		Parameters: map[string]any{"keys": []map[string]any{{"inline": attestationtest.NewKey(t, "ratify-provenance-test").PEM}}},
+	})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+	tests := []struct {
+		desc   ocispec.Descriptor
+		expect bool
+	}{
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: attestation.ArtifactTypeInToto}, true},
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: attestation.ArtifactTypeDSSE}, true},
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: "application/spdx+json"}, false},
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageIndex, ArtifactType: attestation.ArtifactTypeInToto}, false},
+	}
+	for _, test := range tests {
+		if got := v.Verifiable(test.desc); got != test.expect {
+			t.Errorf("expected Verifiable(%s, %s) to be %v, got %v", test.desc.MediaType, test.desc.ArtifactType, test.expect, got)
+		}
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	key := attestationtest.NewKey(t, "ratify-provenance-test")
+	untrusted := attestationtest.NewKey(t, "ratify-provenance-test")
+	subject := digest.FromString("subject")
+
+	v02 := attestationtest.NewStatement(t, predicateV02, subject, map[string]any{
+		"builder":   map[string]any{"id": "https://github.com/actions/runner"},
+		"buildType": "https://github.com/Attestations/GitHubActionsWorkflow@v1",
+		"invocation": map[string]any{
+			"configSource": map[string]any{"uri": "git+https://github.com/org/repo@refs/heads/main"},
+		},
+	})
+	v1 := attestationtest.NewStatement(t, predicateV1, subject, map[string]any{
+		"buildDefinition": map[string]any{
+			"buildType": "https://actions.github.io/buildtypes/workflow/v1",
+			"externalParameters": map[string]any{
+				"workflow": map[string]any{"repository": "https://github.com/org/repo", "ref": "refs/tags/v1.0.0"},
+			},
+		},
+		"runDetails": map[string]any{
+			"builder": map[string]any{"id": "https://github.com/actions/runner/github-hosted"},
+		},
+	})
+	sbom := attestationtest.NewStatement(t, "https://spdx.dev/Document", subject, map[string]any{})
+
+	tests := []struct {
+		name             string
+		params           map[string]any
+		opts             *ratify.VerifyOptions
+		expectErr        bool
+		expectStoreErr   bool
+		expectProvenance int
+		expectViolations int
+	}{
+		{
+			name:             "SLSA v0.2 provenance",
+			opts:             attestationtest.NewVerifyOptions(t, subject, attestationtest.SignEnvelope(t, attestation.PayloadTypeInToto, v02, key)),
+			expectProvenance: 1,
+		},
+		{
+			name: "SLSA v1 provenance meeting expectations",
+			params: map[string]any{
+				"builderIDs":         []string{"https://github.com/actions/runner/*"},
+				"sourceRepositories": []string{"https://github.com/org/repo"},
+				"sourceRefs":         []string{"refs/tags/v*"},
+			},
+			opts:             attestationtest.NewVerifyOptions(t, subject, attestationtest.SignEnvelope(t, attestation.PayloadTypeInToto, v1, key)),
+			expectProvenance: 1,
+		},
+		{
+			name:             "other statements are ignored",
+			opts:             attestationtest.NewVerifyOptions(t, subject, attestationtest.SignEnvelope(t, attestation.PayloadTypeInToto, sbom, key), attestationtest.SignEnvelope(t, attestation.PayloadTypeInToto, v1, key)),
+			expectProvenance: 1,
+		},
+		{
+			name: "provenance violating expectations",
+			params: map[string]any{
+				"sourceRefs": []string{"refs/heads/main"},
+			},
+			opts:             attestationtest.NewVerifyOptions(t, subject, attestationtest.SignEnvelope(t, attestation.PayloadTypeInToto, v02, key), attestationtest.SignEnvelope(t, attestation.PayloadTypeInToto, v1, key)),
+			expectErr:        true,
+			expectProvenance: 2,
+			expectViolations: 1,
+		},
+		{
+			name:      "no provenance statement",
+			opts:      attestationtest.NewVerifyOptions(t, subject, attestationtest.SignEnvelope(t, attestation.PayloadTypeInToto, sbom, key)),
+			expectErr: true,
+		},
+		{
+			name:      "untrusted signature",
+			opts:      attestationtest.NewVerifyOptions(t, subject, attestationtest.SignEnvelope(t, attestation.PayloadTypeInToto, v1, untrusted)),
+			expectErr: true,
+		},
+		{
+			name:      "unsupported payload type",
+			opts:      attestationtest.NewVerifyOptions(t, subject, attestationtest.SignEnvelope(t, "text/plain", v1, key)),
+			expectErr: true,
+		},
+		{
+			name:      "subject mismatch",
+			opts:      attestationtest.NewVerifyOptions(t, digest.FromString("other"), attestationtest.SignEnvelope(t, attestation.PayloadTypeInToto, v1, key)),
+			expectErr: true,
+		},
+		{
+			name: "invalid predicate",
+			opts: attestationtest.NewVerifyOptions(t, subject, attestationtest.SignEnvelope(t, attestation.PayloadTypeInToto,
+				attestationtest.NewStatement(t, predicateV1, subject, "invalid"), key)),
+			expectErr: true,
+		},
+		{
+			name:           "no layers",
+			opts:           attestationtest.NewVerifyOptions(t, subject),
+			expectStoreErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			params := map[string]any{"keys": []map[string]any{{"inline": key.PEM}}}
+			for k, val := range test.params {
+				params[k] = val
+			}
+			v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+				Type:       provenanceType,
+				Name:       testName,
+				Parameters: params,
+			})
+			if err != nil {
+				t.Fatalf("failed to create verifier: %v", err)
+			}
+			result, err := v.Verify(context.Background(), test.opts)
+			if (err != nil) != test.expectStoreErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectStoreErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if (result.Err != nil) != test.expectErr {
+				t.Fatalf("expected verification error: %v, got: %v", test.expectErr, result.Err)
+			}
+			if test.expectProvenance == 0 {
+				return
+			}
+			details := result.Detail.(map[string]any)["Provenance"].([]provenanceDetail)
+			if len(details) != test.expectProvenance {
+				t.Fatalf("expected %d provenance details, got %+v", test.expectProvenance, details)
+			}
+			violations := 0
+			for _, detail := range details {
+				if detail.Signer != key.Certificate.Subject.String() {
+					t.Errorf("expected signer %s, got %s", key.Certificate.Subject, detail.Signer)
+				}
+				violations += len(detail.Violations)
+			}
+			if violations != test.expectViolations {
+				t.Errorf("expected %d violations, got %+v", test.expectViolations, details)
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sbom
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	spdxjson "github.com/spdx/tools-golang/json"
+)
+
+const (
+	formatSPDX      = "SPDX"
+	formatCycloneDX = "CycloneDX"
+
+	spdxNoAssertion = "NOASSERTION"
+	spdxNone        = "NONE"
+	spdxPURLRefType = "purl"
+)
+
+// minCycloneDXVersion is the minimum supported CycloneDX specification
+// version as major and minor version.
+var minCycloneDXVersion = [2]int{1, 5}
+
+// sbomDocument is the content of an SBOM relevant for the verification.
+type sbomDocument struct {
+	// format is the format and version of the SBOM, e.g. "SPDX-2.3" or
+	// "CycloneDX-1.5".
+	format string
+
+	// created is the creation time of the SBOM. It is zero if unknown.
+	created time.Time
+
+	packages []*sbomPackage
+}
+
+// sbomPackage is a package listed in an SBOM.
+type sbomPackage struct {
+	name    string
+	version string
+	purl    string
+
+	// license is the license expression of the package as stated in the
+	// SBOM. It is empty if the license is unknown.
+	license string
+
+	// licenseExpr is the parsed license expression, nil if the license is
+	// unknown or invalid.
+	licenseExpr *licenseExpression
+
+	// licenseErr is the error parsing the license expression.
+	licenseErr error
+}
+
+// setLicense parses and sets the license expression of the package. Unknown
+// licenses, i.e. empty, "NOASSERTION" and "NONE", are ignored.
+func (p *sbomPackage) setLicense(expression string) {
+	expression = strings.TrimSpace(expression)
+	if expression == "" || expression == spdxNoAssertion || expression == spdxNone {
+		return
+	}
+	p.license = expression
+	p.licenseExpr, p.licenseErr = parseLicenseExpression(expression)
+}
+
+// parseDocument detects the format of the SBOM and parses it. Supported are
+// SPDX 2.x JSON and CycloneDX 1.5+ JSON.
+func parseDocument(content []byte) (*sbomDocument, error) {
+	var probe struct {
+		SPDXVersion string `json:"spdxVersion"`
+		BOMFormat   string `json:"bomFormat"`
+		SpecVersion string `json:"specVersion"`
+	}
+	if err := json.Unmarshal(content, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse SBOM: %w", err)
+	}
+	switch {
+	case probe.SPDXVersion != "":
+		return parseSPDX(content)
+	case probe.BOMFormat == formatCycloneDX:
+		return parseCycloneDX(content)
+	default:
+		return nil, errors.New("unsupported SBOM format, expected SPDX or CycloneDX JSON")
+	}
+}
+
+// parseSPDX parses an SPDX JSON document. The concluded license of a package
+// takes precedence over the declared license.
+func parseSPDX(content []byte) (*sbomDocument, error) {
+	spdxDoc, err := spdxjson.Read(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SPDX document: %w", err)
+	}
+	doc := &sbomDocument{
+		format: strings.Replace(spdxDoc.SPDXVersion, "SPDX-", formatSPDX+"-", 1),
+	}
+	if spdxDoc.CreationInfo != nil && spdxDoc.CreationInfo.Created != "" {
+		if doc.created, err = time.Parse(time.RFC3339, spdxDoc.CreationInfo.Created); err != nil {
+			return nil, fmt.Errorf("invalid SPDX creation time %q: %w", spdxDoc.CreationInfo.Created, err)
+		}
+	}
+	for _, p := range spdxDoc.Packages {
+		if p == nil {
+			continue
+		}
+		pkg := &sbomPackage{
+			name:    p.PackageName,
+			version: p.PackageVersion,
+		}
+		for _, ref := range p.PackageExternalReferences {
+			if ref != nil && ref.RefType == spdxPURLRefType {
+				pkg.purl = ref.Locator
+				break
+			}
+		}
+		license := p.PackageLicenseConcluded
+		if license == "" || license == spdxNoAssertion {
+			license = p.PackageLicenseDeclared
+		}
+		pkg.setLicense(license)
+		doc.packages = append(doc.packages, pkg)
+	}
+	return doc, nil
+}
+
+// cycloneDXBOM is the subset of a CycloneDX JSON BOM used by the verifier.
+type cycloneDXBOM struct {
+	BOMFormat   string `json:"bomFormat"`
+	SpecVersion string `json:"specVersion"`
+	Metadata    *struct {
+		Timestamp string `json:"timestamp"`
+	} `json:"metadata"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+type cycloneDXComponent struct {
+	Name       string                   `json:"name"`
+	Version    string                   `json:"version"`
+	PURL       string                   `json:"purl"`
+	Licenses   []cycloneDXLicenseChoice `json:"licenses"`
+	Components []cycloneDXComponent     `json:"components"`
+}
+
+type cycloneDXLicenseChoice struct {
+	License *struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	} `json:"license"`
+	Expression string `json:"expression"`
+}
+
+// parseCycloneDX parses a CycloneDX JSON BOM including nested components.
+// Multiple licenses of a component must all be satisfied.
+func parseCycloneDX(content []byte) (*sbomDocument, error) {
+	var bom cycloneDXBOM
+	if err := json.Unmarshal(content, &bom); err != nil {
+		return nil, fmt.Errorf("failed to parse CycloneDX BOM: %w", err)
+	}
+	if !supportedCycloneDXVersion(bom.SpecVersion) {
+		return nil, fmt.Errorf("unsupported CycloneDX spec version %q, requires %d.%d or later", bom.SpecVersion, minCycloneDXVersion[0], minCycloneDXVersion[1])
+	}
+	doc := &sbomDocument{
+		format: formatCycloneDX + "-" + bom.SpecVersion,
+	}
+	if bom.Metadata != nil && bom.Metadata.Timestamp != "" {
+		var err error
+		if doc.created, err = time.Parse(time.RFC3339, bom.Metadata.Timestamp); err != nil {
+			return nil, fmt.Errorf("invalid CycloneDX timestamp %q: %w", bom.Metadata.Timestamp, err)
+		}
+	}
+
+	var walk func(components []cycloneDXComponent)
+	walk = func(components []cycloneDXComponent) {
+		for _, c := range components {
+			pkg := &sbomPackage{
+				name:    c.Name,
+				version: c.Version,
+				purl:    c.PURL,
+			}
+			setCycloneDXLicenses(pkg, c.Licenses)
+			doc.packages = append(doc.packages, pkg)
+			walk(c.Components)
+		}
+	}
+	walk(bom.Components)
+	return doc, nil
+}
+
+func setCycloneDXLicenses(pkg *sbomPackage, choices []cycloneDXLicenseChoice) {
+	var (
+		expressions []*licenseExpression
+		stated      []string
+	)
+	for _, choice := range choices {
+		switch {
+		case choice.Expression != "":
+			stated = append(stated, choice.Expression)
+			expr, err := parseLicenseExpression(choice.Expression)
+			if err != nil {
+				pkg.licenseErr = err
+				continue
+			}
+			expressions = append(expressions, expr)
+		case choice.License != nil && choice.License.ID != "":
+			stated = append(stated, choice.License.ID)
+			expressions = append(expressions, &licenseExpression{
+				license: license{id: choice.License.ID},
+			})
+		case choice.License != nil && choice.License.Name != "":
+			// license names are not SPDX identifiers and are matched as is.
+			stated = append(stated, choice.License.Name)
+			expressions = append(expressions, &licenseExpression{
+				license: license{id: choice.License.Name},
+			})
+		}
+	}
+	if len(stated) == 0 {
+		return
+	}
+	if len(stated) > 1 {
+		for i, s := range stated {
+			if strings.ContainsRune(s, ' ') {
+				stated[i] = "(" + s + ")"
+			}
+		}
+	}
+	pkg.license = strings.Join(stated, " "+operatorAnd+" ")
+	if pkg.licenseErr == nil {
+		pkg.licenseExpr = allLicenses(expressions...)
+	}
+}
+
+func supportedCycloneDXVersion(version string) bool {
+	major, minor, ok := strings.Cut(version, ".")
+	if !ok {
+		return false
+	}
+	majorVersion, err := strconv.Atoi(major)
+	if err != nil {
+		return false
+	}
+	minorVersion, err := strconv.Atoi(minor)
+	if err != nil {
+		return false
+	}
+	if majorVersion != minCycloneDXVersion[0] {
+		return majorVersion > minCycloneDXVersion[0]
+	}
+	return minorVersion >= minCycloneDXVersion[1]
+}
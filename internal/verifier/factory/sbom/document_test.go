@@ -0,0 +1,153 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sbom
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func readTestData(t *testing.T, name string) []byte {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read test data: %v", err)
+	}
+	return content
+}
+
+func TestParseDocument(t *testing.T) {
+	created := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		content        []byte
+		expectErr      bool
+		expectFormat   string
+		expectCreated  time.Time
+		expectPackages []sbomPackage
+	}{
+		{
+			name:          "SPDX",
+			content:       readTestData(t, "spdx.json"),
+			expectFormat:  "SPDX-2.3",
+			expectCreated: created,
+			expectPackages: []sbomPackage{
+				{name: "lodash", version: "4.17.20", purl: "pkg:npm/lodash@4.17.20", license: "MIT"},
+				{name: "readline", version: "8.2", license: "GPL-3.0-or-later"},
+				{name: "unknown"},
+			},
+		},
+		{
+			name:          "CycloneDX",
+			content:       readTestData(t, "cyclonedx.json"),
+			expectFormat:  "CycloneDX-1.5",
+			expectCreated: created,
+			expectPackages: []sbomPackage{
+				{name: "log4j-core", version: "2.14.1", purl: "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1", license: "Apache-2.0"},
+				{name: "log4j-api", version: "2.14.1", license: "(Apache-2.0 OR MIT) AND (Custom License)"},
+				{name: "no-license", version: "1.0.0"},
+			},
+		},
+		{
+			name:           "CycloneDX without timestamp",
+			content:        []byte(`{"bomFormat":"CycloneDX","specVersion":"1.6"}`),
+			expectFormat:   "CycloneDX-1.6",
+			expectPackages: []sbomPackage{},
+		},
+		{
+			name:      "unsupported CycloneDX version",
+			content:   []byte(`{"bomFormat":"CycloneDX","specVersion":"1.4"}`),
+			expectErr: true,
+		},
+		{
+			name:      "invalid CycloneDX timestamp",
+			content:   []byte(`{"bomFormat":"CycloneDX","specVersion":"1.5","metadata":{"timestamp":"yesterday"}}`),
+			expectErr: true,
+		},
+		{
+			name:      "invalid SPDX",
+			content:   []byte(`{"spdxVersion":"SPDX-2.3","packages":"invalid"}`),
+			expectErr: true,
+		},
+		{
+			name:      "unsupported format",
+			content:   []byte(`{"name":"test"}`),
+			expectErr: true,
+		},
+		{
+			name:      "invalid JSON",
+			content:   []byte(`{`),
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			doc, err := parseDocument(test.content)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if doc.format != test.expectFormat {
+				t.Errorf("expected format %q, got %q", test.expectFormat, doc.format)
+			}
+			if !doc.created.Equal(test.expectCreated) {
+				t.Errorf("expected created %v, got %v", test.expectCreated, doc.created)
+			}
+			if len(doc.packages) != len(test.expectPackages) {
+				t.Fatalf("expected %d packages, got %d", len(test.expectPackages), len(doc.packages))
+			}
+			for i, pkg := range doc.packages {
+				expected := test.expectPackages[i]
+				if pkg.name != expected.name || pkg.version != expected.version || pkg.purl != expected.purl || pkg.license != expected.license {
+					t.Errorf("expected package %+v, got %+v", expected, *pkg)
+				}
+				if (pkg.licenseExpr != nil) != (expected.license != "") {
+					t.Errorf("expected parsed license expression for %q", pkg.license)
+				}
+			}
+		})
+	}
+}
+
+func TestSupportedCycloneDXVersion(t *testing.T) {
+	tests := []struct {
+		version string
+		expect  bool
+	}{
+		{version: "1.4", expect: false},
+		{version: "1.5", expect: true},
+		{version: "1.6", expect: true},
+		{version: "2.0", expect: true},
+		{version: "0.9", expect: false},
+		{version: "1", expect: false},
+		{version: "x.5", expect: false},
+		{version: "1.x", expect: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.version, func(t *testing.T) {
+			if got := supportedCycloneDXVersion(test.version); got != test.expect {
+				t.Errorf("expected %v, got %v", test.expect, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,362 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sbom
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	operatorAnd  = "AND"
+	operatorOr   = "OR"
+	operatorWith = "WITH"
+)
+
+// license is a single license of a license expression, e.g. "GPL-2.0+" or
+// "GPL-2.0-only WITH Classpath-exception-2.0".
+type license struct {
+	// id is the license identifier without the "+" suffix.
+	id string
+
+	// orLater is true if the license has the "+" suffix.
+	orLater bool
+
+	// exception is the license exception. Optional.
+	exception string
+}
+
+// String returns the license in the SPDX license expression syntax.
+func (l license) String() string {
+	s := l.id
+	if l.orLater {
+		s += "+"
+	}
+	if l.exception != "" {
+		s += " " + operatorWith + " " + l.exception
+	}
+	return s
+}
+
+// matches returns true if the license matches the license of a license list.
+// The "+" suffix and exceptions of the license are ignored unless the list
+// entry has them, e.g. "GPL-2.0" matches "GPL-2.0+" and
+// "GPL-2.0 WITH Classpath-exception-2.0", but "GPL-2.0 WITH
+// Classpath-exception-2.0" does not match "GPL-2.0".
+func (l license) matches(entry license) bool {
+	if !strings.EqualFold(l.id, entry.id) {
+		return false
+	}
+	if entry.orLater && !l.orLater {
+		return false
+	}
+	return entry.exception == "" || strings.EqualFold(l.exception, entry.exception)
+}
+
+// licensePolicy checks license expressions against lists of allowed and
+// disallowed licenses.
+type licensePolicy struct {
+	allowed    []license
+	disallowed []license
+}
+
+// newLicensePolicy parses the allowed and disallowed licenses.
+func newLicensePolicy(allowed, disallowed []string) (*licensePolicy, error) {
+	p := &licensePolicy{}
+	for _, s := range allowed {
+		l, err := parseLicense(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid allowed license: %w", err)
+		}
+		p.allowed = append(p.allowed, l)
+	}
+	for _, s := range disallowed {
+		l, err := parseLicense(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid disallowed license: %w", err)
+		}
+		p.disallowed = append(p.disallowed, l)
+	}
+	return p, nil
+}
+
+// enabled returns true if the policy has any allowed or disallowed license.
+func (p *licensePolicy) enabled() bool {
+	return len(p.allowed) > 0 || len(p.disallowed) > 0
+}
+
+// permitted returns true if the license is allowed and not disallowed. All
+// licenses are allowed if there are no allowed licenses.
+func (p *licensePolicy) permitted(l license) bool {
+	for _, entry := range p.disallowed {
+		if l.matches(entry) {
+			return false
+		}
+	}
+	if len(p.allowed) == 0 {
+		return true
+	}
+	for _, entry := range p.allowed {
+		if l.matches(entry) {
+			return true
+		}
+	}
+	return false
+}
+
+// check returns the licenses of the expression that are not permitted if the
+// expression cannot be satisfied with permitted licenses only, e.g.
+// "MIT OR GPL-3.0-only" passes with "GPL-3.0-only" disallowed while
+// "MIT AND GPL-3.0-only" does not. It returns nil if the expression passes.
+func (p *licensePolicy) check(expr *licenseExpression) []license {
+	if expr.satisfiable(p.permitted) {
+		return nil
+	}
+	var violations []license
+	for _, l := range expr.licenses() {
+		if !p.permitted(l) {
+			violations = append(violations, l)
+		}
+	}
+	return violations
+}
+
+// licenseExpression is a parsed SPDX license expression. It is either a
+// single license, or a conjunction or disjunction of expressions.
+type licenseExpression struct {
+	// operator is "AND" or "OR" for compound expressions, and empty for a
+	// single license.
+	operator string
+
+	// operands are the operands of compound expressions.
+	operands []*licenseExpression
+
+	// license is the license of a single license expression.
+	license license
+}
+
+// satisfiable returns true if the expression can be satisfied with accepted
+// licenses only, i.e. all operands of AND and one operand of OR are accepted.
+func (e *licenseExpression) satisfiable(accept func(license) bool) bool {
+	switch e.operator {
+	case operatorAnd:
+		for _, operand := range e.operands {
+			if !operand.satisfiable(accept) {
+				return false
+			}
+		}
+		return true
+	case operatorOr:
+		for _, operand := range e.operands {
+			if operand.satisfiable(accept) {
+				return true
+			}
+		}
+		return false
+	default:
+		return accept(e.license)
+	}
+}
+
+// licenses returns the single licenses of the expression.
+func (e *licenseExpression) licenses() []license {
+	if e.operator == "" {
+		return []license{e.license}
+	}
+	var licenses []license
+	for _, operand := range e.operands {
+		licenses = append(licenses, operand.licenses()...)
+	}
+	return licenses
+}
+
+// String returns the expression in the SPDX license expression syntax.
+func (e *licenseExpression) String() string {
+	if e.operator == "" {
+		return e.license.String()
+	}
+	operands := make([]string, 0, len(e.operands))
+	for _, operand := range e.operands {
+		s := operand.String()
+		if operand.operator != "" && operand.operator != e.operator {
+			s = "(" + s + ")"
+		}
+		operands = append(operands, s)
+	}
+	return strings.Join(operands, " "+e.operator+" ")
+}
+
+// allLicenses returns the conjunction of the expressions, or the expression
+// itself if there is only one.
+func allLicenses(expressions ...*licenseExpression) *licenseExpression {
+	if len(expressions) == 1 {
+		return expressions[0]
+	}
+	return &licenseExpression{
+		operator: operatorAnd,
+		operands: expressions,
+	}
+}
+
+// parseLicenseExpression parses an SPDX license expression. Operators are
+// case-insensitive and AND takes precedence over OR.
+func parseLicenseExpression(expression string) (*licenseExpression, error) {
+	p := &licenseParser{
+		tokens: tokenizeLicenseExpression(expression),
+	}
+	if len(p.tokens) == 0 {
+		return nil, errors.New("empty license expression")
+	}
+	expr, err := p.parseOr()
+	if err != nil {
+		return nil, fmt.Errorf("invalid license expression %q: %w", expression, err)
+	}
+	if p.pos < len(p.tokens) {
+		return nil, fmt.Errorf("invalid license expression %q: unexpected %q", expression, p.tokens[p.pos])
+	}
+	return expr, nil
+}
+
+// parseLicense parses a single license, e.g. "GPL-2.0+" or
+// "GPL-2.0-only WITH Classpath-exception-2.0".
+func parseLicense(s string) (license, error) {
+	expr, err := parseLicenseExpression(s)
+	if err != nil {
+		return license{}, err
+	}
+	if expr.operator != "" {
+		return license{}, fmt.Errorf("%q is not a single license", s)
+	}
+	return expr.license, nil
+}
+
+func tokenizeLicenseExpression(expression string) []string {
+	var tokens []string
+	for _, field := range strings.Fields(expression) {
+		for field != "" {
+			i := strings.IndexAny(field, "()")
+			switch {
+			case i < 0:
+				tokens = append(tokens, field)
+				field = ""
+			case i > 0:
+				tokens = append(tokens, field[:i])
+				field = field[i:]
+			default:
+				tokens = append(tokens, field[:1])
+				field = field[1:]
+			}
+		}
+	}
+	return tokens
+}
+
+type licenseParser struct {
+	tokens []string
+	pos    int
+}
+
+func (p *licenseParser) peek() string {
+	if p.pos < len(p.tokens) {
+		return p.tokens[p.pos]
+	}
+	return ""
+}
+
+func (p *licenseParser) parseOr() (*licenseExpression, error) {
+	return p.parseCompound(operatorOr, p.parseAnd)
+}
+
+func (p *licenseParser) parseAnd() (*licenseExpression, error) {
+	return p.parseCompound(operatorAnd, p.parsePrimary)
+}
+
+func (p *licenseParser) parseCompound(operator string, parseOperand func() (*licenseExpression, error)) (*licenseExpression, error) {
+	operand, err := parseOperand()
+	if err != nil {
+		return nil, err
+	}
+	operands := []*licenseExpression{operand}
+	for strings.EqualFold(p.peek(), operator) {
+		p.pos++
+		if operand, err = parseOperand(); err != nil {
+			return nil, err
+		}
+		// flatten nested expressions of the same operator.
+		if operand.operator == operator {
+			operands = append(operands, operand.operands...)
+		} else {
+			operands = append(operands, operand)
+		}
+	}
+	if len(operands) == 1 {
+		return operands[0], nil
+	}
+	return &licenseExpression{
+		operator: operator,
+		operands: operands,
+	}, nil
+}
+
+func (p *licenseParser) parsePrimary() (*licenseExpression, error) {
+	token := p.peek()
+	switch {
+	case token == "":
+		return nil, errors.New("unexpected end of expression")
+	case token == "(":
+		p.pos++
+		expr, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ")" {
+			return nil, errors.New("missing closing parenthesis")
+		}
+		p.pos++
+		return expr, nil
+	case token == ")" || isLicenseOperator(token):
+		return nil, fmt.Errorf("unexpected %q", token)
+	}
+	p.pos++
+
+	l := license{
+		id: token,
+	}
+	if strings.HasSuffix(l.id, "+") {
+		l.id = strings.TrimSuffix(l.id, "+")
+		l.orLater = true
+	}
+	if l.id == "" {
+		return nil, fmt.Errorf("invalid license %q", token)
+	}
+	if strings.EqualFold(p.peek(), operatorWith) {
+		p.pos++
+		exception := p.peek()
+		if exception == "" || exception == "(" || exception == ")" || isLicenseOperator(exception) {
+			return nil, errors.New("missing license exception")
+		}
+		p.pos++
+		l.exception = exception
+	}
+	return &licenseExpression{
+		license: l,
+	}, nil
+}
+
+func isLicenseOperator(token string) bool {
+	return strings.EqualFold(token, operatorAnd) || strings.EqualFold(token, operatorOr) || strings.EqualFold(token, operatorWith)
+}
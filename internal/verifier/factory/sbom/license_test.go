@@ -0,0 +1,165 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package sbom
+
+import (
+	"testing"
+)
+
+func TestParseLicenseExpression(t *testing.T) {
+	tests := []struct {
+		name         string
+		expression   string
+		expectErr    bool
+		expectString string
+	}{
+		{name: "single license", expression: "MIT", expectString: "MIT"},
+		{name: "or later", expression: "GPL-2.0+", expectString: "GPL-2.0+"},
+		{name: "exception", expression: "GPL-2.0-only with Classpath-exception-2.0", expectString: "GPL-2.0-only WITH Classpath-exception-2.0"},
+		{name: "precedence", expression: "MIT OR Apache-2.0 AND BSD-3-Clause", expectString: "MIT OR (Apache-2.0 AND BSD-3-Clause)"},
+		{name: "parentheses", expression: "(MIT OR Apache-2.0) AND BSD-3-Clause", expectString: "(MIT OR Apache-2.0) AND BSD-3-Clause"},
+		{name: "flattened", expression: "MIT or (Apache-2.0 OR ISC)", expectString: "MIT OR Apache-2.0 OR ISC"},
+		{name: "empty", expression: " ", expectErr: true},
+		{name: "missing operand", expression: "MIT AND", expectErr: true},
+		{name: "missing closing parenthesis", expression: "(MIT OR ISC", expectErr: true},
+		{name: "unexpected closing parenthesis", expression: "MIT)", expectErr: true},
+		{name: "missing exception", expression: "GPL-2.0-only WITH", expectErr: true},
+		{name: "missing operator", expression: "MIT ISC", expectErr: true},
+		{name: "invalid license", expression: "+", expectErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			expr, err := parseLicenseExpression(test.expression)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err == nil && expr.String() != test.expectString {
+				t.Errorf("expected %q, got %q", test.expectString, expr.String())
+			}
+		})
+	}
+}
+
+func TestLicensePolicy_Check(t *testing.T) {
+	tests := []struct {
+		name             string
+		allowed          []string
+		disallowed       []string
+		expression       string
+		expectViolations []string
+	}{
+		{
+			name:             "disallowed license",
+			disallowed:       []string{"GPL-3.0-only"},
+			expression:       "GPL-3.0-only",
+			expectViolations: []string{"GPL-3.0-only"},
+		},
+		{
+			name:       "disallowed license with alternative",
+			disallowed: []string{"GPL-3.0-only"},
+			expression: "MIT OR GPL-3.0-only",
+		},
+		{
+			name:             "disallowed license in conjunction",
+			disallowed:       []string{"GPL-3.0-only"},
+			expression:       "MIT AND GPL-3.0-only",
+			expectViolations: []string{"GPL-3.0-only"},
+		},
+		{
+			name:             "disallowed license matches or later",
+			disallowed:       []string{"gpl-2.0"},
+			expression:       "GPL-2.0+",
+			expectViolations: []string{"GPL-2.0+"},
+		},
+		{
+			name:       "disallowed or later does not match exact",
+			disallowed: []string{"GPL-2.0+"},
+			expression: "GPL-2.0",
+		},
+		{
+			name:       "disallowed exception does not match plain license",
+			disallowed: []string{"GPL-2.0-only WITH Classpath-exception-2.0"},
+			expression: "GPL-2.0-only",
+		},
+		{
+			name:       "allowed licenses",
+			allowed:    []string{"MIT", "Apache-2.0"},
+			expression: "(MIT OR GPL-3.0-only) AND Apache-2.0",
+		},
+		{
+			name:             "not allowed license",
+			allowed:          []string{"MIT"},
+			expression:       "MIT AND (ISC OR BSD-3-Clause)",
+			expectViolations: []string{"ISC", "BSD-3-Clause"},
+		},
+		{
+			name:             "disallowed takes precedence over allowed",
+			allowed:          []string{"MIT"},
+			disallowed:       []string{"MIT"},
+			expression:       "MIT",
+			expectViolations: []string{"MIT"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policy, err := newLicensePolicy(test.allowed, test.disallowed)
+			if err != nil {
+				t.Fatalf("failed to create license policy: %v", err)
+			}
+			expr, err := parseLicenseExpression(test.expression)
+			if err != nil {
+				t.Fatalf("failed to parse license expression: %v", err)
+			}
+			violations := policy.check(expr)
+			if len(violations) != len(test.expectViolations) {
+				t.Fatalf("expected violations %v, got %v", test.expectViolations, violations)
+			}
+			for i, l := range violations {
+				if l.String() != test.expectViolations[i] {
+					t.Errorf("expected violation %q, got %q", test.expectViolations[i], l.String())
+				}
+			}
+		})
+	}
+}
+
+func TestNewLicensePolicy(t *testing.T) {
+	tests := []struct {
+		name          string
+		allowed       []string
+		disallowed    []string
+		expectErr     bool
+		expectEnabled bool
+	}{
+		{name: "no licenses"},
+		{name: "valid licenses", allowed: []string{"MIT"}, disallowed: []string{"GPL-2.0+"}, expectEnabled: true},
+		{name: "compound allowed license", allowed: []string{"MIT OR ISC"}, expectErr: true},
+		{name: "invalid disallowed license", disallowed: []string{"("}, expectErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			policy, err := newLicensePolicy(test.allowed, test.disallowed)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err == nil && policy.enabled() != test.expectEnabled {
+				t.Errorf("expected enabled: %v, got: %v", test.expectEnabled, policy.enabled())
+			}
+		})
+	}
+}
@@ -0,0 +1,118 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sbom
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/Masterminds/semver/v3"
+	"github.com/package-url/packageurl-go"
+)
+
+// packageRule is an entry of the package deny-list.
+type packageRule struct {
+	// Name is the name of the denied package. Either Name or PURL is
+	// required.
+	Name string `json:"name,omitempty"`
+
+	// PURL is the package URL of the denied package, e.g. "pkg:npm/lodash".
+	// Packages match if type, namespace and name are equal. The version and
+	// qualifiers of the package URL are ignored. Either Name or PURL is
+	// required.
+	PURL string `json:"purl,omitempty"`
+
+	// Versions is a semantic version range of the denied versions, e.g.
+	// "< 4.17.21" or ">= 1.0.0, < 1.2.3 || 2.0.0". Pre-release versions are
+	// matched too, e.g. "2.15.0-rc1" is in "< 2.17.1". Versions that are not
+	// semantic versions are denied. Optional. If not provided, all versions
+	// are denied.
+	Versions string `json:"versions,omitempty"`
+}
+
+// packageMatcher is a compiled packageRule.
+type packageMatcher struct {
+	rule        packageRule
+	purl        *packageurl.PackageURL
+	constraints *semver.Constraints
+}
+
+// newPackageMatcher compiles the package rule.
+func newPackageMatcher(rule packageRule) (*packageMatcher, error) {
+	if rule.Name == "" && rule.PURL == "" {
+		return nil, errors.New("disallowed package requires name or purl")
+	}
+	m := &packageMatcher{
+		rule: rule,
+	}
+	if rule.PURL != "" {
+		purl, err := packageurl.FromString(rule.PURL)
+		if err != nil {
+			return nil, fmt.Errorf("invalid purl of disallowed package: %w", err)
+		}
+		m.purl = &purl
+	}
+	if rule.Versions != "" {
+		constraints, err := semver.NewConstraint(rule.Versions)
+		if err != nil {
+			return nil, fmt.Errorf("invalid versions %q of disallowed package: %w", rule.Versions, err)
+		}
+		// pre-release versions of packages are denied like release versions.
+		constraints.IncludePrerelease = true
+		m.constraints = constraints
+	}
+	return m, nil
+}
+
+// match returns the reason if the package is denied by the rule, or an empty
+// string if not.
+func (m *packageMatcher) match(pkg *sbomPackage) string {
+	version := pkg.version
+	if m.purl != nil {
+		if pkg.purl == "" {
+			return ""
+		}
+		purl, err := packageurl.FromString(pkg.purl)
+		if err != nil ||
+			!strings.EqualFold(purl.Type, m.purl.Type) ||
+			!strings.EqualFold(purl.Namespace, m.purl.Namespace) ||
+			!strings.EqualFold(purl.Name, m.purl.Name) {
+			return ""
+		}
+		if version == "" {
+			version = purl.Version
+		}
+	} else if pkg.name != m.rule.Name {
+		return ""
+	}
+
+	target := m.rule.Name
+	if m.purl != nil {
+		target = m.rule.PURL
+	}
+	if m.constraints == nil {
+		return fmt.Sprintf("package %s is disallowed", target)
+	}
+	v, err := semver.NewVersion(version)
+	if err != nil {
+		return fmt.Sprintf("version %q of package %s is not a semantic version comparable to disallowed versions %q", version, target, m.rule.Versions)
+	}
+	if !m.constraints.Check(v) {
+		return ""
+	}
+	return fmt.Sprintf("version %s of package %s is in disallowed versions %q", version, target, m.rule.Versions)
+}
@@ -0,0 +1,135 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package sbom
+
+import (
+	"testing"
+)
+
+func TestPackageMatcher_Match(t *testing.T) {
+	tests := []struct {
+		name        string
+		rule        packageRule
+		pkg         *sbomPackage
+		expectMatch bool
+	}{
+		{
+			name:        "name without versions",
+			rule:        packageRule{Name: "log4j-core"},
+			pkg:         &sbomPackage{name: "log4j-core", version: "2.17.1"},
+			expectMatch: true,
+		},
+		{
+			name: "different name",
+			rule: packageRule{Name: "log4j-core"},
+			pkg:  &sbomPackage{name: "log4j-api", version: "2.14.1"},
+		},
+		{
+			name:        "name in versions",
+			rule:        packageRule{Name: "log4j-core", Versions: ">= 2.0.0, < 2.17.0"},
+			pkg:         &sbomPackage{name: "log4j-core", version: "2.14.1"},
+			expectMatch: true,
+		},
+		{
+			name: "name not in versions",
+			rule: packageRule{Name: "log4j-core", Versions: ">= 2.0.0, < 2.17.0"},
+			pkg:  &sbomPackage{name: "log4j-core", version: "2.17.1"},
+		},
+		{
+			name:        "pre-release in versions",
+			rule:        packageRule{Name: "log4j-core", Versions: "< 2.17.1"},
+			pkg:         &sbomPackage{name: "log4j-core", version: "2.15.0-rc1"},
+			expectMatch: true,
+		},
+		{
+			name:        "pre-release of the upper bound",
+			rule:        packageRule{Name: "log4j-core", Versions: "< 2.17.1"},
+			pkg:         &sbomPackage{name: "log4j-core", version: "2.17.1-rc1"},
+			expectMatch: true,
+		},
+		{
+			name: "pre-release not in versions",
+			rule: packageRule{Name: "log4j-core", Versions: "< 2.17.1"},
+			pkg:  &sbomPackage{name: "log4j-core", version: "2.17.2-rc1"},
+		},
+		{
+			name:        "non-semantic version",
+			rule:        packageRule{Name: "openssl", Versions: "< 3.0.7"},
+			pkg:         &sbomPackage{name: "openssl", version: "1.1.1k-r0-custom+x"},
+			expectMatch: true,
+		},
+		{
+			name:        "purl",
+			rule:        packageRule{PURL: "pkg:npm/lodash", Versions: "< 4.17.21"},
+			pkg:         &sbomPackage{name: "lodash", version: "4.17.20", purl: "pkg:npm/lodash@4.17.20"},
+			expectMatch: true,
+		},
+		{
+			name:        "purl version fallback",
+			rule:        packageRule{PURL: "pkg:maven/org.apache.logging.log4j/log4j-core", Versions: "< 2.17.0"},
+			pkg:         &sbomPackage{name: "core", purl: "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1"},
+			expectMatch: true,
+		},
+		{
+			name: "purl different namespace",
+			rule: packageRule{PURL: "pkg:maven/org.apache.logging.log4j/log4j-core"},
+			pkg:  &sbomPackage{name: "log4j-core", purl: "pkg:maven/org.example/log4j-core@2.14.1"},
+		},
+		{
+			name: "purl without package purl",
+			rule: packageRule{PURL: "pkg:npm/lodash"},
+			pkg:  &sbomPackage{name: "lodash", version: "4.17.20"},
+		},
+		{
+			name: "purl with invalid package purl",
+			rule: packageRule{PURL: "pkg:npm/lodash"},
+			pkg:  &sbomPackage{name: "lodash", purl: "lodash"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			matcher, err := newPackageMatcher(test.rule)
+			if err != nil {
+				t.Fatalf("failed to create package matcher: %v", err)
+			}
+			if reason := matcher.match(test.pkg); (reason != "") != test.expectMatch {
+				t.Errorf("expected match: %v, got reason: %q", test.expectMatch, reason)
+			}
+		})
+	}
+}
+
+func TestNewPackageMatcher(t *testing.T) {
+	tests := []struct {
+		name      string
+		rule      packageRule
+		expectErr bool
+	}{
+		{name: "name", rule: packageRule{Name: "lodash"}},
+		{name: "purl with versions", rule: packageRule{PURL: "pkg:npm/lodash", Versions: "< 4.17.21"}},
+		{name: "missing name and purl", rule: packageRule{Versions: "< 1.0.0"}, expectErr: true},
+		{name: "invalid purl", rule: packageRule{PURL: "npm/lodash"}, expectErr: true},
+		{name: "invalid versions", rule: packageRule{Name: "lodash", Versions: "<<< 1"}, expectErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := newPackageMatcher(test.rule); (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+		})
+	}
+}
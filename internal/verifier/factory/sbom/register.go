@@ -0,0 +1,97 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+)
+
+const sbomType = "sbom"
+
+type options struct {
+	// AllowedLicenses is a list of allowed licenses, e.g. "MIT" or
+	// "GPL-2.0-only WITH Classpath-exception-2.0". Package license expressions
+	// must be satisfiable with allowed licenses only, and packages without
+	// license fail unless AllowUnknownLicenses is set. Optional. If not
+	// provided, all licenses not disallowed are allowed.
+	AllowedLicenses []string `json:"allowedLicenses,omitempty"`
+
+	// AllowUnknownLicenses allows packages without license, i.e. with an
+	// empty, "NOASSERTION" or "NONE" license, if AllowedLicenses is set.
+	// Optional. Defaults to false.
+	AllowUnknownLicenses bool `json:"allowUnknownLicenses,omitempty"`
+
+	// DisallowedLicenses is a list of disallowed licenses. Package license
+	// expressions must be satisfiable without disallowed licenses, e.g.
+	// "MIT OR GPL-3.0-only" passes with "GPL-3.0-only" disallowed. Optional.
+	DisallowedLicenses []string `json:"disallowedLicenses,omitempty"`
+
+	// DisallowedPackages is the package deny-list. Optional.
+	DisallowedPackages []packageRule `json:"disallowedPackages,omitempty"`
+
+	// MaxAge is the maximum age of the SBOM by its creation time, e.g.
+	// "720h". SBOMs without creation time fail if set. Optional. If not
+	// provided, the age is not checked.
+	MaxAge string `json:"maxAge,omitempty"`
+}
+
+func init() {
+	factory.RegisterVerifierFactory(sbomType, func(opts *factory.NewVerifierOptions) (ratify.Verifier, error) {
+		raw, err := json.Marshal(opts.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal verifier parameters: %w", err)
+		}
+
+		var params options
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal verifier parameters: %w", err)
+		}
+
+		licenses, err := newLicensePolicy(params.AllowedLicenses, params.DisallowedLicenses)
+		if err != nil {
+			return nil, err
+		}
+
+		var packages []*packageMatcher
+		for _, rule := range params.DisallowedPackages {
+			matcher, err := newPackageMatcher(rule)
+			if err != nil {
+				return nil, err
+			}
+			packages = append(packages, matcher)
+		}
+
+		var maxAge time.Duration
+		if params.MaxAge != "" {
+			if maxAge, err = time.ParseDuration(params.MaxAge); err != nil || maxAge <= 0 {
+				return nil, fmt.Errorf("invalid maxAge %q", params.MaxAge)
+			}
+		}
+
+		return &verifier{
+			name:                 opts.Name,
+			licenses:             licenses,
+			allowUnknownLicenses: params.AllowUnknownLicenses,
+			packages:             packages,
+			maxAge:               maxAge,
+		}, nil
+	})
+}
@@ -0,0 +1,87 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sbom
+
+import (
+	"testing"
+
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+)
+
+const testName = "sbom-test"
+
+func TestNewVerifier(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    any
+		expectErr bool
+	}{
+		{
+			name:      "Unsupported params",
+			params:    make(chan int),
+			expectErr: true,
+		},
+		{
+			name:      "Malformed params",
+			params:    "{",
+			expectErr: true,
+		},
+		{
+			name:      "Invalid allowed license",
+			params:    options{AllowedLicenses: []string{"MIT OR ISC"}},
+			expectErr: true,
+		},
+		{
+			name:      "Invalid disallowed package",
+			params:    options{DisallowedPackages: []packageRule{{Versions: "< 1.0.0"}}},
+			expectErr: true,
+		},
+		{
+			name:      "Invalid max age",
+			params:    options{MaxAge: "-1h"},
+			expectErr: true,
+		},
+		{
+			name:   "No params",
+			params: nil,
+		},
+		{
+			name: "Valid params",
+			params: map[string]any{
+				"allowedLicenses":    []string{"MIT", "Apache-2.0"},
+				"disallowedLicenses": []string{"GPL-3.0-only"},
+				"disallowedPackages": []map[string]any{{"purl": "pkg:npm/lodash", "versions": "< 4.17.21"}},
+				"maxAge":             "720h",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+				Type:       sbomType,
+				Name:       testName,
+				Parameters: test.params,
+			})
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err == nil && (v.Name() != testName || v.Type() != sbomType) {
+				t.Errorf("unexpected verifier %s of type %s", v.Name(), v.Type())
+			}
+		})
+	}
+}
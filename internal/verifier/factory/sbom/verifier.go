@@ -0,0 +1,204 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sbom
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/artifact"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	// artifactTypeSPDX is the artifact type of SPDX JSON SBOMs.
+	artifactTypeSPDX = "application/spdx+json"
+
+	// artifactTypeCycloneDX is the artifact type of CycloneDX JSON SBOMs.
+	artifactTypeCycloneDX = "application/vnd.cyclonedx+json"
+
+	findingTypeLicense = "license"
+	findingTypePackage = "package"
+	findingTypeAge     = "age"
+)
+
+// finding is a policy violation found in an SBOM.
+type finding struct {
+	// Type is the type of the violation, one of "license", "package" or
+	// "age".
+	Type string `json:"type"`
+
+	// Package is the name of the violating package. Optional.
+	Package string `json:"package,omitempty"`
+
+	// Version is the version of the violating package. Optional.
+	Version string `json:"version,omitempty"`
+
+	// PURL is the package URL of the violating package. Optional.
+	PURL string `json:"purl,omitempty"`
+
+	// License is the license expression of the violating package. Optional.
+	License string `json:"license,omitempty"`
+
+	// Reason describes the violation.
+	Reason string `json:"reason"`
+}
+
+// documentSummary describes a verified SBOM document.
+type documentSummary struct {
+	// Format is the format and version of the SBOM, e.g. "SPDX-2.3".
+	Format string `json:"format"`
+
+	// Created is the creation time of the SBOM. Optional.
+	Created *time.Time `json:"created,omitempty"`
+
+	// Packages is the number of packages listed in the SBOM.
+	Packages int `json:"packages"`
+}
+
+// verifier is a ratify.Verifier implementation that verifies SPDX and
+// CycloneDX SBOMs against license, package and age policies.
+type verifier struct {
+	name     string
+	licenses *licensePolicy
+
+	// allowUnknownLicenses allows packages without license with allowed
+	// licenses configured.
+	allowUnknownLicenses bool
+	packages             []*packageMatcher
+	maxAge               time.Duration
+}
+
+// Name returns the name of the verifier.
+func (v *verifier) Name() string {
+	return v.name
+}
+
+// Type returns the type of the verifier which is always `sbom`.
+func (v *verifier) Type() string {
+	return sbomType
+}
+
+// Verifiable returns true if the artifact is an SPDX or CycloneDX JSON SBOM.
+func (v *verifier) Verifiable(artifact ocispec.Descriptor) bool {
+	return (artifact.ArtifactType == artifactTypeSPDX || artifact.ArtifactType == artifactTypeCycloneDX) && artifact.MediaType == ocispec.MediaTypeImageManifest
+}
+
+// Verify verifies the SBOM documents in the layers of the artifact.
+func (v *verifier) Verify(ctx context.Context, opts *ratify.VerifyOptions) (*ratify.VerificationResult, error) {
+	blobs, err := artifact.FetchBlobs(ctx, opts.Store, opts.Repository, opts.ArtifactDescriptor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ratify.VerificationResult{
+		Verifier: v,
+	}
+	var (
+		documents []documentSummary
+		findings  []finding
+	)
+	for _, blob := range blobs {
+		doc, err := parseDocument(blob.Content)
+		if err != nil {
+			result.Err = fmt.Errorf("failed to parse SBOM %s: %w", blob.Descriptor.Digest, err)
+			return result, nil
+		}
+		summary := documentSummary{
+			Format:   doc.format,
+			Packages: len(doc.packages),
+		}
+		if !doc.created.IsZero() {
+			summary.Created = &doc.created
+		}
+		documents = append(documents, summary)
+		findings = append(findings, v.check(doc)...)
+	}
+
+	detail := map[string]any{
+		"Documents": documents,
+	}
+	result.Detail = detail
+	if len(findings) > 0 {
+		detail["Findings"] = findings
+		result.Err = fmt.Errorf("SBOM has %d policy violations", len(findings))
+		result.Description = "SBOM verification failed"
+		return result, nil
+	}
+	result.Description = "SBOM verification succeeded"
+	return result, nil
+}
+
+// check returns the policy violations of the SBOM.
+func (v *verifier) check(doc *sbomDocument) []finding {
+	var findings []finding
+	if v.maxAge > 0 {
+		switch {
+		case doc.created.IsZero():
+			findings = append(findings, finding{
+				Type:   findingTypeAge,
+				Reason: "SBOM has no creation time",
+			})
+		case time.Since(doc.created) > v.maxAge:
+			findings = append(findings, finding{
+				Type:   findingTypeAge,
+				Reason: fmt.Sprintf("SBOM created at %s is older than the maximum age %s", doc.created.Format(time.RFC3339), v.maxAge),
+			})
+		}
+	}
+
+	for _, pkg := range doc.packages {
+		if v.licenses.enabled() {
+			var reason string
+			if pkg.license == "" {
+				if len(v.licenses.allowed) > 0 && !v.allowUnknownLicenses {
+					reason = "package has no license but allowed licenses are required"
+				}
+			} else if pkg.licenseErr != nil {
+				reason = pkg.licenseErr.Error()
+			} else if violations := v.licenses.check(pkg.licenseExpr); len(violations) > 0 {
+				names := make([]string, 0, len(violations))
+				for _, l := range violations {
+					names = append(names, l.String())
+				}
+				reason = fmt.Sprintf("license expression cannot be satisfied without licenses not permitted: %s", strings.Join(names, ", "))
+			}
+			if reason != "" {
+				findings = append(findings, newPackageFinding(findingTypeLicense, pkg, reason))
+			}
+		}
+		for _, matcher := range v.packages {
+			if reason := matcher.match(pkg); reason != "" {
+				findings = append(findings, newPackageFinding(findingTypePackage, pkg, reason))
+			}
+		}
+	}
+	return findings
+}
+
+func newPackageFinding(findingType string, pkg *sbomPackage, reason string) finding {
+	return finding{
+		Type:    findingType,
+		Package: pkg.name,
+		Version: pkg.version,
+		PURL:    pkg.purl,
+		License: pkg.license,
+		Reason:  reason,
+	}
+}
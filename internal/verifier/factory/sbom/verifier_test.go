@@ -0,0 +1,323 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sbom
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const testRepo = "test.registry.io/test/image"
+
+// mockStore serves a single SBOM manifest and its layers.
+type mockStore struct {
+	manifest    []byte
+	manifestErr error
+	blobs       map[digest.Digest][]byte
+}
+
+func (s *mockStore) Resolve(_ context.Context, _ string) (ocispec.Descriptor, error) {
+	return ocispec.Descriptor{}, errors.New("not implemented")
+}
+
+func (s *mockStore) ListReferrers(_ context.Context, _ string, _ []string, _ func(referrers []ocispec.Descriptor) error) error {
+	return errors.New("not implemented")
+}
+
+func (s *mockStore) FetchBlob(_ context.Context, _ string, desc ocispec.Descriptor) ([]byte, error) {
+	blob, ok := s.blobs[desc.Digest]
+	if !ok {
+		return nil, errors.New("blob not found")
+	}
+	return blob, nil
+}
+
+func (s *mockStore) FetchManifest(_ context.Context, _ string, _ ocispec.Descriptor) ([]byte, error) {
+	return s.manifest, s.manifestErr
+}
+
+// newVerifyOptions returns the verify options of an SBOM artifact with the
+// documents as layers.
+func newVerifyOptions(t *testing.T, artifactType string, documents ...[]byte) *ratify.VerifyOptions {
+	t.Helper()
+	store := &mockStore{
+		blobs: make(map[digest.Digest][]byte),
+	}
+	manifest := ocispec.Manifest{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: artifactType,
+		Config:       ocispec.DescriptorEmptyJSON,
+	}
+	for _, document := range documents {
+		desc := ocispec.Descriptor{
+			MediaType: artifactType,
+			Digest:    digest.FromBytes(document),
+			Size:      int64(len(document)),
+		}
+		store.blobs[desc.Digest] = document
+		manifest.Layers = append(manifest.Layers, desc)
+	}
+	var err error
+	if store.manifest, err = json.Marshal(manifest); err != nil {
+		t.Fatalf("failed to marshal manifest: %v", err)
+	}
+	return &ratify.VerifyOptions{
+		Store:      store,
+		Repository: testRepo,
+		ArtifactDescriptor: ocispec.Descriptor{
+			MediaType:    ocispec.MediaTypeImageManifest,
+			ArtifactType: artifactType,
+			Digest:       digest.FromBytes(store.manifest),
+			Size:         int64(len(store.manifest)),
+		},
+	}
+}
+
+func newTestVerifier(t *testing.T, params options) ratify.Verifier {
+	t.Helper()
+	v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+		Type:       sbomType,
+		Name:       testName,
+		Parameters: params,
+	})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+	return v
+}
+
+func TestVerifier_Verifiable(t *testing.T) {
+	v := newTestVerifier(t, options{})
+	tests := []struct {
+		name   string
+		desc   ocispec.Descriptor
+		expect bool
+	}{
+		{
+			name:   "SPDX",
+			desc:   ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: artifactTypeSPDX},
+			expect: true,
+		},
+		{
+			name:   "CycloneDX",
+			desc:   ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: artifactTypeCycloneDX},
+			expect: true,
+		},
+		{
+			name: "other artifact type",
+			desc: ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: "application/vnd.cncf.notary.signature"},
+		},
+		{
+			name: "other media type",
+			desc: ocispec.Descriptor{MediaType: ocispec.MediaTypeImageIndex, ArtifactType: artifactTypeSPDX},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := v.Verifiable(test.desc); got != test.expect {
+				t.Errorf("expected %v, got %v", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	spdx := readTestData(t, "spdx.json")
+	cycloneDX := readTestData(t, "cyclonedx.json")
+	recent := []byte(`{"bomFormat":"CycloneDX","specVersion":"1.5","metadata":{"timestamp":"` + time.Now().UTC().Format(time.RFC3339) + `"}}`)
+
+	tests := []struct {
+		name           string
+		params         options
+		opts           *ratify.VerifyOptions
+		expectErr      bool
+		expectFailure  bool
+		expectFindings []finding
+	}{
+		{
+			name: "no policies",
+			opts: newVerifyOptions(t, artifactTypeSPDX, spdx),
+		},
+		{
+			name:   "allowed licenses",
+			params: options{AllowedLicenses: []string{"MIT", "GPL-3.0-or-later"}, AllowUnknownLicenses: true},
+			opts:   newVerifyOptions(t, artifactTypeSPDX, spdx),
+		},
+		{
+			name:          "allowed licenses with unknown license",
+			params:        options{AllowedLicenses: []string{"MIT", "GPL-3.0-or-later"}},
+			opts:          newVerifyOptions(t, artifactTypeSPDX, spdx),
+			expectFailure: true,
+			expectFindings: []finding{
+				{Type: findingTypeLicense, Package: "unknown"},
+			},
+		},
+		{
+			name:          "disallowed declared license",
+			params:        options{DisallowedLicenses: []string{"GPL-3.0-or-later"}},
+			opts:          newVerifyOptions(t, artifactTypeSPDX, spdx),
+			expectFailure: true,
+			expectFindings: []finding{
+				{Type: findingTypeLicense, Package: "readline", Version: "8.2", License: "GPL-3.0-or-later"},
+			},
+		},
+		{
+			name:   "disallowed license with alternative",
+			params: options{DisallowedLicenses: []string{"MIT"}},
+			opts:   newVerifyOptions(t, artifactTypeCycloneDX, cycloneDX),
+		},
+		{
+			name:          "not allowed license name",
+			params:        options{AllowedLicenses: []string{"Apache-2.0", "MIT"}},
+			opts:          newVerifyOptions(t, artifactTypeCycloneDX, cycloneDX),
+			expectFailure: true,
+			expectFindings: []finding{
+				{Type: findingTypeLicense, Package: "log4j-api", Version: "2.14.1", License: "(Apache-2.0 OR MIT) AND (Custom License)"},
+				{Type: findingTypeLicense, Package: "no-license", Version: "1.0.0"},
+			},
+		},
+		{
+			name:          "allowed licenses with unknown license allowed",
+			params:        options{AllowedLicenses: []string{"Apache-2.0", "MIT"}, AllowUnknownLicenses: true},
+			opts:          newVerifyOptions(t, artifactTypeCycloneDX, cycloneDX),
+			expectFailure: true,
+			expectFindings: []finding{
+				{Type: findingTypeLicense, Package: "log4j-api", Version: "2.14.1", License: "(Apache-2.0 OR MIT) AND (Custom License)"},
+			},
+		},
+		{
+			name: "disallowed packages",
+			params: options{DisallowedPackages: []packageRule{
+				{PURL: "pkg:maven/org.apache.logging.log4j/log4j-core", Versions: ">= 2.0.0, < 2.17.0"},
+				{Name: "no-license"},
+				{PURL: "pkg:npm/lodash"},
+			}},
+			opts:          newVerifyOptions(t, artifactTypeCycloneDX, cycloneDX),
+			expectFailure: true,
+			expectFindings: []finding{
+				{Type: findingTypePackage, Package: "log4j-core", Version: "2.14.1", PURL: "pkg:maven/org.apache.logging.log4j/log4j-core@2.14.1", License: "Apache-2.0"},
+				{Type: findingTypePackage, Package: "no-license", Version: "1.0.0"},
+			},
+		},
+		{
+			name:          "SBOM too old",
+			params:        options{MaxAge: "24h"},
+			opts:          newVerifyOptions(t, artifactTypeSPDX, spdx),
+			expectFailure: true,
+			expectFindings: []finding{
+				{Type: findingTypeAge},
+			},
+		},
+		{
+			name:   "SBOM recent",
+			params: options{MaxAge: "24h"},
+			opts:   newVerifyOptions(t, artifactTypeCycloneDX, recent),
+		},
+		{
+			name:          "SBOM without creation time",
+			params:        options{MaxAge: "24h"},
+			opts:          newVerifyOptions(t, artifactTypeCycloneDX, []byte(`{"bomFormat":"CycloneDX","specVersion":"1.5"}`)),
+			expectFailure: true,
+			expectFindings: []finding{
+				{Type: findingTypeAge},
+			},
+		},
+		{
+			name:          "invalid license expression",
+			params:        options{DisallowedLicenses: []string{"GPL-3.0-only"}},
+			opts:          newVerifyOptions(t, artifactTypeCycloneDX, []byte(`{"bomFormat":"CycloneDX","specVersion":"1.5","components":[{"name":"a","licenses":[{"expression":"MIT AND"}]}]}`)),
+			expectFailure: true,
+			expectFindings: []finding{
+				{Type: findingTypeLicense, Package: "a", License: "MIT AND"},
+			},
+		},
+		{
+			name:          "multiple documents",
+			params:        options{DisallowedPackages: []packageRule{{PURL: "pkg:npm/lodash", Versions: "< 4.17.21"}}},
+			opts:          newVerifyOptions(t, artifactTypeSPDX, cycloneDX, spdx),
+			expectFailure: true,
+			expectFindings: []finding{
+				{Type: findingTypePackage, Package: "lodash", Version: "4.17.20", PURL: "pkg:npm/lodash@4.17.20", License: "MIT"},
+			},
+		},
+		{
+			name:          "unsupported document",
+			opts:          newVerifyOptions(t, artifactTypeSPDX, []byte(`{"name":"test"}`)),
+			expectFailure: true,
+		},
+		{
+			name:      "no layers",
+			opts:      newVerifyOptions(t, artifactTypeSPDX),
+			expectErr: true,
+		},
+		{
+			name: "manifest not found",
+			opts: &ratify.VerifyOptions{
+				Store:      &mockStore{manifestErr: errors.New("not found")},
+				Repository: testRepo,
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := newTestVerifier(t, test.params)
+			result, err := v.Verify(context.Background(), test.opts)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if (result.Err != nil) != test.expectFailure {
+				t.Fatalf("expected failure: %v, got: %v", test.expectFailure, result.Err)
+			}
+			if result.Verifier != v {
+				t.Errorf("unexpected verifier %v", result.Verifier)
+			}
+			if result.Detail == nil {
+				return
+			}
+			detail := result.Detail.(map[string]any)
+			findings, _ := detail["Findings"].([]finding)
+			if len(findings) != len(test.expectFindings) {
+				t.Fatalf("expected findings %+v, got %+v", test.expectFindings, findings)
+			}
+			for i, f := range findings {
+				expected := test.expectFindings[i]
+				if f.Type != expected.Type || f.Package != expected.Package || f.Version != expected.Version || f.PURL != expected.PURL || f.License != expected.License {
+					t.Errorf("expected finding %+v, got %+v", expected, f)
+				}
+				if f.Reason == "" {
+					t.Errorf("expected reason of finding %+v", f)
+				}
+			}
+			if documents := detail["Documents"].([]documentSummary); len(documents) != len(test.opts.Store.(*mockStore).blobs) {
+				t.Errorf("expected a summary of each document, got %+v", documents)
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemavalidator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+)
+
+const schemaValidatorType = "schema-validator"
+
+type options struct {
+	// Schemas maps blob media types to the sources of their JSON Schemas.
+	// Required.
+	Schemas map[string]schemaOptions `json:"schemas"`
+
+	// ArtifactTypes are the artifact types of the validated artifacts.
+	// Required.
+	ArtifactTypes []string `json:"artifactTypes"`
+}
+
+func init() {
+	factory.RegisterVerifierFactory(schemaValidatorType, func(opts *factory.NewVerifierOptions) (ratify.Verifier, error) {
+		raw, err := json.Marshal(opts.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal verifier parameters: %w", err)
+		}
+
+		var params options
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal verifier parameters: %w", err)
+		}
+
+		if len(params.ArtifactTypes) == 0 {
+			return nil, errors.New("artifactTypes are required")
+		}
+		schemas, err := newSchemaSet(params.Schemas)
+		if err != nil {
+			return nil, err
+		}
+
+		return &verifier{
+			name:          opts.Name,
+			artifactTypes: params.ArtifactTypes,
+			schemas:       schemas,
+		}, nil
+	})
+}
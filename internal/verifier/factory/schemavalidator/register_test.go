@@ -0,0 +1,86 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemavalidator
+
+import (
+	"testing"
+
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+)
+
+func TestNewVerifier(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    any
+		expectErr bool
+	}{
+		{
+			name:      "unsupported params",
+			params:    make(chan int),
+			expectErr: true,
+		},
+		{
+			name:      "malformed params",
+			params:    "{",
+			expectErr: true,
+		},
+		{
+			name: "missing artifact types",
+			params: map[string]any{
+				"schemas": map[string]any{testMediaType: map[string]any{"file": "testdata/package.schema.json"}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "missing schemas",
+			params: map[string]any{
+				"artifactTypes": []string{testArtifactType},
+			},
+			expectErr: true,
+		},
+		{
+			name: "invalid schema",
+			params: map[string]any{
+				"schemas":       map[string]any{testMediaType: map[string]any{"file": "testdata/invalid.schema.json"}},
+				"artifactTypes": []string{testArtifactType},
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid params",
+			params: map[string]any{
+				"schemas": map[string]any{
+					testMediaType:                     map[string]any{"file": "testdata/package.schema.json"},
+					"application/vnd.test.other+json": map[string]any{"inline": map[string]any{"type": "object"}},
+				},
+				"artifactTypes": []string{testArtifactType},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := factory.NewVerifier(&factory.NewVerifierOptions{
+				Name:       testName,
+				Type:       schemaValidatorType,
+				Parameters: test.params,
+			})
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+		})
+	}
+}
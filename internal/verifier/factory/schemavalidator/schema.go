@@ -0,0 +1,181 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemavalidator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/artifact"
+	"github.com/santhosh-tekuri/jsonschema/v6"
+	"oras.land/oras-go/v2/registry"
+)
+
+// schemaOptions configures the source of the JSON Schema of a blob media
+// type. Exactly one of File, Inline and OCI must be set.
+type schemaOptions struct {
+	// File is the path of a schema file. Relative references in the schema
+	// are resolved against the file.
+	File string `json:"file,omitempty"`
+
+	// Inline is the schema document, either as a JSON object or as a string
+	// holding the JSON document.
+	Inline json.RawMessage `json:"inline,omitempty"`
+
+	// OCI is the digest reference of an OCI artifact whose single layer is
+	// the schema, e.g. "registry.example/schemas/sbom@sha256:...". The
+	// artifact is fetched through the store of the first verification
+	// needing it. References to other schemas are not resolved.
+	OCI string `json:"oci,omitempty"`
+}
+
+// schemaSet holds the compiled schemas keyed by blob media type. Schemas from
+// files and inline documents are compiled on creation, schemas from OCI
+// artifacts on first use. Compiled schemas are cached for the lifetime of
+// the verifier.
+type schemaSet struct {
+	mu       sync.Mutex
+	compiled map[string]*jsonschema.Schema
+	oci      map[string]registry.Reference
+}
+
+// newSchemaSet compiles the configured schemas.
+func newSchemaSet(opts map[string]schemaOptions) (*schemaSet, error) {
+	if len(opts) == 0 {
+		return nil, errors.New("schemas are required")
+	}
+	s := &schemaSet{
+		compiled: make(map[string]*jsonschema.Schema),
+		oci:      make(map[string]registry.Reference),
+	}
+	for mediaType, opt := range opts {
+		if mediaType == "" {
+			return nil, errors.New("schema media type must not be empty")
+		}
+		if err := s.add(mediaType, opt); err != nil {
+			return nil, fmt.Errorf("failed to load schema for media type %s: %w", mediaType, err)
+		}
+	}
+	return s, nil
+}
+
+func (s *schemaSet) add(mediaType string, opt schemaOptions) error {
+	sources := 0
+	for _, set := range []bool{opt.File != "", len(opt.Inline) > 0, opt.OCI != ""} {
+		if set {
+			sources++
+		}
+	}
+	if sources != 1 {
+		return errors.New("exactly one of file, inline and oci must be set")
+	}
+
+	switch {
+	case opt.File != "":
+		path, err := filepath.Abs(opt.File)
+		if err != nil {
+			return fmt.Errorf("failed to resolve schema file path: %w", err)
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read schema file: %w", err)
+		}
+		sch, err := compileSchema(path, content, true)
+		if err != nil {
+			return err
+		}
+		s.compiled[mediaType] = sch
+	case len(opt.Inline) > 0:
+		content := []byte(opt.Inline)
+		var doc string
+		if err := json.Unmarshal(opt.Inline, &doc); err == nil {
+			content = []byte(doc)
+		}
+		sch, err := compileSchema(schemaURL("inline", mediaType), content, true)
+		if err != nil {
+			return err
+		}
+		s.compiled[mediaType] = sch
+	default:
+		ref, err := artifact.ParsePinnedReference(opt.OCI)
+		if err != nil {
+			return err
+		}
+		s.oci[mediaType] = ref
+	}
+	return nil
+}
+
+// get returns the schema of the media type. It returns nil if no schema is
+// configured for the media type.
+func (s *schemaSet) get(ctx context.Context, store ratify.Store, mediaType string) (*jsonschema.Schema, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if sch, ok := s.compiled[mediaType]; ok {
+		return sch, nil
+	}
+	ref, ok := s.oci[mediaType]
+	if !ok {
+		return nil, nil
+	}
+	content, err := artifact.FetchPinnedBlob(ctx, store, ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch schema: %w", err)
+	}
+	sch, err := compileSchema(schemaURL("oci", ref.String()), content, false)
+	if err != nil {
+		return nil, fmt.Errorf("schema %s: %w", ref, err)
+	}
+	s.compiled[mediaType] = sch
+	return sch, nil
+}
+
+// compileSchema compiles the schema document identified by location. Schemas
+// without `$schema` are compiled as draft 2020-12. If loadRefs is false,
+// references to schemas outside of the document fail to compile.
+func compileSchema(location string, content []byte, loadRefs bool) (*jsonschema.Schema, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema: %w", err)
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.DefaultDraft(jsonschema.Draft2020)
+	if !loadRefs {
+		compiler.UseLoader(nil)
+	}
+	if err := compiler.AddResource(location, doc); err != nil {
+		return nil, fmt.Errorf("failed to add schema: %w", err)
+	}
+	sch, err := compiler.Compile(location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile schema: %w", err)
+	}
+	return sch, nil
+}
+
+// schemaURL returns the location identifying an in-memory schema.
+func schemaURL(source, name string) string {
+	return "ratify:///" + source + "/" + url.PathEscape(name)
+}
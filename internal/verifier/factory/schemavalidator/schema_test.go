@@ -0,0 +1,203 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemavalidator
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+)
+
+func TestNewSchemaSet(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      map[string]schemaOptions
+		expectErr bool
+	}{
+		{
+			name:      "no schemas",
+			expectErr: true,
+		},
+		{
+			name:      "empty media type",
+			opts:      map[string]schemaOptions{"": {File: "testdata/package.schema.json"}},
+			expectErr: true,
+		},
+		{
+			name:      "no source",
+			opts:      map[string]schemaOptions{testMediaType: {}},
+			expectErr: true,
+		},
+		{
+			name:      "multiple sources",
+			opts:      map[string]schemaOptions{testMediaType: {File: "testdata/package.schema.json", Inline: json.RawMessage(`{}`)}},
+			expectErr: true,
+		},
+		{
+			name: "file with relative reference",
+			opts: map[string]schemaOptions{testMediaType: {File: "testdata/package.schema.json"}},
+		},
+		{
+			name:      "missing file",
+			opts:      map[string]schemaOptions{testMediaType: {File: "testdata/missing.schema.json"}},
+			expectErr: true,
+		},
+		{
+			name:      "malformed file",
+			opts:      map[string]schemaOptions{testMediaType: {File: "testdata/invalid.schema.json"}},
+			expectErr: true,
+		},
+		{
+			name: "inline object",
+			opts: map[string]schemaOptions{testMediaType: {Inline: json.RawMessage(`{"type": "object"}`)}},
+		},
+		{
+			name: "inline string",
+			opts: map[string]schemaOptions{testMediaType: {Inline: json.RawMessage(`"{\"type\": \"object\"}"`)}},
+		},
+		{
+			name:      "invalid inline schema",
+			opts:      map[string]schemaOptions{testMediaType: {Inline: json.RawMessage(`{"type": 5}`)}},
+			expectErr: true,
+		},
+		{
+			name: "oci digest reference",
+			opts: map[string]schemaOptions{testMediaType: {OCI: testRepo + "@" + digest.FromString("schema").String()}},
+		},
+		{
+			name:      "oci tag reference",
+			opts:      map[string]schemaOptions{testMediaType: {OCI: testRepo + ":v1"}},
+			expectErr: true,
+		},
+		{
+			name:      "malformed oci reference",
+			opts:      map[string]schemaOptions{testMediaType: {OCI: "INVALID@@"}},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := newSchemaSet(test.opts)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestSchemaSet_Get(t *testing.T) {
+	tests := []struct {
+		name         string
+		setup        func(store *mockStore) schemaOptions
+		mediaType    string
+		expectErr    bool
+		expectSchema bool
+	}{
+		{
+			name: "compiled schema",
+			setup: func(_ *mockStore) schemaOptions {
+				return schemaOptions{Inline: json.RawMessage(`{"type": "object"}`)}
+			},
+			mediaType:    testMediaType,
+			expectSchema: true,
+		},
+		{
+			name: "media type without schema",
+			setup: func(_ *mockStore) schemaOptions {
+				return schemaOptions{Inline: json.RawMessage(`{"type": "object"}`)}
+			},
+			mediaType: "application/vnd.test.other+json",
+		},
+		{
+			name: "oci schema",
+			setup: func(store *mockStore) schemaOptions {
+				return schemaOptions{OCI: store.addSchema(t, []byte(`{"type": "object"}`))}
+			},
+			mediaType:    testMediaType,
+			expectSchema: true,
+		},
+		{
+			name: "oci schema not found",
+			setup: func(_ *mockStore) schemaOptions {
+				return schemaOptions{OCI: testRepo + "@" + digest.FromString("missing").String()}
+			},
+			mediaType: testMediaType,
+			expectErr: true,
+		},
+		{
+			name: "oci schema resolved to other digest",
+			setup: func(store *mockStore) schemaOptions {
+				ref := testRepo + "@" + digest.FromString("other").String()
+				store.references[ref] = store.references[store.addSchema(t, []byte(`{}`))]
+				return schemaOptions{OCI: ref}
+			},
+			mediaType: testMediaType,
+			expectErr: true,
+		},
+		{
+			name: "oci schema with multiple layers",
+			setup: func(store *mockStore) schemaOptions {
+				return schemaOptions{OCI: store.addSchema(t, []byte(`{}`), []byte(`{"type": "object"}`))}
+			},
+			mediaType: testMediaType,
+			expectErr: true,
+		},
+		{
+			name: "oci schema without layers",
+			setup: func(store *mockStore) schemaOptions {
+				return schemaOptions{OCI: store.addSchema(t)}
+			},
+			mediaType: testMediaType,
+			expectErr: true,
+		},
+		{
+			name: "malformed oci schema",
+			setup: func(store *mockStore) schemaOptions {
+				return schemaOptions{OCI: store.addSchema(t, []byte(`{`))}
+			},
+			mediaType: testMediaType,
+			expectErr: true,
+		},
+		{
+			name: "oci schema with external reference",
+			setup: func(store *mockStore) schemaOptions {
+				return schemaOptions{OCI: store.addSchema(t, []byte(`{"$ref": "file:///etc/schema.json"}`))}
+			},
+			mediaType: testMediaType,
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := newMockStore()
+			s, err := newSchemaSet(map[string]schemaOptions{testMediaType: test.setup(store)})
+			if err != nil {
+				t.Fatalf("failed to create schema set: %v", err)
+			}
+			sch, err := s.get(context.Background(), store, test.mediaType)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if (sch != nil) != test.expectSchema {
+				t.Fatalf("expected schema: %v, got: %v", test.expectSchema, sch)
+			}
+		})
+	}
+}
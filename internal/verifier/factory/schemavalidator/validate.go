@@ -0,0 +1,82 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemavalidator
+
+import (
+	"bytes"
+	"cmp"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/santhosh-tekuri/jsonschema/v6"
+)
+
+// validationError is a single schema violation of a blob.
+type validationError struct {
+	// InstanceLocation is the JSON pointer to the violating value in the
+	// blob.
+	InstanceLocation string `json:"instanceLocation"`
+
+	// KeywordLocation is the JSON pointer to the violated keyword in the
+	// schema.
+	KeywordLocation string `json:"keywordLocation"`
+
+	// Message describes the violation.
+	Message string `json:"message"`
+}
+
+// validate validates the JSON content against the schema. It returns every
+// violation ordered by location, or an error if the content is not JSON.
+func validate(sch *jsonschema.Schema, content []byte) ([]validationError, error) {
+	doc, err := jsonschema.UnmarshalJSON(bytes.NewReader(content))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse JSON: %w", err)
+	}
+	err = sch.Validate(doc)
+	if err == nil {
+		return nil, nil
+	}
+	var verr *jsonschema.ValidationError
+	if !errors.As(err, &verr) {
+		return nil, fmt.Errorf("failed to validate JSON: %w", err)
+	}
+	errs := collectErrors(nil, *verr.DetailedOutput())
+	// the order of the output units is not stable across validations.
+	slices.SortFunc(errs, func(a, b validationError) int {
+		return cmp.Or(
+			strings.Compare(a.InstanceLocation, b.InstanceLocation),
+			strings.Compare(a.KeywordLocation, b.KeywordLocation),
+		)
+	})
+	return errs, nil
+}
+
+// collectErrors appends the leaf errors of the output unit.
+func collectErrors(errs []validationError, unit jsonschema.OutputUnit) []validationError {
+	if unit.Error != nil {
+		return append(errs, validationError{
+			InstanceLocation: unit.InstanceLocation,
+			KeywordLocation:  unit.KeywordLocation,
+			Message:          unit.Error.String(),
+		})
+	}
+	for _, cause := range unit.Errors {
+		errs = collectErrors(errs, cause)
+	}
+	return errs
+}
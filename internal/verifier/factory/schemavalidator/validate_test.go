@@ -0,0 +1,79 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemavalidator
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	s, err := newSchemaSet(map[string]schemaOptions{testMediaType: {File: "testdata/package.schema.json"}})
+	if err != nil {
+		t.Fatalf("failed to create schema set: %v", err)
+	}
+	sch := s.compiled[testMediaType]
+
+	tests := []struct {
+		name      string
+		content   string
+		expectErr bool
+		expect    []validationError
+	}{
+		{
+			name:    "valid",
+			content: `{"name": "ratify", "version": "2.0.0"}`,
+		},
+		{
+			name:      "not JSON",
+			content:   `{"name":`,
+			expectErr: true,
+		},
+		{
+			name:    "every violation is reported",
+			content: `{"name": "", "version": "v2"}`,
+			expect: []validationError{
+				{InstanceLocation: "/name", KeywordLocation: "/properties/name/minLength"},
+				{InstanceLocation: "/version", KeywordLocation: "/properties/version/$ref/pattern"},
+			},
+		},
+		{
+			name:    "missing properties",
+			content: `{}`,
+			expect: []validationError{
+				{InstanceLocation: "", KeywordLocation: "/required"},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			errs, err := validate(sch, []byte(test.content))
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			for i := range errs {
+				if errs[i].Message == "" {
+					t.Errorf("expected message for error %d", i)
+				}
+				errs[i].Message = ""
+			}
+			if !reflect.DeepEqual(errs, test.expect) {
+				t.Errorf("expected errors %v, got %v", test.expect, errs)
+			}
+		})
+	}
+}
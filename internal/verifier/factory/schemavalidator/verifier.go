@@ -0,0 +1,112 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemavalidator
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/artifact"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// blobDetail describes the validation of a blob.
+type blobDetail struct {
+	// MediaType is the media type of the blob.
+	MediaType string `json:"mediaType"`
+
+	// Digest is the digest of the blob.
+	Digest string `json:"digest"`
+
+	// Errors are the schema violations of the blob.
+	Errors []validationError `json:"errors,omitempty"`
+}
+
+// verifier is a ratify.Verifier implementation that validates the blobs of
+// artifacts against the JSON Schemas of their media types.
+type verifier struct {
+	name          string
+	artifactTypes []string
+	schemas       *schemaSet
+}
+
+// Name returns the name of the verifier.
+func (v *verifier) Name() string {
+	return v.name
+}
+
+// Type returns the type of the verifier which is always `schema-validator`.
+func (v *verifier) Type() string {
+	return schemaValidatorType
+}
+
+// Verifiable returns true if the artifact is of the configured artifact
+// types.
+func (v *verifier) Verifiable(artifact ocispec.Descriptor) bool {
+	return slices.Contains(v.artifactTypes, artifact.ArtifactType) && artifact.MediaType == ocispec.MediaTypeImageManifest
+}
+
+// Verify validates every layer of the artifact against the schema of its
+// media type. Layers of media types without a schema fail the verification.
+func (v *verifier) Verify(ctx context.Context, opts *ratify.VerifyOptions) (*ratify.VerificationResult, error) {
+	blobs, err := artifact.FetchBlobs(ctx, opts.Store, opts.Repository, opts.ArtifactDescriptor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ratify.VerificationResult{
+		Verifier: v,
+	}
+	details := make([]blobDetail, 0, len(blobs))
+	invalid := 0
+	for _, blob := range blobs {
+		mediaType := blob.Descriptor.MediaType
+		sch, err := v.schemas.get(ctx, opts.Store, mediaType)
+		if err != nil {
+			return nil, err
+		}
+		if sch == nil {
+			result.Err = fmt.Errorf("no schema configured for media type %s of blob %s", mediaType, blob.Descriptor.Digest)
+			return result, nil
+		}
+		errs, err := validate(sch, blob.Content)
+		if err != nil {
+			result.Err = fmt.Errorf("blob %s: %w", blob.Descriptor.Digest, err)
+			return result, nil
+		}
+		if len(errs) > 0 {
+			invalid++
+		}
+		details = append(details, blobDetail{
+			MediaType: mediaType,
+			Digest:    blob.Descriptor.Digest.String(),
+			Errors:    errs,
+		})
+	}
+
+	result.Detail = map[string]any{
+		"Blobs": details,
+	}
+	if invalid > 0 {
+		result.Err = fmt.Errorf("%d of %d blobs do not match their schemas", invalid, len(blobs))
+		result.Description = "Schema validation failed"
+		return result, nil
+	}
+	result.Description = "Schema validation succeeded"
+	return result, nil
+}
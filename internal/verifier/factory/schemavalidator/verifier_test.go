@@ -0,0 +1,283 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package schemavalidator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	testName         = "schema-validator-test"
+	testRepo         = "registry.test/repo"
+	testArtifactType = "application/vnd.test.package"
+	testMediaType    = "application/vnd.test.package+json"
+)
+
+// mockStore serves manifests and blobs by digest and resolves references
+// to the registered manifests.
+type mockStore struct {
+	manifests      map[digest.Digest][]byte
+	blobs          map[digest.Digest][]byte
+	references     map[string]ocispec.Descriptor
+	manifestsFetch int
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{
+		manifests:  map[digest.Digest][]byte{},
+		blobs:      map[digest.Digest][]byte{},
+		references: map[string]ocispec.Descriptor{},
+	}
+}
+
+func (s *mockStore) Resolve(_ context.Context, ref string) (ocispec.Descriptor, error) {
+	desc, ok := s.references[ref]
+	if !ok {
+		return ocispec.Descriptor{}, errors.New("reference not found")
+	}
+	return desc, nil
+}
+
+func (s *mockStore) ListReferrers(_ context.Context, _ string, _ []string, _ func(referrers []ocispec.Descriptor) error) error {
+	return errors.New("not implemented")
+}
+
+func (s *mockStore) FetchBlob(_ context.Context, _ string, desc ocispec.Descriptor) ([]byte, error) {
+	blob, ok := s.blobs[desc.Digest]
+	if !ok {
+		return nil, errors.New("blob not found")
+	}
+	return blob, nil
+}
+
+func (s *mockStore) FetchManifest(_ context.Context, _ string, desc ocispec.Descriptor) ([]byte, error) {
+	s.manifestsFetch++
+	manifest, ok := s.manifests[desc.Digest]
+	if !ok {
+		return nil, errors.New("manifest not found")
+	}
+	return manifest, nil
+}
+
+// addArtifact stores an artifact with a layer of the media type for each
+// content and returns its descriptor.
+func (s *mockStore) addArtifact(t *testing.T, artifactType, mediaType string, contents ...[]byte) ocispec.Descriptor {
+	t.Helper()
+	manifest := ocispec.Manifest{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: artifactType,
+		Config:       ocispec.DescriptorEmptyJSON,
+		Layers:       []ocispec.Descriptor{},
+	}
+	for _, content := range contents {
+		desc := ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(content),
+			Size:      int64(len(content)),
+		}
+		manifest.Layers = append(manifest.Layers, desc)
+		s.blobs[desc.Digest] = content
+	}
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("failed to marshal manifest: %v", err)
+	}
+	desc := ocispec.Descriptor{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: artifactType,
+		Digest:       digest.FromBytes(content),
+		Size:         int64(len(content)),
+	}
+	s.manifests[desc.Digest] = content
+	return desc
+}
+
+// addSchema stores a schema artifact and returns its digest reference.
+func (s *mockStore) addSchema(t *testing.T, schemas ...[]byte) string {
+	t.Helper()
+	desc := s.addArtifact(t, "application/vnd.test.schema", "application/schema+json", schemas...)
+	ref := testRepo + "@" + desc.Digest.String()
+	s.references[ref] = desc
+	return ref
+}
+
+func newVerifyOptions(store *mockStore, desc ocispec.Descriptor) *ratify.VerifyOptions {
+	return &ratify.VerifyOptions{
+		Store:      store,
+		Repository: testRepo,
+		SubjectDescriptor: ocispec.Descriptor{
+			MediaType: ocispec.MediaTypeImageManifest,
+			Digest:    digest.FromString("subject"),
+			Size:      7,
+		},
+		ArtifactDescriptor: desc,
+	}
+}
+
+func newTestVerifier(t *testing.T, schemas map[string]any) ratify.Verifier {
+	t.Helper()
+	v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+		Name: testName,
+		Type: schemaValidatorType,
+		Parameters: map[string]any{
+			"schemas":       schemas,
+			"artifactTypes": []string{testArtifactType},
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+	return v
+}
+
+func TestVerifier_Verifiable(t *testing.T) {
+	v := newTestVerifier(t, map[string]any{testMediaType: map[string]any{"file": "testdata/package.schema.json"}})
+	tests := []struct {
+		desc   ocispec.Descriptor
+		expect bool
+	}{
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: testArtifactType}, true},
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: "application/spdx+json"}, false},
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageIndex, ArtifactType: testArtifactType}, false},
+	}
+	for _, test := range tests {
+		if got := v.Verifiable(test.desc); got != test.expect {
+			t.Errorf("expected Verifiable(%s, %s) to be %v, got %v", test.desc.MediaType, test.desc.ArtifactType, test.expect, got)
+		}
+	}
+	if v.Name() != testName {
+		t.Errorf("expected name %s, got %s", testName, v.Name())
+	}
+	if v.Type() != schemaValidatorType {
+		t.Errorf("expected type %s, got %s", schemaValidatorType, v.Type())
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	valid := []byte(`{"name": "ratify", "version": "2.0.0"}`)
+	invalid := []byte(`{"name": "", "version": "v2"}`)
+
+	tests := []struct {
+		name           string
+		setup          func(store *mockStore) ocispec.Descriptor
+		expectErr      bool
+		expectStoreErr bool
+		expectBlobs    int
+		expectErrors   []int
+	}{
+		{
+			name: "valid blobs",
+			setup: func(store *mockStore) ocispec.Descriptor {
+				return store.addArtifact(t, testArtifactType, testMediaType, valid, valid)
+			},
+			expectBlobs:  2,
+			expectErrors: []int{0, 0},
+		},
+		{
+			name: "invalid blob",
+			setup: func(store *mockStore) ocispec.Descriptor {
+				return store.addArtifact(t, testArtifactType, testMediaType, valid, invalid)
+			},
+			expectErr:    true,
+			expectBlobs:  2,
+			expectErrors: []int{0, 2},
+		},
+		{
+			name: "blob is not JSON",
+			setup: func(store *mockStore) ocispec.Descriptor {
+				return store.addArtifact(t, testArtifactType, testMediaType, []byte("{"))
+			},
+			expectErr: true,
+		},
+		{
+			name: "media type without schema",
+			setup: func(store *mockStore) ocispec.Descriptor {
+				return store.addArtifact(t, testArtifactType, "application/vnd.test.other+json", valid)
+			},
+			expectErr: true,
+		},
+		{
+			name: "manifest not found",
+			setup: func(_ *mockStore) ocispec.Descriptor {
+				return ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, Digest: digest.FromString("missing")}
+			},
+			expectStoreErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := newTestVerifier(t, map[string]any{testMediaType: map[string]any{"file": "testdata/package.schema.json"}})
+			store := newMockStore()
+			result, err := v.Verify(context.Background(), newVerifyOptions(store, test.setup(store)))
+			if test.expectStoreErr {
+				if err == nil {
+					t.Fatal("expected error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (result.Err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, result.Err)
+			}
+			if test.expectBlobs == 0 {
+				return
+			}
+			details := result.Detail.(map[string]any)["Blobs"].([]blobDetail)
+			if len(details) != test.expectBlobs {
+				t.Fatalf("expected %d blobs, got %d", test.expectBlobs, len(details))
+			}
+			for i, detail := range details {
+				if len(detail.Errors) != test.expectErrors[i] {
+					t.Errorf("expected %d errors for blob %d, got %v", test.expectErrors[i], i, detail.Errors)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifier_Verify_OCISchema(t *testing.T) {
+	store := newMockStore()
+	schemaRef := store.addSchema(t, []byte(`{"type": "object", "required": ["name"]}`))
+	v := newTestVerifier(t, map[string]any{testMediaType: map[string]any{"oci": schemaRef}})
+
+	for _, content := range [][]byte{[]byte(`{"name": "ratify"}`), []byte(`{}`)} {
+		desc := store.addArtifact(t, testArtifactType, testMediaType, content)
+		if _, err := v.Verify(context.Background(), newVerifyOptions(store, desc)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	// One fetch for the schema artifact and one for each verified artifact.
+	if store.manifestsFetch != 3 {
+		t.Errorf("expected the schema to be fetched once, got %d manifest fetches", store.manifestsFetch)
+	}
+
+	v = newTestVerifier(t, map[string]any{testMediaType: map[string]any{"oci": testRepo + "@" + digest.FromString("missing").String()}})
+	desc := store.addArtifact(t, testArtifactType, testMediaType, []byte(`{}`))
+	if _, err := v.Verify(context.Background(), newVerifyOptions(store, desc)); err == nil {
+		t.Error("expected error for missing schema artifact, got nil")
+	}
+}
@@ -0,0 +1,54 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sigstorebundle
+
+import (
+	"errors"
+
+	"github.com/sigstore/sigstore-go/pkg/verify"
+)
+
+// identityOptions is a trusted keyless signing identity, as configured for
+// the cosign verifier.
+type identityOptions struct {
+	// CertificateIdentity is the subject alternative name of the signing
+	// certificate, e.g. an email or a workflow URI.
+	CertificateIdentity string `json:"certificateIdentity,omitempty"`
+
+	// CertificateIdentityRegExp is the regular expression matching the
+	// subject alternative name of the signing certificate.
+	CertificateIdentityRegExp string `json:"certificateIdentityRegExp,omitempty"`
+
+	// CertificateOIDCIssuer is the OIDC issuer of the signing certificate,
+	// e.g. "https://token.actions.githubusercontent.com".
+	CertificateOIDCIssuer string `json:"certificateOIDCIssuer,omitempty"`
+
+	// CertificateOIDCIssuerRegExp is the regular expression matching the OIDC
+	// issuer of the signing certificate.
+	CertificateOIDCIssuerRegExp string `json:"certificateOIDCIssuerRegExp,omitempty"`
+}
+
+// newIdentity validates that exactly one of the value or regular expression
+// is set for the identity and for the issuer.
+func newIdentity(opts identityOptions) (verify.CertificateIdentity, error) {
+	if (opts.CertificateIdentity == "") == (opts.CertificateIdentityRegExp == "") {
+		return verify.CertificateIdentity{}, errors.New("exactly one of certificateIdentity or certificateIdentityRegExp is required")
+	}
+	if (opts.CertificateOIDCIssuer == "") == (opts.CertificateOIDCIssuerRegExp == "") {
+		return verify.CertificateIdentity{}, errors.New("exactly one of certificateOIDCIssuer or certificateOIDCIssuerRegExp is required")
+	}
+	return verify.NewShortCertificateIdentity(opts.CertificateOIDCIssuer, opts.CertificateOIDCIssuerRegExp, opts.CertificateIdentity, opts.CertificateIdentityRegExp)
+}
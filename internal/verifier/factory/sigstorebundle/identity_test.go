@@ -0,0 +1,133 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sigstorebundle
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"testing"
+
+	"github.com/sigstore/sigstore-go/pkg/fulcio/certificate"
+)
+
+func TestNewIdentity(t *testing.T) {
+	tests := []struct {
+		name      string
+		opts      identityOptions
+		expectErr bool
+	}{
+		{
+			name: "exact identity and issuer",
+			opts: identityOptions{CertificateIdentity: testIdentity, CertificateOIDCIssuer: testIssuer},
+		},
+		{
+			name: "regular expressions",
+			opts: identityOptions{CertificateIdentityRegExp: `^https://github\.com/org/`, CertificateOIDCIssuerRegExp: `githubusercontent`},
+		},
+		{
+			name:      "missing identity",
+			opts:      identityOptions{CertificateOIDCIssuer: testIssuer},
+			expectErr: true,
+		},
+		{
+			name:      "both identity and regular expression",
+			opts:      identityOptions{CertificateIdentity: testIdentity, CertificateIdentityRegExp: ".*", CertificateOIDCIssuer: testIssuer},
+			expectErr: true,
+		},
+		{
+			name:      "missing issuer",
+			opts:      identityOptions{CertificateIdentity: testIdentity},
+			expectErr: true,
+		},
+		{
+			name:      "invalid identity regular expression",
+			opts:      identityOptions{CertificateIdentityRegExp: "(", CertificateOIDCIssuer: testIssuer},
+			expectErr: true,
+		},
+		{
+			name:      "invalid issuer regular expression",
+			opts:      identityOptions{CertificateIdentity: testIdentity, CertificateOIDCIssuerRegExp: "("},
+			expectErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := newIdentity(test.opts); (err != nil) != test.expectErr {
+				t.Errorf("expected error: %v, got: %v", test.expectErr, err)
+			}
+		})
+	}
+}
+
+func TestIdentity_Verify(t *testing.T) {
+	s := newTestSigstore(t)
+	cert, _ := s.issue(t, testIdentity, testIssuer)
+	v1Cert := &x509.Certificate{
+		EmailAddresses: []string{"dev@example.com"},
+		Extensions:     []pkix.Extension{{Id: oidIssuerV1, Value: []byte("https://accounts.google.com")}},
+	}
+
+	tests := []struct {
+		name   string
+		opts   identityOptions
+		cert   *x509.Certificate
+		expect bool
+	}{
+		{
+			name:   "exact match",
+			opts:   identityOptions{CertificateIdentity: testIdentity, CertificateOIDCIssuer: testIssuer},
+			cert:   cert,
+			expect: true,
+		},
+		{
+			name:   "regular expression match",
+			opts:   identityOptions{CertificateIdentityRegExp: `^https://github\.com/org/repo/`, CertificateOIDCIssuerRegExp: `^https://token\.actions\.`},
+			cert:   cert,
+			expect: true,
+		},
+		{
+			name: "identity mismatch",
+			opts: identityOptions{CertificateIdentity: "https://github.com/org/other", CertificateOIDCIssuer: testIssuer},
+			cert: cert,
+		},
+		{
+			name: "issuer mismatch",
+			opts: identityOptions{CertificateIdentity: testIdentity, CertificateOIDCIssuer: "https://accounts.google.com"},
+			cert: cert,
+		},
+		{
+			name:   "email with legacy issuer extension",
+			opts:   identityOptions{CertificateIdentity: "dev@example.com", CertificateOIDCIssuer: "https://accounts.google.com"},
+			cert:   v1Cert,
+			expect: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			id, err := newIdentity(test.opts)
+			if err != nil {
+				t.Fatalf("failed to create identity: %v", err)
+			}
+			summary, err := certificate.SummarizeCertificate(test.cert)
+			if err != nil {
+				t.Fatalf("failed to summarize certificate: %v", err)
+			}
+			if err := id.Verify(summary); (err == nil) != test.expect {
+				t.Errorf("expected match: %v, got: %v", test.expect, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,126 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sigstorebundle
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	"github.com/sigstore/sigstore-go/pkg/root"
+	"github.com/sigstore/sigstore-go/pkg/verify"
+)
+
+const (
+	sigstoreBundleType = "sigstore-bundle"
+
+	// defaultTransparencyLogThreshold is the default number of verified
+	// transparency log entries required.
+	defaultTransparencyLogThreshold = 1
+)
+
+type options struct {
+	// TrustedRoot is the path of the trusted_root.json file of the Sigstore
+	// deployment. Required.
+	TrustedRoot string `json:"trustedRoot"`
+
+	// Identities are the trusted keyless signing identities. Required.
+	Identities []identityOptions `json:"identities"`
+
+	// TransparencyLogThreshold is the number of verified transparency log
+	// entries required. Optional. Defaults to 1.
+	TransparencyLogThreshold *int `json:"transparencyLogThreshold,omitempty"`
+
+	// TimestampThreshold is the number of verified signed timestamps
+	// required. Optional. Defaults to 0.
+	TimestampThreshold int `json:"timestampThreshold,omitempty"`
+
+	// ArtifactTypes are the artifact types of the bundles. Optional. Defaults
+	// to the Sigstore bundle v0.3 artifact types.
+	ArtifactTypes []string `json:"artifactTypes,omitempty"`
+}
+
+func init() {
+	factory.RegisterVerifierFactory(sigstoreBundleType, func(opts *factory.NewVerifierOptions) (ratify.Verifier, error) {
+		raw, err := json.Marshal(opts.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal verifier parameters: %w", err)
+		}
+
+		var params options
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal verifier parameters: %w", err)
+		}
+
+		if params.TrustedRoot == "" {
+			return nil, errors.New("trustedRoot is required")
+		}
+		trustedRoot, err := root.NewTrustedRootFromPath(params.TrustedRoot)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load trusted root: %w", err)
+		}
+		if len(params.Identities) == 0 {
+			return nil, errors.New("no identities provided")
+		}
+		identities := make([]verify.PolicyOption, 0, len(params.Identities))
+		for _, opt := range params.Identities {
+			id, err := newIdentity(opt)
+			if err != nil {
+				return nil, err
+			}
+			identities = append(identities, verify.WithCertificateIdentity(id))
+		}
+		tlogThreshold := defaultTransparencyLogThreshold
+		if params.TransparencyLogThreshold != nil {
+			tlogThreshold = *params.TransparencyLogThreshold
+		}
+		if tlogThreshold < 0 || params.TimestampThreshold < 0 {
+			return nil, errors.New("thresholds must not be negative")
+		}
+		if tlogThreshold == 0 && params.TimestampThreshold == 0 {
+			return nil, errors.New("at least one of transparencyLogThreshold or timestampThreshold must be positive")
+		}
+		// the signing certificate is verified at the times attested by the
+		// transparency logs and the timestamping authorities, so at least one
+		// of them is required.
+		var verifierOpts []verify.VerifierOption
+		if tlogThreshold > 0 {
+			verifierOpts = append(verifierOpts, verify.WithTransparencyLog(tlogThreshold))
+		}
+		if params.TimestampThreshold > 0 {
+			verifierOpts = append(verifierOpts, verify.WithSignedTimestamps(params.TimestampThreshold))
+		} else {
+			verifierOpts = append(verifierOpts, verify.WithObserverTimestamps(1))
+		}
+		bundleVerifier, err := verify.NewSignedEntityVerifier(trustedRoot, verifierOpts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create Sigstore bundle verifier: %w", err)
+		}
+		artifactTypes := params.ArtifactTypes
+		if len(artifactTypes) == 0 {
+			artifactTypes = defaultArtifactTypes
+		}
+
+		return &verifier{
+			name:           opts.Name,
+			artifactTypes:  artifactTypes,
+			bundleVerifier: bundleVerifier,
+			identities:     identities,
+		}, nil
+	})
+}
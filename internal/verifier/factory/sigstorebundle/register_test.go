@@ -0,0 +1,114 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sigstorebundle
+
+import (
+	"testing"
+
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+)
+
+func TestNewVerifier(t *testing.T) {
+	trustedRoot := newTestSigstore(t).writeTrustedRoot(t)
+	identities := []map[string]any{{"certificateIdentity": testIdentity, "certificateOIDCIssuer": testIssuer}}
+
+	tests := []struct {
+		name      string
+		params    any
+		expectErr bool
+	}{
+		{
+			name:      "unsupported params",
+			params:    make(chan int),
+			expectErr: true,
+		},
+		{
+			name:      "malformed params",
+			params:    "{",
+			expectErr: true,
+		},
+		{
+			name:      "missing trusted root",
+			params:    map[string]any{"identities": identities},
+			expectErr: true,
+		},
+		{
+			name:      "unreadable trusted root",
+			params:    map[string]any{"trustedRoot": t.TempDir() + "/missing.json", "identities": identities},
+			expectErr: true,
+		},
+		{
+			name:      "missing identities",
+			params:    map[string]any{"trustedRoot": trustedRoot},
+			expectErr: true,
+		},
+		{
+			name: "invalid identity",
+			params: map[string]any{
+				"trustedRoot": trustedRoot,
+				"identities":  []map[string]any{{"certificateIdentity": testIdentity}},
+			},
+			expectErr: true,
+		},
+		{
+			name: "negative threshold",
+			params: map[string]any{
+				"trustedRoot":        trustedRoot,
+				"identities":         identities,
+				"timestampThreshold": -1,
+			},
+			expectErr: true,
+		},
+		{
+			name: "no verified time required",
+			params: map[string]any{
+				"trustedRoot":              trustedRoot,
+				"identities":               identities,
+				"transparencyLogThreshold": 0,
+			},
+			expectErr: true,
+		},
+		{
+			name: "valid options",
+			params: map[string]any{
+				"trustedRoot":              trustedRoot,
+				"identities":               identities,
+				"transparencyLogThreshold": 1,
+				"timestampThreshold":       1,
+				"artifactTypes":            []string{testBundleType},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+				Type:       sigstoreBundleType,
+				Name:       testName,
+				Parameters: test.params,
+			})
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if v.Name() != testName || v.Type() != sigstoreBundleType {
+				t.Errorf("unexpected verifier %s of type %s", v.Name(), v.Type())
+			}
+		})
+	}
+}
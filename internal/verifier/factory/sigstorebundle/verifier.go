@@ -0,0 +1,147 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sigstorebundle
+
+import (
+	"context"
+	"encoding/hex"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/artifact"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sigstore/sigstore-go/pkg/bundle"
+	"github.com/sigstore/sigstore-go/pkg/verify"
+)
+
+// mediaTypePrefix is the prefix of the media types of all bundle versions.
+const mediaTypePrefix = "application/vnd.dev.sigstore.bundle"
+
+// defaultArtifactTypes are the artifact types verified by default.
+var defaultArtifactTypes = []string{
+	"application/vnd.dev.sigstore.bundle.v0.3+json",
+	"application/vnd.dev.sigstore.bundle+json;version=0.3",
+}
+
+// bundleDetail describes a verified Sigstore bundle.
+type bundleDetail struct {
+	// MediaType is the media type of the bundle.
+	MediaType string `json:"mediaType"`
+
+	// Identity is the subject alternative name of the signing certificate.
+	Identity string `json:"identity"`
+
+	// Issuer is the OIDC issuer of the signing certificate.
+	Issuer string `json:"issuer"`
+
+	// VerifiedTimestamps are the signing times attested by the transparency
+	// logs and the timestamping authorities.
+	VerifiedTimestamps []verify.TimestampVerificationResult `json:"verifiedTimestamps"`
+}
+
+// verifier is a ratify.Verifier implementation that verifies Sigstore
+// bundles offline against a trusted root.
+type verifier struct {
+	name           string
+	artifactTypes  []string
+	bundleVerifier *verify.Verifier
+
+	// identities are the policy options of the trusted identities, any of
+	// which must match the signing certificate.
+	identities []verify.PolicyOption
+}
+
+// Name returns the name of the verifier.
+func (v *verifier) Name() string {
+	return v.name
+}
+
+// Type returns the type of the verifier which is always `sigstore-bundle`.
+func (v *verifier) Type() string {
+	return sigstoreBundleType
+}
+
+// Verifiable returns true if the artifact is a Sigstore bundle of the
+// configured artifact types.
+func (v *verifier) Verifiable(artifact ocispec.Descriptor) bool {
+	return slices.Contains(v.artifactTypes, artifact.ArtifactType) && artifact.MediaType == ocispec.MediaTypeImageManifest
+}
+
+// Verify verifies the Sigstore bundles in the layers of the artifact. The
+// signing certificate must chain to a certificate authority of the trusted
+// root at the times attested by the transparency log and the timestamping
+// authorities, and match a trusted identity.
+func (v *verifier) Verify(ctx context.Context, opts *ratify.VerifyOptions) (*ratify.VerificationResult, error) {
+	blobs, err := artifact.FetchBlobs(ctx, opts.Store, opts.Repository, opts.ArtifactDescriptor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ratify.VerificationResult{
+		Verifier: v,
+	}
+	var details []bundleDetail
+	for _, blob := range blobs {
+		if !strings.HasPrefix(blob.Descriptor.MediaType, mediaTypePrefix) {
+			continue
+		}
+		detail, err := v.verifyBundle(blob.Content, opts.SubjectDescriptor)
+		if err != nil {
+			result.Err = fmt.Errorf("failed to verify Sigstore bundle %s: %w", blob.Descriptor.Digest, err)
+			result.Description = "Sigstore bundle verification failed"
+			return result, nil
+		}
+		details = append(details, *detail)
+	}
+	if len(details) == 0 {
+		result.Err = fmt.Errorf("no Sigstore bundle found in artifact %s", opts.ArtifactDescriptor.Digest)
+		result.Description = "Sigstore bundle verification failed"
+		return result, nil
+	}
+
+	result.Detail = map[string]any{
+		"Bundles": details,
+	}
+	result.Description = "Sigstore bundle verification succeeded"
+	return result, nil
+}
+
+// verifyBundle verifies a Sigstore bundle for the subject, signed either as
+// the message digest or as a subject of the in-toto statement of the DSSE
+// envelope.
+func (v *verifier) verifyBundle(content []byte, subject ocispec.Descriptor) (*bundleDetail, error) {
+	var b bundle.Bundle
+	if err := b.UnmarshalJSON(content); err != nil {
+		return nil, fmt.Errorf("failed to parse Sigstore bundle: %w", err)
+	}
+	subjectDigest, err := hex.DecodeString(subject.Digest.Encoded())
+	if err != nil {
+		return nil, fmt.Errorf("invalid subject digest %s: %w", subject.Digest, err)
+	}
+	policy := verify.NewPolicy(verify.WithArtifactDigest(subject.Digest.Algorithm().String(), subjectDigest), v.identities...)
+	res, err := v.bundleVerifier.Verify(&b, policy)
+	if err != nil {
+		return nil, err
+	}
+	return &bundleDetail{
+		MediaType:          b.GetMediaType(),
+		Identity:           res.Signature.Certificate.SubjectAlternativeName,
+		Issuer:             res.Signature.Certificate.Issuer,
+		VerifiedTimestamps: res.VerifiedTimestamps,
+	}, nil
+}
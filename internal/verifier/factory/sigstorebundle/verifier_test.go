@@ -0,0 +1,760 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package sigstorebundle
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/digitorus/timestamp"
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/secure-systems-lab/go-securesystemslib/dsse"
+	protobundle "github.com/sigstore/protobuf-specs/gen/pb-go/bundle/v1"
+	protocommon "github.com/sigstore/protobuf-specs/gen/pb-go/common/v1"
+	protodsse "github.com/sigstore/protobuf-specs/gen/pb-go/dsse"
+	protorekor "github.com/sigstore/protobuf-specs/gen/pb-go/rekor/v1"
+	prototrustroot "github.com/sigstore/protobuf-specs/gen/pb-go/trustroot/v1"
+	"github.com/transparency-dev/merkle/rfc6962"
+	"google.golang.org/protobuf/encoding/protojson"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	testRepo       = "test.registry.io/test/image"
+	testName       = "sigstore-bundle-test"
+	testIdentity   = "https://github.com/org/repo/.github/workflows/release.yml@refs/heads/main"
+	testIssuer     = "https://token.actions.githubusercontent.com"
+	testBundleType = "application/vnd.dev.sigstore.bundle.v0.3+json"
+)
+
+var (
+	// oidIssuerV1 is the Fulcio extension of the OIDC issuer as raw string.
+	oidIssuerV1 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 1}
+
+	// oidIssuerV2 is the Fulcio extension of the OIDC issuer as DER encoded
+	// UTF8String.
+	oidIssuerV2 = asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 57264, 1, 8}
+)
+
+// setPayload is the payload of the signed entry timestamp of a Rekor entry.
+// Fields are sorted to serialize as canonical JSON.
+type setPayload struct {
+	Body           string `json:"body"`
+	IntegratedTime int64  `json:"integratedTime"`
+	LogID          string `json:"logID"`
+	LogIndex       int64  `json:"logIndex"`
+}
+
+// testSigstore is a local Sigstore deployment with a certificate authority,
+// a transparency log and a timestamping authority.
+type testSigstore struct {
+	caKey    *ecdsa.PrivateKey
+	ca       *x509.Certificate
+	rekorKey *ecdsa.PrivateKey
+	logID    []byte
+	tsaKey   *ecdsa.PrivateKey
+	tsaRoot  *x509.Certificate
+	tsa      *x509.Certificate
+}
+
+func generateKey(t *testing.T) *ecdsa.PrivateKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	return key
+}
+
+func createCertificate(t *testing.T, template, parent *x509.Certificate, pub crypto.PublicKey, priv crypto.Signer) *x509.Certificate {
+	t.Helper()
+	if parent == nil {
+		parent = template
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, pub, priv)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	return cert
+}
+
+func newCATemplate(name string) *x509.Certificate {
+	return &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{Organization: []string{"Ratify"}, CommonName: name},
+		NotBefore:             time.Now().Add(-24 * time.Hour),
+		NotAfter:              time.Now().Add(24 * time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+}
+
+func newTestSigstore(t *testing.T) *testSigstore {
+	t.Helper()
+	s := &testSigstore{
+		caKey:    generateKey(t),
+		rekorKey: generateKey(t),
+		tsaKey:   generateKey(t),
+	}
+	s.ca = createCertificate(t, newCATemplate("Fulcio Test Root"), nil, &s.caKey.PublicKey, s.caKey)
+
+	der, err := x509.MarshalPKIXPublicKey(&s.rekorKey.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+	logID := sha256.Sum256(der)
+	s.logID = logID[:]
+
+	tsaRootKey := generateKey(t)
+	s.tsaRoot = createCertificate(t, newCATemplate("TSA Test Root"), nil, &tsaRootKey.PublicKey, tsaRootKey)
+	// RFC 3161 requires the timestamping extended key usage to be critical.
+	timeStamping, err := asn1.Marshal([]asn1.ObjectIdentifier{{1, 3, 6, 1, 5, 5, 7, 3, 8}})
+	if err != nil {
+		t.Fatalf("failed to marshal extended key usage: %v", err)
+	}
+	s.tsa = createCertificate(t, &x509.Certificate{
+		SerialNumber:    big.NewInt(2),
+		Subject:         pkix.Name{Organization: []string{"Ratify"}, CommonName: "TSA Test"},
+		NotBefore:       time.Now().Add(-24 * time.Hour),
+		NotAfter:        time.Now().Add(24 * time.Hour),
+		KeyUsage:        x509.KeyUsageDigitalSignature,
+		ExtraExtensions: []pkix.Extension{{Id: asn1.ObjectIdentifier{2, 5, 29, 37}, Critical: true, Value: timeStamping}},
+	}, s.tsaRoot, &s.tsaKey.PublicKey, tsaRootKey)
+	return s
+}
+
+// trustedRoot returns the trusted_root.json of the deployment.
+func (s *testSigstore) trustedRoot(t *testing.T) []byte {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(&s.rekorKey.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to marshal public key: %v", err)
+	}
+	validFor := &protocommon.TimeRange{Start: timestamppb.New(time.Now().Add(-24 * time.Hour))}
+	root := &prototrustroot.TrustedRoot{
+		MediaType: "application/vnd.dev.sigstore.trustedroot+json;version=0.1",
+		Tlogs: []*prototrustroot.TransparencyLogInstance{
+			{
+				BaseUrl:       "https://rekor.test",
+				HashAlgorithm: protocommon.HashAlgorithm_SHA2_256,
+				PublicKey: &protocommon.PublicKey{
+					RawBytes:   der,
+					KeyDetails: protocommon.PublicKeyDetails_PKIX_ECDSA_P256_SHA_256,
+					ValidFor:   validFor,
+				},
+				LogId: &protocommon.LogId{KeyId: s.logID},
+			},
+		},
+		CertificateAuthorities: []*prototrustroot.CertificateAuthority{
+			{
+				Uri: "https://fulcio.test",
+				CertChain: &protocommon.X509CertificateChain{
+					Certificates: []*protocommon.X509Certificate{{RawBytes: s.ca.Raw}},
+				},
+				ValidFor: validFor,
+			},
+		},
+		TimestampAuthorities: []*prototrustroot.CertificateAuthority{
+			{
+				Uri: "https://tsa.test",
+				CertChain: &protocommon.X509CertificateChain{
+					Certificates: []*protocommon.X509Certificate{{RawBytes: s.tsa.Raw}, {RawBytes: s.tsaRoot.Raw}},
+				},
+				ValidFor: validFor,
+			},
+		},
+	}
+	content, err := protojson.Marshal(root)
+	if err != nil {
+		t.Fatalf("failed to marshal trusted root: %v", err)
+	}
+	return content
+}
+
+// writeTrustedRoot writes the trusted_root.json of the deployment to a
+// temporary file.
+func (s *testSigstore) writeTrustedRoot(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "trusted_root.json")
+	if err := os.WriteFile(path, s.trustedRoot(t), 0600); err != nil {
+		t.Fatalf("failed to write trusted root: %v", err)
+	}
+	return path
+}
+
+// issue issues a short-lived keyless signing certificate.
+func (s *testSigstore) issue(t *testing.T, identity, issuer string) (*x509.Certificate, *ecdsa.PrivateKey) {
+	t.Helper()
+	key := generateKey(t)
+	issuerValue, err := asn1.MarshalWithParams(issuer, "utf8")
+	if err != nil {
+		t.Fatalf("failed to marshal issuer: %v", err)
+	}
+	uri, err := url.Parse(identity)
+	if err != nil {
+		t.Fatalf("failed to parse identity: %v", err)
+	}
+	cert := createCertificate(t, &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		NotBefore:    time.Now().Add(-5 * time.Minute),
+		NotAfter:     time.Now().Add(5 * time.Minute),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageCodeSigning},
+		URIs:         []*url.URL{uri},
+		ExtraExtensions: []pkix.Extension{
+			{Id: oidIssuerV2, Value: issuerValue},
+		},
+	}, s.ca, &key.PublicKey, s.caKey)
+	return cert, key
+}
+
+// bundleOptions configures a test bundle.
+type bundleOptions struct {
+	subject   digest.Digest
+	dsse      bool
+	noTlog    bool
+	noProof   bool
+	noPromise bool
+	timestamp bool
+	identity  string
+	issuer    string
+}
+
+// sign returns a bundle signing the subject.
+func (s *testSigstore) sign(t *testing.T, opts bundleOptions) *protobundle.Bundle {
+	t.Helper()
+	if opts.identity == "" {
+		opts.identity = testIdentity
+	}
+	if opts.issuer == "" {
+		opts.issuer = testIssuer
+	}
+	cert, key := s.issue(t, opts.identity, opts.issuer)
+	certPEM := base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw}))
+
+	b := &protobundle.Bundle{
+		MediaType: testBundleType,
+		VerificationMaterial: &protobundle.VerificationMaterial{
+			Content: &protobundle.VerificationMaterial_Certificate{
+				Certificate: &protocommon.X509Certificate{RawBytes: cert.Raw},
+			},
+		},
+	}
+	var sig []byte
+	var body map[string]any
+	if opts.dsse {
+		payload, err := json.Marshal(map[string]any{
+			"_type":         "https://in-toto.io/Statement/v1",
+			"predicateType": "https://sigstore.dev/cosign/sign/v1",
+			"subject": []map[string]any{
+				{"name": testRepo, "digest": map[string]string{"sha256": opts.subject.Encoded()}},
+			},
+			"predicate": map[string]any{},
+		})
+		if err != nil {
+			t.Fatalf("failed to marshal statement: %v", err)
+		}
+		sum := sha256.Sum256(dsse.PAE("application/vnd.in-toto+json", payload))
+		if sig, err = ecdsa.SignASN1(rand.Reader, key, sum[:]); err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		b.Content = &protobundle.Bundle_DsseEnvelope{
+			DsseEnvelope: &protodsse.Envelope{
+				Payload:     payload,
+				PayloadType: "application/vnd.in-toto+json",
+				Signatures:  []*protodsse.Signature{{Sig: sig}},
+			},
+		}
+		envelope, err := json.Marshal(dsse.Envelope{
+			PayloadType: "application/vnd.in-toto+json",
+			Payload:     base64.StdEncoding.EncodeToString(payload),
+			Signatures:  []dsse.Signature{{Sig: base64.StdEncoding.EncodeToString(sig)}},
+		})
+		if err != nil {
+			t.Fatalf("failed to marshal envelope: %v", err)
+		}
+		envelopeHash := sha256.Sum256(envelope)
+		payloadHash := sha256.Sum256(payload)
+		body = map[string]any{
+			"apiVersion": "0.0.1",
+			"kind":       "dsse",
+			"spec": map[string]any{
+				"envelopeHash": map[string]string{"algorithm": "sha256", "value": hex.EncodeToString(envelopeHash[:])},
+				"payloadHash":  map[string]string{"algorithm": "sha256", "value": hex.EncodeToString(payloadHash[:])},
+				"signatures":   []map[string]string{{"signature": base64.StdEncoding.EncodeToString(sig), "verifier": certPEM}},
+			},
+		}
+	} else {
+		subjectDigest, err := hex.DecodeString(opts.subject.Encoded())
+		if err != nil {
+			t.Fatalf("failed to decode digest: %v", err)
+		}
+		if sig, err = ecdsa.SignASN1(rand.Reader, key, subjectDigest); err != nil {
+			t.Fatalf("failed to sign: %v", err)
+		}
+		b.Content = &protobundle.Bundle_MessageSignature{
+			MessageSignature: &protocommon.MessageSignature{
+				MessageDigest: &protocommon.HashOutput{Algorithm: protocommon.HashAlgorithm_SHA2_256, Digest: subjectDigest},
+				Signature:     sig,
+			},
+		}
+		body = map[string]any{
+			"apiVersion": "0.0.1",
+			"kind":       "hashedrekord",
+			"spec": map[string]any{
+				"data":      map[string]any{"hash": map[string]string{"algorithm": "sha256", "value": opts.subject.Encoded()}},
+				"signature": map[string]any{"content": base64.StdEncoding.EncodeToString(sig), "publicKey": map[string]string{"content": certPEM}},
+			},
+		}
+	}
+
+	if !opts.noTlog {
+		b.VerificationMaterial.TlogEntries = []*protorekor.TransparencyLogEntry{s.logEntry(t, body, opts)}
+	}
+	if opts.timestamp {
+		b.VerificationMaterial.TimestampVerificationData = &protobundle.TimestampVerificationData{
+			Rfc3161Timestamps: []*protocommon.RFC3161SignedTimestamp{{SignedTimestamp: s.timestamp(t, sig)}},
+		}
+	}
+	return b
+}
+
+// logEntry returns a transparency log entry of the body at index 1 of a log
+// of size 2.
+func (s *testSigstore) logEntry(t *testing.T, body map[string]any, opts bundleOptions) *protorekor.TransparencyLogEntry {
+	t.Helper()
+	canonicalized, err := json.Marshal(body)
+	if err != nil {
+		t.Fatalf("failed to marshal entry body: %v", err)
+	}
+	entry := &protorekor.TransparencyLogEntry{
+		LogIndex:          1,
+		LogId:             &protocommon.LogId{KeyId: s.logID},
+		KindVersion:       &protorekor.KindVersion{Kind: body["kind"].(string), Version: "0.0.1"},
+		IntegratedTime:    time.Now().Unix(),
+		CanonicalizedBody: canonicalized,
+	}
+	if !opts.noProof {
+		sibling := rfc6962.DefaultHasher.HashLeaf([]byte("sibling"))
+		root := rfc6962.DefaultHasher.HashChildren(sibling, rfc6962.DefaultHasher.HashLeaf(canonicalized))
+		entry.InclusionProof = &protorekor.InclusionProof{
+			LogIndex:   1,
+			RootHash:   root,
+			TreeSize:   2,
+			Hashes:     [][]byte{sibling},
+			Checkpoint: &protorekor.Checkpoint{Envelope: s.checkpoint(t, 2, root)},
+		}
+	}
+	if !opts.noPromise {
+		payload, err := json.Marshal(setPayload{
+			Body:           base64.StdEncoding.EncodeToString(canonicalized),
+			IntegratedTime: entry.IntegratedTime,
+			LogID:          hex.EncodeToString(s.logID),
+			LogIndex:       entry.LogIndex,
+		})
+		if err != nil {
+			t.Fatalf("failed to marshal signed entry timestamp payload: %v", err)
+		}
+		entry.InclusionPromise = &protorekor.InclusionPromise{SignedEntryTimestamp: s.signRekor(t, payload)}
+	}
+	return entry
+}
+
+func (s *testSigstore) signRekor(t *testing.T, data []byte) []byte {
+	t.Helper()
+	sum := sha256.Sum256(data)
+	sig, err := ecdsa.SignASN1(rand.Reader, s.rekorKey, sum[:])
+	if err != nil {
+		t.Fatalf("failed to sign: %v", err)
+	}
+	return sig
+}
+
+// checkpoint returns a checkpoint signed by the transparency log.
+func (s *testSigstore) checkpoint(t *testing.T, size int64, root []byte) string {
+	t.Helper()
+	return s.checkpointOf(t, "rekor.test - 1", size, root)
+}
+
+// checkpointOf returns a checkpoint of the origin signed by the transparency
+// log.
+func (s *testSigstore) checkpointOf(t *testing.T, origin string, size int64, root []byte) string {
+	t.Helper()
+	body := origin + "\n" + big.NewInt(size).String() + "\n" + base64.StdEncoding.EncodeToString(root) + "\n"
+	sig := append(s.logID[:4:4], s.signRekor(t, []byte(body))...)
+	return body + "\n— rekor.test " + base64.StdEncoding.EncodeToString(sig) + "\n"
+}
+
+// timestamp returns an RFC 3161 timestamp response over the signature.
+func (s *testSigstore) timestamp(t *testing.T, sig []byte) []byte {
+	t.Helper()
+	sum := sha256.Sum256(sig)
+	ts := timestamp.Timestamp{
+		HashAlgorithm: crypto.SHA256,
+		HashedMessage: sum[:],
+		Time:          time.Now(),
+		Policy:        asn1.ObjectIdentifier{1, 2, 3, 4, 1},
+	}
+	resp, err := ts.CreateResponse(s.tsa, s.tsaKey)
+	if err != nil {
+		t.Fatalf("failed to create timestamp: %v", err)
+	}
+	return resp
+}
+
+// withTlogEntries replaces the transparency log entries of the bundle with
+// the entries of another bundle.
+func withTlogEntries(b, other *protobundle.Bundle) *protobundle.Bundle {
+	b.VerificationMaterial.TlogEntries = other.GetVerificationMaterial().GetTlogEntries()
+	return b
+}
+
+func marshalBundle(t *testing.T, b *protobundle.Bundle) []byte {
+	t.Helper()
+	content, err := protojson.Marshal(b)
+	if err != nil {
+		t.Fatalf("failed to marshal bundle: %v", err)
+	}
+	return content
+}
+
+// mockStore serves a single bundle manifest and its layers.
+type mockStore struct {
+	manifest []byte
+	blobs    map[digest.Digest][]byte
+}
+
+func (s *mockStore) Resolve(_ context.Context, _ string) (ocispec.Descriptor, error) {
+	return ocispec.Descriptor{}, errors.New("not implemented")
+}
+
+func (s *mockStore) ListReferrers(_ context.Context, _ string, _ []string, _ func(referrers []ocispec.Descriptor) error) error {
+	return errors.New("not implemented")
+}
+
+func (s *mockStore) FetchBlob(_ context.Context, _ string, desc ocispec.Descriptor) ([]byte, error) {
+	blob, ok := s.blobs[desc.Digest]
+	if !ok {
+		return nil, errors.New("blob not found")
+	}
+	return blob, nil
+}
+
+func (s *mockStore) FetchManifest(_ context.Context, _ string, _ ocispec.Descriptor) ([]byte, error) {
+	return s.manifest, nil
+}
+
+// newVerifyOptions returns the verify options of a bundle artifact with the
+// layers of the media type.
+func newVerifyOptions(t *testing.T, subject digest.Digest, mediaType string, layers ...[]byte) *ratify.VerifyOptions {
+	t.Helper()
+	store := &mockStore{blobs: map[digest.Digest][]byte{}}
+	manifest := ocispec.Manifest{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: testBundleType,
+		Config:       ocispec.DescriptorEmptyJSON,
+		Layers:       []ocispec.Descriptor{},
+	}
+	for _, layer := range layers {
+		desc := ocispec.Descriptor{
+			MediaType: mediaType,
+			Digest:    digest.FromBytes(layer),
+			Size:      int64(len(layer)),
+		}
+		manifest.Layers = append(manifest.Layers, desc)
+		store.blobs[desc.Digest] = layer
+	}
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("failed to marshal manifest: %v", err)
+	}
+	store.manifest = content
+	return &ratify.VerifyOptions{
+		Store:      store,
+		Repository: testRepo,
+		SubjectDescriptor: ocispec.Descriptor{
+			MediaType: ocispec.MediaTypeImageManifest,
+			Digest:    subject,
+			Size:      7,
+		},
+		ArtifactDescriptor: ocispec.Descriptor{
+			MediaType:    ocispec.MediaTypeImageManifest,
+			ArtifactType: testBundleType,
+			Digest:       digest.FromBytes(content),
+			Size:         int64(len(content)),
+		},
+	}
+}
+
+func newTestVerifier(t *testing.T, s *testSigstore, params map[string]any) ratify.Verifier {
+	t.Helper()
+	parameters := map[string]any{
+		"trustedRoot": s.writeTrustedRoot(t),
+		"identities": []map[string]any{
+			{"certificateIdentity": testIdentity, "certificateOIDCIssuer": testIssuer},
+		},
+	}
+	for k, val := range params {
+		parameters[k] = val
+	}
+	v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+		Type:       sigstoreBundleType,
+		Name:       testName,
+		Parameters: parameters,
+	})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+	return v
+}
+
+func TestVerifier_Verifiable(t *testing.T) {
+	v := newTestVerifier(t, newTestSigstore(t), nil)
+	tests := []struct {
+		desc   ocispec.Descriptor
+		expect bool
+	}{
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: testBundleType}, true},
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: "application/vnd.dev.sigstore.bundle+json;version=0.3"}, true},
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: "application/vnd.dev.cosign.artifact.sig.v1+json"}, false},
+		{ocispec.Descriptor{MediaType: ocispec.MediaTypeImageIndex, ArtifactType: testBundleType}, false},
+	}
+	for _, test := range tests {
+		if got := v.Verifiable(test.desc); got != test.expect {
+			t.Errorf("expected Verifiable(%s, %s) to be %v, got %v", test.desc.MediaType, test.desc.ArtifactType, test.expect, got)
+		}
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	s := newTestSigstore(t)
+	untrusted := newTestSigstore(t)
+	subject := digest.FromString("subject")
+	other := digest.FromString("other")
+
+	tests := []struct {
+		name           string
+		params         map[string]any
+		opts           *ratify.VerifyOptions
+		expectErr      bool
+		expectStoreErr bool
+		expectTimes    int
+	}{
+		{
+			name:        "message signature",
+			opts:        newVerifyOptions(t, subject, testBundleType, marshalBundle(t, s.sign(t, bundleOptions{subject: subject}))),
+			expectTimes: 1,
+		},
+		{
+			name:        "DSSE envelope with signed timestamp",
+			opts:        newVerifyOptions(t, subject, testBundleType, marshalBundle(t, s.sign(t, bundleOptions{subject: subject, dsse: true, timestamp: true}))),
+			expectTimes: 2,
+		},
+		{
+			name:        "inclusion proof with signed timestamp",
+			params:      map[string]any{"timestampThreshold": 1},
+			opts:        newVerifyOptions(t, subject, testBundleType, marshalBundle(t, s.sign(t, bundleOptions{subject: subject, noPromise: true, timestamp: true}))),
+			expectTimes: 1,
+		},
+		{
+			name:        "signed timestamp without transparency log",
+			params:      map[string]any{"transparencyLogThreshold": 0, "timestampThreshold": 1},
+			opts:        newVerifyOptions(t, subject, testBundleType, marshalBundle(t, s.sign(t, bundleOptions{subject: subject, noTlog: true, timestamp: true}))),
+			expectTimes: 1,
+		},
+		{
+			name:      "inclusion proof without verified time",
+			opts:      newVerifyOptions(t, subject, testBundleType, marshalBundle(t, s.sign(t, bundleOptions{subject: subject, noPromise: true}))),
+			expectErr: true,
+		},
+		{
+			name:      "missing transparency log entry",
+			opts:      newVerifyOptions(t, subject, testBundleType, marshalBundle(t, s.sign(t, bundleOptions{subject: subject, noTlog: true, timestamp: true}))),
+			expectErr: true,
+		},
+		{
+			name:      "missing signed timestamp",
+			params:    map[string]any{"timestampThreshold": 1},
+			opts:      newVerifyOptions(t, subject, testBundleType, marshalBundle(t, s.sign(t, bundleOptions{subject: subject}))),
+			expectErr: true,
+		},
+		{
+			name:      "untrusted deployment",
+			opts:      newVerifyOptions(t, subject, testBundleType, marshalBundle(t, untrusted.sign(t, bundleOptions{subject: subject}))),
+			expectErr: true,
+		},
+		{
+			name:      "untrusted identity",
+			opts:      newVerifyOptions(t, subject, testBundleType, marshalBundle(t, s.sign(t, bundleOptions{subject: subject, identity: "https://github.com/org/other/.github/workflows/release.yml@refs/heads/main"}))),
+			expectErr: true,
+		},
+		{
+			name:      "untrusted issuer",
+			opts:      newVerifyOptions(t, subject, testBundleType, marshalBundle(t, s.sign(t, bundleOptions{subject: subject, issuer: "https://accounts.google.com"}))),
+			expectErr: true,
+		},
+		{
+			name:      "message signature for other subject",
+			opts:      newVerifyOptions(t, subject, testBundleType, marshalBundle(t, s.sign(t, bundleOptions{subject: other}))),
+			expectErr: true,
+		},
+		{
+			name:      "transparency log entry of other certificate",
+			opts:      newVerifyOptions(t, subject, testBundleType, marshalBundle(t, withTlogEntries(s.sign(t, bundleOptions{subject: subject}), s.sign(t, bundleOptions{subject: subject})))),
+			expectErr: true,
+		},
+		{
+			name:      "DSSE transparency log entry of other certificate",
+			opts:      newVerifyOptions(t, subject, testBundleType, marshalBundle(t, withTlogEntries(s.sign(t, bundleOptions{subject: subject, dsse: true}), s.sign(t, bundleOptions{subject: subject, dsse: true})))),
+			expectErr: true,
+		},
+		{
+			name:      "DSSE envelope for other subject",
+			opts:      newVerifyOptions(t, subject, testBundleType, marshalBundle(t, s.sign(t, bundleOptions{subject: other, dsse: true}))),
+			expectErr: true,
+		},
+		{
+			name:      "invalid bundle",
+			opts:      newVerifyOptions(t, subject, testBundleType, []byte(`{`)),
+			expectErr: true,
+		},
+		{
+			name:      "no bundle layer",
+			opts:      newVerifyOptions(t, subject, "application/octet-stream", []byte(`{}`)),
+			expectErr: true,
+		},
+		{
+			name:           "no layers",
+			opts:           newVerifyOptions(t, subject, testBundleType),
+			expectStoreErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := newTestVerifier(t, s, test.params)
+			result, err := v.Verify(context.Background(), test.opts)
+			if (err != nil) != test.expectStoreErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectStoreErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if (result.Err != nil) != test.expectErr {
+				t.Fatalf("expected verification error: %v, got: %v", test.expectErr, result.Err)
+			}
+			if result.Err != nil {
+				return
+			}
+			details := result.Detail.(map[string]any)["Bundles"].([]bundleDetail)
+			if len(details) != 1 {
+				t.Fatalf("expected 1 bundle, got %+v", details)
+			}
+			if len(details[0].VerifiedTimestamps) != test.expectTimes {
+				t.Errorf("expected %d verified timestamps, got %v", test.expectTimes, details[0].VerifiedTimestamps)
+			}
+			if details[0].Issuer != testIssuer || details[0].Identity != testIdentity {
+				t.Errorf("unexpected identity %s issued by %s", details[0].Identity, details[0].Issuer)
+			}
+		})
+	}
+}
+
+// TestVerifier_VerifyPublicGood verifies a bundle of the public good instance
+// taken from the sigstore-go examples.
+func TestVerifier_VerifyPublicGood(t *testing.T) {
+	content, err := os.ReadFile(filepath.Join("testdata", "bundle-provenance.json"))
+	if err != nil {
+		t.Fatalf("failed to read bundle: %v", err)
+	}
+	subject := digest.Digest("sha512:76176ffa33808b54602c7c35de5c6e9a4deb96066dba6533f50ac234f4f1f4c6b3527515dc17c06fbe2860030f410eee69ea20079bd3a2c6f3dcf3b329b10751")
+	const identity = "https://github.com/sigstore/sigstore-js/.github/workflows/release.yml@refs/heads/main"
+
+	tests := []struct {
+		name      string
+		identity  string
+		subject   digest.Digest
+		expectErr bool
+	}{
+		{
+			name:     "trusted identity",
+			identity: identity,
+			subject:  subject,
+		},
+		{
+			name:      "untrusted identity",
+			identity:  testIdentity,
+			subject:   subject,
+			expectErr: true,
+		},
+		{
+			name:      "other subject",
+			identity:  identity,
+			subject:   digest.FromString("subject"),
+			expectErr: true,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+				Type: sigstoreBundleType,
+				Name: testName,
+				Parameters: map[string]any{
+					"trustedRoot": filepath.Join("testdata", "trusted-root-public-good.json"),
+					"identities": []map[string]any{
+						{"certificateIdentity": test.identity, "certificateOIDCIssuer": testIssuer},
+					},
+				},
+			})
+			if err != nil {
+				t.Fatalf("failed to create verifier: %v", err)
+			}
+			result, err := v.Verify(context.Background(), newVerifyOptions(t, test.subject, testBundleType, content))
+			if err != nil {
+				t.Fatalf("failed to verify: %v", err)
+			}
+			if (result.Err != nil) != test.expectErr {
+				t.Errorf("expected verification error: %v, got: %v", test.expectErr, result.Err)
+			}
+		})
+	}
+}
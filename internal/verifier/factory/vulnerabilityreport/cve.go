@@ -0,0 +1,112 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vulnerabilityreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// cveEntry is an entry of the CVE deny-list or allow-list. Entries are either
+// a vulnerability identifier, e.g. "CVE-2021-44228", or an object with an
+// expiry date.
+type cveEntry struct {
+	// ID is the identifier of the vulnerability. Aliases of vulnerabilities,
+	// e.g. the CVE of a GitHub advisory, are matched as well. Required.
+	ID string `json:"id"`
+
+	// Expires is the expiry date of the entry in RFC 3339 format, e.g.
+	// "2025-12-31T00:00:00Z" or "2025-12-31". Expired entries are ignored.
+	// Optional. If not provided, the entry never expires.
+	Expires string `json:"expires,omitempty"`
+
+	// Reason describes why the vulnerability is listed. Optional.
+	Reason string `json:"reason,omitempty"`
+}
+
+// UnmarshalJSON accepts a vulnerability identifier or an object.
+func (e *cveEntry) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err == nil {
+		*e = cveEntry{ID: id}
+		return nil
+	}
+	type entry cveEntry
+	return json.Unmarshal(data, (*entry)(e))
+}
+
+// cveList is a compiled list of CVE entries.
+type cveList struct {
+	// entries maps the lower-cased identifiers to the entries.
+	entries map[string]cveListEntry
+}
+
+type cveListEntry struct {
+	// expires is the expiry time of the entry. The zero time never expires.
+	expires time.Time
+	reason  string
+}
+
+// newCVEList compiles the entries.
+func newCVEList(entries []cveEntry) (*cveList, error) {
+	l := &cveList{
+		entries: make(map[string]cveListEntry, len(entries)),
+	}
+	for _, entry := range entries {
+		if entry.ID == "" {
+			return nil, fmt.Errorf("CVE entry requires id")
+		}
+		listEntry := cveListEntry{
+			reason: entry.Reason,
+		}
+		if entry.Expires != "" {
+			var err error
+			if listEntry.expires, err = parseExpiry(entry.Expires); err != nil {
+				return nil, fmt.Errorf("invalid expiry of CVE entry %s: %w", entry.ID, err)
+			}
+		}
+		l.entries[strings.ToLower(entry.ID)] = listEntry
+	}
+	return l, nil
+}
+
+// match returns the first identifier listed and not expired at the given
+// time with the reason of its entry. The identifier is empty if none is
+// listed.
+func (l *cveList) match(ids []string, now time.Time) (id string, reason string) {
+	for _, id := range ids {
+		entry, ok := l.entries[strings.ToLower(id)]
+		if ok && (entry.expires.IsZero() || now.Before(entry.expires)) {
+			return id, entry.reason
+		}
+	}
+	return "", ""
+}
+
+// parseExpiry parses an RFC 3339 time or a date. Dates expire at the end of
+// the day in UTC.
+func parseExpiry(s string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, s); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC 3339 time or date, got %q", s)
+	}
+	return t.AddDate(0, 0, 1), nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vulnerabilityreport
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestCVEEntry_UnmarshalJSON(t *testing.T) {
+	var entries []cveEntry
+	if err := json.Unmarshal([]byte(`["CVE-1",{"id":"CVE-2","expires":"2025-01-01","reason":"no fix"}]`), &entries); err != nil {
+		t.Fatalf("failed to unmarshal entries: %v", err)
+	}
+	expected := []cveEntry{{ID: "CVE-1"}, {ID: "CVE-2", Expires: "2025-01-01", Reason: "no fix"}}
+	if len(entries) != len(expected) || entries[0] != expected[0] || entries[1] != expected[1] {
+		t.Errorf("expected %+v, got %+v", expected, entries)
+	}
+	if err := json.Unmarshal([]byte(`[1]`), &entries); err == nil {
+		t.Errorf("expected error for invalid entry")
+	}
+}
+
+func TestCVEList_Match(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	list, err := newCVEList([]cveEntry{
+		{ID: "CVE-2021-44228", Reason: "log4shell"},
+		{ID: "CVE-2024-0001", Expires: "2025-01-01"},
+		{ID: "CVE-2024-0002", Expires: "2024-12-31"},
+		{ID: "CVE-2024-0003", Expires: "2025-01-01T12:00:00Z"},
+	})
+	if err != nil {
+		t.Fatalf("failed to create CVE list: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		ids          []string
+		expectID     string
+		expectReason string
+	}{
+		{name: "listed", ids: []string{"cve-2021-44228"}, expectID: "cve-2021-44228", expectReason: "log4shell"},
+		{name: "listed alias", ids: []string{"GHSA-jfh8-c2jp-5v3q", "CVE-2021-44228"}, expectID: "CVE-2021-44228", expectReason: "log4shell"},
+		{name: "date not expired", ids: []string{"CVE-2024-0001"}, expectID: "CVE-2024-0001"},
+		{name: "date expired", ids: []string{"CVE-2024-0002"}},
+		{name: "time expired", ids: []string{"CVE-2024-0003"}},
+		{name: "not listed", ids: []string{"CVE-2024-0004"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			id, reason := list.match(test.ids, now)
+			if id != test.expectID || reason != test.expectReason {
+				t.Errorf("expected %q (%q), got %q (%q)", test.expectID, test.expectReason, id, reason)
+			}
+		})
+	}
+}
+
+func TestNewCVEList(t *testing.T) {
+	tests := []struct {
+		name      string
+		entries   []cveEntry
+		expectErr bool
+	}{
+		{name: "no entries"},
+		{name: "valid entries", entries: []cveEntry{{ID: "CVE-1"}, {ID: "CVE-2", Expires: "2025-01-01T00:00:00+01:00"}}},
+		{name: "missing id", entries: []cveEntry{{Expires: "2025-01-01"}}, expectErr: true},
+		{name: "invalid expiry", entries: []cveEntry{{ID: "CVE-1", Expires: "tomorrow"}}, expectErr: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := newCVEList(test.entries); (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vulnerabilityreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// grypeReport is the subset of a Grype JSON report used by the verifier.
+type grypeReport struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID       string `json:"id"`
+			Severity string `json:"severity"`
+			Fix      struct {
+				Versions []string `json:"versions"`
+			} `json:"fix"`
+		} `json:"vulnerability"`
+		RelatedVulnerabilities []struct {
+			ID string `json:"id"`
+		} `json:"relatedVulnerabilities"`
+		Artifact struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"artifact"`
+	} `json:"matches"`
+	Descriptor struct {
+		Name string `json:"name"`
+	} `json:"descriptor"`
+}
+
+// parseGrype parses a Grype JSON report. Related vulnerabilities, e.g. the CVE
+// of a GitHub advisory, are aliases of the matched vulnerability.
+func parseGrype(content []byte) (*report, error) {
+	var grype grypeReport
+	if err := json.Unmarshal(content, &grype); err != nil {
+		return nil, fmt.Errorf("failed to parse Grype report: %w", err)
+	}
+	r := &report{
+		format:  formatGrype,
+		scanner: strings.ToLower(grype.Descriptor.Name),
+	}
+	if r.scanner == "" {
+		r.scanner = formatGrype
+	}
+	for _, match := range grype.Matches {
+		vuln := vulnerability{
+			id:           match.Vulnerability.ID,
+			severity:     normalizeSeverity(match.Vulnerability.Severity),
+			pkg:          match.Artifact.Name,
+			version:      match.Artifact.Version,
+			fixedVersion: strings.Join(match.Vulnerability.Fix.Versions, ", "),
+		}
+		for _, related := range match.RelatedVulnerabilities {
+			if related.ID != "" && related.ID != vuln.id {
+				vuln.aliases = append(vuln.aliases, related.ID)
+			}
+		}
+		r.vulnerabilities = append(r.vulnerabilities, vuln)
+	}
+	return r, nil
+}
@@ -0,0 +1,69 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vulnerabilityreport
+
+import (
+	"testing"
+)
+
+func TestParseGrype(t *testing.T) {
+	tests := []struct {
+		name                  string
+		content               []byte
+		expectErr             bool
+		expectScanner         string
+		expectVulnerabilities []vulnerability
+	}{
+		{
+			name:          "vulnerabilities",
+			content:       readTestData(t, "grype.json"),
+			expectScanner: "grype",
+			expectVulnerabilities: []vulnerability{
+				{id: "GHSA-jfh8-c2jp-5v3q", aliases: []string{"CVE-2021-44228"}, severity: severityCritical, pkg: "log4j-core", version: "2.14.1", fixedVersion: "2.15.0"},
+				{id: "CVE-2023-0001", severity: "negligible", pkg: "libc6", version: "2.36-9"},
+			},
+		},
+		{
+			name:          "no descriptor",
+			content:       []byte(`{"matches":[{"vulnerability":{"id":"CVE-1","severity":"High"},"relatedVulnerabilities":[{"id":"CVE-1"}]}]}`),
+			expectScanner: "grype",
+			expectVulnerabilities: []vulnerability{
+				{id: "CVE-1", severity: severityHigh},
+			},
+		},
+		{
+			name:      "invalid report",
+			content:   []byte(`{"matches":{}}`),
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := parseGrype(test.content)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if r.scanner != test.expectScanner {
+				t.Errorf("expected scanner %q, got %q", test.expectScanner, r.scanner)
+			}
+			checkVulnerabilities(t, r.vulnerabilities, test.expectVulnerabilities)
+		})
+	}
+}
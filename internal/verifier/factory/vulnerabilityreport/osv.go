@@ -0,0 +1,84 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vulnerabilityreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"slices"
+)
+
+// osvResults is the subset of the JSON results of OSV-Scanner used by the
+// verifier.
+type osvResults struct {
+	Results []struct {
+		Packages []struct {
+			Package struct {
+				Name    string `json:"name"`
+				Version string `json:"version"`
+			} `json:"package"`
+			Vulnerabilities []struct {
+				ID               string   `json:"id"`
+				Aliases          []string `json:"aliases"`
+				DatabaseSpecific struct {
+					Severity string `json:"severity"`
+				} `json:"database_specific"`
+			} `json:"vulnerabilities"`
+			Groups []struct {
+				IDs         []string `json:"ids"`
+				MaxSeverity string   `json:"max_severity"`
+			} `json:"groups"`
+		} `json:"packages"`
+	} `json:"results"`
+}
+
+// parseOSV parses the JSON results of OSV-Scanner. The severity of a
+// vulnerability is the maximum CVSS score of its group, or the severity
+// reported by the advisory database if the group has no score.
+func parseOSV(content []byte) (*report, error) {
+	var osv osvResults
+	if err := json.Unmarshal(content, &osv); err != nil {
+		return nil, fmt.Errorf("failed to parse OSV results: %w", err)
+	}
+	r := &report{
+		format:  formatOSV,
+		scanner: "osv-scanner",
+	}
+	for _, result := range osv.Results {
+		for _, pkg := range result.Packages {
+			for _, vuln := range pkg.Vulnerabilities {
+				severity := severityUnknown
+				for _, group := range pkg.Groups {
+					if slices.Contains(group.IDs, vuln.ID) && group.MaxSeverity != "" {
+						severity = severityFromScore(group.MaxSeverity)
+						break
+					}
+				}
+				if severity == severityUnknown {
+					severity = normalizeSeverity(vuln.DatabaseSpecific.Severity)
+				}
+				r.vulnerabilities = append(r.vulnerabilities, vulnerability{
+					id:       vuln.ID,
+					aliases:  vuln.Aliases,
+					severity: severity,
+					pkg:      pkg.Package.Name,
+					version:  pkg.Package.Version,
+				})
+			}
+		}
+	}
+	return r, nil
+}
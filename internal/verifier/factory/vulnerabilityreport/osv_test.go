@@ -0,0 +1,62 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vulnerabilityreport
+
+import (
+	"testing"
+)
+
+func TestParseOSV(t *testing.T) {
+	tests := []struct {
+		name                  string
+		content               []byte
+		expectErr             bool
+		expectVulnerabilities []vulnerability
+	}{
+		{
+			name:    "vulnerabilities",
+			content: readTestData(t, "osv.json"),
+			expectVulnerabilities: []vulnerability{
+				{id: "GHSA-35jh-r3h4-6jhm", aliases: []string{"CVE-2021-23337"}, severity: severityHigh, pkg: "lodash", version: "4.17.20"},
+				{id: "GHSA-29mw-wpgm-hmr9", aliases: []string{"CVE-2020-28500"}, severity: severityMedium, pkg: "lodash", version: "4.17.20"},
+			},
+		},
+		{
+			name:    "no severity",
+			content: []byte(`{"results":[{"packages":[{"package":{"name":"a","version":"1"},"vulnerabilities":[{"id":"OSV-1"}]}]}]}`),
+			expectVulnerabilities: []vulnerability{
+				{id: "OSV-1", severity: severityUnknown, pkg: "a", version: "1"},
+			},
+		},
+		{
+			name:      "invalid results",
+			content:   []byte(`{"results":{}}`),
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := parseOSV(test.content)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err == nil {
+				checkVulnerabilities(t, r.vulnerabilities, test.expectVulnerabilities)
+			}
+		})
+	}
+}
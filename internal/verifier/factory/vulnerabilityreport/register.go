@@ -0,0 +1,113 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vulnerabilityreport
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const vulnerabilityReportType = "vulnerability-report"
+
+type options struct {
+	// ArtifactTypes are the artifact types of the vulnerability reports.
+	// Optional. Defaults to the SARIF, Trivy, Grype and OSV artifact types.
+	ArtifactTypes []string `json:"artifactTypes,omitempty"`
+
+	// DisallowedSeverities is a list of disallowed severities, e.g.
+	// "critical" and "high". Severities are case-insensitive, and
+	// vulnerabilities without severity have the "unknown" severity. Optional.
+	DisallowedSeverities []string `json:"disallowedSeverities,omitempty"`
+
+	// DenylistCVEs is a list of denied vulnerabilities regardless of their
+	// severity. Optional.
+	DenylistCVEs []cveEntry `json:"denylistCVEs,omitempty"`
+
+	// AllowlistCVEs is a list of accepted vulnerabilities regardless of their
+	// severity, e.g. vulnerabilities without fix. Denied vulnerabilities
+	// cannot be accepted. Optional.
+	AllowlistCVEs []cveEntry `json:"allowlistCVEs,omitempty"`
+
+	// MaximumAge is the maximum age of the report by its creation time, e.g.
+	// "24h". Optional. If not provided, the age is not checked.
+	MaximumAge string `json:"maximumAge,omitempty"`
+
+	// CreatedAnnotationName is the annotation of the report manifest with the
+	// creation time in RFC 3339 format. Optional. Defaults to
+	// "org.opencontainers.image.created".
+	CreatedAnnotationName string `json:"createdAnnotationName,omitempty"`
+
+	// NewestReportOnly verifies only the newest report by creation time if a
+	// subject has several reports. Older reports pass as superseded. Reports
+	// without creation time are the oldest. Optional.
+	NewestReportOnly bool `json:"newestReportOnly,omitempty"`
+
+	// VEX configures the VEX documents suppressing findings of vulnerabilities
+	// not affecting the subject. Optional.
+	VEX *vexOptions `json:"vex,omitempty"`
+}
+
+func init() {
+	factory.RegisterVerifierFactory(vulnerabilityReportType, func(opts *factory.NewVerifierOptions) (ratify.Verifier, error) {
+		raw, err := json.Marshal(opts.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal verifier parameters: %w", err)
+		}
+
+		var params options
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal verifier parameters: %w", err)
+		}
+
+		v := &verifier{
+			name:                 opts.Name,
+			artifactTypes:        params.ArtifactTypes,
+			createdAnnotation:    params.CreatedAnnotationName,
+			newestReportOnly:     params.NewestReportOnly,
+			disallowedSeverities: make(map[string]bool, len(params.DisallowedSeverities)),
+		}
+		if len(v.artifactTypes) == 0 {
+			v.artifactTypes = defaultArtifactTypes
+		}
+		if v.createdAnnotation == "" {
+			v.createdAnnotation = ocispec.AnnotationCreated
+		}
+		for _, severity := range params.DisallowedSeverities {
+			v.disallowedSeverities[strings.ToLower(severity)] = true
+		}
+		if v.denylist, err = newCVEList(params.DenylistCVEs); err != nil {
+			return nil, fmt.Errorf("invalid denylistCVEs: %w", err)
+		}
+		if v.allowlist, err = newCVEList(params.AllowlistCVEs); err != nil {
+			return nil, fmt.Errorf("invalid allowlistCVEs: %w", err)
+		}
+		if v.vex, err = newVEXResolver(params.VEX); err != nil {
+			return nil, err
+		}
+		if params.MaximumAge != "" {
+			if v.maximumAge, err = time.ParseDuration(params.MaximumAge); err != nil || v.maximumAge <= 0 {
+				return nil, fmt.Errorf("invalid maximumAge %q", params.MaximumAge)
+			}
+		}
+		return v, nil
+	})
+}
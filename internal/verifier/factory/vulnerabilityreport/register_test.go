@@ -0,0 +1,95 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vulnerabilityreport
+
+import (
+	"testing"
+
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+)
+
+const testName = "vulnerability-report-test"
+
+func TestNewVerifier(t *testing.T) {
+	tests := []struct {
+		name      string
+		params    any
+		expectErr bool
+	}{
+		{
+			name:      "Unsupported params",
+			params:    make(chan int),
+			expectErr: true,
+		},
+		{
+			name:      "Malformed params",
+			params:    "{",
+			expectErr: true,
+		},
+		{
+			name:      "Invalid denylist",
+			params:    map[string]any{"denylistCVEs": []map[string]any{{"expires": "2025-01-01"}}},
+			expectErr: true,
+		},
+		{
+			name:      "Invalid allowlist",
+			params:    map[string]any{"allowlistCVEs": []map[string]any{{"id": "CVE-1", "expires": "soon"}}},
+			expectErr: true,
+		},
+		{
+			name:      "Invalid VEX",
+			params:    map[string]any{"vex": map[string]any{}},
+			expectErr: true,
+		},
+		{
+			name:      "Invalid maximum age",
+			params:    options{MaximumAge: "1 day"},
+			expectErr: true,
+		},
+		{
+			name:   "No params",
+			params: nil,
+		},
+		{
+			name: "Valid params",
+			params: map[string]any{
+				"artifactTypes":         []string{artifactTypeSARIF},
+				"disallowedSeverities":  []string{"CRITICAL", "high"},
+				"denylistCVEs":          []any{"CVE-2021-44228", map[string]any{"id": "CVE-2021-45046", "expires": "2030-01-01"}},
+				"allowlistCVEs":         []any{map[string]any{"id": "CVE-2021-45105", "expires": "2030-01-01", "reason": "not exploitable"}},
+				"maximumAge":            "24h",
+				"createdAnnotationName": "createdAt",
+				"newestReportOnly":      true,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+				Type:       vulnerabilityReportType,
+				Name:       testName,
+				Parameters: test.params,
+			})
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err == nil && (v.Name() != testName || v.Type() != vulnerabilityReportType) {
+				t.Errorf("unexpected verifier %s of type %s", v.Name(), v.Type())
+			}
+		})
+	}
+}
@@ -0,0 +1,129 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vulnerabilityreport
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+const (
+	formatSARIF = "sarif"
+	formatTrivy = "trivy"
+	formatGrype = "grype"
+	formatOSV   = "osv"
+
+	severityCritical = "critical"
+	severityHigh     = "high"
+	severityMedium   = "medium"
+	severityModerate = "moderate"
+	severityLow      = "low"
+	severityUnknown  = "unknown"
+)
+
+// report is the content of a vulnerability report relevant for the
+// verification.
+type report struct {
+	// format is the format of the report, one of "sarif", "trivy", "grype" or
+	// "osv".
+	format string
+
+	// scanner is the lower-cased name of the scanner producing the report.
+	scanner string
+
+	vulnerabilities []vulnerability
+}
+
+// vulnerability is a vulnerability found in a package.
+type vulnerability struct {
+	// id is the identifier of the vulnerability, e.g. "CVE-2021-44228" or
+	// "GHSA-jfh8-c2jp-5v3q".
+	id string
+
+	// aliases are other identifiers of the same vulnerability.
+	aliases []string
+
+	// severity is the lower-cased severity, e.g. "critical". It is "unknown"
+	// if the report has no severity.
+	severity string
+
+	pkg          string
+	version      string
+	fixedVersion string
+}
+
+// ids returns the identifier and aliases of the vulnerability.
+func (v vulnerability) ids() []string {
+	return append([]string{v.id}, v.aliases...)
+}
+
+// parseReport detects the format of the report and parses it.
+func parseReport(content []byte) (*report, error) {
+	var probe map[string]json.RawMessage
+	if err := json.Unmarshal(content, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse vulnerability report: %w", err)
+	}
+	switch {
+	case probe["runs"] != nil:
+		return parseSARIF(content)
+	case probe["SchemaVersion"] != nil && probe["ArtifactName"] != nil:
+		return parseTrivy(content)
+	case probe["matches"] != nil:
+		return parseGrype(content)
+	case probe["results"] != nil:
+		return parseOSV(content)
+	default:
+		return nil, errors.New("unsupported vulnerability report format, expected SARIF, Trivy JSON, Grype JSON or OSV results")
+	}
+}
+
+// normalizeSeverity lower-cases the severity and maps empty severities to
+// "unknown". The "moderate" severity of GitHub advisories is mapped to
+// "medium".
+func normalizeSeverity(severity string) string {
+	switch severity = strings.ToLower(strings.TrimSpace(severity)); severity {
+	case "":
+		return severityUnknown
+	case severityModerate:
+		return severityMedium
+	default:
+		return severity
+	}
+}
+
+// severityFromScore maps a CVSS base score to the qualitative severity rating
+// of CVSS v3.
+func severityFromScore(score string) string {
+	value, err := strconv.ParseFloat(strings.TrimSpace(score), 64)
+	if err != nil {
+		return severityUnknown
+	}
+	switch {
+	case value >= 9.0:
+		return severityCritical
+	case value >= 7.0:
+		return severityHigh
+	case value >= 4.0:
+		return severityMedium
+	case value > 0:
+		return severityLow
+	default:
+		return severityUnknown
+	}
+}
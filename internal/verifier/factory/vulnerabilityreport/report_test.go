@@ -0,0 +1,155 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vulnerabilityreport
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func readTestData(t *testing.T, name string) []byte {
+	t.Helper()
+	content, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatalf("failed to read test data: %v", err)
+	}
+	return content
+}
+
+// checkVulnerabilities compares the vulnerabilities of the report.
+func checkVulnerabilities(t *testing.T, got, expected []vulnerability) {
+	t.Helper()
+	if len(got) != len(expected) {
+		t.Fatalf("expected %d vulnerabilities, got %d: %+v", len(expected), len(got), got)
+	}
+	for i, vuln := range got {
+		e := expected[i]
+		if vuln.id != e.id || !slices.Equal(vuln.aliases, e.aliases) || vuln.severity != e.severity || vuln.pkg != e.pkg || vuln.version != e.version || vuln.fixedVersion != e.fixedVersion {
+			t.Errorf("expected vulnerability %+v, got %+v", e, vuln)
+		}
+	}
+}
+
+func TestParseReport(t *testing.T) {
+	tests := []struct {
+		name          string
+		content       []byte
+		expectErr     bool
+		expectFormat  string
+		expectScanner string
+		expectCount   int
+	}{
+		{
+			name:          "SARIF",
+			content:       readTestData(t, "sarif.json"),
+			expectFormat:  formatSARIF,
+			expectScanner: "trivy",
+			expectCount:   3,
+		},
+		{
+			name:          "Trivy",
+			content:       readTestData(t, "trivy.json"),
+			expectFormat:  formatTrivy,
+			expectScanner: "trivy",
+			expectCount:   2,
+		},
+		{
+			name:          "Grype",
+			content:       readTestData(t, "grype.json"),
+			expectFormat:  formatGrype,
+			expectScanner: "grype",
+			expectCount:   2,
+		},
+		{
+			name:          "OSV",
+			content:       readTestData(t, "osv.json"),
+			expectFormat:  formatOSV,
+			expectScanner: "osv-scanner",
+			expectCount:   2,
+		},
+		{
+			name:      "unsupported format",
+			content:   []byte(`{"name":"test"}`),
+			expectErr: true,
+		},
+		{
+			name:      "invalid JSON",
+			content:   []byte(`[`),
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := parseReport(test.content)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if r.format != test.expectFormat || r.scanner != test.expectScanner || len(r.vulnerabilities) != test.expectCount {
+				t.Errorf("expected %s report of %s with %d vulnerabilities, got %s report of %s with %d vulnerabilities", test.expectFormat, test.expectScanner, test.expectCount, r.format, r.scanner, len(r.vulnerabilities))
+			}
+		})
+	}
+}
+
+func TestNormalizeSeverity(t *testing.T) {
+	tests := []struct {
+		severity string
+		expect   string
+	}{
+		{severity: "CRITICAL", expect: severityCritical},
+		{severity: " High ", expect: severityHigh},
+		{severity: "MODERATE", expect: severityMedium},
+		{severity: "Negligible", expect: "negligible"},
+		{severity: "", expect: severityUnknown},
+	}
+
+	for _, test := range tests {
+		t.Run(test.severity, func(t *testing.T) {
+			if got := normalizeSeverity(test.severity); got != test.expect {
+				t.Errorf("expected %q, got %q", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestSeverityFromScore(t *testing.T) {
+	tests := []struct {
+		score  string
+		expect string
+	}{
+		{score: "10.0", expect: severityCritical},
+		{score: "9.0", expect: severityCritical},
+		{score: "8.8", expect: severityHigh},
+		{score: "4", expect: severityMedium},
+		{score: "3.9", expect: severityLow},
+		{score: "0.0", expect: severityUnknown},
+		{score: "CVSS:3.1/AV:N", expect: severityUnknown},
+	}
+
+	for _, test := range tests {
+		t.Run(test.score, func(t *testing.T) {
+			if got := severityFromScore(test.score); got != test.expect {
+				t.Errorf("expected %q, got %q", test.expect, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vulnerabilityreport
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/owenrumney/go-sarif/v2/sarif"
+)
+
+// sarifSecuritySeverity is the rule property of the CVSS score used by GitHub
+// code scanning and scanners like Trivy and Grype.
+const sarifSecuritySeverity = "security-severity"
+
+// sarifSeverityRegex matches the severity in the rule help text of Trivy and
+// Grype SARIF reports.
+var sarifSeverityRegex = regexp.MustCompile(`Severity:\s*(\w+)`)
+
+// parseSARIF parses a SARIF report. Each result of the first run is a
+// vulnerability identified by its rule ID.
+func parseSARIF(content []byte) (*report, error) {
+	sarifReport, err := sarif.FromBytes(content)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse SARIF report: %w", err)
+	}
+	if len(sarifReport.Runs) == 0 {
+		return nil, errors.New("no runs found in SARIF report")
+	}
+	run := sarifReport.Runs[0]
+	r := &report{
+		format:  formatSARIF,
+		scanner: strings.ToLower(run.Tool.Driver.Name),
+	}
+	rules := make(map[string]*sarif.ReportingDescriptor)
+	for _, rule := range run.Tool.Driver.Rules {
+		rules[rule.ID] = rule
+	}
+	for _, result := range run.Results {
+		if result.RuleID == nil || *result.RuleID == "" {
+			return nil, errors.New("rule id not found for SARIF result")
+		}
+		rule, ok := rules[*result.RuleID]
+		if !ok {
+			return nil, fmt.Errorf("rule %s not found for SARIF result", *result.RuleID)
+		}
+		r.vulnerabilities = append(r.vulnerabilities, vulnerability{
+			id:       rule.ID,
+			severity: sarifSeverity(rule),
+		})
+	}
+	return r, nil
+}
+
+// sarifSeverity returns the severity of the rule from its help text, or from
+// its security severity score if the help text has no severity.
+func sarifSeverity(rule *sarif.ReportingDescriptor) string {
+	if rule.Help != nil && rule.Help.Text != nil {
+		if match := sarifSeverityRegex.FindStringSubmatch(*rule.Help.Text); len(match) == 2 {
+			return normalizeSeverity(match[1])
+		}
+	}
+	if score, ok := rule.Properties[sarifSecuritySeverity]; ok {
+		return severityFromScore(fmt.Sprint(score))
+	}
+	return severityUnknown
+}
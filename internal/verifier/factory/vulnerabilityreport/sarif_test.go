@@ -0,0 +1,71 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vulnerabilityreport
+
+import (
+	"testing"
+)
+
+func TestParseSARIF(t *testing.T) {
+	tests := []struct {
+		name                  string
+		content               []byte
+		expectErr             bool
+		expectVulnerabilities []vulnerability
+	}{
+		{
+			name:    "severities",
+			content: readTestData(t, "sarif.json"),
+			expectVulnerabilities: []vulnerability{
+				{id: "CVE-2021-44228", severity: severityCritical},
+				{id: "CVE-2022-23307", severity: severityHigh},
+				{id: "CVE-2020-0001", severity: severityUnknown},
+			},
+		},
+		{
+			name:      "no runs",
+			content:   []byte(`{"version":"2.1.0","runs":[]}`),
+			expectErr: true,
+		},
+		{
+			name:      "missing rule id",
+			content:   []byte(`{"version":"2.1.0","runs":[{"tool":{"driver":{"name":"trivy"}},"results":[{"message":{"text":"a"}}]}]}`),
+			expectErr: true,
+		},
+		{
+			name:      "missing rule",
+			content:   []byte(`{"version":"2.1.0","runs":[{"tool":{"driver":{"name":"trivy"}},"results":[{"ruleId":"CVE-1","message":{"text":"a"}}]}]}`),
+			expectErr: true,
+		},
+		{
+			name:      "invalid report",
+			content:   []byte(`{"runs":{}}`),
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := parseSARIF(test.content)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err == nil {
+				checkVulnerabilities(t, r.vulnerabilities, test.expectVulnerabilities)
+			}
+		})
+	}
+}
@@ -0,0 +1,58 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vulnerabilityreport
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// trivyReport is the subset of a Trivy JSON report used by the verifier.
+type trivyReport struct {
+	Results []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			FixedVersion     string `json:"FixedVersion"`
+			Severity         string `json:"Severity"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// parseTrivy parses a Trivy JSON report.
+func parseTrivy(content []byte) (*report, error) {
+	var trivy trivyReport
+	if err := json.Unmarshal(content, &trivy); err != nil {
+		return nil, fmt.Errorf("failed to parse Trivy report: %w", err)
+	}
+	r := &report{
+		format:  formatTrivy,
+		scanner: formatTrivy,
+	}
+	for _, result := range trivy.Results {
+		for _, vuln := range result.Vulnerabilities {
+			r.vulnerabilities = append(r.vulnerabilities, vulnerability{
+				id:           vuln.VulnerabilityID,
+				severity:     normalizeSeverity(vuln.Severity),
+				pkg:          vuln.PkgName,
+				version:      vuln.InstalledVersion,
+				fixedVersion: vuln.FixedVersion,
+			})
+		}
+	}
+	return r, nil
+}
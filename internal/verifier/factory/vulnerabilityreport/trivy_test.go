@@ -0,0 +1,59 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vulnerabilityreport
+
+import (
+	"testing"
+)
+
+func TestParseTrivy(t *testing.T) {
+	tests := []struct {
+		name                  string
+		content               []byte
+		expectErr             bool
+		expectVulnerabilities []vulnerability
+	}{
+		{
+			name:    "vulnerabilities",
+			content: readTestData(t, "trivy.json"),
+			expectVulnerabilities: []vulnerability{
+				{id: "CVE-2021-44228", severity: severityCritical, pkg: "org.apache.logging.log4j:log4j-core", version: "2.14.1", fixedVersion: "2.15.0"},
+				{id: "CVE-2021-45105", severity: severityMedium, pkg: "org.apache.logging.log4j:log4j-core", version: "2.14.1", fixedVersion: "2.17.0"},
+			},
+		},
+		{
+			name:    "no results",
+			content: []byte(`{"SchemaVersion":2,"ArtifactName":"test"}`),
+		},
+		{
+			name:      "invalid report",
+			content:   []byte(`{"SchemaVersion":2,"ArtifactName":"test","Results":{}}`),
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			r, err := parseTrivy(test.content)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err == nil {
+				checkVulnerabilities(t, r.vulnerabilities, test.expectVulnerabilities)
+			}
+		})
+	}
+}
@@ -0,0 +1,279 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vulnerabilityreport
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/artifact"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	artifactTypeSARIF = "application/sarif+json"
+	artifactTypeTrivy = "application/trivy+json"
+	artifactTypeGrype = "application/grype+json"
+	artifactTypeOSV   = "application/osv+json"
+)
+
+// defaultArtifactTypes are the artifact types verified by default.
+var defaultArtifactTypes = []string{artifactTypeSARIF, artifactTypeTrivy, artifactTypeGrype, artifactTypeOSV}
+
+// finding is a vulnerability violating the policy.
+type finding struct {
+	// ID is the identifier of the vulnerability.
+	ID string `json:"id"`
+
+	// Aliases are other identifiers of the vulnerability. Optional.
+	Aliases []string `json:"aliases,omitempty"`
+
+	// Severity is the lower-cased severity of the vulnerability.
+	Severity string `json:"severity"`
+
+	// Package is the name of the vulnerable package. Optional.
+	Package string `json:"package,omitempty"`
+
+	// Version is the installed version of the vulnerable package. Optional.
+	Version string `json:"version,omitempty"`
+
+	// FixedVersion is the version fixing the vulnerability. Optional.
+	FixedVersion string `json:"fixedVersion,omitempty"`
+
+	// Reason describes the violation.
+	Reason string `json:"reason"`
+}
+
+// verifier is a ratify.Verifier implementation that verifies vulnerability
+// reports against severity, CVE and age policies.
+type verifier struct {
+	name                 string
+	artifactTypes        []string
+	disallowedSeverities map[string]bool
+	denylist             *cveList
+	allowlist            *cveList
+	maximumAge           time.Duration
+	createdAnnotation    string
+	newestReportOnly     bool
+	vex                  *vexResolver
+}
+
+// Name returns the name of the verifier.
+func (v *verifier) Name() string {
+	return v.name
+}
+
+// Type returns the type of the verifier which is always
+// `vulnerability-report`.
+func (v *verifier) Type() string {
+	return vulnerabilityReportType
+}
+
+// Verifiable returns true if the artifact is a vulnerability report of the
+// configured artifact types.
+func (v *verifier) Verifiable(artifact ocispec.Descriptor) bool {
+	return slices.Contains(v.artifactTypes, artifact.ArtifactType) && artifact.MediaType == ocispec.MediaTypeImageManifest
+}
+
+// Verify verifies the vulnerability report in the first layer of the
+// artifact.
+func (v *verifier) Verify(ctx context.Context, opts *ratify.VerifyOptions) (*ratify.VerificationResult, error) {
+	manifest, err := artifact.FetchManifest(ctx, opts.Store, opts.Repository, opts.ArtifactDescriptor)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &ratify.VerificationResult{
+		Verifier: v,
+	}
+	detail := map[string]any{}
+	created, err := v.createdTime(opts.ArtifactDescriptor, manifest)
+	if err != nil {
+		result.Err = err
+		return result, nil
+	}
+	if !created.IsZero() {
+		detail["Created"] = created
+	}
+	result.Detail = detail
+
+	if v.newestReportOnly {
+		newest, err := v.newestReport(ctx, opts, created)
+		if err != nil {
+			return nil, err
+		}
+		if newest.Digest != opts.ArtifactDescriptor.Digest {
+			detail["Superseded"] = true
+			detail["NewestReport"] = newest.Digest.String()
+			result.Description = fmt.Sprintf("Vulnerability report superseded by newer report %s", newest.Digest)
+			return result, nil
+		}
+	}
+
+	if v.maximumAge > 0 {
+		if created.IsZero() {
+			result.Err = fmt.Errorf("created annotation %s not found for report %s", v.createdAnnotation, opts.ArtifactDescriptor.Digest)
+			return result, nil
+		}
+		if time.Since(created) > v.maximumAge {
+			result.Err = fmt.Errorf("report created at %s is older than the maximum age %s", created.Format(time.RFC3339), v.maximumAge)
+			return result, nil
+		}
+	}
+
+	if len(manifest.Layers) == 0 {
+		result.Err = fmt.Errorf("no layers found in report %s", opts.ArtifactDescriptor.Digest)
+		return result, nil
+	}
+	content, err := opts.Store.FetchBlob(ctx, opts.Repository, manifest.Layers[0])
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch blob %s: %w", manifest.Layers[0].Digest, err)
+	}
+	r, err := parseReport(content)
+	if err != nil {
+		result.Err = err
+		return result, nil
+	}
+	detail["Format"] = r.format
+	detail["Scanner"] = r.scanner
+	detail["Vulnerabilities"] = len(r.vulnerabilities)
+
+	var index vexIndex
+	if v.vex != nil && len(r.vulnerabilities) > 0 {
+		var ignored []ignoredVEX
+		if index, ignored, err = v.vex.resolve(ctx, opts); err != nil {
+			return nil, err
+		}
+		if len(ignored) > 0 {
+			detail["IgnoredVEX"] = ignored
+		}
+	}
+
+	findings, suppressions := v.check(r, time.Now(), index)
+	if len(suppressions) > 0 {
+		detail["Suppressed"] = suppressions
+	}
+	if len(findings) > 0 {
+		detail["Findings"] = findings
+		result.Err = fmt.Errorf("report has %d disallowed vulnerabilities", len(findings))
+		result.Description = "Vulnerability report verification failed"
+		return result, nil
+	}
+	result.Description = "Vulnerability report verification succeeded"
+	return result, nil
+}
+
+// check returns the vulnerabilities of the report violating the policy at the
+// given time, and the violations suppressed by VEX statements. Denied
+// vulnerabilities cannot be suppressed.
+func (v *verifier) check(r *report, now time.Time, index vexIndex) ([]finding, []suppression) {
+	var (
+		findings     []finding
+		suppressions []suppression
+	)
+	for _, vuln := range r.vulnerabilities {
+		var (
+			reason    string
+			statement *vexStatement
+		)
+		if id, listReason := v.denylist.match(vuln.ids(), now); id != "" {
+			reason = fmt.Sprintf("%s is denied", id)
+			if listReason != "" {
+				reason += ": " + listReason
+			}
+		} else if id, _ := v.allowlist.match(vuln.ids(), now); id != "" {
+			continue
+		} else if v.disallowedSeverities[vuln.severity] {
+			reason = fmt.Sprintf("severity %s is disallowed", vuln.severity)
+			statement = index.suppression(vuln)
+		} else {
+			continue
+		}
+		f := finding{
+			ID:           vuln.id,
+			Aliases:      vuln.aliases,
+			Severity:     vuln.severity,
+			Package:      vuln.pkg,
+			Version:      vuln.version,
+			FixedVersion: vuln.fixedVersion,
+			Reason:       reason,
+		}
+		if statement != nil {
+			suppressions = append(suppressions, suppression{
+				Finding:   f,
+				Statement: statement,
+			})
+			continue
+		}
+		findings = append(findings, f)
+	}
+	return findings, suppressions
+}
+
+// createdTime returns the creation time of the report from the created
+// annotation of the descriptor or, if absent, of the manifest. It returns the
+// zero time if neither has the annotation.
+func (v *verifier) createdTime(desc ocispec.Descriptor, manifest *ocispec.Manifest) (time.Time, error) {
+	created, ok := desc.Annotations[v.createdAnnotation]
+	if !ok && manifest != nil {
+		created, ok = manifest.Annotations[v.createdAnnotation]
+	}
+	if !ok {
+		return time.Time{}, nil
+	}
+	t, err := time.Parse(time.RFC3339, created)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid created annotation %s: %q is not in RFC 3339 format", v.createdAnnotation, created)
+	}
+	return t, nil
+}
+
+// newestReport returns the newest vulnerability report of the subject by
+// creation time. Reports created at the same time are ordered by digest.
+func (v *verifier) newestReport(ctx context.Context, opts *ratify.VerifyOptions, created time.Time) (ocispec.Descriptor, error) {
+	newest, newestCreated := opts.ArtifactDescriptor, created
+	subject := opts.Repository + "@" + opts.SubjectDescriptor.Digest.String()
+	err := opts.Store.ListReferrers(ctx, subject, v.artifactTypes, func(referrers []ocispec.Descriptor) error {
+		for _, referrer := range referrers {
+			if referrer.Digest == opts.ArtifactDescriptor.Digest || !v.Verifiable(referrer) {
+				continue
+			}
+			var manifest *ocispec.Manifest
+			if _, ok := referrer.Annotations[v.createdAnnotation]; !ok {
+				var err error
+				if manifest, err = artifact.FetchManifest(ctx, opts.Store, opts.Repository, referrer); err != nil {
+					return err
+				}
+			}
+			referrerCreated, err := v.createdTime(referrer, manifest)
+			if err != nil {
+				// reports with invalid creation time cannot be newer.
+				continue
+			}
+			if referrerCreated.After(newestCreated) || (referrerCreated.Equal(newestCreated) && referrer.Digest > newest.Digest) {
+				newest, newestCreated = referrer, referrerCreated
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return ocispec.Descriptor{}, fmt.Errorf("failed to list vulnerability reports of subject %s: %w", subject, err)
+	}
+	return newest, nil
+}
@@ -0,0 +1,435 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vulnerabilityreport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const testRepo = "test.registry.io/test/image"
+
+var testSubject = ocispec.Descriptor{
+	MediaType: ocispec.MediaTypeImageManifest,
+	Digest:    digest.FromString("subject"),
+	Size:      7,
+}
+
+// mockStore serves vulnerability reports of the test subject and referrers of
+// the reports.
+type mockStore struct {
+	referrers         []ocispec.Descriptor
+	artifactReferrers map[digest.Digest][]ocispec.Descriptor
+	manifests         map[digest.Digest][]byte
+	blobs             map[digest.Digest][]byte
+	referrersErr      error
+}
+
+func newMockStore() *mockStore {
+	return &mockStore{
+		artifactReferrers: make(map[digest.Digest][]ocispec.Descriptor),
+		manifests:         make(map[digest.Digest][]byte),
+		blobs:             make(map[digest.Digest][]byte),
+	}
+}
+
+func (s *mockStore) Resolve(_ context.Context, _ string) (ocispec.Descriptor, error) {
+	return ocispec.Descriptor{}, errors.New("not implemented")
+}
+
+func (s *mockStore) ListReferrers(_ context.Context, ref string, _ []string, fn func(referrers []ocispec.Descriptor) error) error {
+	if s.referrersErr != nil {
+		return s.referrersErr
+	}
+	repo, dgst, ok := strings.Cut(ref, "@")
+	if !ok || repo != testRepo {
+		return errors.New("unexpected subject")
+	}
+	if dgst == testSubject.Digest.String() {
+		return fn(s.referrers)
+	}
+	return fn(s.artifactReferrers[digest.Digest(dgst)])
+}
+
+func (s *mockStore) FetchBlob(_ context.Context, _ string, desc ocispec.Descriptor) ([]byte, error) {
+	blob, ok := s.blobs[desc.Digest]
+	if !ok {
+		return nil, errors.New("blob not found")
+	}
+	return blob, nil
+}
+
+func (s *mockStore) FetchManifest(_ context.Context, _ string, desc ocispec.Descriptor) ([]byte, error) {
+	manifest, ok := s.manifests[desc.Digest]
+	if !ok {
+		return nil, errors.New("manifest not found")
+	}
+	return manifest, nil
+}
+
+// addReferrer adds an artifact referring the test subject and returns its
+// descriptor. The created time is set as annotation of the descriptor if
+// descriptorAnnotations is true, and of the manifest otherwise.
+func (s *mockStore) addReferrer(t *testing.T, artifactType string, content []byte, created string, descriptorAnnotations bool) ocispec.Descriptor {
+	t.Helper()
+	manifest := ocispec.Manifest{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: artifactType,
+		Config:       ocispec.DescriptorEmptyJSON,
+		Layers:       []ocispec.Descriptor{},
+		Subject:      &testSubject,
+	}
+	var annotations map[string]string
+	if created != "" {
+		annotations = map[string]string{ocispec.AnnotationCreated: created}
+	}
+	if !descriptorAnnotations {
+		manifest.Annotations = annotations
+	}
+	if content != nil {
+		layer := ocispec.Descriptor{
+			MediaType: artifactType,
+			Digest:    digest.FromBytes(content),
+			Size:      int64(len(content)),
+		}
+		s.blobs[layer.Digest] = content
+		manifest.Layers = append(manifest.Layers, layer)
+	}
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("failed to marshal manifest: %v", err)
+	}
+	desc := ocispec.Descriptor{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: artifactType,
+		Digest:       digest.FromBytes(manifestBytes),
+		Size:         int64(len(manifestBytes)),
+	}
+	if descriptorAnnotations {
+		desc.Annotations = annotations
+	}
+	s.manifests[desc.Digest] = manifestBytes
+	s.referrers = append(s.referrers, desc)
+	return desc
+}
+
+func newTestVerifier(t *testing.T, params map[string]any) ratify.Verifier {
+	t.Helper()
+	v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+		Type:       vulnerabilityReportType,
+		Name:       testName,
+		Parameters: params,
+	})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+	return v
+}
+
+func TestVerifier_Verifiable(t *testing.T) {
+	tests := []struct {
+		name   string
+		params map[string]any
+		desc   ocispec.Descriptor
+		expect bool
+	}{
+		{
+			name:   "default artifact type",
+			desc:   ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: artifactTypeTrivy},
+			expect: true,
+		},
+		{
+			name: "configured artifact types",
+			params: map[string]any{
+				"artifactTypes": []string{"application/vnd.example.report+json"},
+			},
+			desc:   ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: "application/vnd.example.report+json"},
+			expect: true,
+		},
+		{
+			name: "artifact type not configured",
+			params: map[string]any{
+				"artifactTypes": []string{"application/vnd.example.report+json"},
+			},
+			desc: ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: artifactTypeSARIF},
+		},
+		{
+			name: "other media type",
+			desc: ocispec.Descriptor{MediaType: ocispec.MediaTypeImageIndex, ArtifactType: artifactTypeSARIF},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v := newTestVerifier(t, test.params)
+			if got := v.Verifiable(test.desc); got != test.expect {
+				t.Errorf("expected %v, got %v", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	now := time.Now().UTC()
+	recent := now.Add(-time.Hour).Format(time.RFC3339)
+	old := now.Add(-48 * time.Hour).Format(time.RFC3339)
+
+	tests := []struct {
+		name           string
+		params         map[string]any
+		artifactType   string
+		report         []byte
+		created        string
+		expectFailure  bool
+		expectFindings []string
+	}{
+		{
+			name:         "no policies",
+			artifactType: artifactTypeTrivy,
+			report:       readTestData(t, "trivy.json"),
+		},
+		{
+			name:           "disallowed severities",
+			params:         map[string]any{"disallowedSeverities": []string{"CRITICAL", "high"}},
+			artifactType:   artifactTypeSARIF,
+			report:         readTestData(t, "sarif.json"),
+			expectFailure:  true,
+			expectFindings: []string{"CVE-2021-44228", "CVE-2022-23307"},
+		},
+		{
+			name:           "disallowed unknown severity",
+			params:         map[string]any{"disallowedSeverities": []string{"unknown"}},
+			artifactType:   artifactTypeSARIF,
+			report:         readTestData(t, "sarif.json"),
+			expectFailure:  true,
+			expectFindings: []string{"CVE-2020-0001"},
+		},
+		{
+			name:           "denied CVE alias",
+			params:         map[string]any{"denylistCVEs": []string{"CVE-2021-44228"}},
+			artifactType:   artifactTypeGrype,
+			report:         readTestData(t, "grype.json"),
+			expectFailure:  true,
+			expectFindings: []string{"GHSA-jfh8-c2jp-5v3q"},
+		},
+		{
+			name:         "expired denied CVE",
+			params:       map[string]any{"denylistCVEs": []map[string]any{{"id": "CVE-2021-44228", "expires": "2020-01-01"}}},
+			artifactType: artifactTypeGrype,
+			report:       readTestData(t, "grype.json"),
+		},
+		{
+			name: "allowed CVE",
+			params: map[string]any{
+				"disallowedSeverities": []string{"high", "medium"},
+				"allowlistCVEs":        []map[string]any{{"id": "CVE-2021-23337", "expires": now.AddDate(0, 0, 7).Format(time.DateOnly)}},
+			},
+			artifactType:   artifactTypeOSV,
+			report:         readTestData(t, "osv.json"),
+			expectFailure:  true,
+			expectFindings: []string{"GHSA-29mw-wpgm-hmr9"},
+		},
+		{
+			name: "expired allowed CVE",
+			params: map[string]any{
+				"disallowedSeverities": []string{"high"},
+				"allowlistCVEs":        []map[string]any{{"id": "CVE-2021-23337", "expires": "2020-01-01"}},
+			},
+			artifactType:   artifactTypeOSV,
+			report:         readTestData(t, "osv.json"),
+			expectFailure:  true,
+			expectFindings: []string{"GHSA-35jh-r3h4-6jhm"},
+		},
+		{
+			name: "denied CVE cannot be allowed",
+			params: map[string]any{
+				"denylistCVEs":  []string{"CVE-2021-44228"},
+				"allowlistCVEs": []string{"CVE-2021-44228"},
+			},
+			artifactType:   artifactTypeTrivy,
+			report:         readTestData(t, "trivy.json"),
+			expectFailure:  true,
+			expectFindings: []string{"CVE-2021-44228"},
+		},
+		{
+			name:         "recent report",
+			params:       map[string]any{"maximumAge": "24h"},
+			artifactType: artifactTypeTrivy,
+			report:       readTestData(t, "trivy.json"),
+			created:      recent,
+		},
+		{
+			name:          "report too old",
+			params:        map[string]any{"maximumAge": "24h"},
+			artifactType:  artifactTypeTrivy,
+			report:        readTestData(t, "trivy.json"),
+			created:       old,
+			expectFailure: true,
+		},
+		{
+			name:          "report without created annotation",
+			params:        map[string]any{"maximumAge": "24h"},
+			artifactType:  artifactTypeTrivy,
+			report:        readTestData(t, "trivy.json"),
+			expectFailure: true,
+		},
+		{
+			name:          "invalid created annotation",
+			artifactType:  artifactTypeTrivy,
+			report:        readTestData(t, "trivy.json"),
+			created:       "yesterday",
+			expectFailure: true,
+		},
+		{
+			name:          "unsupported report",
+			artifactType:  artifactTypeTrivy,
+			report:        []byte(`{}`),
+			expectFailure: true,
+		},
+		{
+			name:          "no layers",
+			artifactType:  artifactTypeTrivy,
+			expectFailure: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := newMockStore()
+			desc := store.addReferrer(t, test.artifactType, test.report, test.created, true)
+			v := newTestVerifier(t, test.params)
+			result, err := v.Verify(context.Background(), &ratify.VerifyOptions{
+				Store:              store,
+				Repository:         testRepo,
+				SubjectDescriptor:  testSubject,
+				ArtifactDescriptor: desc,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (result.Err != nil) != test.expectFailure {
+				t.Fatalf("expected failure: %v, got: %v", test.expectFailure, result.Err)
+			}
+			detail, _ := result.Detail.(map[string]any)
+			findings, _ := detail["Findings"].([]finding)
+			if len(findings) != len(test.expectFindings) {
+				t.Fatalf("expected findings %v, got %+v", test.expectFindings, findings)
+			}
+			for i, f := range findings {
+				if f.ID != test.expectFindings[i] || f.Reason == "" {
+					t.Errorf("expected finding %s, got %+v", test.expectFindings[i], f)
+				}
+			}
+		})
+	}
+}
+
+func TestVerifier_NewestReportOnly(t *testing.T) {
+	now := time.Now().UTC()
+	trivy := readTestData(t, "trivy.json")
+	store := newMockStore()
+	oldest := store.addReferrer(t, artifactTypeTrivy, trivy, "", true)
+	older := store.addReferrer(t, artifactTypeSARIF, readTestData(t, "sarif.json"), now.Add(-2*time.Hour).Format(time.RFC3339), true)
+	newest := store.addReferrer(t, artifactTypeTrivy, trivy, now.Add(-time.Hour).Format(time.RFC3339), false)
+	store.addReferrer(t, "application/vnd.example.other+json", trivy, now.Format(time.RFC3339), true)
+
+	tests := []struct {
+		name             string
+		store            *mockStore
+		desc             ocispec.Descriptor
+		expectErr        bool
+		expectFailure    bool
+		expectSuperseded bool
+	}{
+		{
+			name:             "report without created time",
+			store:            store,
+			desc:             oldest,
+			expectSuperseded: true,
+		},
+		{
+			name:             "older report",
+			store:            store,
+			desc:             older,
+			expectSuperseded: true,
+		},
+		{
+			name:          "newest report",
+			store:         store,
+			desc:          newest,
+			expectFailure: true,
+		},
+		{
+			name:      "failed to list referrers",
+			store:     &mockStore{manifests: store.manifests, referrersErr: errors.New("not found")},
+			desc:      newest,
+			expectErr: true,
+		},
+	}
+
+	v := newTestVerifier(t, map[string]any{
+		"newestReportOnly":     true,
+		"disallowedSeverities": []string{"critical"},
+	})
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			result, err := v.Verify(context.Background(), &ratify.VerifyOptions{
+				Store:              test.store,
+				Repository:         testRepo,
+				SubjectDescriptor:  testSubject,
+				ArtifactDescriptor: test.desc,
+			})
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if (result.Err != nil) != test.expectFailure {
+				t.Fatalf("expected failure: %v, got: %v", test.expectFailure, result.Err)
+			}
+			detail := result.Detail.(map[string]any)
+			if superseded, _ := detail["Superseded"].(bool); superseded != test.expectSuperseded {
+				t.Fatalf("expected superseded: %v, got: %v", test.expectSuperseded, superseded)
+			}
+			if test.expectSuperseded && detail["NewestReport"] != newest.Digest.String() {
+				t.Errorf("expected newest report %s, got %v", newest.Digest, detail["NewestReport"])
+			}
+		})
+	}
+}
+
+func TestVerifier_ManifestNotFound(t *testing.T) {
+	v := newTestVerifier(t, nil)
+	_, err := v.Verify(context.Background(), &ratify.VerifyOptions{
+		Store:              newMockStore(),
+		Repository:         testRepo,
+		SubjectDescriptor:  testSubject,
+		ArtifactDescriptor: ocispec.Descriptor{MediaType: ocispec.MediaTypeImageManifest, ArtifactType: artifactTypeTrivy},
+	})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+}
@@ -0,0 +1,423 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vulnerabilityreport
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/artifact"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	artifactTypeOpenVEX = "application/openvex+json"
+	artifactTypeCSAF    = "application/csaf+json"
+
+	vexStatusNotAffected        = "not_affected"
+	vexStatusAffected           = "affected"
+	vexStatusFixed              = "fixed"
+	vexStatusUnderInvestigation = "under_investigation"
+
+	csafCategoryVEX = "csaf_vex"
+)
+
+// defaultVEXArtifactTypes are the artifact types of VEX documents discovered
+// by default.
+var defaultVEXArtifactTypes = []string{artifactTypeOpenVEX, artifactTypeCSAF}
+
+// vexOptions configures the VEX documents suppressing vulnerabilities. VEX
+// documents are discovered as referrers of the subject of the report and
+// apply to the subject.
+type vexOptions struct {
+	// ArtifactTypes are the artifact types of the VEX documents. Optional.
+	// Defaults to the OpenVEX and CSAF artifact types.
+	ArtifactTypes []string `json:"artifactTypes,omitempty"`
+
+	// SignatureVerifiers verify the signatures of the VEX documents. VEX
+	// documents without a signature verified by any of the verifiers are
+	// ignored. Required.
+	SignatureVerifiers []*factory.NewVerifierOptions `json:"signatureVerifiers"`
+}
+
+// vexStatement is a statement of a VEX document on a vulnerability of the
+// subject.
+type vexStatement struct {
+	// Artifact is the digest of the VEX artifact.
+	Artifact string `json:"artifact"`
+
+	// Document is the identifier of the VEX document. Optional.
+	Document string `json:"document,omitempty"`
+
+	// Vulnerability is the identifier of the vulnerability of the statement.
+	Vulnerability string `json:"vulnerability"`
+
+	// Status is the status of the vulnerability, e.g. "not_affected".
+	Status string `json:"status"`
+
+	// Justification justifies the status. Optional.
+	Justification string `json:"justification,omitempty"`
+
+	// ids are the identifier and aliases of the vulnerability.
+	ids []string
+
+	// timestamp is the time of the statement. The latest statement on a
+	// vulnerability wins.
+	timestamp time.Time
+}
+
+// suppresses returns true if the status of the statement suppresses findings.
+func (s *vexStatement) suppresses() bool {
+	return s.Status == vexStatusNotAffected || s.Status == vexStatusFixed
+}
+
+// ignoredVEX is a VEX document not applied.
+type ignoredVEX struct {
+	// Artifact is the digest of the VEX artifact.
+	Artifact string `json:"artifact"`
+
+	// Reason describes why the VEX document is ignored.
+	Reason string `json:"reason"`
+}
+
+// suppression is a finding suppressed by a VEX statement.
+type suppression struct {
+	// Finding is the suppressed finding.
+	Finding finding `json:"finding"`
+
+	// Statement is the VEX statement suppressing the finding.
+	Statement *vexStatement `json:"statement"`
+}
+
+// vexResolver discovers the VEX documents of subjects.
+type vexResolver struct {
+	artifactTypes      []string
+	signatureVerifiers []ratify.Verifier
+}
+
+// newVEXResolver creates the signature verifiers of the options, or returns
+// nil if VEX is not configured.
+func newVEXResolver(opts *vexOptions) (*vexResolver, error) {
+	if opts == nil {
+		return nil, nil
+	}
+	if len(opts.SignatureVerifiers) == 0 {
+		return nil, errors.New("VEX requires signatureVerifiers")
+	}
+	r := &vexResolver{
+		artifactTypes: opts.ArtifactTypes,
+	}
+	if len(r.artifactTypes) == 0 {
+		r.artifactTypes = defaultVEXArtifactTypes
+	}
+	for _, verifierOpts := range opts.SignatureVerifiers {
+		v, err := factory.NewVerifier(verifierOpts)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create VEX signature verifier: %w", err)
+		}
+		r.signatureVerifiers = append(r.signatureVerifiers, v)
+	}
+	return r, nil
+}
+
+// vexIndex indexes the latest VEX statement of each vulnerability.
+type vexIndex map[string]*vexStatement
+
+// add adds the statement if it is the latest on any of its identifiers.
+func (idx vexIndex) add(statement *vexStatement) {
+	for _, id := range statement.ids {
+		key := strings.ToLower(id)
+		if current, ok := idx[key]; !ok || !statement.timestamp.Before(current.timestamp) {
+			idx[key] = statement
+		}
+	}
+}
+
+// suppression returns the latest statement on any identifier of the
+// vulnerability if it suppresses findings, or nil otherwise.
+func (idx vexIndex) suppression(vuln vulnerability) *vexStatement {
+	var latest *vexStatement
+	for _, id := range vuln.ids() {
+		if statement, ok := idx[strings.ToLower(id)]; ok && (latest == nil || statement.timestamp.After(latest.timestamp)) {
+			latest = statement
+		}
+	}
+	if latest == nil || !latest.suppresses() {
+		return nil
+	}
+	return latest
+}
+
+// resolve returns the statements of the VEX documents of the subject with
+// verified signatures, and the ignored VEX documents.
+func (r *vexResolver) resolve(ctx context.Context, opts *ratify.VerifyOptions) (vexIndex, []ignoredVEX, error) {
+	var documents []ocispec.Descriptor
+	subject := opts.Repository + "@" + opts.SubjectDescriptor.Digest.String()
+	err := opts.Store.ListReferrers(ctx, subject, r.artifactTypes, func(referrers []ocispec.Descriptor) error {
+		for _, referrer := range referrers {
+			if slices.Contains(r.artifactTypes, referrer.ArtifactType) {
+				documents = append(documents, referrer)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to list VEX documents of subject %s: %w", subject, err)
+	}
+
+	index := vexIndex{}
+	var ignored []ignoredVEX
+	for _, desc := range documents {
+		verified, err := r.verifySignature(ctx, opts, desc)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !verified {
+			ignored = append(ignored, ignoredVEX{
+				Artifact: desc.Digest.String(),
+				Reason:   "no verified signature",
+			})
+			continue
+		}
+		blobs, err := artifact.FetchBlobs(ctx, opts.Store, opts.Repository, desc)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, blob := range blobs {
+			statements, err := parseVEX(blob.Content, desc.Digest)
+			if err != nil {
+				ignored = append(ignored, ignoredVEX{
+					Artifact: desc.Digest.String(),
+					Reason:   err.Error(),
+				})
+				continue
+			}
+			for _, statement := range statements {
+				index.add(statement)
+			}
+		}
+	}
+	return index, ignored, nil
+}
+
+// verifySignature returns true if any signature of the VEX document is
+// verified by any of the signature verifiers.
+func (r *vexResolver) verifySignature(ctx context.Context, opts *ratify.VerifyOptions, vex ocispec.Descriptor) (bool, error) {
+	verified := false
+	ref := opts.Repository + "@" + vex.Digest.String()
+	err := opts.Store.ListReferrers(ctx, ref, nil, func(referrers []ocispec.Descriptor) error {
+		for _, referrer := range referrers {
+			for _, v := range r.signatureVerifiers {
+				if verified || !v.Verifiable(referrer) {
+					continue
+				}
+				result, err := v.Verify(ctx, &ratify.VerifyOptions{
+					Store:              opts.Store,
+					Repository:         opts.Repository,
+					SubjectDescriptor:  vex,
+					ArtifactDescriptor: referrer,
+				})
+				if err != nil {
+					return fmt.Errorf("failed to verify signature %s of VEX document %s: %w", referrer.Digest, vex.Digest, err)
+				}
+				verified = result.Err == nil
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return false, fmt.Errorf("failed to list signatures of VEX document %s: %w", vex.Digest, err)
+	}
+	return verified, nil
+}
+
+// parseVEX detects the format of the VEX document and parses its statements.
+func parseVEX(content []byte, artifactDigest digest.Digest) ([]*vexStatement, error) {
+	var probe struct {
+		Context  string `json:"@context"`
+		Document *struct {
+			Category string `json:"category"`
+		} `json:"document"`
+	}
+	if err := json.Unmarshal(content, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse VEX document: %w", err)
+	}
+	switch {
+	case strings.Contains(probe.Context, "openvex"):
+		return parseOpenVEX(content, artifactDigest)
+	case probe.Document != nil && probe.Document.Category == csafCategoryVEX:
+		return parseCSAF(content, artifactDigest)
+	default:
+		return nil, errors.New("unsupported VEX format, expected OpenVEX or CSAF VEX")
+	}
+}
+
+// openVEXDocument is the subset of an OpenVEX document used by the verifier.
+type openVEXDocument struct {
+	ID         string `json:"@id"`
+	Timestamp  string `json:"timestamp"`
+	Statements []struct {
+		Vulnerability openVEXVulnerability `json:"vulnerability"`
+		Timestamp     string               `json:"timestamp"`
+		Status        string               `json:"status"`
+		Justification string               `json:"justification"`
+	} `json:"statements"`
+}
+
+// openVEXVulnerability is the vulnerability of an OpenVEX statement. It is an
+// object since OpenVEX v0.2.0 and a string before.
+type openVEXVulnerability struct {
+	Name    string   `json:"name"`
+	ID      string   `json:"@id"`
+	Aliases []string `json:"aliases"`
+}
+
+// UnmarshalJSON accepts a vulnerability identifier or an object.
+func (v *openVEXVulnerability) UnmarshalJSON(data []byte) error {
+	var name string
+	if err := json.Unmarshal(data, &name); err == nil {
+		*v = openVEXVulnerability{Name: name}
+		return nil
+	}
+	type vulnerability openVEXVulnerability
+	return json.Unmarshal(data, (*vulnerability)(v))
+}
+
+// parseOpenVEX parses the statements of an OpenVEX document. Statements
+// without timestamp have the timestamp of the document.
+func parseOpenVEX(content []byte, artifactDigest digest.Digest) ([]*vexStatement, error) {
+	var doc openVEXDocument
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenVEX document: %w", err)
+	}
+	docTimestamp := parseVEXTime(doc.Timestamp)
+	var statements []*vexStatement
+	for _, s := range doc.Statements {
+		name := s.Vulnerability.Name
+		if name == "" {
+			name = s.Vulnerability.ID
+		}
+		if name == "" {
+			return nil, errors.New("vulnerability not found for OpenVEX statement")
+		}
+		statement := &vexStatement{
+			Artifact:      artifactDigest.String(),
+			Document:      doc.ID,
+			Vulnerability: name,
+			Status:        s.Status,
+			Justification: s.Justification,
+			ids:           append([]string{name}, s.Vulnerability.Aliases...),
+			timestamp:     docTimestamp,
+		}
+		if s.Timestamp != "" {
+			statement.timestamp = parseVEXTime(s.Timestamp)
+		}
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
+
+// csafDocument is the subset of a CSAF VEX document used by the verifier.
+type csafDocument struct {
+	Document struct {
+		Tracking struct {
+			ID                 string `json:"id"`
+			CurrentReleaseDate string `json:"current_release_date"`
+		} `json:"tracking"`
+	} `json:"document"`
+	Vulnerabilities []struct {
+		CVE string `json:"cve"`
+		IDs []struct {
+			Text string `json:"text"`
+		} `json:"ids"`
+		ProductStatus struct {
+			KnownAffected      []string `json:"known_affected"`
+			KnownNotAffected   []string `json:"known_not_affected"`
+			Fixed              []string `json:"fixed"`
+			UnderInvestigation []string `json:"under_investigation"`
+		} `json:"product_status"`
+		Flags []struct {
+			Label string `json:"label"`
+		} `json:"flags"`
+	} `json:"vulnerabilities"`
+}
+
+// parseCSAF parses the vulnerabilities of a CSAF VEX document. A
+// vulnerability known to affect any product is affected regardless of the
+// status of other products.
+func parseCSAF(content []byte, artifactDigest digest.Digest) ([]*vexStatement, error) {
+	var doc csafDocument
+	if err := json.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse CSAF document: %w", err)
+	}
+	timestamp := parseVEXTime(doc.Document.Tracking.CurrentReleaseDate)
+	var statements []*vexStatement
+	for _, v := range doc.Vulnerabilities {
+		statement := &vexStatement{
+			Artifact:  artifactDigest.String(),
+			Document:  doc.Document.Tracking.ID,
+			timestamp: timestamp,
+		}
+		if v.CVE != "" {
+			statement.ids = append(statement.ids, v.CVE)
+		}
+		for _, id := range v.IDs {
+			if id.Text != "" {
+				statement.ids = append(statement.ids, id.Text)
+			}
+		}
+		if len(statement.ids) == 0 {
+			return nil, errors.New("vulnerability identifier not found for CSAF vulnerability")
+		}
+		statement.Vulnerability = statement.ids[0]
+
+		status := v.ProductStatus
+		switch {
+		case len(status.KnownAffected) > 0:
+			statement.Status = vexStatusAffected
+		case len(status.UnderInvestigation) > 0:
+			statement.Status = vexStatusUnderInvestigation
+		case len(status.KnownNotAffected) > 0:
+			statement.Status = vexStatusNotAffected
+		case len(status.Fixed) > 0:
+			statement.Status = vexStatusFixed
+		default:
+			continue
+		}
+		if statement.Status == vexStatusNotAffected && len(v.Flags) > 0 {
+			statement.Justification = v.Flags[0].Label
+		}
+		statements = append(statements, statement)
+	}
+	return statements, nil
+}
+
+// parseVEXTime parses an RFC 3339 time, or returns the zero time if invalid.
+func parseVEXTime(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
@@ -0,0 +1,411 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package vulnerabilityreport
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	mockSignatureVerifierType = "mock-vex-signature"
+	artifactTypeMockSignature = "application/vnd.test.signature"
+	mockSignatureValid        = "valid"
+)
+
+// mockSignatureVerifier accepts signatures annotated as valid.
+type mockSignatureVerifier struct{}
+
+func (v *mockSignatureVerifier) Name() string {
+	return mockSignatureVerifierType
+}
+
+func (v *mockSignatureVerifier) Type() string {
+	return mockSignatureVerifierType
+}
+
+func (v *mockSignatureVerifier) Verifiable(artifact ocispec.Descriptor) bool {
+	return artifact.ArtifactType == artifactTypeMockSignature
+}
+
+func (v *mockSignatureVerifier) Verify(_ context.Context, opts *ratify.VerifyOptions) (*ratify.VerificationResult, error) {
+	result := &ratify.VerificationResult{
+		Verifier: v,
+	}
+	if opts.ArtifactDescriptor.Annotations[mockSignatureValid] != "true" {
+		result.Err = errors.New("invalid signature")
+	}
+	return result, nil
+}
+
+func init() {
+	factory.RegisterVerifierFactory(mockSignatureVerifierType, func(_ *factory.NewVerifierOptions) (ratify.Verifier, error) {
+		return &mockSignatureVerifier{}, nil
+	})
+}
+
+// addSignature adds a signature of the artifact.
+func (s *mockStore) addSignature(desc ocispec.Descriptor, valid bool) {
+	signature := ocispec.Descriptor{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: artifactTypeMockSignature,
+		Digest:       digest.FromString(desc.Digest.String() + "signature"),
+		Annotations: map[string]string{
+			mockSignatureValid: strconv.FormatBool(valid),
+		},
+	}
+	s.artifactReferrers[desc.Digest] = append(s.artifactReferrers[desc.Digest], signature)
+}
+
+var testVEXOptions = map[string]any{
+	"signatureVerifiers": []map[string]any{
+		{"name": "vex-signature", "type": mockSignatureVerifierType},
+	},
+}
+
+func TestVerifier_VEX(t *testing.T) {
+	trivy := readTestData(t, "trivy.json")
+	openVEX := readTestData(t, "openvex.json")
+	csaf := readTestData(t, "csaf.json")
+	affected := []byte(`{"@context":"https://openvex.dev/ns/v0.2.0","@id":"later","timestamp":"2024-02-01T00:00:00Z","statements":[{"vulnerability":{"name":"CVE-2021-44228"},"status":"affected"}]}`)
+
+	tests := []struct {
+		name             string
+		params           map[string]any
+		report           []byte
+		reportType       string
+		vex              [][]byte
+		vexType          string
+		signed           bool
+		validSignature   bool
+		expectFailure    bool
+		expectSuppressed []string
+		expectStatement  string
+		expectIgnored    int
+	}{
+		{
+			name:             "signed OpenVEX",
+			report:           trivy,
+			reportType:       artifactTypeTrivy,
+			vex:              [][]byte{openVEX},
+			vexType:          artifactTypeOpenVEX,
+			signed:           true,
+			validSignature:   true,
+			expectSuppressed: []string{"CVE-2021-44228"},
+			expectStatement:  "https://example.com/vex/test-image-2024-01",
+		},
+		{
+			name:          "unsigned OpenVEX",
+			report:        trivy,
+			reportType:    artifactTypeTrivy,
+			vex:           [][]byte{openVEX},
+			vexType:       artifactTypeOpenVEX,
+			expectFailure: true,
+			expectIgnored: 1,
+		},
+		{
+			name:          "invalid signature",
+			report:        trivy,
+			reportType:    artifactTypeTrivy,
+			vex:           [][]byte{openVEX},
+			vexType:       artifactTypeOpenVEX,
+			signed:        true,
+			expectFailure: true,
+			expectIgnored: 1,
+		},
+		{
+			name:           "later affected statement",
+			report:         trivy,
+			reportType:     artifactTypeTrivy,
+			vex:            [][]byte{openVEX, affected},
+			vexType:        artifactTypeOpenVEX,
+			signed:         true,
+			validSignature: true,
+			expectFailure:  true,
+		},
+		{
+			name:           "denied vulnerability",
+			params:         map[string]any{"denylistCVEs": []string{"CVE-2021-44228"}},
+			report:         trivy,
+			reportType:     artifactTypeTrivy,
+			vex:            [][]byte{openVEX},
+			vexType:        artifactTypeOpenVEX,
+			signed:         true,
+			validSignature: true,
+			expectFailure:  true,
+		},
+		{
+			name:             "CSAF alias",
+			report:           readTestData(t, "grype.json"),
+			reportType:       artifactTypeGrype,
+			vex:              [][]byte{csaf},
+			vexType:          artifactTypeCSAF,
+			signed:           true,
+			validSignature:   true,
+			expectSuppressed: []string{"GHSA-jfh8-c2jp-5v3q"},
+			expectStatement:  "EXAMPLE-VEX-2024-0001",
+		},
+		{
+			name:           "invalid VEX document",
+			report:         trivy,
+			reportType:     artifactTypeTrivy,
+			vex:            [][]byte{[]byte(`{"name":"test"}`)},
+			vexType:        artifactTypeOpenVEX,
+			signed:         true,
+			validSignature: true,
+			expectFailure:  true,
+			expectIgnored:  1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			store := newMockStore()
+			report := store.addReferrer(t, test.reportType, test.report, "", true)
+			for _, vex := range test.vex {
+				desc := store.addReferrer(t, test.vexType, vex, "", true)
+				if test.signed {
+					store.addSignature(desc, test.validSignature)
+				}
+			}
+			params := map[string]any{
+				"disallowedSeverities": []string{"critical"},
+				"vex":                  testVEXOptions,
+			}
+			for k, v := range test.params {
+				params[k] = v
+			}
+			v := newTestVerifier(t, params)
+
+			result, err := v.Verify(context.Background(), &ratify.VerifyOptions{
+				Store:              store,
+				Repository:         testRepo,
+				SubjectDescriptor:  testSubject,
+				ArtifactDescriptor: report,
+			})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (result.Err != nil) != test.expectFailure {
+				t.Fatalf("expected failure: %v, got: %v", test.expectFailure, result.Err)
+			}
+			detail := result.Detail.(map[string]any)
+			suppressions, _ := detail["Suppressed"].([]suppression)
+			var suppressed []string
+			for _, s := range suppressions {
+				suppressed = append(suppressed, s.Finding.ID)
+				if s.Statement.Document != test.expectStatement || s.Statement.Artifact == "" {
+					t.Errorf("expected statement of %s, got %+v", test.expectStatement, s.Statement)
+				}
+			}
+			if !slices.Equal(suppressed, test.expectSuppressed) {
+				t.Errorf("expected suppressed %v, got %v", test.expectSuppressed, suppressed)
+			}
+			ignored, _ := detail["IgnoredVEX"].([]ignoredVEX)
+			if len(ignored) != test.expectIgnored {
+				t.Errorf("expected %d ignored VEX documents, got %+v", test.expectIgnored, ignored)
+			}
+		})
+	}
+}
+
+func TestVerifier_VEXListReferrersFailure(t *testing.T) {
+	store := newMockStore()
+	report := store.addReferrer(t, artifactTypeTrivy, readTestData(t, "trivy.json"), "", true)
+	store.referrersErr = errors.New("not found")
+	v := newTestVerifier(t, map[string]any{"vex": testVEXOptions})
+	if _, err := v.Verify(context.Background(), &ratify.VerifyOptions{
+		Store:              store,
+		Repository:         testRepo,
+		SubjectDescriptor:  testSubject,
+		ArtifactDescriptor: report,
+	}); err == nil {
+		t.Fatalf("expected error")
+	}
+}
+
+func TestParseVEX(t *testing.T) {
+	tests := []struct {
+		name             string
+		content          []byte
+		expectErr        bool
+		expectStatements []vexStatement
+	}{
+		{
+			name:    "OpenVEX",
+			content: readTestData(t, "openvex.json"),
+			expectStatements: []vexStatement{
+				{Vulnerability: "CVE-2021-44228", Status: vexStatusNotAffected, Justification: "vulnerable_code_not_in_execute_path", ids: []string{"CVE-2021-44228", "GHSA-jfh8-c2jp-5v3q"}},
+				{Vulnerability: "CVE-2021-45105", Status: vexStatusUnderInvestigation, ids: []string{"CVE-2021-45105"}},
+			},
+		},
+		{
+			name:    "OpenVEX v0.0.1",
+			content: []byte(`{"@context":"https://openvex.dev/ns","statements":[{"vulnerability":"CVE-1","status":"fixed","timestamp":"2024-01-02T03:04:05Z"}]}`),
+			expectStatements: []vexStatement{
+				{Vulnerability: "CVE-1", Status: vexStatusFixed, ids: []string{"CVE-1"}},
+			},
+		},
+		{
+			name:    "OpenVEX vulnerability identifier",
+			content: []byte(`{"@context":"https://openvex.dev/ns/v0.2.0","statements":[{"vulnerability":{"@id":"https://nvd.nist.gov/vuln/detail/CVE-1"},"status":"fixed"}]}`),
+			expectStatements: []vexStatement{
+				{Vulnerability: "https://nvd.nist.gov/vuln/detail/CVE-1", Status: vexStatusFixed, ids: []string{"https://nvd.nist.gov/vuln/detail/CVE-1"}},
+			},
+		},
+		{
+			name:      "OpenVEX without vulnerability",
+			content:   []byte(`{"@context":"https://openvex.dev/ns/v0.2.0","statements":[{"status":"fixed"}]}`),
+			expectErr: true,
+		},
+		{
+			name:      "invalid OpenVEX",
+			content:   []byte(`{"@context":"https://openvex.dev/ns/v0.2.0","statements":{}}`),
+			expectErr: true,
+		},
+		{
+			name:    "CSAF",
+			content: readTestData(t, "csaf.json"),
+			expectStatements: []vexStatement{
+				{Vulnerability: "CVE-2021-44228", Status: vexStatusNotAffected, Justification: "vulnerable_code_not_present", ids: []string{"CVE-2021-44228"}},
+				{Vulnerability: "GHSA-35jh-r3h4-6jhm", Status: vexStatusFixed, ids: []string{"GHSA-35jh-r3h4-6jhm"}},
+				{Vulnerability: "CVE-2021-45105", Status: vexStatusAffected, ids: []string{"CVE-2021-45105"}},
+				{Vulnerability: "CVE-2021-45046", Status: vexStatusUnderInvestigation, ids: []string{"CVE-2021-45046"}},
+			},
+		},
+		{
+			name:      "CSAF without vulnerability identifier",
+			content:   []byte(`{"document":{"category":"csaf_vex"},"vulnerabilities":[{"product_status":{"fixed":["a"]}}]}`),
+			expectErr: true,
+		},
+		{
+			name:      "invalid CSAF",
+			content:   []byte(`{"document":{"category":"csaf_vex"},"vulnerabilities":{}}`),
+			expectErr: true,
+		},
+		{
+			name:      "CSAF security advisory",
+			content:   []byte(`{"document":{"category":"csaf_security_advisory"}}`),
+			expectErr: true,
+		},
+		{
+			name:      "invalid JSON",
+			content:   []byte(`{`),
+			expectErr: true,
+		},
+	}
+
+	artifactDigest := digest.FromString("vex")
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			statements, err := parseVEX(test.content, artifactDigest)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if len(statements) != len(test.expectStatements) {
+				t.Fatalf("expected %d statements, got %d", len(test.expectStatements), len(statements))
+			}
+			for i, s := range statements {
+				e := test.expectStatements[i]
+				if s.Vulnerability != e.Vulnerability || s.Status != e.Status || s.Justification != e.Justification || !slices.Equal(s.ids, e.ids) || s.Artifact != artifactDigest.String() {
+					t.Errorf("expected statement %+v, got %+v", e, *s)
+				}
+			}
+		})
+	}
+}
+
+func TestVEXIndex_Suppression(t *testing.T) {
+	earlier := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+	index := vexIndex{}
+	index.add(&vexStatement{Status: vexStatusAffected, ids: []string{"CVE-1"}, timestamp: earlier})
+	index.add(&vexStatement{Status: vexStatusNotAffected, ids: []string{"CVE-1", "GHSA-1"}, timestamp: later})
+	index.add(&vexStatement{Status: vexStatusNotAffected, ids: []string{"CVE-2"}, timestamp: later})
+	index.add(&vexStatement{Status: vexStatusAffected, ids: []string{"CVE-2"}, timestamp: earlier})
+	index.add(&vexStatement{Status: vexStatusFixed, ids: []string{"CVE-3"}})
+	index.add(&vexStatement{Status: vexStatusUnderInvestigation, ids: []string{"GHSA-3"}, timestamp: later})
+
+	tests := []struct {
+		name   string
+		vuln   vulnerability
+		expect bool
+	}{
+		{name: "later not affected", vuln: vulnerability{id: "cve-1"}, expect: true},
+		{name: "earlier affected", vuln: vulnerability{id: "CVE-2"}, expect: true},
+		{name: "alias", vuln: vulnerability{id: "GHSA-1"}, expect: true},
+		{name: "fixed", vuln: vulnerability{id: "CVE-3"}, expect: true},
+		{name: "later statement on alias", vuln: vulnerability{id: "CVE-3", aliases: []string{"GHSA-3"}}},
+		{name: "no statement", vuln: vulnerability{id: "CVE-4"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := index.suppression(test.vuln) != nil; got != test.expect {
+				t.Errorf("expected suppression: %v, got: %v", test.expect, got)
+			}
+		})
+	}
+}
+
+func TestNewVEXResolver(t *testing.T) {
+	tests := []struct {
+		name           string
+		opts           *vexOptions
+		expectErr      bool
+		expectResolver bool
+	}{
+		{
+			name: "VEX not configured",
+		},
+		{
+			name:      "missing signature verifiers",
+			opts:      &vexOptions{},
+			expectErr: true,
+		},
+		{
+			name:      "unregistered signature verifier",
+			opts:      &vexOptions{SignatureVerifiers: []*factory.NewVerifierOptions{{Name: "a", Type: "unregistered"}}},
+			expectErr: true,
+		},
+		{
+			name:           "valid options",
+			opts:           &vexOptions{SignatureVerifiers: []*factory.NewVerifierOptions{{Name: "a", Type: mockSignatureVerifierType}}},
+			expectResolver: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			resolver, err := newVEXResolver(test.opts)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if (resolver != nil) != test.expectResolver {
+				t.Errorf("expected resolver: %v, got: %v", test.expectResolver, resolver)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package wasm registers the `wasm` verifier, which runs custom verification
+// logic compiled to WebAssembly in a sandboxed pure-Go runtime with memory and
+// time limits.
+//
+// # ABI
+//
+// Modules are WebAssembly core modules, optionally built for WASI preview 1
+// (e.g. GOOS=wasip1). WASI modules have no access to files, network, clocks
+// beyond the wall clock, or the environment, and their stdout and stderr are
+// discarded. Modules exporting `_initialize` (WASI reactors) are initialized
+// on every instantiation. Each verification runs in a fresh instance.
+//
+// A module exports:
+//
+//   - memory: its linear memory.
+//   - allocate(size i32) i32: returns the offset of size bytes of linear
+//     memory the host writes the input to.
+//   - verify(offset i32, size i32) i64: verifies the artifact described by
+//     the JSON input at offset and returns the offset of the JSON output in
+//     the upper and its size in the lower 32 bits.
+//
+// The input is a JSON object:
+//
+//	{
+//	  "repository": "registry.example/repo",
+//	  "subject": <OCI descriptor of the subject>,
+//	  "artifact": <OCI descriptor of the verified artifact>,
+//	  "config": <the configured module config>
+//	}
+//
+// The output is a JSON object:
+//
+//	{
+//	  "isSuccess": true,
+//	  "message": "human readable description",
+//	  "detail": <any JSON value reported as result detail>
+//	}
+//
+// The host module "ratify" provides:
+//
+//   - fetch_manifest(offset i32, size i32) i32 and
+//     fetch_blob(offset i32, size i32) i32: fetch the manifest or blob of the
+//     JSON OCI descriptor at offset from the verified repository through the
+//     configured store. They return the size of the content, or -1 on
+//     failure, and keep the content or the error message in the fetch buffer.
+//   - buffer_size() i32: returns the size of the fetch buffer.
+//   - read_buffer(offset i32, size i32) i32: copies up to size bytes of the
+//     fetch buffer to offset and returns the number of bytes copied.
+//   - log(offset i32, size i32): logs the message at offset at debug level.
+//
+// See testdata/example for a module written in Go.
+package wasm
@@ -0,0 +1,130 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/notaryproject/ratify-go"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+)
+
+const hostModuleName = "ratify"
+
+// invocation is the state of a single verification shared with the host
+// functions through the context of the module call.
+type invocation struct {
+	name   string
+	store  ratify.Store
+	repo   string
+	buffer []byte
+}
+
+type invocationKey struct{}
+
+func withInvocation(ctx context.Context, inv *invocation) context.Context {
+	return context.WithValue(ctx, invocationKey{}, inv)
+}
+
+func invocationFrom(ctx context.Context) *invocation {
+	inv, _ := ctx.Value(invocationKey{}).(*invocation)
+	return inv
+}
+
+// instantiateHostModule instantiates the "ratify" host module in the runtime.
+func instantiateHostModule(ctx context.Context, r wazero.Runtime) error {
+	_, err := r.NewHostModuleBuilder(hostModuleName).
+		NewFunctionBuilder().WithFunc(fetchManifest).Export("fetch_manifest").
+		NewFunctionBuilder().WithFunc(fetchBlob).Export("fetch_blob").
+		NewFunctionBuilder().WithFunc(bufferSize).Export("buffer_size").
+		NewFunctionBuilder().WithFunc(readBuffer).Export("read_buffer").
+		NewFunctionBuilder().WithFunc(log).Export("log").
+		Instantiate(ctx)
+	return err
+}
+
+func fetchManifest(ctx context.Context, m api.Module, offset, size uint32) int32 {
+	return fetch(ctx, m, offset, size, func(inv *invocation, desc ocispec.Descriptor) ([]byte, error) {
+		return inv.store.FetchManifest(ctx, inv.repo, desc)
+	})
+}
+
+func fetchBlob(ctx context.Context, m api.Module, offset, size uint32) int32 {
+	return fetch(ctx, m, offset, size, func(inv *invocation, desc ocispec.Descriptor) ([]byte, error) {
+		return inv.store.FetchBlob(ctx, inv.repo, desc)
+	})
+}
+
+// fetch reads the descriptor at offset and keeps the fetched content or the
+// error message in the fetch buffer.
+func fetch(ctx context.Context, m api.Module, offset, size uint32, fetchFn func(*invocation, ocispec.Descriptor) ([]byte, error)) int32 {
+	inv := invocationFrom(ctx)
+	if inv == nil {
+		return -1
+	}
+	content, err := func() ([]byte, error) {
+		raw, ok := m.Memory().Read(offset, size)
+		if !ok {
+			return nil, fmt.Errorf("descriptor out of memory range")
+		}
+		var desc ocispec.Descriptor
+		if err := json.Unmarshal(raw, &desc); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal descriptor: %w", err)
+		}
+		return fetchFn(inv, desc)
+	}()
+	if err != nil {
+		inv.buffer = []byte(err.Error())
+		return -1
+	}
+	inv.buffer = content
+	return int32(len(content))
+}
+
+func bufferSize(ctx context.Context) int32 {
+	inv := invocationFrom(ctx)
+	if inv == nil {
+		return 0
+	}
+	return int32(len(inv.buffer))
+}
+
+func readBuffer(ctx context.Context, m api.Module, offset, size uint32) int32 {
+	inv := invocationFrom(ctx)
+	if inv == nil {
+		return 0
+	}
+	n := min(int(size), len(inv.buffer))
+	if !m.Memory().Write(offset, inv.buffer[:n]) {
+		return 0
+	}
+	return int32(n)
+}
+
+func log(ctx context.Context, m api.Module, offset, size uint32) {
+	inv := invocationFrom(ctx)
+	if inv == nil {
+		return
+	}
+	if msg, ok := m.Memory().Read(offset, size); ok {
+		logrus.Debugf("[wasm] %s: %s", inv.name, msg)
+	}
+}
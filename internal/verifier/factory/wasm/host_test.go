@@ -0,0 +1,236 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/tetratelabs/wazero/api"
+)
+
+// mockMemory is a linear memory backed by a byte slice.
+type mockMemory struct {
+	api.Memory
+	data []byte
+}
+
+func (m *mockMemory) Read(offset, size uint32) ([]byte, bool) {
+	if uint64(offset)+uint64(size) > uint64(len(m.data)) {
+		return nil, false
+	}
+	return m.data[offset : offset+size], true
+}
+
+func (m *mockMemory) Write(offset uint32, v []byte) bool {
+	if uint64(offset)+uint64(len(v)) > uint64(len(m.data)) {
+		return false
+	}
+	copy(m.data[offset:], v)
+	return true
+}
+
+// mockModule is a module instance with a mock memory.
+type mockModule struct {
+	api.Module
+	memory *mockMemory
+}
+
+func (m *mockModule) Memory() api.Memory {
+	return m.memory
+}
+
+// newMockModule returns a module whose memory holds the content at offset 0.
+func newMockModule(content []byte, size int) *mockModule {
+	memory := &mockMemory{data: make([]byte, size)}
+	copy(memory.data, content)
+	return &mockModule{memory: memory}
+}
+
+func TestFetch(t *testing.T) {
+	blob := []byte("blob")
+	store := newMockStore(t, blob)
+	desc, err := json.Marshal(ocispec.Descriptor{Digest: digest.FromBytes(blob), Size: int64(len(blob))})
+	if err != nil {
+		t.Fatalf("failed to marshal descriptor: %v", err)
+	}
+	manifestDesc, err := json.Marshal(store.resolved)
+	if err != nil {
+		t.Fatalf("failed to marshal descriptor: %v", err)
+	}
+	missing, err := json.Marshal(ocispec.Descriptor{Digest: digest.FromString("missing")})
+	if err != nil {
+		t.Fatalf("failed to marshal descriptor: %v", err)
+	}
+
+	tests := []struct {
+		name         string
+		inv          *invocation
+		memory       []byte
+		size         uint32
+		fetchFn      func(context.Context, api.Module, uint32, uint32) int32
+		expectResult int32
+		expectBuffer string
+	}{
+		{
+			name:         "fetch blob",
+			inv:          &invocation{store: store},
+			memory:       desc,
+			size:         uint32(len(desc)),
+			fetchFn:      fetchBlob,
+			expectResult: int32(len(blob)),
+			expectBuffer: string(blob),
+		},
+		{
+			name:         "fetch manifest",
+			inv:          &invocation{store: store},
+			memory:       manifestDesc,
+			size:         uint32(len(manifestDesc)),
+			fetchFn:      fetchManifest,
+			expectResult: int32(len(store.manifest)),
+			expectBuffer: string(store.manifest),
+		},
+		{
+			name:         "no invocation",
+			memory:       desc,
+			size:         uint32(len(desc)),
+			fetchFn:      fetchBlob,
+			expectResult: -1,
+		},
+		{
+			name:         "descriptor out of memory range",
+			inv:          &invocation{store: store},
+			memory:       desc,
+			size:         1 << 20,
+			fetchFn:      fetchBlob,
+			expectResult: -1,
+			expectBuffer: "descriptor out of memory range",
+		},
+		{
+			name:         "invalid descriptor",
+			inv:          &invocation{store: store},
+			memory:       []byte("{"),
+			size:         1,
+			fetchFn:      fetchBlob,
+			expectResult: -1,
+			expectBuffer: "failed to unmarshal descriptor: unexpected end of JSON input",
+		},
+		{
+			name:         "blob not found",
+			inv:          &invocation{store: store},
+			memory:       missing,
+			size:         uint32(len(missing)),
+			fetchFn:      fetchBlob,
+			expectResult: -1,
+			expectBuffer: "blob not found",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.inv != nil {
+				ctx = withInvocation(ctx, test.inv)
+			}
+			m := newMockModule(test.memory, 1024)
+			if got := test.fetchFn(ctx, m, 0, test.size); got != test.expectResult {
+				t.Fatalf("expected result %d, got %d", test.expectResult, got)
+			}
+			if test.inv != nil && string(test.inv.buffer) != test.expectBuffer {
+				t.Errorf("expected buffer %q, got %q", test.expectBuffer, test.inv.buffer)
+			}
+		})
+	}
+}
+
+func TestReadBuffer(t *testing.T) {
+	tests := []struct {
+		name       string
+		inv        *invocation
+		offset     uint32
+		size       uint32
+		expectSize int32
+		expectRead int32
+	}{
+		{
+			name:       "read whole buffer",
+			inv:        &invocation{buffer: []byte("content")},
+			size:       16,
+			expectSize: 7,
+			expectRead: 7,
+		},
+		{
+			name:       "read part of buffer",
+			inv:        &invocation{buffer: []byte("content")},
+			size:       3,
+			expectSize: 7,
+			expectRead: 3,
+		},
+		{
+			name:       "out of memory range",
+			inv:        &invocation{buffer: []byte("content")},
+			offset:     60,
+			size:       16,
+			expectSize: 7,
+		},
+		{
+			name: "no invocation",
+			size: 16,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctx := context.Background()
+			if test.inv != nil {
+				ctx = withInvocation(ctx, test.inv)
+			}
+			if got := bufferSize(ctx); got != test.expectSize {
+				t.Errorf("expected buffer size %d, got %d", test.expectSize, got)
+			}
+			m := newMockModule(nil, 64)
+			got := readBuffer(ctx, m, test.offset, test.size)
+			if got != test.expectRead {
+				t.Fatalf("expected %d bytes read, got %d", test.expectRead, got)
+			}
+			if got > 0 && string(m.memory.data[:got]) != string(test.inv.buffer[:got]) {
+				t.Errorf("expected memory %q, got %q", test.inv.buffer[:got], m.memory.data[:got])
+			}
+		})
+	}
+}
+
+func TestLog(t *testing.T) {
+	m := newMockModule([]byte("message"), 64)
+	// log must not panic without invocation or with out of range messages.
+	log(context.Background(), m, 0, 7)
+	ctx := withInvocation(context.Background(), &invocation{name: testName})
+	log(ctx, m, 0, 7)
+	log(ctx, m, 0, 1<<20)
+}
+
+func TestInvocationFrom(t *testing.T) {
+	if inv := invocationFrom(context.Background()); inv != nil {
+		t.Errorf("expected no invocation, got %+v", inv)
+	}
+	inv := &invocation{name: testName}
+	if got := invocationFrom(withInvocation(context.Background(), inv)); got != inv {
+		t.Errorf("expected invocation %+v, got %+v", inv, got)
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wasm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/artifact"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/api"
+	"oras.land/oras-go/v2/registry"
+)
+
+const (
+	exportAllocate   = "allocate"
+	exportVerify     = "verify"
+	exportInitialize = "_initialize"
+)
+
+// exportSignatures are the parameter and result types of the functions a
+// module must export.
+var exportSignatures = map[string][2][]api.ValueType{
+	exportAllocate: {{api.ValueTypeI32}, {api.ValueTypeI32}},
+	exportVerify:   {{api.ValueTypeI32, api.ValueTypeI32}, {api.ValueTypeI64}},
+}
+
+// moduleOptions configures the source of the WebAssembly module. Exactly one
+// of File and OCI must be set.
+type moduleOptions struct {
+	// File is the path of the module file.
+	File string `json:"file,omitempty"`
+
+	// OCI is the digest reference of an OCI artifact whose single layer is
+	// the module, e.g. "registry.example/verifiers/license@sha256:...". The
+	// artifact is fetched through the store of the first verification.
+	OCI string `json:"oci,omitempty"`
+}
+
+// module compiles the WebAssembly module once and caches the compiled module.
+// Modules from files are compiled on creation, modules from OCI artifacts on
+// first use.
+type module struct {
+	runtime wazero.Runtime
+
+	mu       sync.Mutex
+	ref      registry.Reference
+	compiled wazero.CompiledModule
+}
+
+// newModule loads the module into the runtime.
+func newModule(ctx context.Context, r wazero.Runtime, opts moduleOptions) (*module, error) {
+	m := &module{runtime: r}
+	switch {
+	case (opts.File == "") == (opts.OCI == ""):
+		return nil, errors.New("exactly one of module file and oci must be set")
+	case opts.File != "":
+		bin, err := os.ReadFile(opts.File)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read module file: %w", err)
+		}
+		if m.compiled, err = m.compile(ctx, bin); err != nil {
+			return nil, err
+		}
+	default:
+		ref, err := artifact.ParsePinnedReference(opts.OCI)
+		if err != nil {
+			return nil, err
+		}
+		m.ref = ref
+	}
+	return m, nil
+}
+
+// get returns the compiled module, fetching the module artifact through the
+// store if not compiled yet.
+func (m *module) get(ctx context.Context, store ratify.Store) (wazero.CompiledModule, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.compiled != nil {
+		return m.compiled, nil
+	}
+	bin, err := artifact.FetchPinnedBlob(ctx, store, m.ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch module: %w", err)
+	}
+	if m.compiled, err = m.compile(ctx, bin); err != nil {
+		return nil, err
+	}
+	return m.compiled, nil
+}
+
+// compile compiles the module and checks its exports.
+func (m *module) compile(ctx context.Context, bin []byte) (wazero.CompiledModule, error) {
+	compiled, err := m.runtime.CompileModule(ctx, bin)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compile module: %w", err)
+	}
+	exports := compiled.ExportedFunctions()
+	for name, signature := range exportSignatures {
+		fn, ok := exports[name]
+		if !ok {
+			_ = compiled.Close(ctx)
+			return nil, fmt.Errorf("module does not export function %s", name)
+		}
+		if !slices.Equal(fn.ParamTypes(), signature[0]) || !slices.Equal(fn.ResultTypes(), signature[1]) {
+			_ = compiled.Close(ctx)
+			return nil, fmt.Errorf("module function %s has unexpected signature", name)
+		}
+	}
+	if len(compiled.ExportedMemories()) == 0 {
+		_ = compiled.Close(ctx)
+		return nil, errors.New("module does not export memory")
+	}
+	return compiled, nil
+}
@@ -0,0 +1,205 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wasm
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/opencontainers/go-digest"
+	"github.com/tetratelabs/wazero"
+)
+
+const (
+	valueTypeI32 = 0x7f
+	valueTypeI64 = 0x7e
+)
+
+// newTestModule returns the binary of a module exporting allocate and verify
+// functions returning zero, where verify returns the value type, and
+// optionally a memory.
+func newTestModule(verifyResult byte, withMemory bool) []byte {
+	section := func(id byte, content ...byte) []byte {
+		return append([]byte{id, byte(len(content))}, content...)
+	}
+	name := func(s string) []byte {
+		return append([]byte{byte(len(s))}, s...)
+	}
+
+	bin := []byte("\x00asm\x01\x00\x00\x00")
+	// types: (i32) -> i32 and (i32, i32) -> verifyResult
+	bin = append(bin, section(0x01, 0x02,
+		0x60, 0x01, valueTypeI32, 0x01, valueTypeI32,
+		0x60, 0x02, valueTypeI32, valueTypeI32, 0x01, verifyResult)...)
+	// functions
+	bin = append(bin, section(0x03, 0x02, 0x00, 0x01)...)
+	exports := []byte{0x02}
+	if withMemory {
+		// memory of 1 page
+		bin = append(bin, section(0x05, 0x01, 0x00, 0x01)...)
+		exports = []byte{0x03}
+		exports = append(append(exports, name("memory")...), 0x02, 0x00)
+	}
+	exports = append(append(exports, name(exportAllocate)...), 0x00, 0x00)
+	exports = append(append(exports, name(exportVerify)...), 0x00, 0x01)
+	bin = append(bin, section(0x07, exports...)...)
+	// code: i32.const 0 and <verifyResult>.const 0
+	constOp := byte(0x41)
+	if verifyResult == valueTypeI64 {
+		constOp = 0x42
+	}
+	bin = append(bin, section(0x0a, 0x02,
+		0x04, 0x00, 0x41, 0x00, 0x0b,
+		0x04, 0x00, constOp, 0x00, 0x0b)...)
+	return bin
+}
+
+func TestNewModule(t *testing.T) {
+	dir := t.TempDir()
+	writeFile := func(name string, content []byte) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, content, 0600); err != nil {
+			t.Fatalf("failed to write file: %v", err)
+		}
+		return path
+	}
+	valid := writeFile("valid.wasm", newTestModule(valueTypeI64, true))
+	noMemory := writeFile("no-memory.wasm", newTestModule(valueTypeI64, false))
+	badSignature := writeFile("bad-signature.wasm", newTestModule(valueTypeI32, true))
+	noExports := writeFile("no-exports.wasm", []byte("\x00asm\x01\x00\x00\x00"))
+	invalid := writeFile("invalid.wasm", []byte("invalid"))
+
+	tests := []struct {
+		name           string
+		opts           moduleOptions
+		expectErr      bool
+		expectCompiled bool
+	}{
+		{
+			name:           "valid module file",
+			opts:           moduleOptions{File: valid},
+			expectCompiled: true,
+		},
+		{
+			name: "oci module",
+			opts: moduleOptions{OCI: testRepo + "@" + digest.FromString("module").String()},
+		},
+		{
+			name:      "no source",
+			expectErr: true,
+		},
+		{
+			name:      "both sources",
+			opts:      moduleOptions{File: valid, OCI: testRepo + "@" + digest.FromString("module").String()},
+			expectErr: true,
+		},
+		{
+			name:      "tagged oci module",
+			opts:      moduleOptions{OCI: testRepo + ":v1"},
+			expectErr: true,
+		},
+		{
+			name:      "missing file",
+			opts:      moduleOptions{File: filepath.Join(dir, "missing.wasm")},
+			expectErr: true,
+		},
+		{
+			name:      "invalid module",
+			opts:      moduleOptions{File: invalid},
+			expectErr: true,
+		},
+		{
+			name:      "missing exported functions",
+			opts:      moduleOptions{File: noExports},
+			expectErr: true,
+		},
+		{
+			name:      "unexpected signature",
+			opts:      moduleOptions{File: badSignature},
+			expectErr: true,
+		},
+		{
+			name:      "missing exported memory",
+			opts:      moduleOptions{File: noMemory},
+			expectErr: true,
+		},
+	}
+
+	ctx := context.Background()
+	r := wazero.NewRuntime(ctx)
+	defer r.Close(ctx)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := newModule(ctx, r, test.opts)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err == nil && (m.compiled != nil) != test.expectCompiled {
+				t.Errorf("expected compiled: %v, got: %v", test.expectCompiled, m.compiled != nil)
+			}
+		})
+	}
+}
+
+func TestModule_Get(t *testing.T) {
+	bin := newTestModule(valueTypeI64, true)
+	tests := []struct {
+		name      string
+		store     *mockStore
+		expectErr bool
+	}{
+		{
+			name:  "valid module",
+			store: newMockStore(t, bin),
+		},
+		{
+			name:      "invalid module",
+			store:     newMockStore(t, []byte("invalid")),
+			expectErr: true,
+		},
+		{
+			name:      "module not found",
+			store:     newMockStore(t),
+			expectErr: true,
+		},
+	}
+
+	ctx := context.Background()
+	r := wazero.NewRuntime(ctx)
+	defer r.Close(ctx)
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			m, err := newModule(ctx, r, moduleOptions{OCI: testRepo + "@" + test.store.resolved.Digest.String()})
+			if err != nil {
+				t.Fatalf("failed to create module: %v", err)
+			}
+			compiled, err := m.get(ctx, test.store)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			// the compiled module is cached.
+			again, err := m.get(ctx, nil)
+			if err != nil || again != compiled {
+				t.Errorf("expected cached module, got: %v, %v", again, err)
+			}
+		})
+	}
+}
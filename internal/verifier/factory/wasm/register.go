@@ -0,0 +1,120 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	"github.com/tetratelabs/wazero"
+	"github.com/tetratelabs/wazero/imports/wasi_snapshot_preview1"
+)
+
+const (
+	wasmType = "wasm"
+
+	defaultTimeout      = 5 * time.Second
+	defaultMaxMemoryMiB = 128
+
+	// pagesPerMiB is the number of 64 KiB WebAssembly pages in a MiB.
+	pagesPerMiB = 16
+)
+
+// compilationCache shares compiled modules between the runtimes of verifiers
+// so that verifiers of the same module compile it once.
+var compilationCache = wazero.NewCompilationCache()
+
+type options struct {
+	// Module is the source of the WebAssembly module. Required.
+	Module moduleOptions `json:"module"`
+
+	// ArtifactTypes are the artifact types the module verifies. "*" matches
+	// any artifact type but the subject referrer type. Required.
+	ArtifactTypes []string `json:"artifactTypes"`
+
+	// Config is passed to the module as the "config" of the input. Optional.
+	Config map[string]any `json:"config,omitempty"`
+
+	// Timeout is the maximum duration of a single verification, e.g. "1s".
+	// Optional. Defaults to 5 seconds.
+	Timeout string `json:"timeout,omitempty"`
+
+	// MaxMemoryMiB is the maximum size of the linear memory of the module in
+	// MiB. Optional. Defaults to 128.
+	MaxMemoryMiB int `json:"maxMemoryMiB,omitempty"`
+}
+
+func init() {
+	factory.RegisterVerifierFactory(wasmType, func(opts *factory.NewVerifierOptions) (ratify.Verifier, error) {
+		raw, err := json.Marshal(opts.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal verifier parameters: %w", err)
+		}
+
+		var params options
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal verifier parameters: %w", err)
+		}
+
+		if len(params.ArtifactTypes) == 0 {
+			return nil, errors.New("artifactTypes are required")
+		}
+		timeout := defaultTimeout
+		if params.Timeout != "" {
+			if timeout, err = time.ParseDuration(params.Timeout); err != nil || timeout <= 0 {
+				return nil, fmt.Errorf("invalid module timeout %q", params.Timeout)
+			}
+		}
+		maxMemoryMiB := params.MaxMemoryMiB
+		switch {
+		case maxMemoryMiB < 0 || maxMemoryMiB > 4096:
+			return nil, fmt.Errorf("invalid module maxMemoryMiB %d", maxMemoryMiB)
+		case maxMemoryMiB == 0:
+			maxMemoryMiB = defaultMaxMemoryMiB
+		}
+
+		ctx := context.Background()
+		r := wazero.NewRuntimeWithConfig(ctx, wazero.NewRuntimeConfig().
+			WithMemoryLimitPages(uint32(maxMemoryMiB*pagesPerMiB)).
+			WithCloseOnContextDone(true).
+			WithCompilationCache(compilationCache))
+		if _, err := wasi_snapshot_preview1.Instantiate(ctx, r); err != nil {
+			return nil, fmt.Errorf("failed to instantiate WASI: %w", err)
+		}
+		if err := instantiateHostModule(ctx, r); err != nil {
+			return nil, fmt.Errorf("failed to instantiate host module: %w", err)
+		}
+		m, err := newModule(ctx, r, params.Module)
+		if err != nil {
+			_ = r.Close(ctx)
+			return nil, err
+		}
+
+		return &verifier{
+			name:          opts.Name,
+			artifactTypes: params.ArtifactTypes,
+			config:        params.Config,
+			timeout:       timeout,
+			runtime:       r,
+			module:        m,
+		}, nil
+	})
+}
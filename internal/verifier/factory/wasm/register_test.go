@@ -0,0 +1,86 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wasm
+
+import (
+	"testing"
+
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+)
+
+func TestNewVerifier(t *testing.T) {
+	module := map[string]any{"file": writeTestModule(t)}
+	artifactTypes := []string{testArtifactType}
+
+	tests := []struct {
+		name      string
+		params    any
+		expectErr bool
+	}{
+		{
+			name:   "valid parameters",
+			params: map[string]any{"module": module, "artifactTypes": artifactTypes, "timeout": "1s", "maxMemoryMiB": 16},
+		},
+		{
+			name:      "unmarshalable parameters",
+			params:    make(chan int),
+			expectErr: true,
+		},
+		{
+			name:      "invalid parameters",
+			params:    map[string]any{"artifactTypes": "*"},
+			expectErr: true,
+		},
+		{
+			name:      "missing artifact types",
+			params:    map[string]any{"module": module},
+			expectErr: true,
+		},
+		{
+			name:      "invalid timeout",
+			params:    map[string]any{"module": module, "artifactTypes": artifactTypes, "timeout": "soon"},
+			expectErr: true,
+		},
+		{
+			name:      "non-positive timeout",
+			params:    map[string]any{"module": module, "artifactTypes": artifactTypes, "timeout": "0s"},
+			expectErr: true,
+		},
+		{
+			name:      "invalid memory limit",
+			params:    map[string]any{"module": module, "artifactTypes": artifactTypes, "maxMemoryMiB": -1},
+			expectErr: true,
+		},
+		{
+			name:      "missing module",
+			params:    map[string]any{"artifactTypes": artifactTypes},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := factory.NewVerifier(&factory.NewVerifierOptions{
+				Type:       wasmType,
+				Name:       testName,
+				Parameters: test.params,
+			})
+			if (err != nil) != test.expectErr {
+				t.Errorf("expected error: %v, got: %v", test.expectErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,171 @@
+//go:build wasip1
+
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command example is an example WebAssembly module for the wasm verifier. It
+// succeeds if any layer of the verified artifact contains the configured
+// string.
+//
+// Build it with:
+//
+//	GOOS=wasip1 GOARCH=wasm go build -buildmode=c-shared -o example.wasm .
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"unsafe"
+
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+//go:wasmimport ratify fetch_manifest
+func fetchManifest(offset, size uint32) int32
+
+//go:wasmimport ratify fetch_blob
+func fetchBlob(offset, size uint32) int32
+
+//go:wasmimport ratify buffer_size
+func bufferSize() int32
+
+//go:wasmimport ratify read_buffer
+func readBuffer(offset, size uint32) int32
+
+//go:wasmimport ratify log
+func logMessage(offset, size uint32)
+
+type input struct {
+	Repository string             `json:"repository"`
+	Subject    ocispec.Descriptor `json:"subject"`
+	Artifact   ocispec.Descriptor `json:"artifact"`
+	Config     struct {
+		Contains string `json:"contains"`
+		// Mode switches to misbehaving for tests: "spin" never returns,
+		// "grow" exhausts the memory and "invalid" returns invalid output.
+		Mode string `json:"mode"`
+	} `json:"config"`
+}
+
+type output struct {
+	IsSuccess bool   `json:"isSuccess"`
+	Message   string `json:"message"`
+	Detail    any    `json:"detail,omitempty"`
+}
+
+// pinned keeps the memory shared with the host alive.
+var pinned = map[uintptr][]byte{}
+
+func pin(b []byte) uint32 {
+	if len(b) == 0 {
+		b = make([]byte, 1)
+	}
+	ptr := uintptr(unsafe.Pointer(&b[0]))
+	pinned[ptr] = b
+	return uint32(ptr)
+}
+
+//go:wasmexport allocate
+func allocate(size uint32) uint32 {
+	return pin(make([]byte, size))
+}
+
+//go:wasmexport verify
+func verify(offset, size uint32) uint64 {
+	in := pinned[uintptr(offset)][:size]
+	raw, err := json.Marshal(run(in))
+	if err != nil {
+		raw = []byte(err.Error())
+	}
+	return uint64(pin(raw))<<32 | uint64(len(raw))
+}
+
+func run(raw []byte) any {
+	var in input
+	if err := json.Unmarshal(raw, &in); err != nil {
+		return output{Message: fmt.Sprintf("invalid input: %v", err)}
+	}
+	switch in.Config.Mode {
+	case "spin":
+		for {
+		}
+	case "grow":
+		var chunks [][]byte
+		for {
+			chunks = append(chunks, make([]byte, 1<<20))
+		}
+	case "invalid":
+		return "invalid"
+	}
+
+	log("verifying " + in.Artifact.Digest.String())
+	manifestContent, err := fetch(fetchManifest, in.Artifact)
+	if err != nil {
+		return output{Message: err.Error()}
+	}
+	var manifest ocispec.Manifest
+	if err := json.Unmarshal(manifestContent, &manifest); err != nil {
+		return output{Message: fmt.Sprintf("invalid manifest: %v", err)}
+	}
+	for _, layer := range manifest.Layers {
+		blob, err := fetch(fetchBlob, layer)
+		if err != nil {
+			return output{Message: err.Error()}
+		}
+		if bytes.Contains(blob, []byte(in.Config.Contains)) {
+			return output{
+				IsSuccess: true,
+				Message:   fmt.Sprintf("found %q", in.Config.Contains),
+				Detail:    map[string]any{"Layer": layer.Digest},
+			}
+		}
+	}
+	return output{Message: fmt.Sprintf("%q not found", in.Config.Contains)}
+}
+
+// fetch fetches the content of the descriptor with the host function.
+func fetch(fn func(offset, size uint32) int32, desc ocispec.Descriptor) ([]byte, error) {
+	raw, err := json.Marshal(desc)
+	if err != nil {
+		return nil, err
+	}
+	offset := pin(raw)
+	defer delete(pinned, uintptr(offset))
+
+	n := fn(offset, uint32(len(raw)))
+	if n < 0 {
+		return nil, fmt.Errorf("failed to fetch %s: %s", desc.Digest, readAll())
+	}
+	return readAll(), nil
+}
+
+// readAll reads the fetch buffer of the host.
+func readAll() []byte {
+	size := bufferSize()
+	if size == 0 {
+		return nil
+	}
+	buf := make([]byte, size)
+	n := readBuffer(uint32(uintptr(unsafe.Pointer(&buf[0]))), uint32(size))
+	return buf[:n]
+}
+
+func log(msg string) {
+	b := []byte(msg)
+	logMessage(uint32(uintptr(unsafe.Pointer(&b[0]))), uint32(len(b)))
+}
+
+func main() {}
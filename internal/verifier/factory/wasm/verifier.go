@@ -0,0 +1,152 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/store/subjectreferrer"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/tetratelabs/wazero"
+)
+
+// input is the JSON input passed to the verify function of the module.
+type input struct {
+	Repository string             `json:"repository"`
+	Subject    ocispec.Descriptor `json:"subject"`
+	Artifact   ocispec.Descriptor `json:"artifact"`
+	Config     map[string]any     `json:"config,omitempty"`
+}
+
+// output is the JSON output returned by the verify function of the module.
+type output struct {
+	IsSuccess bool   `json:"isSuccess"`
+	Message   string `json:"message"`
+	Detail    any    `json:"detail,omitempty"`
+}
+
+// verifier is a ratify.Verifier implementation that runs a WebAssembly module
+// against the artifact.
+type verifier struct {
+	name          string
+	artifactTypes []string
+	config        map[string]any
+	timeout       time.Duration
+	runtime       wazero.Runtime
+	module        *module
+}
+
+// Name returns the name of the verifier.
+func (v *verifier) Name() string {
+	return v.name
+}
+
+// Type returns the type of the verifier which is always `wasm`.
+func (v *verifier) Type() string {
+	return wasmType
+}
+
+// Verifiable returns true if the artifact is of the configured artifact
+// types.
+func (v *verifier) Verifiable(artifact ocispec.Descriptor) bool {
+	return subjectreferrer.MatchArtifactType(v.artifactTypes, artifact.ArtifactType)
+}
+
+// Verify runs the module in a fresh instance. Module failures, including
+// traps, timeouts and exceeding the memory limit, fail the verification.
+func (v *verifier) Verify(ctx context.Context, opts *ratify.VerifyOptions) (*ratify.VerificationResult, error) {
+	compiled, err := v.module.get(ctx, opts.Store)
+	if err != nil {
+		return nil, err
+	}
+	in, err := json.Marshal(input{
+		Repository: opts.Repository,
+		Subject:    opts.SubjectDescriptor,
+		Artifact:   opts.ArtifactDescriptor,
+		Config:     v.config,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal module input: %w", err)
+	}
+
+	result := &ratify.VerificationResult{
+		Verifier: v,
+	}
+	ctx, cancel := context.WithTimeout(ctx, v.timeout)
+	defer cancel()
+	ctx = withInvocation(ctx, &invocation{
+		name:  v.name,
+		store: opts.Store,
+		repo:  opts.Repository,
+	})
+	out, err := v.run(ctx, compiled, in)
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			result.Err = fmt.Errorf("module timed out after %s", v.timeout)
+		} else {
+			result.Err = err
+		}
+		return result, nil
+	}
+
+	result.Description = out.Message
+	if out.Detail != nil {
+		result.Detail = out.Detail
+	}
+	if !out.IsSuccess {
+		result.Err = fmt.Errorf("module verification failed: %s", out.Message)
+	}
+	return result, nil
+}
+
+// run instantiates the module and calls its verify function with the input.
+func (v *verifier) run(ctx context.Context, compiled wazero.CompiledModule, in []byte) (*output, error) {
+	config := wazero.NewModuleConfig().
+		WithName("").
+		WithStartFunctions(exportInitialize).
+		WithSysWalltime()
+	mod, err := v.runtime.InstantiateModule(ctx, compiled, config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to instantiate module: %w", err)
+	}
+	defer mod.Close(context.Background())
+
+	results, err := mod.ExportedFunction(exportAllocate).Call(ctx, uint64(len(in)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to allocate module input: %w", err)
+	}
+	offset := uint32(results[0])
+	if !mod.Memory().Write(offset, in) {
+		return nil, errors.New("module input out of memory range")
+	}
+	if results, err = mod.ExportedFunction(exportVerify).Call(ctx, uint64(offset), uint64(len(in))); err != nil {
+		return nil, fmt.Errorf("module verify failed: %w", err)
+	}
+	raw, ok := mod.Memory().Read(uint32(results[0]>>32), uint32(results[0]))
+	if !ok {
+		return nil, errors.New("module output out of memory range")
+	}
+	var out output
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal module output: %w", err)
+	}
+	return &out, nil
+}
@@ -0,0 +1,288 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package wasm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	testName         = "test-wasm"
+	testRepo         = "test.registry.io/test/image"
+	testArtifactType = "application/vnd.test.artifact"
+)
+
+// examplePath is the path of the example module built from testdata/example,
+// or empty if it cannot be built.
+var examplePath string
+
+func TestMain(m *testing.M) {
+	os.Exit(func() int {
+		dir, err := os.MkdirTemp("", "ratify-wasm-")
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to create temp dir: %v\n", err)
+			return 1
+		}
+		defer os.RemoveAll(dir)
+		if err := buildExample(filepath.Join(dir, "example.wasm")); err != nil {
+			fmt.Fprintf(os.Stderr, "skipping tests of the example module: %v\n", err)
+		}
+		return m.Run()
+	}())
+}
+
+// buildExample builds the example module to path.
+func buildExample(path string) error {
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		return err
+	}
+	cmd := exec.Command(goBin, "build", "-buildmode=c-shared", "-o", path, ".")
+	cmd.Dir = filepath.Join("testdata", "example")
+	cmd.Env = append(os.Environ(), "GOOS=wasip1", "GOARCH=wasm")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to build example module: %v: %s", err, out)
+	}
+	examplePath = path
+	return nil
+}
+
+// mockStore serves a single artifact manifest and its layer blobs.
+type mockStore struct {
+	resolved ocispec.Descriptor
+	manifest []byte
+	blobs    map[digest.Digest][]byte
+}
+
+func (s *mockStore) Resolve(_ context.Context, _ string) (ocispec.Descriptor, error) {
+	return s.resolved, nil
+}
+
+func (s *mockStore) ListReferrers(_ context.Context, _ string, _ []string, _ func(referrers []ocispec.Descriptor) error) error {
+	return errors.New("not implemented")
+}
+
+func (s *mockStore) FetchBlob(_ context.Context, _ string, desc ocispec.Descriptor) ([]byte, error) {
+	blob, ok := s.blobs[desc.Digest]
+	if !ok {
+		return nil, errors.New("blob not found")
+	}
+	return blob, nil
+}
+
+func (s *mockStore) FetchManifest(_ context.Context, _ string, desc ocispec.Descriptor) ([]byte, error) {
+	if desc.Digest != s.resolved.Digest {
+		return nil, errors.New("manifest not found")
+	}
+	return s.manifest, nil
+}
+
+// newMockStore returns a store serving an artifact with the layers.
+func newMockStore(t *testing.T, layers ...[]byte) *mockStore {
+	t.Helper()
+	store := &mockStore{blobs: map[digest.Digest][]byte{}}
+	manifest := ocispec.Manifest{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: testArtifactType,
+		Config:       ocispec.DescriptorEmptyJSON,
+		Layers:       []ocispec.Descriptor{},
+	}
+	for _, layer := range layers {
+		desc := ocispec.Descriptor{
+			MediaType: "application/octet-stream",
+			Digest:    digest.FromBytes(layer),
+			Size:      int64(len(layer)),
+		}
+		manifest.Layers = append(manifest.Layers, desc)
+		store.blobs[desc.Digest] = layer
+	}
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("failed to marshal manifest: %v", err)
+	}
+	store.manifest = content
+	store.resolved = ocispec.Descriptor{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: testArtifactType,
+		Digest:       digest.FromBytes(content),
+		Size:         int64(len(content)),
+	}
+	return store
+}
+
+func newVerifyOptions(store *mockStore) *ratify.VerifyOptions {
+	return &ratify.VerifyOptions{
+		Store:      store,
+		Repository: testRepo,
+		SubjectDescriptor: ocispec.Descriptor{
+			MediaType: ocispec.MediaTypeImageManifest,
+			Digest:    digest.FromString("subject"),
+			Size:      7,
+		},
+		ArtifactDescriptor: store.resolved,
+	}
+}
+
+func newTestVerifier(t *testing.T, params map[string]any) ratify.Verifier {
+	t.Helper()
+	params["artifactTypes"] = []string{testArtifactType}
+	v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+		Type:       wasmType,
+		Name:       testName,
+		Parameters: params,
+	})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+	return v
+}
+
+func TestVerifier(t *testing.T) {
+	v := newTestVerifier(t, map[string]any{
+		"module": map[string]any{"file": writeTestModule(t)},
+	})
+	if v.Name() != testName {
+		t.Errorf("expected name %s, got %s", testName, v.Name())
+	}
+	if v.Type() != wasmType {
+		t.Errorf("expected type %s, got %s", wasmType, v.Type())
+	}
+	if !v.Verifiable(ocispec.Descriptor{ArtifactType: testArtifactType}) {
+		t.Error("expected the configured artifact type to be verifiable")
+	}
+	if v.Verifiable(ocispec.Descriptor{ArtifactType: "application/vnd.other"}) {
+		t.Error("expected other artifact types not to be verifiable")
+	}
+}
+
+// writeTestModule writes a valid module to a file and returns its path.
+func writeTestModule(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "test.wasm")
+	if err := os.WriteFile(path, newTestModule(valueTypeI64, true), 0600); err != nil {
+		t.Fatalf("failed to write module: %v", err)
+	}
+	return path
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	if examplePath == "" {
+		t.Skip("example module is not built")
+	}
+	store := newMockStore(t, []byte("license: MIT"), []byte("license: Apache-2.0"))
+	missingBlob := newMockStore(t, []byte("license: MIT"))
+	missingBlob.blobs = map[digest.Digest][]byte{}
+
+	tests := []struct {
+		name          string
+		params        map[string]any
+		store         *mockStore
+		expectErr     bool
+		expectMessage string
+	}{
+		{
+			name:          "content found",
+			params:        map[string]any{"config": map[string]any{"contains": "Apache-2.0"}},
+			store:         store,
+			expectMessage: `found "Apache-2.0"`,
+		},
+		{
+			name:          "content not found",
+			params:        map[string]any{"config": map[string]any{"contains": "GPL"}},
+			store:         store,
+			expectErr:     true,
+			expectMessage: `"GPL" not found`,
+		},
+		{
+			name:      "blob not found",
+			params:    map[string]any{"config": map[string]any{"contains": "MIT"}},
+			store:     missingBlob,
+			expectErr: true,
+		},
+		{
+			name:      "timeout",
+			params:    map[string]any{"config": map[string]any{"mode": "spin"}, "timeout": "500ms"},
+			store:     store,
+			expectErr: true,
+		},
+		{
+			name:      "memory limit",
+			params:    map[string]any{"config": map[string]any{"mode": "grow"}, "maxMemoryMiB": 64},
+			store:     store,
+			expectErr: true,
+		},
+		{
+			name:      "invalid output",
+			params:    map[string]any{"config": map[string]any{"mode": "invalid"}},
+			store:     store,
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			test.params["module"] = map[string]any{"file": examplePath}
+			v := newTestVerifier(t, test.params)
+			result, err := v.Verify(context.Background(), newVerifyOptions(test.store))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if (result.Err != nil) != test.expectErr {
+				t.Fatalf("expected verification error: %v, got: %v", test.expectErr, result.Err)
+			}
+			if test.expectMessage != "" && result.Description != test.expectMessage {
+				t.Errorf("expected description %q, got %q", test.expectMessage, result.Description)
+			}
+			if result.Err == nil && result.Detail == nil {
+				t.Error("expected detail of the module output")
+			}
+		})
+	}
+}
+
+func TestVerifier_VerifyOCIModule(t *testing.T) {
+	bin := newTestModule(valueTypeI64, true)
+	moduleStore := newMockStore(t, bin)
+	v := newTestVerifier(t, map[string]any{
+		"module": map[string]any{"oci": testRepo + "@" + moduleStore.resolved.Digest.String()},
+	})
+
+	// the module cannot be fetched from a store without it.
+	if _, err := v.Verify(context.Background(), newVerifyOptions(newMockStore(t))); err == nil {
+		t.Fatal("expected error fetching the module, got nil")
+	}
+
+	// the test module returns an empty output at offset 0.
+	result, err := v.Verify(context.Background(), newVerifyOptions(moduleStore))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Err == nil {
+		t.Error("expected verification error of the empty module output, got nil")
+	}
+}
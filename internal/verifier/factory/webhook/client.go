@@ -0,0 +1,109 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"time"
+)
+
+const (
+	// headerTimestamp is the header of the Unix time the request is signed at.
+	headerTimestamp = "X-Ratify-Timestamp"
+
+	// headerSignature is the header of the request signature.
+	headerSignature = "X-Ratify-Signature"
+)
+
+// tlsOptions configures the TLS connections to the webhook.
+type tlsOptions struct {
+	// CACertFile is the path of the PEM encoded CA certificates the server
+	// certificate is verified against. Optional. Defaults to the system
+	// roots.
+	CACertFile string `json:"caCertFile,omitempty"`
+
+	// CertFile and KeyFile are the paths of the PEM encoded client
+	// certificate and key for mutual TLS. They are read on every handshake so
+	// that rotated certificates are picked up. Optional. Must be set
+	// together.
+	CertFile string `json:"certFile,omitempty"`
+	KeyFile  string `json:"keyFile,omitempty"`
+}
+
+// newHTTPClient creates an HTTP client with the TLS options.
+func newHTTPClient(opts *tlsOptions, timeout time.Duration) (*http.Client, error) {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if opts != nil {
+		tlsConfig := &tls.Config{
+			MinVersion: tls.VersionTLS12,
+		}
+		if opts.CACertFile != "" {
+			caCerts, err := os.ReadFile(opts.CACertFile)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read CA certificates: %w", err)
+			}
+			tlsConfig.RootCAs = x509.NewCertPool()
+			if !tlsConfig.RootCAs.AppendCertsFromPEM(caCerts) {
+				return nil, fmt.Errorf("no CA certificates found in %s", opts.CACertFile)
+			}
+		}
+		if (opts.CertFile == "") != (opts.KeyFile == "") {
+			return nil, errors.New("tls certFile and keyFile must be set together")
+		}
+		if opts.CertFile != "" {
+			// fail early on invalid client certificates.
+			if _, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile); err != nil {
+				return nil, fmt.Errorf("failed to load client certificate: %w", err)
+			}
+			tlsConfig.GetClientCertificate = func(_ *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+				cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
+				if err != nil {
+					return nil, fmt.Errorf("failed to load client certificate: %w", err)
+				}
+				return &cert, nil
+			}
+		}
+		transport.TLSClientConfig = tlsConfig
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   timeout,
+	}, nil
+}
+
+// sign signs the request with the secret. The signature is the hex encoded
+// HMAC-SHA256 of "<timestamp>.<body>" in the X-Ratify-Signature header as
+// "sha256=<signature>", where timestamp is the Unix time in the
+// X-Ratify-Timestamp header. Webhooks should reject stale timestamps to
+// prevent replays.
+func sign(req *http.Request, body, secret []byte, now time.Time) {
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	req.Header.Set(headerTimestamp, timestamp)
+	req.Header.Set(headerSignature, "sha256="+hex.EncodeToString(mac.Sum(nil)))
+}
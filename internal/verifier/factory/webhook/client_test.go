@@ -0,0 +1,182 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeClientCert writes a self-signed client certificate and its key to the
+// directory and returns the certificate and the paths.
+func writeClientCert(t *testing.T, dir string) (*x509.Certificate, string, string) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ratify-webhook-test"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, key.Public(), key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("failed to parse certificate: %v", err)
+	}
+	keyDER, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("failed to marshal key: %v", err)
+	}
+	certPath := writeFile(t, dir, "client.crt", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}))
+	keyPath := writeFile(t, dir, "client.key", pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: keyDER}))
+	return cert, certPath, keyPath
+}
+
+func writeFile(t *testing.T, dir, name string, content []byte) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	if err := os.WriteFile(path, content, 0600); err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+	return path
+}
+
+// newMTLSServer starts a TLS server requiring client certificates issued by
+// the client certificate and writes its CA certificate to the directory.
+func newMTLSServer(t *testing.T, dir string, clientCert *x509.Certificate, handler http.Handler) (*httptest.Server, string) {
+	t.Helper()
+	server := httptest.NewUnstartedServer(handler)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(clientCert)
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	caPath := writeFile(t, dir, "ca.crt", pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	return server, caPath
+}
+
+func TestNewHTTPClient(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, certPath, keyPath := writeClientCert(t, dir)
+	server, caPath := newMTLSServer(t, dir, clientCert, http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	invalidPEM := writeFile(t, dir, "invalid.pem", []byte("invalid"))
+
+	tests := []struct {
+		name          string
+		opts          *tlsOptions
+		expectErr     bool
+		expectConnErr bool
+	}{
+		{
+			name: "mutual TLS",
+			opts: &tlsOptions{CACertFile: caPath, CertFile: certPath, KeyFile: keyPath},
+		},
+		{
+			name:          "missing client certificate",
+			opts:          &tlsOptions{CACertFile: caPath},
+			expectConnErr: true,
+		},
+		{
+			name:          "untrusted server certificate",
+			opts:          &tlsOptions{CertFile: certPath, KeyFile: keyPath},
+			expectConnErr: true,
+		},
+		{
+			name:          "no TLS options",
+			expectConnErr: true,
+		},
+		{
+			name:      "missing CA file",
+			opts:      &tlsOptions{CACertFile: filepath.Join(dir, "missing.crt")},
+			expectErr: true,
+		},
+		{
+			name:      "invalid CA file",
+			opts:      &tlsOptions{CACertFile: invalidPEM},
+			expectErr: true,
+		},
+		{
+			name:      "certificate without key",
+			opts:      &tlsOptions{CertFile: certPath},
+			expectErr: true,
+		},
+		{
+			name:      "invalid client certificate",
+			opts:      &tlsOptions{CertFile: invalidPEM, KeyFile: keyPath},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, err := newHTTPClient(test.opts, time.Second)
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if err != nil {
+				return
+			}
+			resp, err := client.Get(server.URL)
+			if (err != nil) != test.expectConnErr {
+				t.Fatalf("expected connection error: %v, got: %v", test.expectConnErr, err)
+			}
+			if err == nil {
+				resp.Body.Close()
+			}
+		})
+	}
+}
+
+func TestSign(t *testing.T) {
+	req, err := http.NewRequest(http.MethodPost, "https://webhook.example", nil)
+	if err != nil {
+		t.Fatalf("failed to create request: %v", err)
+	}
+	sign(req, []byte(`{"key":"value"}`), []byte("secret"), time.Unix(1700000000, 0))
+
+	if got := req.Header.Get(headerTimestamp); got != "1700000000" {
+		t.Errorf("expected timestamp 1700000000, got %s", got)
+	}
+	// echo -n '1700000000.{"key":"value"}' | openssl dgst -sha256 -hmac secret
+	expected := "sha256=ce724092962939b8d844688d0f3c108c762f100973443f7e6688bdc19beb1a5b"
+	if got := req.Header.Get(headerSignature); got != expected {
+		t.Errorf("expected signature %s, got %s", expected, got)
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/cache"
+	"github.com/notaryproject/ratify/v2/internal/cache/ristretto"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+)
+
+const (
+	webhookType     = "webhook"
+	defaultTimeout  = 10 * time.Second
+	defaultCacheTTL = 5 * time.Minute
+)
+
+type options struct {
+	// URL is the http or https URL the verification requests are posted to.
+	// Required.
+	URL string `json:"url"`
+
+	// ArtifactTypes are the artifact types the webhook verifies. "*" matches
+	// any artifact type but the subject referrer type. Required.
+	ArtifactTypes []string `json:"artifactTypes"`
+
+	// IncludeContent includes the manifest and the layer blobs of the artifact
+	// in the requests. Optional. Defaults to false.
+	IncludeContent bool `json:"includeContent,omitempty"`
+
+	// TLS configures the server certificate verification and the client
+	// certificate of the requests. Optional.
+	TLS *tlsOptions `json:"tls,omitempty"`
+
+	// SigningSecretFile is the path of a file containing the secret the
+	// requests are signed with. Surrounding whitespace is trimmed and the
+	// secret must not be empty. See sign for the signature scheme. Optional.
+	SigningSecretFile string `json:"signingSecretFile,omitempty"`
+
+	// Timeout is the maximum duration of a single request, e.g. "5s".
+	// Optional. Defaults to 10 seconds.
+	Timeout string `json:"timeout,omitempty"`
+
+	// CacheTTL is the duration the response for an artifact digest is
+	// cached, e.g. "1m". "0s" disables caching. Optional. Defaults to 5
+	// minutes.
+	CacheTTL string `json:"cacheTTL,omitempty"`
+}
+
+func init() {
+	factory.RegisterVerifierFactory(webhookType, func(opts *factory.NewVerifierOptions) (ratify.Verifier, error) {
+		raw, err := json.Marshal(opts.Parameters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal verifier parameters: %w", err)
+		}
+
+		var params options
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal verifier parameters: %w", err)
+		}
+
+		u, err := url.Parse(params.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid webhook url %q", params.URL)
+		}
+		if len(params.ArtifactTypes) == 0 {
+			return nil, errors.New("artifactTypes are required")
+		}
+		timeout := defaultTimeout
+		if params.Timeout != "" {
+			if timeout, err = time.ParseDuration(params.Timeout); err != nil || timeout <= 0 {
+				return nil, fmt.Errorf("invalid webhook timeout %q", params.Timeout)
+			}
+		}
+		cacheTTL := defaultCacheTTL
+		if params.CacheTTL != "" {
+			if cacheTTL, err = time.ParseDuration(params.CacheTTL); err != nil || cacheTTL < 0 {
+				return nil, fmt.Errorf("invalid webhook cacheTTL %q", params.CacheTTL)
+			}
+		}
+		var responses cache.Cache
+		if cacheTTL > 0 {
+			if responses, err = ristretto.NewRistrettoCache(cacheTTL); err != nil {
+				return nil, fmt.Errorf("failed to create webhook response cache: %w", err)
+			}
+		}
+		var secret []byte
+		if params.SigningSecretFile != "" {
+			if secret, err = os.ReadFile(params.SigningSecretFile); err != nil {
+				return nil, fmt.Errorf("failed to read signing secret: %w", err)
+			}
+			if secret = bytes.TrimSpace(secret); len(secret) == 0 {
+				return nil, fmt.Errorf("signing secret file %s is empty", params.SigningSecretFile)
+			}
+		}
+		client, err := newHTTPClient(params.TLS, timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		return &verifier{
+			name:           opts.Name,
+			url:            u.String(),
+			artifactTypes:  params.ArtifactTypes,
+			includeContent: params.IncludeContent,
+			secret:         secret,
+			client:         client,
+			cache:          responses,
+		}, nil
+	})
+}
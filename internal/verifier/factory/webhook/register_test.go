@@ -0,0 +1,114 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+)
+
+func TestNewVerifier(t *testing.T) {
+	dir := t.TempDir()
+	artifactTypes := []string{testArtifactType}
+	const testURL = "https://webhook.example/verify"
+
+	tests := []struct {
+		name      string
+		params    any
+		secret    string
+		expectErr bool
+	}{
+		{
+			name: "valid parameters",
+			params: map[string]any{
+				"url":               testURL,
+				"artifactTypes":     artifactTypes,
+				"timeout":           "1s",
+				"cacheTTL":          "1m",
+				"signingSecretFile": writeFile(t, dir, "secret", []byte(testSecret+"\n")),
+			},
+			secret: testSecret,
+		},
+		{
+			name:      "unmarshalable parameters",
+			params:    make(chan int),
+			expectErr: true,
+		},
+		{
+			name:      "invalid parameters",
+			params:    map[string]any{"url": 1},
+			expectErr: true,
+		},
+		{
+			name:      "missing url",
+			params:    map[string]any{"artifactTypes": artifactTypes},
+			expectErr: true,
+		},
+		{
+			name:      "unsupported url scheme",
+			params:    map[string]any{"url": "ftp://webhook.example", "artifactTypes": artifactTypes},
+			expectErr: true,
+		},
+		{
+			name:      "missing artifact types",
+			params:    map[string]any{"url": testURL},
+			expectErr: true,
+		},
+		{
+			name:      "invalid timeout",
+			params:    map[string]any{"url": testURL, "artifactTypes": artifactTypes, "timeout": "0s"},
+			expectErr: true,
+		},
+		{
+			name:      "invalid cache ttl",
+			params:    map[string]any{"url": testURL, "artifactTypes": artifactTypes, "cacheTTL": "-1m"},
+			expectErr: true,
+		},
+		{
+			name:      "missing signing secret",
+			params:    map[string]any{"url": testURL, "artifactTypes": artifactTypes, "signingSecretFile": filepath.Join(dir, "missing")},
+			expectErr: true,
+		},
+		{
+			name:      "empty signing secret",
+			params:    map[string]any{"url": testURL, "artifactTypes": artifactTypes, "signingSecretFile": writeFile(t, dir, "empty", []byte(" \n"))},
+			expectErr: true,
+		},
+		{
+			name:      "invalid tls options",
+			params:    map[string]any{"url": testURL, "artifactTypes": artifactTypes, "tls": map[string]any{"keyFile": "client.key"}},
+			expectErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+				Type:       webhookType,
+				Name:       testName,
+				Parameters: test.params,
+			})
+			if (err != nil) != test.expectErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectErr, err)
+			}
+			if test.secret != "" && string(v.(*verifier).secret) != test.secret {
+				t.Errorf("expected signing secret %q, got %q", test.secret, v.(*verifier).secret)
+			}
+		})
+	}
+}
@@ -0,0 +1,207 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/cache"
+	"github.com/notaryproject/ratify/v2/internal/store/subjectreferrer"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+	"github.com/sirupsen/logrus"
+)
+
+// maxResponseSize is the maximum size of a webhook response.
+const maxResponseSize = 1 << 20
+
+// request is the JSON body posted to the webhook.
+type request struct {
+	Repository string             `json:"repository"`
+	Subject    ocispec.Descriptor `json:"subject"`
+	Artifact   ocispec.Descriptor `json:"artifact"`
+
+	// Manifest and Blobs are the content of the artifact, set if content is
+	// included.
+	Manifest json.RawMessage `json:"manifest,omitempty"`
+	Blobs    []blob          `json:"blobs,omitempty"`
+}
+
+// blob is a layer of the artifact. The content is base64 encoded in JSON.
+type blob struct {
+	Descriptor ocispec.Descriptor `json:"descriptor"`
+	Content    []byte             `json:"content"`
+}
+
+// response is the JSON body returned by the webhook.
+type response struct {
+	IsSuccess bool   `json:"isSuccess"`
+	Message   string `json:"message"`
+	Detail    any    `json:"detail,omitempty"`
+}
+
+// verifier is a ratify.Verifier implementation that delegates the
+// verification to an HTTP webhook.
+type verifier struct {
+	name           string
+	url            string
+	artifactTypes  []string
+	includeContent bool
+	secret         []byte
+	client         *http.Client
+
+	// cache caches the responses by artifact digest. Nil if caching is
+	// disabled.
+	cache cache.Cache
+}
+
+// Name returns the name of the verifier.
+func (v *verifier) Name() string {
+	return v.name
+}
+
+// Type returns the type of the verifier which is always `webhook`.
+func (v *verifier) Type() string {
+	return webhookType
+}
+
+// Verifiable returns true if the artifact is of the configured artifact
+// types.
+func (v *verifier) Verifiable(artifact ocispec.Descriptor) bool {
+	return subjectreferrer.MatchArtifactType(v.artifactTypes, artifact.ArtifactType)
+}
+
+// Verify posts the artifact to the webhook and maps the response to the
+// result. Failed requests and non-2xx responses fail the verification and are
+// not cached.
+func (v *verifier) Verify(ctx context.Context, opts *ratify.VerifyOptions) (*ratify.VerificationResult, error) {
+	key := opts.ArtifactDescriptor.Digest.String()
+	resp, ok := v.cached(ctx, key)
+	if !ok {
+		body, err := v.newRequestBody(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		if resp, err = v.post(ctx, body); err != nil {
+			return &ratify.VerificationResult{
+				Verifier: v,
+				Err:      err,
+			}, nil
+		}
+		if v.cache != nil {
+			if err := v.cache.Set(ctx, key, resp); err != nil {
+				logrus.Warnf("failed to cache webhook response for artifact %s: %v", key, err)
+			}
+		}
+	}
+
+	result := &ratify.VerificationResult{
+		Verifier:    v,
+		Description: resp.Message,
+	}
+	if resp.Detail != nil {
+		result.Detail = resp.Detail
+	}
+	if !resp.IsSuccess {
+		result.Err = fmt.Errorf("webhook verification failed: %s", resp.Message)
+	}
+	return result, nil
+}
+
+// cached returns the cached response for the key if present.
+func (v *verifier) cached(ctx context.Context, key string) (*response, bool) {
+	if v.cache == nil {
+		return nil, false
+	}
+	val, err := v.cache.Get(ctx, key)
+	if err != nil {
+		return nil, false
+	}
+	resp, ok := val.(*response)
+	return resp, ok
+}
+
+// newRequestBody returns the JSON request body, fetching the artifact content
+// if content is included.
+func (v *verifier) newRequestBody(ctx context.Context, opts *ratify.VerifyOptions) ([]byte, error) {
+	req := request{
+		Repository: opts.Repository,
+		Subject:    opts.SubjectDescriptor,
+		Artifact:   opts.ArtifactDescriptor,
+	}
+	if v.includeContent {
+		manifestBytes, err := opts.Store.FetchManifest(ctx, opts.Repository, opts.ArtifactDescriptor)
+		if err != nil {
+			return nil, fmt.Errorf("failed to fetch manifest for artifact: %w", err)
+		}
+		var manifest ocispec.Manifest
+		if err := json.Unmarshal(manifestBytes, &manifest); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal manifest: %w", err)
+		}
+		req.Manifest = manifestBytes
+		for _, layer := range manifest.Layers {
+			content, err := opts.Store.FetchBlob(ctx, opts.Repository, layer)
+			if err != nil {
+				return nil, fmt.Errorf("failed to fetch blob %s: %w", layer.Digest, err)
+			}
+			req.Blobs = append(req.Blobs, blob{Descriptor: layer, Content: content})
+		}
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal webhook request: %w", err)
+	}
+	return body, nil
+}
+
+// post posts the request body to the webhook and decodes the response.
+func (v *verifier) post(ctx context.Context, body []byte) (*response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, v.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if len(v.secret) > 0 {
+		sign(req, body, v.secret, time.Now())
+	}
+
+	httpResp, err := v.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer httpResp.Body.Close()
+	respBody, err := io.ReadAll(io.LimitReader(httpResp.Body, maxResponseSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read webhook response: %w", err)
+	}
+	if len(respBody) > maxResponseSize {
+		return nil, fmt.Errorf("webhook response exceeds the limit of %d bytes", maxResponseSize)
+	}
+	if httpResp.StatusCode < 200 || httpResp.StatusCode > 299 {
+		return nil, fmt.Errorf("webhook returned status %d: %s", httpResp.StatusCode, bytes.TrimSpace(respBody))
+	}
+	var resp response
+	if err := json.Unmarshal(respBody, &resp); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal webhook response: %w", err)
+	}
+	return &resp, nil
+}
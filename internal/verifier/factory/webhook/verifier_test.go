@@ -0,0 +1,382 @@
+/*
+Copyright The Ratify Authors.
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/notaryproject/ratify-go"
+	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
+	"github.com/opencontainers/go-digest"
+	ocispec "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+const (
+	testName         = "test-webhook"
+	testRepo         = "test.registry.io/test/image"
+	testArtifactType = "application/vnd.test.artifact"
+	testSecret       = "secret"
+)
+
+// mockStore serves a single artifact manifest and its layer blobs.
+type mockStore struct {
+	manifest []byte
+	blobs    map[digest.Digest][]byte
+}
+
+func (s *mockStore) Resolve(_ context.Context, _ string) (ocispec.Descriptor, error) {
+	return ocispec.Descriptor{}, errors.New("not implemented")
+}
+
+func (s *mockStore) ListReferrers(_ context.Context, _ string, _ []string, _ func(referrers []ocispec.Descriptor) error) error {
+	return errors.New("not implemented")
+}
+
+func (s *mockStore) FetchBlob(_ context.Context, _ string, desc ocispec.Descriptor) ([]byte, error) {
+	blob, ok := s.blobs[desc.Digest]
+	if !ok {
+		return nil, errors.New("blob not found")
+	}
+	return blob, nil
+}
+
+func (s *mockStore) FetchManifest(_ context.Context, _ string, _ ocispec.Descriptor) ([]byte, error) {
+	if s.manifest == nil {
+		return nil, errors.New("manifest not found")
+	}
+	return s.manifest, nil
+}
+
+// newVerifyOptions returns the verify options of an artifact with the layers.
+func newVerifyOptions(t *testing.T, layers ...[]byte) *ratify.VerifyOptions {
+	t.Helper()
+	store := &mockStore{blobs: map[digest.Digest][]byte{}}
+	manifest := ocispec.Manifest{
+		MediaType:    ocispec.MediaTypeImageManifest,
+		ArtifactType: testArtifactType,
+		Config:       ocispec.DescriptorEmptyJSON,
+		Layers:       []ocispec.Descriptor{},
+	}
+	for _, layer := range layers {
+		desc := ocispec.Descriptor{
+			MediaType: "application/json",
+			Digest:    digest.FromBytes(layer),
+			Size:      int64(len(layer)),
+		}
+		manifest.Layers = append(manifest.Layers, desc)
+		store.blobs[desc.Digest] = layer
+	}
+	content, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatalf("failed to marshal manifest: %v", err)
+	}
+	store.manifest = content
+	return &ratify.VerifyOptions{
+		Store:      store,
+		Repository: testRepo,
+		SubjectDescriptor: ocispec.Descriptor{
+			MediaType: ocispec.MediaTypeImageManifest,
+			Digest:    digest.FromString("subject"),
+			Size:      7,
+		},
+		ArtifactDescriptor: ocispec.Descriptor{
+			MediaType:    ocispec.MediaTypeImageManifest,
+			ArtifactType: testArtifactType,
+			Digest:       digest.FromBytes(content),
+			Size:         int64(len(content)),
+		},
+	}
+}
+
+// webhook is a test webhook approving artifacts whose request matches the
+// approved function.
+type webhook struct {
+	requests atomic.Int32
+	status   int
+	body     string
+	approve  func(req *request) bool
+}
+
+func (h *webhook) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.requests.Add(1)
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if r.Header.Get(headerSignature) != "" {
+		mac := hmac.New(sha256.New, []byte(testSecret))
+		mac.Write([]byte(r.Header.Get(headerTimestamp) + "."))
+		mac.Write(body)
+		if r.Header.Get(headerSignature) != "sha256="+hex.EncodeToString(mac.Sum(nil)) {
+			http.Error(w, "invalid signature", http.StatusUnauthorized)
+			return
+		}
+	}
+	if h.status != 0 {
+		w.WriteHeader(h.status)
+	}
+	if h.body != "" {
+		_, _ = w.Write([]byte(h.body))
+		return
+	}
+	var req request
+	if err := json.Unmarshal(body, &req); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	resp := response{Message: "rejected"}
+	if h.approve(&req) {
+		resp = response{IsSuccess: true, Message: "approved", Detail: map[string]any{"Repository": req.Repository}}
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func newTestVerifier(t *testing.T, params map[string]any) ratify.Verifier {
+	t.Helper()
+	params["artifactTypes"] = []string{testArtifactType}
+	v, err := factory.NewVerifier(&factory.NewVerifierOptions{
+		Type:       webhookType,
+		Name:       testName,
+		Parameters: params,
+	})
+	if err != nil {
+		t.Fatalf("failed to create verifier: %v", err)
+	}
+	return v
+}
+
+func TestVerifier(t *testing.T) {
+	v := newTestVerifier(t, map[string]any{"url": "https://webhook.example"})
+	if v.Name() != testName {
+		t.Errorf("expected name %s, got %s", testName, v.Name())
+	}
+	if v.Type() != webhookType {
+		t.Errorf("expected type %s, got %s", webhookType, v.Type())
+	}
+	if !v.Verifiable(ocispec.Descriptor{ArtifactType: testArtifactType}) {
+		t.Error("expected the configured artifact type to be verifiable")
+	}
+	if v.Verifiable(ocispec.Descriptor{ArtifactType: "application/vnd.other"}) {
+		t.Error("expected other artifact types not to be verifiable")
+	}
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	approveRepo := func(req *request) bool {
+		return req.Repository == testRepo && req.Subject.Digest == digest.FromString("subject")
+	}
+	approveContent := func(req *request) bool {
+		return len(req.Manifest) > 0 && len(req.Blobs) == 1 && string(req.Blobs[0].Content) == `{"releasable":true}`
+	}
+	noManifest := newVerifyOptions(t)
+	noManifest.Store = &mockStore{}
+	missingBlob := newVerifyOptions(t, []byte("{}"))
+	missingBlob.Store.(*mockStore).blobs = map[digest.Digest][]byte{}
+
+	tests := []struct {
+		name           string
+		webhook        *webhook
+		params         map[string]any
+		opts           *ratify.VerifyOptions
+		expectStoreErr bool
+		expectErr      bool
+		expectMessage  string
+	}{
+		{
+			name:          "approved",
+			webhook:       &webhook{approve: approveRepo},
+			opts:          newVerifyOptions(t),
+			expectMessage: "approved",
+		},
+		{
+			name:          "rejected",
+			webhook:       &webhook{approve: func(*request) bool { return false }},
+			opts:          newVerifyOptions(t),
+			expectErr:     true,
+			expectMessage: "rejected",
+		},
+		{
+			name:          "content included",
+			webhook:       &webhook{approve: approveContent},
+			params:        map[string]any{"includeContent": true},
+			opts:          newVerifyOptions(t, []byte(`{"releasable":true}`)),
+			expectMessage: "approved",
+		},
+		{
+			name:          "content not included",
+			webhook:       &webhook{approve: approveContent},
+			opts:          newVerifyOptions(t, []byte(`{"releasable":true}`)),
+			expectErr:     true,
+			expectMessage: "rejected",
+		},
+		{
+			name:          "signed request",
+			webhook:       &webhook{approve: approveRepo},
+			params:        map[string]any{"signingSecretFile": writeFile(t, t.TempDir(), "secret", []byte(testSecret))},
+			opts:          newVerifyOptions(t),
+			expectMessage: "approved",
+		},
+		{
+			name:      "invalid signature",
+			webhook:   &webhook{approve: approveRepo},
+			params:    map[string]any{"signingSecretFile": writeFile(t, t.TempDir(), "secret", []byte("other"))},
+			opts:      newVerifyOptions(t),
+			expectErr: true,
+		},
+		{
+			name:      "error status",
+			webhook:   &webhook{status: http.StatusInternalServerError, body: "internal error"},
+			opts:      newVerifyOptions(t),
+			expectErr: true,
+		},
+		{
+			name:      "invalid response",
+			webhook:   &webhook{body: "{"},
+			opts:      newVerifyOptions(t),
+			expectErr: true,
+		},
+		{
+			name:      "response too large",
+			webhook:   &webhook{body: strings.Repeat(" ", maxResponseSize+1)},
+			opts:      newVerifyOptions(t),
+			expectErr: true,
+		},
+		{
+			name:           "manifest not found",
+			webhook:        &webhook{approve: approveContent},
+			params:         map[string]any{"includeContent": true},
+			opts:           noManifest,
+			expectStoreErr: true,
+		},
+		{
+			name:           "blob not found",
+			webhook:        &webhook{approve: approveContent},
+			params:         map[string]any{"includeContent": true},
+			opts:           missingBlob,
+			expectStoreErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(test.webhook)
+			defer server.Close()
+			if test.params == nil {
+				test.params = map[string]any{}
+			}
+			test.params["url"] = server.URL
+			v := newTestVerifier(t, test.params)
+
+			result, err := v.Verify(context.Background(), test.opts)
+			if (err != nil) != test.expectStoreErr {
+				t.Fatalf("expected error: %v, got: %v", test.expectStoreErr, err)
+			}
+			if err != nil {
+				return
+			}
+			if (result.Err != nil) != test.expectErr {
+				t.Fatalf("expected verification error: %v, got: %v", test.expectErr, result.Err)
+			}
+			if test.expectMessage != "" && result.Description != test.expectMessage {
+				t.Errorf("expected description %q, got %q", test.expectMessage, result.Description)
+			}
+			if result.Err == nil && result.Detail == nil {
+				t.Error("expected detail of the webhook response")
+			}
+		})
+	}
+}
+
+func TestVerifier_VerifyCache(t *testing.T) {
+	tests := []struct {
+		name           string
+		webhook        *webhook
+		cacheTTL       string
+		expectRequests int32
+	}{
+		{
+			name:           "responses are cached",
+			webhook:        &webhook{approve: func(*request) bool { return false }},
+			expectRequests: 1,
+		},
+		{
+			name:           "caching disabled",
+			webhook:        &webhook{approve: func(*request) bool { return false }},
+			cacheTTL:       "0s",
+			expectRequests: 2,
+		},
+		{
+			name:           "error responses are not cached",
+			webhook:        &webhook{status: http.StatusServiceUnavailable, body: "unavailable"},
+			expectRequests: 2,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(test.webhook)
+			defer server.Close()
+			params := map[string]any{"url": server.URL}
+			if test.cacheTTL != "" {
+				params["cacheTTL"] = test.cacheTTL
+			}
+			v := newTestVerifier(t, params)
+			opts := newVerifyOptions(t)
+			for range 2 {
+				result, err := v.Verify(context.Background(), opts)
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if result.Err == nil {
+					t.Fatal("expected verification error, got nil")
+				}
+			}
+			if got := test.webhook.requests.Load(); got != test.expectRequests {
+				t.Errorf("expected %d requests, got %d", test.expectRequests, got)
+			}
+		})
+	}
+}
+
+func TestVerifier_VerifyMTLS(t *testing.T) {
+	dir := t.TempDir()
+	clientCert, certPath, keyPath := writeClientCert(t, dir)
+	server, caPath := newMTLSServer(t, dir, clientCert, &webhook{approve: func(*request) bool { return true }})
+
+	v := newTestVerifier(t, map[string]any{
+		"url": server.URL,
+		"tls": map[string]any{"caCertFile": caPath, "certFile": certPath, "keyFile": keyPath},
+	})
+	result, err := v.Verify(context.Background(), newVerifyOptions(t))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if result.Err != nil {
+		t.Errorf("expected verification to succeed, got: %v", result.Err)
+	}
+}
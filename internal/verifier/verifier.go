@@ -20,7 +20,18 @@ import (
 
 	"github.com/notaryproject/ratify-go"
 	"github.com/notaryproject/ratify/v2/internal/verifier/factory"
-	_ "github.com/notaryproject/ratify/v2/internal/verifier/factory/notation" // Register the Notation verifier factory
+	_ "github.com/notaryproject/ratify/v2/internal/verifier/factory/celattestation"      // Register the CEL attestation verifier factory
+	_ "github.com/notaryproject/ratify/v2/internal/verifier/factory/freshness"           // Register the freshness verifier factory
+	_ "github.com/notaryproject/ratify/v2/internal/verifier/factory/image"               // Register the image verifier factory
+	_ "github.com/notaryproject/ratify/v2/internal/verifier/factory/notation"            // Register the Notation verifier factory
+	_ "github.com/notaryproject/ratify/v2/internal/verifier/factory/plugin"              // Register the plugin verifier factory
+	_ "github.com/notaryproject/ratify/v2/internal/verifier/factory/provenance"          // Register the provenance verifier factory
+	_ "github.com/notaryproject/ratify/v2/internal/verifier/factory/sbom"                // Register the SBOM verifier factory
+	_ "github.com/notaryproject/ratify/v2/internal/verifier/factory/schemavalidator"     // Register the schema validator verifier factory
+	_ "github.com/notaryproject/ratify/v2/internal/verifier/factory/sigstorebundle"      // Register the Sigstore bundle verifier factory
+	_ "github.com/notaryproject/ratify/v2/internal/verifier/factory/vulnerabilityreport" // Register the vulnerability report verifier factory
+	_ "github.com/notaryproject/ratify/v2/internal/verifier/factory/wasm"                // Register the WASM verifier factory
+	_ "github.com/notaryproject/ratify/v2/internal/verifier/factory/webhook"             // Register the webhook verifier factory
 )
 
 // NewVerifiers creates a slice of ratify.Verifier instances based on the
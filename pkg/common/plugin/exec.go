@@ -51,12 +51,41 @@ type Executor interface {
 // DefaultExecutor finds the plugin executable and invokes it as a os command
 type DefaultExecutor struct {
 	Stderr io.Writer
+
+	// MaxOutputSize is the maximum number of bytes the plugin may write to
+	// each of stdout and stderr. Output beyond the limit is discarded and the
+	// execution fails. Zero means no limit.
+	MaxOutputSize int64
+}
+
+// limitedBuffer is a buffer discarding the writes beyond its limit. The
+// buffer is not embedded so that copying into it cannot bypass Write.
+type limitedBuffer struct {
+	buf      bytes.Buffer
+	limit    int64
+	exceeded bool
+}
+
+// Write writes p to the buffer up to the limit. It never fails so that the
+// plugin is not blocked writing to a full pipe.
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if b.limit > 0 {
+		remaining := b.limit - int64(b.buf.Len())
+		if int64(len(p)) > remaining {
+			b.exceeded = true
+			if remaining > 0 {
+				b.buf.Write(p[:remaining])
+			}
+			return len(p), nil
+		}
+	}
+	return b.buf.Write(p)
 }
 
 // return the command output and the error
 func (e *DefaultExecutor) ExecutePlugin(ctx context.Context, pluginPath string, cmdArgs []string, stdinData []byte, environ []string) ([]byte, error) {
-	stdout := &bytes.Buffer{}
-	stderr := &bytes.Buffer{}
+	stdout := &limitedBuffer{limit: e.MaxOutputSize}
+	stderr := &limitedBuffer{limit: e.MaxOutputSize}
 	c := exec.CommandContext(ctx, pluginPath, cmdArgs...)
 	c.Env = environ
 	c.Stdin = bytes.NewBuffer(stdinData)
@@ -97,10 +126,14 @@ func (e *DefaultExecutor) ExecutePlugin(ctx context.Context, pluginPath string,
 		}
 
 		// For all other errors return failed.
-		return nil, e.pluginErr(err, stdout.Bytes(), stderr.Bytes())
+		return nil, e.pluginErr(err, stdout.buf.Bytes(), stderr.buf.Bytes())
+	}
+
+	if stdout.exceeded || stderr.exceeded {
+		return nil, &Error{Msg: fmt.Sprintf("plugin output exceeds the limit of %d bytes", e.MaxOutputSize)}
 	}
 
-	pluginOutputJSON, pluginOutputMsgs := parsePluginOutput(stdout, stderr)
+	pluginOutputJSON, pluginOutputMsgs := parsePluginOutput(&stdout.buf, &stderr.buf)
 
 	// Disregards plugin source stream and logs the plugin messages to stderr
 	for _, msg := range pluginOutputMsgs {
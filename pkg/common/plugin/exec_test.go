@@ -17,6 +17,8 @@ package plugin
 
 import (
 	"bytes"
+	"context"
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -107,3 +109,42 @@ func TestParsePluginOutput_InvalidJson(t *testing.T) {
 		t.Fatalf("unexpected json, expected empty, got '%s'", json)
 	}
 }
+
+func TestLimitedBuffer(t *testing.T) {
+	b := &limitedBuffer{limit: 5}
+	if n, err := b.Write([]byte("abc")); n != 3 || err != nil {
+		t.Fatalf("unexpected write result %d, %v", n, err)
+	}
+	if n, err := b.Write([]byte("defg")); n != 4 || err != nil {
+		t.Fatalf("unexpected write result %d, %v", n, err)
+	}
+	if b.buf.String() != "abcde" || !b.exceeded {
+		t.Fatalf("expected truncated exceeded buffer, got %q, exceeded: %v", b.buf.String(), b.exceeded)
+	}
+
+	unlimited := &limitedBuffer{}
+	if _, err := unlimited.Write([]byte("abcdefg")); err != nil || unlimited.exceeded {
+		t.Fatalf("expected unlimited buffer, got error: %v, exceeded: %v", err, unlimited.exceeded)
+	}
+}
+
+func TestExecutePlugin_MaxOutputSize(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+	script := []string{"-c", `echo '{"isSuccess":true}'`}
+
+	e := DefaultExecutor{MaxOutputSize: 1024}
+	out, err := e.ExecutePlugin(context.Background(), "/bin/sh", script, nil, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if string(out) != `{"isSuccess":true}` {
+		t.Fatalf("unexpected output %q", out)
+	}
+
+	e = DefaultExecutor{MaxOutputSize: 8}
+	if _, err := e.ExecutePlugin(context.Background(), "/bin/sh", script, nil, nil); err == nil {
+		t.Fatal("expected error for output exceeding the limit, got nil")
+	}
+}